@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/textileio/textile-go/core"
+)
+
+func init() {
+	register(&blocklistCmd{})
+}
+
+type blocklistCmd struct {
+	Ls     lsBlocklistCmd  `command:"ls" description:"List blocked and muted peers"`
+	Add    addBlocklistCmd `command:"add" description:"Block or mute a peer"`
+	Remove rmBlocklistCmd  `command:"rm" description:"Unblock or unmute a peer"`
+}
+
+func (x *blocklistCmd) Name() string {
+	return "blocklist"
+}
+
+func (x *blocklistCmd) Short() string {
+	return "Manage blocked and muted peers"
+}
+
+func (x *blocklistCmd) Long() string {
+	return `
+Invites from blocked peers are dropped, their blocks are hidden
+from listings, and their notifications are suppressed.
+Muted peers only have their notifications suppressed.
+`
+}
+
+type lsBlocklistCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
+}
+
+func (x *lsBlocklistCmd) Usage() string {
+	return `
+
+Lists blocked and muted peers.`
+}
+
+func (x *lsBlocklistCmd) Execute(args []string) error {
+	setApi(x.Client)
+	var list []core.BlockedPeerInfo
+	return callLsPages("blocklist", pageOpts(nil, x.Offset, x.Limit), &list)
+}
+
+type addBlocklistCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Mute   bool          `short:"m" long:"mute" description:"Only suppress the peer's notifications."`
+}
+
+func (x *addBlocklistCmd) Usage() string {
+	return `
+
+Blocks a peer.
+
+Include the --mute flag to only suppress the peer's notifications.`
+}
+
+func (x *addBlocklistCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(POST, "blocklist", params{
+		args: []string{args[0]},
+		opts: map[string]string{"mute": strconv.FormatBool(x.Mute)},
+	})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
+
+type rmBlocklistCmd struct {
+	Client ClientOptions `group:"Client Options"`
+}
+
+func (x *rmBlocklistCmd) Usage() string {
+	return `
+
+Removes a peer from the block list.`
+}
+
+func (x *rmBlocklistCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(DEL, "blocklist/"+args[0], params{})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
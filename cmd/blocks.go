@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"bufio"
 	"errors"
-	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/textileio/textile-go/core"
@@ -56,7 +53,7 @@ Use this command to get and list blocks in a thread.
 type lsBlocksCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Thread string        `short:"t" long:"thread" description:"Thread ID. Omit for default."`
-	Offset string        `short:"o" long:"offset" description:"Offset ID to start listing from."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
 	Limit  int           `short:"l" long:"limit" description:"List page size." default:"5"`
 }
 
@@ -83,32 +80,7 @@ func callLsBlocks(opts map[string]string) error {
 	}
 
 	var list []core.BlockInfo
-	res, err := executeJsonCmd(GET, "blocks", params{opts: opts}, &list)
-	if err != nil {
-		return err
-	}
-
-	output(res)
-
-	limit, err := strconv.Atoi(opts["limit"])
-	if err != nil {
-		return err
-	}
-	if len(list) < limit {
-		return nil
-	}
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("next page...")
-	if _, err := reader.ReadString('\n'); err != nil {
-		return err
-	}
-
-	return callLsBlocks(map[string]string{
-		"thread": opts["thread"],
-		"offset": list[len(list)-1].Id,
-		"limit":  opts["limit"],
-	})
+	return callLsPages("blocks", opts, &list)
 }
 
 type getBlocksCmd struct {
@@ -58,6 +58,8 @@ func (x *addCafesCmd) Execute(args []string) error {
 
 type lsCafesCmd struct {
 	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsCafesCmd) Usage() string {
@@ -69,12 +71,7 @@ List info about all active cafe sessions.`
 func (x *lsCafesCmd) Execute(args []string) error {
 	setApi(x.Client)
 	var list []pb.CafeSession
-	res, err := executeJsonCmd(GET, "cafes", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("cafes", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type getCafesCmd struct {
@@ -67,6 +67,8 @@ func (x *addCommentsCmd) Execute(args []string) error {
 type lsCommentsCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Block  string        `required:"true" short:"b" long:"block" description:"Thread block ID. Usually a file(s) block."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsCommentsCmd) Usage() string {
@@ -78,12 +80,7 @@ Lists comments on a thread block.`
 func (x *lsCommentsCmd) Execute(args []string) error {
 	setApi(x.Client)
 	var list []core.ThreadCommentInfo
-	res, err := executeJsonCmd(GET, "blocks/"+x.Block+"/comments", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("blocks/"+x.Block+"/comments", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type getCommentsCmd struct {
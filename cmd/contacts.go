@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"strconv"
 
 	"github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/util"
 )
 
 var errMissingPeerId = errors.New("missing peer id")
@@ -15,10 +18,11 @@ func init() {
 }
 
 type contactsCmd struct {
-	Ls     lsContactsCmd   `command:"ls" description:"List known contacts"`
-	Get    getContactsCmd  `command:"get" description:"Get a known contact"`
-	Remove rmContactsCmd   `command:"rm" description:"Remove a known contact"`
-	Find   findContactsCmd `command:"find" description:"Find contacts"`
+	Ls     lsContactsCmd     `command:"ls" description:"List known contacts"`
+	Get    getContactsCmd    `command:"get" description:"Get a known contact"`
+	Remove rmContactsCmd     `command:"rm" description:"Remove a known contact"`
+	Find   findContactsCmd   `command:"find" description:"Find contacts"`
+	Verify verifyContactsCmd `command:"verify" description:"Verify a known contact's safety number"`
 }
 
 func (x *contactsCmd) Name() string {
@@ -36,6 +40,8 @@ func (x *contactsCmd) Long() string {
 type lsContactsCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Thread string        `short:"t" long:"thread" description:"Thread ID. Omit for all known contacts."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsContactsCmd) Usage() string {
@@ -48,17 +54,11 @@ Include the --thread flag to list contacts for a given thread.`
 
 func (x *lsContactsCmd) Execute(args []string) error {
 	setApi(x.Client)
-	var list []core.ContactInfo
-	res, err := executeJsonCmd(GET, "contacts", params{
-		opts: map[string]string{
-			"thread": x.Thread,
-		},
-	}, &list)
-	if err != nil {
-		return err
+	opts := map[string]string{
+		"thread": x.Thread,
 	}
-	output(res)
-	return nil
+	var list []core.ContactInfo
+	return callLsPages("contacts", pageOpts(opts, x.Offset, x.Limit), &list)
 }
 
 type getContactsCmd struct {
@@ -108,6 +108,56 @@ func (x *rmContactsCmd) Execute(args []string) error {
 	return nil
 }
 
+type verifyContactsCmd struct {
+	Client  ClientOptions `group:"Client Options"`
+	Confirm bool          `long:"confirm" description:"Mark the contact verified after comparing safety numbers."`
+	Number  string        `short:"n" long:"number" description:"A safety number or scanned QR code to check before confirming."`
+	Reset   bool          `long:"reset" description:"Clear the contact's verified flag."`
+}
+
+func (x *verifyContactsCmd) Usage() string {
+	return `
+
+Shows the safety number shared with a known contact.
+
+Compare the number with the contact out-of-band (in person,
+over a call, or by scanning the QR code payload), then use
+the --confirm flag to mark the contact verified.
+Include the --number flag to have the number checked first.
+Use the --reset flag to clear a previous verification.`
+}
+
+func (x *verifyContactsCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	path := "verify/" + args[0]
+
+	if x.Reset {
+		res, err := executeStringCmd(DEL, path, params{})
+		if err != nil {
+			return err
+		}
+		output(res)
+		return nil
+	}
+
+	method := GET
+	if x.Confirm {
+		method = POST
+	}
+	var info core.ContactVerification
+	res, err := executeJsonCmd(method, path, params{
+		opts: map[string]string{"number": x.Number},
+	}, &info)
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
+
 type findContactsCmd struct {
 	Client   ClientOptions `group:"Client Options"`
 	Username string        `short:"u" long:"username" description:"A username to use in the search."`
@@ -116,12 +166,16 @@ type findContactsCmd struct {
 	Local    bool          `long:"local" description:"Only search local contacts."`
 	Limit    int           `long:"limit" description:"Stops searching after limit results are found." default:"5"`
 	Wait     int           `long:"wait" description:"Stops searching after 'wait' seconds have elapsed." default:"5"`
+	Stream   bool          `long:"stream" description:"Output results from each source as they arrive."`
 }
 
 func (x *findContactsCmd) Usage() string {
 	return `
 
-Finds contacts known locally and on the network.`
+Finds contacts known locally and on the network.
+
+Include the --stream flag to output results from local contacts
+and each cafe as they arrive, instead of waiting for all of them.`
 }
 
 func (x *findContactsCmd) Execute(args []string) error {
@@ -129,20 +183,57 @@ func (x *findContactsCmd) Execute(args []string) error {
 	if x.Username == "" && x.Peer == "" && x.Address == "" {
 		return errMissingSearchInfo
 	}
+	opts := map[string]string{
+		"username": x.Username,
+		"peer":     x.Peer,
+		"address":  x.Address,
+		"local":    strconv.FormatBool(x.Local),
+		"limit":    strconv.Itoa(x.Limit),
+		"wait":     strconv.Itoa(x.Wait),
+	}
+
+	if x.Stream {
+		opts["stream"] = "true"
+		return streamContactResults(opts)
+	}
+
 	var infos core.ContactInfoQueryResult
-	res, err := executeJsonCmd(POST, "contacts/search", params{
-		opts: map[string]string{
-			"username": x.Username,
-			"peer":     x.Peer,
-			"address":  x.Address,
-			"local":    strconv.FormatBool(x.Local),
-			"limit":    strconv.Itoa(x.Limit),
-			"wait":     strconv.Itoa(x.Wait),
-		},
-	}, &infos)
+	res, err := executeJsonCmd(POST, "contacts/search", params{opts: opts}, &infos)
 	if err != nil {
 		return err
 	}
 	output(res)
 	return nil
 }
+
+func streamContactResults(opts map[string]string) error {
+	req, err := request(POST, "contacts/search", params{opts: opts})
+	if err != nil {
+		return err
+	}
+	defer req.Body.Close()
+
+	if req.StatusCode >= 400 {
+		res, err := util.UnmarshalString(req.Body)
+		if err != nil {
+			return err
+		}
+		return errors.New(res)
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	for {
+		var update core.ContactInfoQueryUpdate
+		if err := decoder.Decode(&update); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(update, "", "    ")
+		if err != nil {
+			return err
+		}
+		output(string(data))
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"github.com/textileio/textile-go/core"
+)
+
+func init() {
+	register(&devicesCmd{})
+}
+
+type devicesCmd struct {
+	Ls     lsDevicesCmd   `command:"ls" description:"List account devices and link requests"`
+	Link   linkDevicesCmd `command:"link" description:"Request to link this device"`
+	Add    addDevicesCmd  `command:"add" description:"Approve a device link"`
+	Remove rmDevicesCmd   `command:"rm" description:"Remove a device"`
+}
+
+func (x *devicesCmd) Name() string {
+	return "devices"
+}
+
+func (x *devicesCmd) Short() string {
+	return "Manage account devices"
+}
+
+func (x *devicesCmd) Long() string {
+	return `
+Devices sharing a wallet seed are linked through the account thread.
+A new device requests a link from an existing device, which then
+approves it. Removing a device rotates the account thread key.
+`
+}
+
+type lsDevicesCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
+}
+
+func (x *lsDevicesCmd) Usage() string {
+	return `
+
+Lists account devices, including pending link requests.`
+}
+
+func (x *lsDevicesCmd) Execute(args []string) error {
+	setApi(x.Client)
+	var list []core.DeviceInfo
+	return callLsPages("devices", pageOpts(nil, x.Offset, x.Limit), &list)
+}
+
+type linkDevicesCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Name   string        `short:"n" long:"name" description:"A name for this device."`
+}
+
+func (x *linkDevicesCmd) Usage() string {
+	return `
+
+Sends a link request to an existing device of this account.
+The existing device must approve the request with 'devices add'.`
+}
+
+func (x *linkDevicesCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(POST, "devices/link", params{
+		args: []string{args[0]},
+		opts: map[string]string{"name": x.Name},
+	})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
+
+type addDevicesCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Name   string        `short:"n" long:"name" description:"A name for the device. Defaults to the requested name."`
+}
+
+func (x *addDevicesCmd) Usage() string {
+	return `
+
+Approves a device link request, adding the device to the account thread.`
+}
+
+func (x *addDevicesCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(POST, "devices", params{
+		args: []string{args[0]},
+		opts: map[string]string{"name": x.Name},
+	})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
+
+type rmDevicesCmd struct {
+	Client ClientOptions `group:"Client Options"`
+}
+
+func (x *rmDevicesCmd) Usage() string {
+	return `
+
+Removes a device (or declines a link request).
+The account thread key is rotated and re-shared with the remaining devices.`
+}
+
+func (x *rmDevicesCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(DEL, "devices/"+args[0], params{})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/textileio/textile-go/core"
+)
+
+func init() {
+	register(&dmCmd{})
+}
+
+type dmCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Offset string        `long:"offset" description:"Cursor to start listing direct threads from, the next value of a previous page."`
+	Limit  int           `long:"limit" description:"Direct threads list page size. Omit to list all."`
+}
+
+func (x *dmCmd) Name() string {
+	return "dm"
+}
+
+func (x *dmCmd) Short() string {
+	return "Send direct messages to a contact"
+}
+
+func (x *dmCmd) Long() string {
+	return `
+Direct messages are sent over a private two-party thread,
+which is found or created for the given contact's peer ID.
+The contact is invited automatically, either directly or via
+their cafe inbox(es).
+
+Omit the message to just find or create the thread.
+Omit all arguments to list direct threads.
+`
+}
+
+func (x *dmCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		var list []core.DirectThreadInfo
+		return callLsPages("dms", pageOpts(nil, x.Offset, x.Limit), &list)
+	}
+
+	var info *core.ThreadInfo
+	res, err := executeJsonCmd(POST, "dms", params{
+		args: []string{args[0]},
+	}, &info)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		output(res)
+		return nil
+	}
+
+	res, err = callAddMessages(info.Id, args[1])
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -470,7 +469,7 @@ func multipartReader(f *os.File) (io.ReadSeeker, string, error) {
 type lsCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Thread string        `short:"t" long:"thread" description:"Thread ID. Omit for all."`
-	Offset string        `short:"o" long:"offset" description:"Offset ID to start listing from."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
 	Limit  int           `short:"l" long:"limit" description:"List page size." default:"5"`
 }
 
@@ -502,32 +501,7 @@ func (x *lsCmd) Execute(args []string) error {
 
 func callLs(opts map[string]string) error {
 	var list []core.ThreadFilesInfo
-	res, err := executeJsonCmd(GET, "files", params{opts: opts}, &list)
-	if err != nil {
-		return err
-	}
-
-	output(res)
-
-	limit, err := strconv.Atoi(opts["limit"])
-	if err != nil {
-		return err
-	}
-	if len(list) < limit {
-		return nil
-	}
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("next page...")
-	if _, err := reader.ReadString('\n'); err != nil {
-		return err
-	}
-
-	return callLs(map[string]string{
-		"thread": opts["thread"],
-		"offset": list[len(list)-1].Block,
-		"limit":  opts["limit"],
-	})
+	return callLsPages("files", opts, &list)
 }
 
 type getCmd struct {
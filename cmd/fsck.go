@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/textileio/textile-go/core"
+)
+
+// FsckCmd checks (and optionally repairs) the datastore of a running daemon.
+// It's exported so that it can be nested under the offline 'repo' command.
+type FsckCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Repair bool          `long:"repair" description:"Repair the problems which can be fixed."`
+}
+
+func (x *FsckCmd) Usage() string {
+	return `
+
+Cross-checks the datastore tables against each other and against IPFS,
+e.g., blocks and peers of removed threads, file targets pointing at
+ignored blocks, notifications for missing blocks, unresolvable thread
+heads, and unpinned files whose mill requires pinning.
+Use --repair to fix the problems which can be fixed.
+The daemon must be running.
+`
+}
+
+func (x *FsckCmd) Execute(args []string) error {
+	setApi(x.Client)
+	method := GET
+	if x.Repair {
+		method = POST
+	}
+	var report core.FsckReport
+	res, err := executeJsonCmd(method, "fsck", params{}, &report)
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
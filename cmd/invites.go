@@ -77,6 +77,8 @@ func (x *createInvitesCmd) Execute(args []string) error {
 
 type lsInvitesCmd struct {
 	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsInvitesCmd) Usage() string {
@@ -88,12 +90,7 @@ Lists all pending thread invites.`
 func (x *lsInvitesCmd) Execute(_ []string) error {
 	setApi(x.Client)
 	var list []core.ThreadInviteInfo
-	res, err := executeJsonCmd(GET, "invites", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("invites", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type acceptInvitesCmd struct {
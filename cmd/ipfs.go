@@ -1,12 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
 	"strconv"
 
-	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/util"
 )
 
@@ -73,6 +73,8 @@ type swarmPeersCmd struct {
 	Streams   bool          `short:"s" long:"streams" description:"Also list information about open streams for each peer."`
 	Latency   bool          `short:"l" long:"latency" description:"Also list information about latency to each peer."`
 	Direction bool          `short:"d" long:"direction" description:"Also list information about the direction of connection."`
+	Offset    string        `long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit     int           `long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *swarmPeersCmd) Usage() string {
@@ -83,20 +85,14 @@ Lists the set of peers this node is connected to.`
 
 func (x *swarmPeersCmd) Execute(args []string) error {
 	setApi(x.Client)
-	var info *ipfs.ConnInfos
-	res, err := executeJsonCmd(GET, "swarm/peers", params{
-		opts: map[string]string{
-			"verbose":   strconv.FormatBool(x.Verbose),
-			"streams":   strconv.FormatBool(x.Streams),
-			"latency":   strconv.FormatBool(x.Latency),
-			"direction": strconv.FormatBool(x.Direction),
-		},
-	}, &info)
-	if err != nil {
-		return err
+	opts := map[string]string{
+		"verbose":   strconv.FormatBool(x.Verbose),
+		"streams":   strconv.FormatBool(x.Streams),
+		"latency":   strconv.FormatBool(x.Latency),
+		"direction": strconv.FormatBool(x.Direction),
 	}
-	output(res)
-	return nil
+	var list []json.RawMessage
+	return callLsPages("swarm/peers", pageOpts(opts, x.Offset, x.Limit), &list)
 }
 
 type ipfsCatCmd struct {
@@ -60,6 +60,8 @@ func (x *addLikesCmd) Execute(args []string) error {
 type lsLikesCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Block  string        `required:"true" short:"b" long:"block" description:"Thread block ID. Usually a file(s) block."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsLikesCmd) Usage() string {
@@ -71,12 +73,7 @@ Lists likes on a thread block.`
 func (x *lsLikesCmd) Execute(args []string) error {
 	setApi(x.Client)
 	var list []core.ThreadLikeInfo
-	res, err := executeJsonCmd(GET, "blocks/"+x.Block+"/likes", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("blocks/"+x.Block+"/likes", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type getLikesCmd struct {
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/textileio/textile-go/service"
+)
+
+func init() {
+	register(&limitsCmd{})
+}
+
+type limitsCmd struct {
+	Ls    lsLimitsCmd `command:"ls" description:"Show inbound message limit counters and bans"`
+	Unban unbanCmd    `command:"unban" description:"Lift a peer's temporary ban"`
+}
+
+func (x *limitsCmd) Name() string {
+	return "limits"
+}
+
+func (x *limitsCmd) Short() string {
+	return "Inspect inbound message limits"
+}
+
+func (x *limitsCmd) Long() string {
+	return `
+Inbound service messages are rate limited per peer and message type.
+Peers which repeatedly send messages that fail verification are
+temporarily banned. Limits are set in the Limits section of the config.
+`
+}
+
+type lsLimitsCmd struct {
+	Client ClientOptions `group:"Client Options"`
+}
+
+func (x *lsLimitsCmd) Usage() string {
+	return `
+
+Shows the number of messages dropped by rate limits (per message type),
+the number of verify failures and bans, and the currently banned peers.`
+}
+
+func (x *lsLimitsCmd) Execute(args []string) error {
+	setApi(x.Client)
+	var stats service.LimiterStats
+	res, err := executeJsonCmd(GET, "limits", params{}, &stats)
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
+
+type unbanCmd struct {
+	Client ClientOptions `group:"Client Options"`
+}
+
+func (x *unbanCmd) Usage() string {
+	return `
+
+Lifts a peer's temporary ban.`
+}
+
+func (x *unbanCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingPeerId
+	}
+	res, err := executeStringCmd(DEL, "limits/bans/"+args[0], params{})
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
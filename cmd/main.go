@@ -1,16 +1,20 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
 
+	"github.com/textileio/textile-go/core"
 	"github.com/textileio/textile-go/util"
 )
 
@@ -132,3 +136,43 @@ func request(meth method, pth string, pars params) (*http.Response, error) {
 func output(value interface{}) {
 	fmt.Println(value)
 }
+
+// pageOpts adds list page options to opts,
+// a limit of zero lists all items
+func pageOpts(opts map[string]string, offset string, limit int) map[string]string {
+	if opts == nil {
+		opts = make(map[string]string)
+	}
+	if offset != "" {
+		opts["offset"] = offset
+	}
+	if limit != 0 {
+		opts["limit"] = strconv.Itoa(limit)
+	}
+	return opts
+}
+
+// callLsPages outputs a page of list items, then prompts for each following page.
+// list should be a pointer to a slice of the expected item type.
+func callLsPages(pth string, opts map[string]string, list interface{}) error {
+	page := &core.ListPage{Items: list}
+	res, err := executeJsonCmd(GET, pth, params{opts: opts}, page)
+	if err != nil {
+		return err
+	}
+
+	output(res)
+
+	if page.Next == "" {
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("next page...")
+	if _, err := reader.ReadString('\n'); err != nil {
+		return err
+	}
+
+	opts["offset"] = page.Next
+	return callLsPages(pth, opts, list)
+}
@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"bufio"
 	"errors"
-	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/textileio/textile-go/core"
@@ -84,7 +81,7 @@ func callAddMessages(threadId string, body string) (string, error) {
 type lsMessagesCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Thread string        `short:"t" long:"thread" description:"Thread ID. Omit for all."`
-	Offset string        `short:"o" long:"offset" description:"Offset ID to start listing from."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
 	Limit  int           `short:"l" long:"limit" description:"List page size." default:"10"`
 }
 
@@ -109,32 +106,7 @@ func (x *lsMessagesCmd) Execute(args []string) error {
 
 func callLsMessages(opts map[string]string) error {
 	var list []core.ThreadMessageInfo
-	res, err := executeJsonCmd(GET, "messages", params{opts: opts}, &list)
-	if err != nil {
-		return err
-	}
-
-	output(res)
-
-	limit, err := strconv.Atoi(opts["limit"])
-	if err != nil {
-		return err
-	}
-	if len(list) < limit {
-		return nil
-	}
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("next page...")
-	if _, err := reader.ReadString('\n'); err != nil {
-		return err
-	}
-
-	return callLs(map[string]string{
-		"thread": opts["thread"],
-		"offset": list[len(list)-1].Id,
-		"limit":  opts["limit"],
-	})
+	return callLsPages("messages", opts, &list)
 }
 
 type getMessagesCmd struct {
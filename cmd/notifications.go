@@ -34,6 +34,8 @@ Use this command to list, get, and mark notifications as read.
 
 type lsNotificationsCmd struct {
 	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsNotificationsCmd) Usage() string {
@@ -45,12 +47,7 @@ Lists notifications.`
 func (x *lsNotificationsCmd) Execute(args []string) error {
 	setApi(x.Client)
 	var list []core.NotificationInfo
-	res, err := executeJsonCmd(GET, "notifications", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("notifications", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type readNotificationsCmd struct {
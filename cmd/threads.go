@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"io"
 	"io/ioutil"
 	"os"
 
@@ -11,9 +12,11 @@ import (
 	"github.com/textileio/textile-go/core"
 	"github.com/textileio/textile-go/repo"
 	"github.com/textileio/textile-go/schema/textile"
+	"github.com/textileio/textile-go/util"
 )
 
 var errMissingThreadId = errors.New("missing thread id")
+var errMissingArchivePath = errors.New("missing archive path")
 
 func init() {
 	register(&threadsCmd{})
@@ -26,6 +29,8 @@ type threadsCmd struct {
 	GetDefault getDefaultThreadsCmd `command:"default" description:"Get default thread"`
 	Peers      peersThreadsCmd      `command:"peers" description:"List thread peers"`
 	Remove     rmThreadsCmd         `command:"rm" description:"Remove a thread"`
+	Export     exportThreadsCmd     `command:"export" description:"Export a thread to an archive"`
+	Import     importThreadsCmd     `command:"import" description:"Import a thread from an archive"`
 }
 
 func (x *threadsCmd) Name() string {
@@ -147,6 +152,8 @@ func callAddThreads(args []string, opts map[string]string) error {
 
 type lsThreadsCmd struct {
 	Client ClientOptions `group:"Client Options"`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *lsThreadsCmd) Usage() string {
@@ -158,12 +165,7 @@ Lists info on all threads.`
 func (x *lsThreadsCmd) Execute(args []string) error {
 	setApi(x.Client)
 	var list []core.ThreadInfo
-	res, err := executeJsonCmd(GET, "threads", params{}, &list)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	return callLsPages("threads", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type getThreadsCmd struct {
@@ -214,6 +216,8 @@ func (x *getDefaultThreadsCmd) Execute(args []string) error {
 type peersThreadsCmd struct {
 	Client ClientOptions `group:"Client Options"`
 	Thread string        `short:"t" long:"thread" description:"Thread ID. Omit for default."`
+	Offset string        `short:"o" long:"offset" description:"Cursor to start listing from, the next value of a previous page."`
+	Limit  int           `short:"l" long:"limit" description:"List page size. Omit to list all."`
 }
 
 func (x *peersThreadsCmd) Usage() string {
@@ -229,13 +233,8 @@ func (x *peersThreadsCmd) Execute(args []string) error {
 	if x.Thread == "" {
 		x.Thread = "default"
 	}
-	var result []core.ContactInfo
-	res, err := executeJsonCmd(GET, "threads/"+x.Thread+"/peers", params{}, &result)
-	if err != nil {
-		return err
-	}
-	output(res)
-	return nil
+	var list []core.ThreadPeerInfo
+	return callLsPages("threads/"+x.Thread+"/peers", pageOpts(nil, x.Offset, x.Limit), &list)
 }
 
 type rmThreadsCmd struct {
@@ -260,3 +259,88 @@ func (x *rmThreadsCmd) Execute(args []string) error {
 	output(res)
 	return nil
 }
+
+type exportThreadsCmd struct {
+	Client ClientOptions `group:"Client Options"`
+	Output string        `short:"o" long:"output" description:"Path to write the archive to."`
+}
+
+func (x *exportThreadsCmd) Usage() string {
+	return `
+
+Exports a thread's blocks, files, and schema to a portable archive,
+which can be imported by another node with 'threads import'.
+The archive contains the thread key, so anyone with the archive
+can read (and write to) the thread.`
+}
+
+func (x *exportThreadsCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingThreadId
+	}
+	if x.Output == "" {
+		return errMissingArchivePath
+	}
+
+	req, err := request(GET, "archives/"+args[0], params{})
+	if err != nil {
+		return err
+	}
+	defer req.Body.Close()
+	if req.StatusCode >= 400 {
+		res, err := util.UnmarshalString(req.Body)
+		if err != nil {
+			return err
+		}
+		return errors.New(res)
+	}
+
+	f, err := os.Create(x.Output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, req.Body); err != nil {
+		return err
+	}
+	output("wrote thread archive to " + x.Output)
+	return nil
+}
+
+type importThreadsCmd struct {
+	Client ClientOptions `group:"Client Options"`
+}
+
+func (x *importThreadsCmd) Usage() string {
+	return `
+
+Imports a thread from an archive created with 'threads export'.`
+}
+
+func (x *importThreadsCmd) Execute(args []string) error {
+	setApi(x.Client)
+	if len(args) == 0 {
+		return errMissingArchivePath
+	}
+	path, err := homedir.Expand(args[0])
+	if err != nil {
+		path = args[0]
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var info *core.ThreadInfo
+	res, err := executeJsonCmd(POST, "archives", params{
+		payload: f,
+		ctype:   "application/octet-stream",
+	}, &info)
+	if err != nil {
+		return err
+	}
+	output(res)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -69,6 +70,9 @@ func (a *api) Start() {
 	router.GET("/health", func(g *gin.Context) {
 		g.Writer.WriteHeader(http.StatusNoContent)
 	})
+	router.GET("/metrics", a.metrics)
+	router.GET("/debug/traces", a.lsTraces)
+	router.GET("/debug/traces/:id", a.getTraces)
 
 	// middleware
 	conf := a.node.Config()
@@ -86,6 +90,12 @@ func (a *api) Start() {
 		v0.GET("/address", a.address)
 		v0.GET("/ping", a.ping)
 
+		fsck := v0.Group("/fsck")
+		{
+			fsck.GET("", a.checkRepo)
+			fsck.POST("", a.repairRepo)
+		}
+
 		profile := v0.Group("/profile")
 		{
 			profile.GET("", a.getProfile)
@@ -113,6 +123,18 @@ func (a *api) Start() {
 			threads.POST("/:id/files", a.addThreadFiles)
 		}
 
+		archives := v0.Group("/archives")
+		{
+			archives.GET("/:id", a.exportThreads)
+			archives.POST("", a.importThreads)
+		}
+
+		dms := v0.Group("/dms")
+		{
+			dms.POST("", a.addDirectThreads)
+			dms.GET("", a.lsDirectThreads)
+		}
+
 		blocks := v0.Group("/blocks")
 		{
 			blocks.GET("", a.lsBlocks)
@@ -198,6 +220,34 @@ func (a *api) Start() {
 			contacts.POST("/search", a.searchContacts)
 		}
 
+		verify := v0.Group("/verify")
+		{
+			verify.GET("/:id", a.getContactsVerification)
+			verify.POST("/:id", a.verifyContacts)
+			verify.DELETE("/:id", a.unverifyContacts)
+		}
+
+		blocklist := v0.Group("/blocklist")
+		{
+			blocklist.GET("", a.lsBlockedPeers)
+			blocklist.POST("", a.addBlockedPeers)
+			blocklist.DELETE("/:id", a.rmBlockedPeers)
+		}
+
+		limits := v0.Group("/limits")
+		{
+			limits.GET("", a.lsLimits)
+			limits.DELETE("/bans/:id", a.rmBans)
+		}
+
+		devices := v0.Group("/devices")
+		{
+			devices.GET("", a.lsDevices)
+			devices.POST("", a.addDevices)
+			devices.POST("/link", a.linkDevices)
+			devices.DELETE("/:id", a.rmDevices)
+		}
+
 		ipfs := v0.Group("/ipfs")
 		{
 			ipfs.GET("/:cid", a.ipfsCat)
@@ -331,6 +381,19 @@ func (a *api) readOpts(g *gin.Context) (map[string]string, error) {
 	return opts, nil
 }
 
+// readPage returns the list cursor (offset) and limit options
+func (a *api) readPage(opts map[string]string, defaultLimit int) (string, int, error) {
+	limit := defaultLimit
+	if opts["limit"] != "" {
+		var err error
+		limit, err = strconv.Atoi(opts["limit"])
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	return opts["offset"], limit, nil
+}
+
 func (a *api) openFile(g *gin.Context) (multipart.File, string, error) {
 	form, err := g.MultipartForm()
 	if err != nil {
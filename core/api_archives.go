@@ -0,0 +1,47 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) exportThreads(g *gin.Context) {
+	// the archive is streamed, errors can only be reported before the first write
+	g.Header("Content-Type", "application/octet-stream")
+	g.Status(http.StatusOK)
+	if err := a.node.ExportThread(g.Param("id"), g.Writer); err != nil {
+		if g.Writer.Written() {
+			log.Errorf("error streaming thread archive: %s", err)
+			return
+		}
+		switch err {
+		case ErrThreadNotFound:
+			g.String(http.StatusNotFound, err.Error())
+		default:
+			a.abort500(g, err)
+		}
+	}
+}
+
+func (a *api) importThreads(g *gin.Context) {
+	thrd, err := a.node.ImportThread(g.Request.Body)
+	if err != nil {
+		switch err {
+		case ErrInvalidThreadArchive:
+			g.String(http.StatusBadRequest, err.Error())
+		case ErrThreadExists:
+			g.String(http.StatusConflict, err.Error())
+		default:
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	info, err := thrd.Info()
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	g.JSON(http.StatusCreated, info)
+}
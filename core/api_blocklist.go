@@ -0,0 +1,68 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) lsBlockedPeers(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	infos := a.node.BlockedPeers()
+	start, end, next, err := PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: infos[start:end], Next: next})
+}
+
+func (a *api) addBlockedPeers(g *gin.Context) {
+	args, err := a.readArgs(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	if len(args) == 0 {
+		g.String(http.StatusBadRequest, "missing peer id")
+		return
+	}
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	if err := a.node.BlockPeer(args[0], opts["mute"] == "true"); err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.String(http.StatusCreated, "ok")
+}
+
+func (a *api) rmBlockedPeers(g *gin.Context) {
+	id := g.Param("id")
+
+	if err := a.node.UnblockPeer(id); err != nil {
+		if err == ErrPeerNotBlocked {
+			g.String(http.StatusNotFound, err.Error())
+		} else {
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	g.String(http.StatusOK, "ok")
+}
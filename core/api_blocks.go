@@ -3,7 +3,6 @@ package core
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,18 +29,22 @@ func (a *api) lsBlocks(g *gin.Context) {
 		return
 	}
 
-	limit := 5
-	if opts["limit"] != "" {
-		limit, err = strconv.Atoi(opts["limit"])
-		if err != nil {
-			g.String(http.StatusBadRequest, err.Error())
-			return
-		}
+	cursor, limit, err := a.readPage(opts, 5)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
 	infos := make([]BlockInfo, 0)
 	query := fmt.Sprintf("threadId='%s'", thrd.Id)
-	for _, block := range a.node.datastore.Blocks().List(opts["offset"], limit, query) {
+	blocks := a.node.datastore.Blocks().List(offset, storeLimit(limit), withoutBlocked(query))
+	_, end, next, _ := PageBounds(len(blocks), func(i int) string { return blocks[i].Id }, "", limit)
+	for _, block := range blocks[:end] {
 		username, avatar := a.node.ContactDisplayInfo(block.AuthorId)
 
 		infos = append(infos, BlockInfo{
@@ -58,7 +61,7 @@ func (a *api) lsBlocks(g *gin.Context) {
 		})
 	}
 
-	g.JSON(http.StatusOK, infos)
+	g.JSON(http.StatusOK, &ListPage{Items: infos, Next: next})
 }
 
 func (a *api) getBlocks(g *gin.Context) {
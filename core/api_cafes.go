@@ -26,6 +26,17 @@ func (a *api) addCafes(g *gin.Context) {
 }
 
 func (a *api) lsCafes(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	sessions, err := a.node.CafeSessions()
 	if err != nil {
 		a.abort500(g, err)
@@ -34,7 +45,14 @@ func (a *api) lsCafes(g *gin.Context) {
 	if len(sessions) == 0 {
 		sessions = make([]*pb.CafeSession, 0)
 	}
-	g.JSON(http.StatusOK, sessions)
+
+	start, end, next, err := PageBounds(len(sessions), func(i int) string { return sessions[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: sessions[start:end], Next: next})
 }
 
 func (a *api) getCafes(g *gin.Context) {
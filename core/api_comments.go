@@ -46,6 +46,17 @@ func (a *api) addBlockComments(g *gin.Context) {
 }
 
 func (a *api) lsBlockComments(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id := g.Param("id")
 
 	comments, err := a.node.ThreadComments(id)
@@ -57,7 +68,13 @@ func (a *api) lsBlockComments(g *gin.Context) {
 		comments = make([]ThreadCommentInfo, 0)
 	}
 
-	g.JSON(http.StatusOK, comments)
+	start, end, next, err := PageBounds(len(comments), func(i int) string { return comments[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: comments[start:end], Next: next})
 }
 
 func (a *api) getBlockComment(g *gin.Context) {
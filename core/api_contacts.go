@@ -1,6 +1,8 @@
 package core
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -13,6 +15,11 @@ func (a *api) lsContacts(g *gin.Context) {
 		a.abort500(g, err)
 		return
 	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var contacts []ContactInfo
 
@@ -35,9 +42,19 @@ func (a *api) lsContacts(g *gin.Context) {
 		}
 	} else {
 		contacts, err = a.node.Contacts()
+		if err != nil {
+			a.abort500(g, err)
+			return
+		}
+	}
+
+	start, end, next, err := PageBounds(len(contacts), func(i int) string { return contacts[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
-	g.JSON(http.StatusOK, contacts)
+	g.JSON(http.StatusOK, &ListPage{Items: contacts[start:end], Next: next})
 }
 
 func (a *api) getContacts(g *gin.Context) {
@@ -69,6 +86,60 @@ func (a *api) rmContacts(g *gin.Context) {
 	g.String(http.StatusOK, "ok")
 }
 
+func (a *api) getContactsVerification(g *gin.Context) {
+	id := g.Param("id")
+
+	info, err := a.node.ContactVerification(id)
+	if err != nil {
+		g.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, info)
+}
+
+func (a *api) verifyContacts(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	id := g.Param("id")
+
+	if err := a.node.VerifyContact(id, opts["number"]); err != nil {
+		switch err {
+		case ErrContactNotFound:
+			g.String(http.StatusNotFound, err.Error())
+		default:
+			g.String(http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	info, err := a.node.ContactVerification(id)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	g.JSON(http.StatusOK, info)
+}
+
+func (a *api) unverifyContacts(g *gin.Context) {
+	id := g.Param("id")
+
+	if err := a.node.UnverifyContact(id); err != nil {
+		if err == ErrContactNotFound {
+			g.String(http.StatusNotFound, err.Error())
+		} else {
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	g.String(http.StatusOK, "ok")
+}
+
 func (a *api) searchContacts(g *gin.Context) {
 	opts, err := a.readOpts(g)
 	if err != nil {
@@ -97,11 +168,31 @@ func (a *api) searchContacts(g *gin.Context) {
 		Wait:     wait,
 	}
 
-	infos, err := a.node.FindContact(query)
-	if err != nil {
-		g.String(http.StatusBadRequest, err.Error())
+	if opts["stream"] != "true" && opts["events"] != "true" {
+		infos, err := a.node.FindContact(query)
+		if err != nil {
+			g.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		g.JSON(http.StatusOK, infos)
 		return
 	}
 
-	g.JSON(http.StatusOK, infos)
+	// stream results from each source as they arrive
+	updates := a.node.FindContactStream(g.Request.Context(), query)
+	g.Stream(func(w io.Writer) bool {
+		update, ok := <-updates
+		if !ok {
+			return false
+		}
+		if opts["events"] == "true" {
+			g.SSEvent("update", update)
+		} else {
+			if err := json.NewEncoder(w).Encode(update); err != nil {
+				return false
+			}
+		}
+		return true
+	})
 }
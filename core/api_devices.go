@@ -0,0 +1,105 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) lsDevices(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	infos := a.node.Devices()
+	start, end, next, err := PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: infos[start:end], Next: next})
+}
+
+func (a *api) addDevices(g *gin.Context) {
+	args, err := a.readArgs(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	if len(args) == 0 {
+		g.String(http.StatusBadRequest, "missing peer id")
+		return
+	}
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	if err := a.node.AddDevice(args[0], opts["name"]); err != nil {
+		switch err {
+		case ErrDeviceSelf:
+			g.String(http.StatusBadRequest, err.Error())
+		default:
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	g.String(http.StatusCreated, "ok")
+}
+
+func (a *api) linkDevices(g *gin.Context) {
+	args, err := a.readArgs(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	if len(args) == 0 {
+		g.String(http.StatusBadRequest, "missing peer id")
+		return
+	}
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	if err := a.node.RequestDeviceLink(args[0], opts["name"]); err != nil {
+		switch err {
+		case ErrDeviceSelf:
+			g.String(http.StatusBadRequest, err.Error())
+		default:
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	g.String(http.StatusOK, "ok")
+}
+
+func (a *api) rmDevices(g *gin.Context) {
+	id := g.Param("id")
+
+	if err := a.node.RemoveDevice(id); err != nil {
+		switch err {
+		case ErrDeviceNotFound:
+			g.String(http.StatusNotFound, err.Error())
+		case ErrDeviceSelf:
+			g.String(http.StatusBadRequest, err.Error())
+		default:
+			a.abort500(g, err)
+		}
+		return
+	}
+
+	g.String(http.StatusOK, "ok")
+}
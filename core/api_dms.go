@@ -0,0 +1,64 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) addDirectThreads(g *gin.Context) {
+	args, err := a.readArgs(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	if len(args) == 0 {
+		g.String(http.StatusBadRequest, "missing peer id")
+		return
+	}
+
+	thrd, err := a.node.DirectThread(args[0])
+	if err != nil {
+		switch err {
+		case ErrContactNotFound:
+			g.String(http.StatusNotFound, err.Error())
+		default:
+			g.String(http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	info, err := thrd.Info()
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	g.JSON(http.StatusCreated, info)
+}
+
+func (a *api) lsDirectThreads(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	infos, err := a.node.DirectThreads()
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+
+	start, end, next, err := PageBounds(len(infos), func(i int) string { return infos[i].Thread.Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: infos[start:end], Next: next})
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"net/http"
-	"strconv"
 
 	ipld "gx/ipfs/QmR7TcHkR9nxkUorfi8XMTAMLUK7GiP64TWWBzY3aacc1o/go-ipld-format"
 
@@ -101,22 +100,19 @@ func (a *api) lsThreadFiles(g *gin.Context) {
 		}
 	}
 
-	limit := 5
-	if opts["limit"] != "" {
-		limit, err = strconv.Atoi(opts["limit"])
-		if err != nil {
-			g.String(http.StatusBadRequest, err.Error())
-			return
-		}
+	offset, limit, err := a.readPage(opts, 5)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
-	list, err := a.node.ThreadFiles(opts["offset"], limit, threadId)
+	page, err := a.node.ThreadFiles(offset, limit, threadId)
 	if err != nil {
 		g.String(http.StatusBadRequest, err.Error())
 		return
 	}
 
-	g.JSON(http.StatusOK, list)
+	g.JSON(http.StatusOK, page)
 }
 
 func (a *api) getThreadFiles(g *gin.Context) {
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) checkRepo(g *gin.Context) {
+	a.fsck(g, false)
+}
+
+func (a *api) repairRepo(g *gin.Context) {
+	a.fsck(g, true)
+}
+
+func (a *api) fsck(g *gin.Context, repair bool) {
+	report, err := a.node.Fsck(repair)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, report)
+}
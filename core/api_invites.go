@@ -57,13 +57,30 @@ func (a *api) createInvites(g *gin.Context) {
 }
 
 func (a *api) lsInvites(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	list := make([]ThreadInviteInfo, 0)
 	res := a.node.ThreadInvites()
 	if len(res) > 0 {
 		list = res
 	}
 
-	g.JSON(http.StatusOK, list)
+	start, end, next, err := PageBounds(len(list), func(i int) string { return list[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: list[start:end], Next: next})
 }
 
 func (a *api) acceptInvites(g *gin.Context) {
@@ -40,13 +40,25 @@ func (a *api) swarmPeers(g *gin.Context) {
 	streams := opts["streams"] == "true"
 	direction := opts["direction"] == "true"
 
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	res, err := ipfs.SwarmPeers(a.node.node, verbose, latency, streams, direction)
 	if err != nil {
 		g.String(http.StatusBadRequest, err.Error())
 		return
 	}
 
-	g.JSON(http.StatusOK, res)
+	start, end, next, err := PageBounds(len(res.Peers), func(i int) string { return res.Peers[i].Peer }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: res.Peers[start:end], Next: next})
 }
 
 func (a *api) ipfsCat(g *gin.Context) {
@@ -36,6 +36,17 @@ func (a *api) addBlockLikes(g *gin.Context) {
 }
 
 func (a *api) lsBlockLikes(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id := g.Param("id")
 
 	likes, err := a.node.ThreadLikes(id)
@@ -47,7 +58,13 @@ func (a *api) lsBlockLikes(g *gin.Context) {
 		likes = make([]ThreadLikeInfo, 0)
 	}
 
-	g.JSON(http.StatusOK, likes)
+	start, end, next, err := PageBounds(len(likes), func(i int) string { return likes[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: likes[start:end], Next: next})
 }
 
 func (a *api) getBlockLike(g *gin.Context) {
@@ -0,0 +1,20 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *api) lsLimits(g *gin.Context) {
+	g.JSON(http.StatusOK, a.node.Limits())
+}
+
+func (a *api) rmBans(g *gin.Context) {
+	id := g.Param("id")
+	if err := a.node.Unban(id); err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	g.String(http.StatusOK, "ok")
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -62,22 +61,19 @@ func (a *api) lsThreadMessages(g *gin.Context) {
 		}
 	}
 
-	limit := 10
-	if opts["limit"] != "" {
-		limit, err = strconv.Atoi(opts["limit"])
-		if err != nil {
-			g.String(http.StatusBadRequest, err.Error())
-			return
-		}
+	offset, limit, err := a.readPage(opts, 10)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
-	list, err := a.node.ThreadMessages(opts["offset"], limit, threadId)
+	page, err := a.node.ThreadMessages(offset, limit, threadId)
 	if err != nil {
 		g.String(http.StatusBadRequest, err.Error())
 		return
 	}
 
-	g.JSON(http.StatusOK, list)
+	g.JSON(http.StatusOK, page)
 }
 
 func (a *api) getThreadMessages(g *gin.Context) {
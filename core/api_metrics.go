@@ -0,0 +1,19 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/textileio/textile-go/metrics"
+)
+
+// metrics writes datastore and outbox metrics in the Prometheus text format
+func (a *api) metrics(g *gin.Context) {
+	a.node.observeOutboxes()
+
+	g.Writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	g.Writer.WriteHeader(http.StatusOK)
+	if err := metrics.WriteText(g.Writer); err != nil {
+		log.Errorf("error writing metrics: %s", err)
+	}
+}
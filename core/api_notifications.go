@@ -7,8 +7,24 @@ import (
 )
 
 func (a *api) lsNotifications(g *gin.Context) {
-	list := a.node.Notifications("", -1)
-	g.JSON(http.StatusOK, list)
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := a.node.Notifications(offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, page)
 }
 
 func (a *api) readNotifications(g *gin.Context) {
@@ -80,6 +80,17 @@ func (a *api) addThreads(g *gin.Context) {
 }
 
 func (a *api) lsThreads(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	infos := make([]*ThreadInfo, 0)
 	for _, thrd := range a.node.Threads() {
 		info, err := thrd.Info()
@@ -90,7 +101,13 @@ func (a *api) lsThreads(g *gin.Context) {
 		infos = append(infos, info)
 	}
 
-	g.JSON(http.StatusOK, infos)
+	start, end, next, err := PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: infos[start:end], Next: next})
 }
 
 func (a *api) getThreads(g *gin.Context) {
@@ -114,6 +131,17 @@ func (a *api) getThreads(g *gin.Context) {
 }
 
 func (a *api) peersThreads(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, -1)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id := g.Param("id")
 	if id == "default" {
 		id = a.node.config.Threads.Defaults.ID
@@ -125,15 +153,27 @@ func (a *api) peersThreads(g *gin.Context) {
 		return
 	}
 
-	contacts := make([]ContactInfo, 0)
+	peers := make([]ThreadPeerInfo, 0)
 	for _, p := range thrd.Peers() {
 		contact := a.node.Contact(p.Id)
-		if contact != nil {
-			contacts = append(contacts, *contact)
+		if contact == nil {
+			continue
+		}
+		info := ThreadPeerInfo{ContactInfo: *contact, Addrs: p.Addrs}
+		if !p.LastSeen.IsZero() {
+			seen := p.LastSeen
+			info.LastSeen = &seen
 		}
+		peers = append(peers, info)
+	}
+
+	start, end, next, err := PageBounds(len(peers), func(i int) string { return peers[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
-	g.JSON(http.StatusOK, contacts)
+	g.JSON(http.StatusOK, &ListPage{Items: peers[start:end], Next: next})
 }
 
 func (a *api) rmThreads(g *gin.Context) {
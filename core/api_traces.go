@@ -0,0 +1,43 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/textileio/textile-go/service"
+)
+
+// defaultTraceLimit is the number of traces listed when no limit is given
+const defaultTraceLimit = 50
+
+func (a *api) lsTraces(g *gin.Context) {
+	opts, err := a.readOpts(g)
+	if err != nil {
+		a.abort500(g, err)
+		return
+	}
+	offset, limit, err := a.readPage(opts, defaultTraceLimit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	traces := service.Traces(-1)
+	start, end, next, err := PageBounds(len(traces), func(i int) string { return traces[i].Id }, offset, limit)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, &ListPage{Items: traces[start:end], Next: next})
+}
+
+func (a *api) getTraces(g *gin.Context) {
+	trace := service.GetTrace(g.Param("id"))
+	if trace == nil {
+		g.String(http.StatusNotFound, "trace not found")
+		return
+	}
+
+	g.JSON(http.StatusOK, trace)
+}
@@ -0,0 +1,456 @@
+package core
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/repo/fsrepo"
+
+	"github.com/textileio/textile-go/crypto"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrInvalidBackup indicates a backup archive is malformed or could not be decrypted
+var ErrInvalidBackup = errors.New("invalid backup archive")
+
+// ErrBackupAccountMismatch indicates a backup archive belongs to a different account
+var ErrBackupAccountMismatch = errors.New("backup archive belongs to a different account")
+
+// ErrBackupNodeRunning indicates a backup was attempted while the node is running
+var ErrBackupNodeRunning = errors.New("node must be stopped to back up its repo")
+
+// backupMagic starts every backup archive
+const backupMagic = "textile-backup/1\n"
+
+// backupManifestName is the archive entry describing the backup
+const backupManifestName = "manifest.json"
+
+// backupDatastoreName is the repo path of the datastore, which is backed up from a snapshot
+var backupDatastoreName = filepath.Join("datastore", "mainnet.db")
+
+// backupExcludes lists repo paths which are never backed up
+var backupExcludes = []string{
+	"logs",
+	"backups",
+	"tmp",
+	fsrepo.LockFile,
+	filepath.Join("datastore", "LOCK"),
+}
+
+// BackupManifest describes a backup archive
+type BackupManifest struct {
+	Address    string    `json:"address"`
+	PeerId     string    `json:"peer_id"`
+	Repover    int       `json:"repover"`
+	PinnedOnly bool      `json:"pinned_only,omitempty"`
+	Date       time.Time `json:"date"`
+}
+
+// BackupConfig is used to back up a repo which is not in use by a running node
+type BackupConfig struct {
+	RepoPath   string
+	PinCode    string
+	Writer     io.Writer
+	PinnedOnly bool
+}
+
+// RestoreConfig is used to restore a repo from a backup archive
+type RestoreConfig struct {
+	Account  *keypair.Full
+	RepoPath string
+	Reader   io.Reader
+}
+
+// BackupRepo writes an archive of a repo which is not in use by a running node
+func BackupRepo(conf BackupConfig) error {
+	if err := checkRepoUnlocked(conf.RepoPath); err != nil {
+		return err
+	}
+	node, err := NewTextile(RunConfig{
+		RepoPath: conf.RepoPath,
+		PinCode:  conf.PinCode,
+	})
+	if err != nil {
+		return err
+	}
+	defer node.datastore.Close()
+	return node.Backup(conf.Writer, conf.PinnedOnly)
+}
+
+// Backup writes an archive of the repo (datastore, ipfs repo, config, and blocks)
+// encrypted with a key derived from the account seed. The archive is streamed to w.
+// If pinnedOnly is true, blocks which are not pinned are left out.
+// The ipfs leveldb and flatfs can't be copied consistently while they're written to,
+// so the node must not be started. An offline ipfs node holds the repo lock meanwhile.
+func (t *Textile) Backup(w io.Writer, pinnedOnly bool) error {
+	if t.started {
+		return ErrBackupNodeRunning
+	}
+	repover, err := repo.Version(t.repoPath)
+	if err != nil {
+		return err
+	}
+
+	if err := t.createIPFS(false); err != nil {
+		return err
+	}
+	defer func() {
+		t.cancel()
+		if err := t.node.Close(); err != nil {
+			log.Errorf("error closing ipfs node: %s", err)
+		}
+		t.node = nil
+	}()
+
+	var keep map[string]struct{}
+	if pinnedOnly {
+		keep, err = t.pinnedBlocks()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the datastore is copied under its lock
+	tmp := filepath.Join(t.repoPath, "tmp")
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	snapshot := filepath.Join(tmp, fmt.Sprintf("backup-%d.db", time.Now().UnixNano()))
+	if err := t.datastore.Export(snapshot); err != nil {
+		return err
+	}
+	defer os.Remove(snapshot)
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, backupMagic+t.account.Address()+"\n"); err != nil {
+		return err
+	}
+	if _, err := w.Write(nonce); err != nil {
+		return err
+	}
+	cw, err := crypto.NewAESWriter(w, backupKey(t.account, nonce))
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(cw)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.Marshal(&BackupManifest{
+		Address:    t.account.Address(),
+		PeerId:     t.node.Identity.Pretty(),
+		Repover:    repover,
+		PinnedOnly: pinnedOnly,
+		Date:       time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    backupManifestName,
+		Mode:    0600,
+		Size:    int64(len(manifest)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	err = filepath.Walk(t.repoPath, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.repoPath, pth)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		for _, ex := range backupExcludes {
+			if rel == ex {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+		if strings.HasPrefix(rel, backupDatastoreName) {
+			// the datastore and its wal files are replaced by the snapshot
+			return nil
+		}
+		if keep != nil && strings.HasPrefix(rel, "blocks"+string(filepath.Separator)) && !keepBlockFile(info.Name(), keep) {
+			return nil
+		}
+		return addBackupFile(tw, pth, filepath.ToSlash(filepath.Join("repo", rel)), info)
+	})
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := addBackupFile(tw, snapshot, filepath.ToSlash(filepath.Join("repo", backupDatastoreName)), info); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("backed up repo at version %d (pinned only: %t)", repover, pinnedOnly)
+
+	return nil
+}
+
+// RestoreRepo restores a repo from a backup archive. The archive is streamed into a
+// temporary directory and fully authenticated against the account before anything on
+// disk is replaced. A repo in use by a running node is never replaced.
+func RestoreRepo(conf RestoreConfig) error {
+	if conf.Account == nil {
+		return ErrAccountRequired
+	}
+	if err := checkRepoUnlocked(conf.RepoPath); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conf.Reader)
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != backupMagic {
+		return ErrInvalidBackup
+	}
+	address, err := reader.ReadString('\n')
+	if err != nil {
+		return ErrInvalidBackup
+	}
+	if strings.TrimSpace(address) != conf.Account.Address() {
+		return ErrBackupAccountMismatch
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(reader, nonce); err != nil {
+		return ErrInvalidBackup
+	}
+	cr, err := crypto.NewAESReader(reader, backupKey(conf.Account, nonce))
+	if err != nil {
+		return err
+	}
+
+	// unpack next to the repo, then swap it in
+	tmp, err := ioutil.TempDir(filepath.Dir(filepath.Clean(conf.RepoPath)), ".textile-restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, err := extractBackup(cr, tmp)
+	if err != nil {
+		return err
+	}
+	// make sure the stream was not truncated after the archive
+	if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+		return ErrInvalidBackup
+	}
+	if manifest.Address != conf.Account.Address() {
+		return ErrBackupAccountMismatch
+	}
+
+	// a node may have been started while unpacking
+	if err := checkRepoUnlocked(conf.RepoPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(conf.RepoPath, 0755); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(filepath.Join(tmp, "repo"))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		dst := filepath.Join(conf.RepoPath, entry.Name())
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(tmp, "repo", entry.Name()), dst); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("restored repo at version %d for %s", manifest.Repover, manifest.Address)
+
+	return nil
+}
+
+// checkRepoUnlocked returns an error if the repo is locked by a running node
+func checkRepoUnlocked(repoPath string) error {
+	locked, err := fsrepo.LockedByOtherProcess(repoPath)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return repo.ErrRepoLocked
+	}
+	return nil
+}
+
+// pinnedBlocks collects the keys of all blocks reachable from pins
+func (t *Textile) pinnedBlocks() (map[string]struct{}, error) {
+	keep := make(map[string]struct{})
+
+	var roots []cid.Cid
+	roots = append(roots, t.node.Pinning.RecursiveKeys()...)
+	roots = append(roots, t.node.Pinning.InternalPins()...)
+	for _, id := range roots {
+		if err := t.walkBlocks(id, keep); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range t.node.Pinning.DirectKeys() {
+		keep[id.KeyString()] = struct{}{}
+	}
+	return keep, nil
+}
+
+// walkBlocks adds a block and all of its descendants to set
+func (t *Textile) walkBlocks(id cid.Cid, set map[string]struct{}) error {
+	if _, ok := set[id.KeyString()]; ok {
+		return nil
+	}
+	set[id.KeyString()] = struct{}{}
+
+	node, err := ipfs.NodeAtCid(t.node, id)
+	if err != nil {
+		return err
+	}
+	for _, link := range node.Links() {
+		if err := t.walkBlocks(link.Cid, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepBlockFile returns whether or not a flatfs block file is in the keep set.
+// Files which can't be mapped back to a cid are kept.
+func keepBlockFile(name string, keep map[string]struct{}) bool {
+	if !strings.HasSuffix(name, ".data") {
+		return true
+	}
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimSuffix(name, ".data"))
+	if err != nil {
+		return true
+	}
+	id, err := cid.Cast(raw)
+	if err != nil {
+		return true
+	}
+	_, ok := keep[id.KeyString()]
+	return ok
+}
+
+// backupKey derives an AES key from the account seed and a nonce
+func backupKey(account *keypair.Full, nonce []byte) []byte {
+	sum := sha256.Sum256([]byte("textile-backup:" + account.Seed()))
+	return append(sum[:], nonce...)
+}
+
+// addBackupFile writes a single file into the archive
+func addBackupFile(tw *tar.Writer, pth string, name string, info os.FileInfo) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	f, err := os.Open(pth)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// extractBackup unpacks a decrypted archive into dir, returning its manifest
+func extractBackup(archive io.Reader, dir string) (*BackupManifest, error) {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, ErrInvalidBackup
+	}
+	defer gz.Close()
+
+	var manifest *BackupManifest
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidBackup
+		}
+
+		if hdr.Name == backupManifestName {
+			manifest = new(BackupManifest)
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, ErrInvalidBackup
+			}
+			continue
+		}
+
+		// guard against entries escaping the target dir
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Join(dir, "repo")+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid backup entry: %s", hdr.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.Close()
+	}
+
+	if manifest == nil {
+		return nil, ErrInvalidBackup
+	}
+	return manifest, nil
+}
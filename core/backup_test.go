@@ -0,0 +1,163 @@
+package core_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"testing"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+
+	"github.com/segmentio/ksuid"
+	. "github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/repo"
+)
+
+var backupRepoPath = "testdata/.textile-backup"
+var restoreRepoPath = "testdata/.textile-restore"
+var backupAccount *keypair.Full
+var backupThreadId string
+var backupArchive bytes.Buffer
+
+func TestTextile_Backup(t *testing.T) {
+	os.RemoveAll(backupRepoPath)
+	backupAccount = keypair.Random()
+	if err := InitRepo(InitConfig{
+		Account:  backupAccount,
+		RepoPath: backupRepoPath,
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+	node, err := NewTextile(RunConfig{
+		RepoPath: backupRepoPath,
+	})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thrd, err := node.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "backup",
+		Initiator: backupAccount.Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatalf("add thread failed: %s", err)
+	}
+	if _, err := thrd.AddMessage("hello backup"); err != nil {
+		t.Fatalf("add message failed: %s", err)
+	}
+	backupThreadId = thrd.Id
+
+	// a running node's ipfs repo can't be copied consistently
+	if err := node.Backup(&backupArchive, false); err != ErrBackupNodeRunning {
+		t.Fatalf("expected node running error, got %v", err)
+	}
+	if err := BackupRepo(BackupConfig{
+		RepoPath: backupRepoPath,
+		Writer:   &backupArchive,
+	}); err != repo.ErrRepoLocked {
+		t.Fatalf("expected repo locked error, got %v", err)
+	}
+	if err := node.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := BackupRepo(BackupConfig{
+		RepoPath: backupRepoPath,
+		Writer:   &backupArchive,
+	}); err != nil {
+		t.Fatalf("backup failed: %s", err)
+	}
+	if backupArchive.Len() == 0 {
+		t.Fatal("backup archive is empty")
+	}
+}
+
+func TestRestoreRepo_WrongAccount(t *testing.T) {
+	err := RestoreRepo(RestoreConfig{
+		Account:  keypair.Random(),
+		RepoPath: restoreRepoPath,
+		Reader:   bytes.NewReader(backupArchive.Bytes()),
+	})
+	if err != ErrBackupAccountMismatch {
+		t.Errorf("expected account mismatch, got %v", err)
+	}
+}
+
+func TestRestoreRepo_Truncated(t *testing.T) {
+	data := backupArchive.Bytes()
+	err := RestoreRepo(RestoreConfig{
+		Account:  backupAccount,
+		RepoPath: restoreRepoPath,
+		Reader:   bytes.NewReader(data[:len(data)-1]),
+	})
+	if err == nil {
+		t.Error("truncated backup should not restore")
+	}
+	if _, err := os.Stat(restoreRepoPath); !os.IsNotExist(err) {
+		t.Error("truncated backup should not touch the repo path")
+	}
+}
+
+func TestRestoreRepo(t *testing.T) {
+	os.RemoveAll(restoreRepoPath)
+	if err := RestoreRepo(RestoreConfig{
+		Account:  backupAccount,
+		RepoPath: restoreRepoPath,
+		Reader:   bytes.NewReader(backupArchive.Bytes()),
+	}); err != nil {
+		t.Fatalf("restore failed: %s", err)
+	}
+
+	node, err := NewTextile(RunConfig{
+		RepoPath: restoreRepoPath,
+	})
+	if err != nil {
+		t.Fatalf("create restored node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start restored node failed: %s", err)
+	}
+	defer node.Stop()
+
+	// a running node's repo should not be replaced
+	if err := RestoreRepo(RestoreConfig{
+		Account:  backupAccount,
+		RepoPath: restoreRepoPath,
+		Reader:   bytes.NewReader(backupArchive.Bytes()),
+	}); err != repo.ErrRepoLocked {
+		t.Errorf("expected repo locked error, got %v", err)
+	}
+
+	if node.Account().Address() != backupAccount.Address() {
+		t.Error("restored account does not match")
+	}
+	thrd := node.Thread(backupThreadId)
+	if thrd == nil {
+		t.Fatal("restored thread not found")
+	}
+	page, err := node.ThreadMessages("", -1, thrd.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := page.Items.([]ThreadMessageInfo)
+	if len(msgs) != 1 || msgs[0].Body != "hello backup" {
+		t.Error("restored thread messages do not match")
+	}
+}
+
+func TestBackup_Teardown(t *testing.T) {
+	os.RemoveAll(backupRepoPath)
+	os.RemoveAll(restoreRepoPath)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrBlockSelf indicates a peer tried to block itself
+var ErrBlockSelf = errors.New("cannot block self")
+
+// ErrPeerNotBlocked indicates a peer is not on the block list
+var ErrPeerNotBlocked = errors.New("peer not blocked")
+
+// BlockedPeerInfo displays info about a blocked or muted peer
+type BlockedPeerInfo struct {
+	Id       string    `json:"id"`
+	Username string    `json:"username,omitempty"`
+	Avatar   string    `json:"avatar,omitempty"`
+	Muted    bool      `json:"muted,omitempty"`
+	Date     time.Time `json:"date"`
+}
+
+// BlockPeer adds a peer to the block list.
+// Invites from blocked peers are dropped, their blocks are hidden from listings,
+// and their notifications are suppressed. Muted peers only have their notifications suppressed.
+func (t *Textile) BlockPeer(id string, muted bool) error {
+	if id == t.node.Identity.Pretty() {
+		return ErrBlockSelf
+	}
+	if _, err := peer.IDB58Decode(id); err != nil {
+		return err
+	}
+	return t.datastore.BlockedPeers().AddOrUpdate(&repo.BlockedPeer{
+		Id:    id,
+		Muted: muted,
+		Date:  time.Now(),
+	})
+}
+
+// UnblockPeer removes a peer from the block list
+func (t *Textile) UnblockPeer(id string) error {
+	if t.datastore.BlockedPeers().Get(id) == nil {
+		return ErrPeerNotBlocked
+	}
+	return t.datastore.BlockedPeers().Delete(id)
+}
+
+// BlockedPeers lists all blocked and muted peers
+func (t *Textile) BlockedPeers() []BlockedPeerInfo {
+	infos := make([]BlockedPeerInfo, 0)
+	for _, b := range t.datastore.BlockedPeers().List() {
+		username, avatar := t.ContactDisplayInfo(b.Id)
+		infos = append(infos, BlockedPeerInfo{
+			Id:       b.Id,
+			Username: username,
+			Avatar:   avatar,
+			Muted:    b.Muted,
+			Date:     b.Date,
+		})
+	}
+	return infos
+}
+
+// refuseInboxMessage returns whether or not an inbox message from a peer should be dropped
+func (t *Textile) refuseInboxMessage(id string) bool {
+	return t.config.Cafe.Client.RefuseBlocked && isBlocked(t.datastore, id)
+}
+
+// isBlocked returns whether or not a peer is blocked (muted peers are not)
+func isBlocked(datastore repo.Datastore, id string) bool {
+	b := datastore.BlockedPeers().Get(id)
+	return b != nil && !b.Muted
+}
+
+// isMuted returns whether or not a peer's notifications are suppressed
+func isMuted(datastore repo.Datastore, id string) bool {
+	return datastore.BlockedPeers().Get(id) != nil
+}
+
+// withoutBlocked extends a block query to exclude blocks authored by blocked peers
+func withoutBlocked(query string) string {
+	clause := "authorId not in (select id from blocked_peers where muted=0)"
+	if query == "" {
+		return clause
+	}
+	return query + " and " + clause
+}
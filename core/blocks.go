@@ -9,18 +9,9 @@ import (
 // ErrBlockNotFound indicates a block was not found in the index
 var ErrBlockNotFound = errors.New("block not found")
 
-// GetBlocks paginates blocks
+// GetBlocks paginates blocks, skipping ignored blocks
 func (t *Textile) Blocks(offset string, limit int, query string) []repo.Block {
-	var filtered []repo.Block
-
-	for _, block := range t.datastore.Blocks().List(offset, limit, query) {
-		ignored := t.datastore.Blocks().List("", -1, "target='ignore-"+block.Id+"'")
-		if len(ignored) == 0 {
-			filtered = append(filtered, block)
-		}
-	}
-
-	return filtered
+	return t.datastore.Blocks().List(offset, limit, withoutIgnored(withoutBlocked(query)))
 }
 
 // Block returns block with id
@@ -34,7 +25,7 @@ func (t *Textile) Block(id string) (*repo.Block, error) {
 
 // BlocksByTarget returns block with parent
 func (t *Textile) BlocksByTarget(target string) []repo.Block {
-	return t.datastore.Blocks().List("", -1, "target='"+target+"'")
+	return t.datastore.Blocks().List("", -1, withoutBlocked("target='"+target+"'"))
 }
 
 // BlockInfo returns block info with id
@@ -59,3 +50,13 @@ func (t *Textile) BlockInfo(id string) (*BlockInfo, error) {
 		Body:     block.Body,
 	}, nil
 }
+
+// withoutIgnored adds a clause which skips ignored blocks to a block query,
+// filtering in the query keeps page sizes intact
+func withoutIgnored(query string) string {
+	clause := "id not in (select substr(target, 8) from blocks where target like 'ignore-%')"
+	if query == "" {
+		return clause
+	}
+	return query + " and " + clause
+}
@@ -5,7 +5,6 @@ import (
 	"compress/gzip"
 	"context"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
@@ -17,17 +16,22 @@ import (
 	njwt "github.com/dgrijalva/jwt-go"
 	limit "github.com/gin-contrib/size"
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/render"
-	"github.com/golang/protobuf/proto"
 	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/jwt"
 	"github.com/textileio/textile-go/pb"
 	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/service"
 )
 
 // cafeApiVersion is the cafe api version
 const cafeApiVersion = "v0"
 
+// cafeServiceOverhead is room for envelope fields around an object sent to the cafe service
+const cafeServiceOverhead = 64 << 10
+
+// threadsServiceMaxSize is the max size of a thread envelope held for a client
+const threadsServiceMaxSize = 4 << 20
+
 // cafeApiHost is the instance used by the core instance
 var cafeApiHost *cafeApi
 
@@ -88,6 +92,7 @@ func (c *cafeApi) start() {
 	{
 		v0.POST("/pin", c.pin)
 		v0.POST("/service", c.service)
+		v0.POST("/service/threads/:client", c.threadsService)
 	}
 	c.server = &http.Server{
 		Addr:    c.addr,
@@ -240,63 +245,25 @@ func (c *cafeApi) pin(g *gin.Context) {
 
 // service is an HTTP entry point for the cafe service
 func (c *cafeApi) service(g *gin.Context) {
-	if !c.node.Online() {
-		g.String(http.StatusInternalServerError, "node is offline")
-		return
-	}
-
-	body, err := ioutil.ReadAll(g.Request.Body)
-	if err != nil {
-		g.String(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	// parse body as a service envelope
-	pmes := new(pb.Envelope)
-	if err := proto.Unmarshal(body, pmes); err != nil {
-		g.String(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	peerId := g.Request.Header.Get("X-Textile-Peer")
-	if peerId == "" {
-		g.String(http.StatusBadRequest, "missing peer ID")
-		return
-	}
-	mPeer, err := peer.IDB58Decode(peerId)
-	if err != nil {
-		g.String(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := c.node.cafe.service.VerifyEnvelope(pmes, mPeer); err != nil {
-		g.String(http.StatusBadRequest, err.Error())
-	}
-
-	// handle the message as normal
-	log.Debugf("received %s from %s", pmes.Message.Type.String(), mPeer.Pretty())
-	rpmes, err := c.node.cafe.Handle(mPeer, pmes)
-	if err != nil {
-		g.String(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if rpmes == nil {
-		g.Status(200)
-		return
-	}
-
-	// send out response msg
-	log.Debugf("responding with %s to %s", rpmes.Message.Type.String(), mPeer.Pretty())
+	maxSize := c.node.cafe.maxObjectSize() + cafeServiceOverhead
+	serveServiceHTTP(g, c.node, c.node.cafe.service, maxSize, c.node.cafe.Handle)
+}
 
-	res, err := proto.Marshal(rpmes)
-	if err != nil {
-		g.String(http.StatusBadRequest, err.Error())
+// threadsService is an HTTP entry point for thread envelopes sent to a registered client,
+// which are held in its inbox. Envelopes for anyone else are refused.
+func (c *cafeApi) threadsService(g *gin.Context) {
+	client := c.node.datastore.CafeClients().Get(g.Param("client"))
+	if client == nil {
+		g.String(http.StatusNotFound, "client not found")
 		return
 	}
-
-	// ship it
-	g.Render(200, render.Data{Data: res})
+	serveServiceHTTP(g, c.node, c.node.threads.service, threadsServiceMaxSize,
+		func(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
+			if env.Message.Type != pb.Message_THREAD_ENVELOPE {
+				return nil, service.ErrUnsupportedMessage
+			}
+			return nil, c.node.cafe.deliverEnvelope(pid, client, env)
+		})
 }
 
 // validToken aborts the request if the token is invalid
@@ -26,6 +26,7 @@ type CafeInbox struct {
 	threadsService func() *ThreadsService
 	node           func() *core.IpfsNode
 	datastore      repo.Datastore
+	refuse         func(peerId string) bool
 	checking       bool
 	mux            sync.Mutex
 }
@@ -36,12 +37,14 @@ func NewCafeInbox(
 	threadsService func() *ThreadsService,
 	node func() *core.IpfsNode,
 	datastore repo.Datastore,
+	refuse func(peerId string) bool,
 ) *CafeInbox {
 	return &CafeInbox{
 		service:        service,
 		threadsService: threadsService,
 		node:           node,
 		datastore:      datastore,
+		refuse:         refuse,
 	}
 }
 
@@ -85,6 +88,10 @@ func (q *CafeInbox) CheckMessages() error {
 // Add adds an inbound message
 func (q *CafeInbox) Add(msg *pb.CafeMessage) error {
 	log.Debugf("received cafe message from %s: %s", ipfs.ShortenID(msg.PeerId), msg.Id)
+	if q.refuse(msg.PeerId) {
+		log.Debugf("refused cafe message from blocked peer %s: %s", ipfs.ShortenID(msg.PeerId), msg.Id)
+		return nil
+	}
 	date, err := ptypes.Timestamp(msg.Date)
 	if err != nil {
 		return err
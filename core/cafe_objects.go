@@ -0,0 +1,440 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	cid "gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/pin"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+	protocol "gx/ipfs/QmZNkThpqfVXs9GNbexPrfBbXSLNYeKrE7jwFM2oqHbyqN/go-libp2p-protocol"
+	uio "gx/ipfs/QmfB3oNXGGq9S4B2a9YeCajoATms3Zw2VvDm8fK7VeLSV8/go-unixfs/io"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+)
+
+// cafeObjectChunksProtocol is the first cafe protocol which accepts chunked objects
+const cafeObjectChunksProtocol = protocol.ID("/textile/cafe/1.2.0")
+
+// cafeObjectChunksCapability is advertised by cafes which accept chunked objects
+const cafeObjectChunksCapability = "object-chunks"
+
+// objectChunkSize is the max size of a single object chunk
+const objectChunkSize = 256 * 1024
+
+// objectChunkAttempts is the number of times a chunk is sent before giving up
+const objectChunkAttempts = 5
+
+// objectRetryLimit is the number of sends beyond one per chunk allowed for a whole object
+const objectRetryLimit = 20
+
+// objectChunkTimeout is the timeout for sending a single chunk over HTTP
+const objectChunkTimeout = time.Second * 30
+
+// objectChunkBackoff is the delay before the first retry, doubled after each failure
+const objectChunkBackoff = time.Second
+
+// objectUploadExpiry is how long an incomplete upload is kept without progress
+const objectUploadExpiry = time.Hour * 24
+
+// objectMaxSize is the max declared size of a chunked object if the cafe has no size limit
+const objectMaxSize = 1 << 30
+
+// objectUploadsPerClient is the max number of incomplete uploads a client can have open
+const objectUploadsPerClient = 8
+
+// chunked object validation errors
+const (
+	errObjectTooLarge = "object too large"
+	errObjectMismatch = "object does not match cid"
+	errObjectUploads  = "too many open uploads"
+)
+
+// errTooManyUploads indicates a client has too many incomplete uploads to start another
+var errTooManyUploads = errors.New(errObjectUploads)
+
+// finalObjectError returns whether or not a cafe error can't be fixed by resending
+func finalObjectError(err error) bool {
+	switch err.Error() {
+	case errObjectTooLarge, errObjectMismatch, errObjectUploads:
+		return true
+	}
+	return false
+}
+
+// maxObjectSize returns the max declared size of a chunked object
+func (h *CafeService) maxObjectSize() int64 {
+	if h.sizeLimit > 0 {
+		return h.sizeLimit
+	}
+	return objectMaxSize
+}
+
+// supportsObjectChunks returns whether or not a cafe is known to accept chunked objects.
+// Capabilities are used if exchanged over libp2p, otherwise the session protocol.
+func (h *CafeService) supportsObjectChunks(cafe peer.ID, info *pb.Cafe) bool {
+	if h.service.Supports(cafe, cafeObjectChunksCapability) {
+		return true
+	}
+	for _, pt := range cafeServiceProtocols {
+		if string(pt) == info.Protocol {
+			return true
+		}
+		if pt == cafeObjectChunksProtocol {
+			break
+		}
+	}
+	return false
+}
+
+// openObject returns a reader for the raw data of a cid, or the raw node if it is not a file,
+// along with its size. File data is read from the dag as needed.
+func (h *CafeService) openObject(id cid.Cid) (io.ReadSeeker, int64, bool, error) {
+	reader, err := ipfs.DataReaderAtCid(h.service.Node(), id)
+	if err == nil {
+		return reader, int64(reader.Size()), false, nil
+	}
+	if err != uio.ErrIsDir {
+		return nil, 0, false, err
+	}
+	data, err := ipfs.GetObjectAtPath(h.service.Node(), id.Hash().B58String())
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return bytes.NewReader(data), int64(len(data)), true, nil
+}
+
+// sendObjectChunks sends an object by cid to a cafe in chunks.
+// The cafe keeps partial uploads, so a transfer interrupted by a bad link
+// resumes from the last acknowledged offset. Rejected objects are not resent, and
+// the total number of sends is capped so a cafe which keeps resetting can't hold
+// the outbox forever.
+func (h *CafeService) sendObjectChunks(id cid.Cid, cafe peer.ID, addr string, token string) error {
+	reader, size, node, err := h.openObject(id)
+	if err != nil {
+		return err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	buf := make([]byte, objectChunkSize)
+
+	// larger objects start with an empty chunk to learn where to resume
+	probe := size > objectChunkSize
+	var offset int64
+	var attempts, sends int
+	limit := int(size/objectChunkSize) + 1 + objectRetryLimit
+	backoff := objectChunkBackoff
+	for {
+		if sends >= limit {
+			return fmt.Errorf("gave up sending %s after %d chunks", id.Hash().B58String(), sends)
+		}
+		sends++
+
+		end := offset + objectChunkSize
+		if probe {
+			end = offset
+		}
+		if end > size {
+			end = size
+		}
+		data := buf[:end-offset]
+		if err := readObjectChunk(reader, offset, data); err != nil {
+			return err
+		}
+
+		ack, err := h.sendObjectChunk(cafe, addr, &pb.CafeObjectChunk{
+			Token:  token,
+			Cid:    id.Hash().B58String(),
+			Node:   node,
+			Size:   size,
+			Offset: offset,
+			Data:   data,
+		})
+		if err == nil && ack.Offset == offset && end > offset && !ack.Stored {
+			err = fmt.Errorf("no progress at offset %d", offset)
+		}
+		if err != nil {
+			if finalObjectError(err) {
+				return err
+			}
+			attempts++
+			if attempts >= objectChunkAttempts {
+				return err
+			}
+			log.Debugf("error sending chunk of %s at offset %d (attempt %d): %s",
+				id.Hash().B58String(), offset, attempts, err)
+			time.Sleep(backoff)
+			backoff *= 2
+
+			// the cafe may have received the chunk, ask where to resume
+			probe = true
+			continue
+		}
+		if ack.Stored {
+			return nil
+		}
+		if ack.Offset < 0 || ack.Offset > size {
+			return fmt.Errorf("invalid chunk offset %d for %s", ack.Offset, id.Hash().B58String())
+		}
+
+		offset = ack.Offset
+		probe = false
+		attempts = 0
+		backoff = objectChunkBackoff
+	}
+}
+
+// readObjectChunk fills data from an object starting at offset
+func readObjectChunk(reader io.ReadSeeker, offset int64, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(reader, data)
+	return err
+}
+
+// sendObjectChunk sends a chunk over libp2p if the cafe is connected, otherwise over HTTP
+func (h *CafeService) sendObjectChunk(cafe peer.ID, addr string, chunk *pb.CafeObjectChunk) (*pb.CafeObjectChunkAck, error) {
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_OBJECT_CHUNK, chunk, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var renv *pb.Envelope
+	if h.service.Node().PeerHost.Network().Connectedness(cafe) == inet.Connected {
+		renv, err = h.service.SendRequest(cafe, env)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), objectChunkTimeout)
+		renv, err = h.service.SendHTTPRequestContext(ctx, addr, env)
+		cancel()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ack := new(pb.CafeObjectChunkAck)
+	if err := ptypes.UnmarshalAny(renv.Message.Payload, ack); err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// handleObjectChunk receives an object chunk request
+func (h *CafeService) handleObjectChunk(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
+	chunk := new(pb.CafeObjectChunk)
+	if err := ptypes.UnmarshalAny(env.Message.Payload, chunk); err != nil {
+		return nil, err
+	}
+
+	rerr, err := h.authToken(pid, chunk.Token, false, env)
+	if err != nil {
+		return nil, err
+	}
+	if rerr != nil {
+		return rerr, nil
+	}
+
+	id, err := cid.Decode(chunk.Cid)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.Size > h.maxObjectSize() {
+		return h.service.NewError(413, errObjectTooLarge, env)
+	}
+	if chunk.Offset < 0 || chunk.Offset+int64(len(chunk.Data)) > chunk.Size {
+		return nil, fmt.Errorf("chunk out of range for %s", chunk.Cid)
+	}
+	key := pid.Pretty() + "-" + id.Hash().B58String()
+
+	ack := &pb.CafeObjectChunkAck{Cid: chunk.Cid}
+	respond := func() (*pb.Envelope, error) {
+		return h.service.NewResponse(pb.Message_CAFE_OBJECT_CHUNK_ACK, ack, env)
+	}
+
+	// a retried final chunk may arrive after the object was pinned
+	if !h.uploads.exists(key) {
+		pinned, err := h.service.Node().Pinning.CheckIfPinned(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinned) > 0 && pinned[0].Mode != pin.NotPinned {
+			ack.Offset = chunk.Size
+			ack.Stored = true
+			return respond()
+		}
+	}
+
+	ack.Offset, err = h.uploads.write(pid.Pretty(), key, chunk)
+	if err == errTooManyUploads {
+		return h.service.NewError(429, errObjectUploads, env)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ack.Offset < chunk.Size {
+		return respond()
+	}
+
+	// the object is complete, add it and only pin it if it resolves to the declared cid
+	file, err := h.uploads.open(key)
+	if err != nil {
+		return nil, err
+	}
+	var aid *cid.Cid
+	if chunk.Node {
+		aid, err = ipfs.AddObject(h.service.Node(), file, false)
+	} else {
+		aid, err = ipfs.AddData(h.service.Node(), file, false)
+	}
+	file.Close()
+	h.uploads.remove(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if aid.Hash().B58String() != id.Hash().B58String() {
+		log.Warningf("cids do not match (received %s, resolved %s)", chunk.Cid, aid.Hash().B58String())
+		return h.service.NewError(400, errObjectMismatch, env)
+	}
+	node, err := ipfs.NodeAtCid(h.service.Node(), *aid)
+	if err != nil {
+		return nil, err
+	}
+	if err := ipfs.PinNode(h.service.Node(), node, false); err != nil {
+		return nil, err
+	}
+	log.Debugf("pinned chunked object %s", chunk.Cid)
+
+	ack.Stored = true
+	return respond()
+}
+
+// objectUploads holds incomplete chunked uploads on disk
+type objectUploads struct {
+	dir string
+	mux sync.Mutex
+}
+
+// newObjectUploads returns uploads stored in dir
+func newObjectUploads(dir string) *objectUploads {
+	return &objectUploads{dir: dir}
+}
+
+// exists returns whether or not an upload has been started
+func (u *objectUploads) exists(key string) bool {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+	_, err := os.Stat(u.path(key))
+	return err == nil
+}
+
+// write appends a chunk to an upload if it starts at the received offset,
+// returning the new received offset. A client can only start a new upload
+// while it has less than objectUploadsPerClient incomplete ones.
+func (u *objectUploads) write(client string, key string, chunk *pb.CafeObjectChunk) (int64, error) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+
+	pth := u.path(key)
+	var offset int64
+	info, err := os.Stat(pth)
+	switch {
+	case err == nil:
+		offset = info.Size()
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(u.dir, os.ModePerm); err != nil {
+			return 0, err
+		}
+		u.sweep()
+		if u.count(client) >= objectUploadsPerClient {
+			return 0, errTooManyUploads
+		}
+	default:
+		return 0, err
+	}
+
+	// a sender which lost track resumes from what was received
+	if chunk.Offset != offset {
+		if offset > chunk.Size {
+			return 0, os.Remove(pth)
+		}
+		return offset, nil
+	}
+
+	file, err := os.OpenFile(pth, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := file.Write(chunk.Data)
+	offset += int64(n)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return offset, err
+}
+
+// open opens an upload for reading
+func (u *objectUploads) open(key string) (io.ReadCloser, error) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+	return os.Open(u.path(key))
+}
+
+// remove deletes an upload
+func (u *objectUploads) remove(key string) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+	if err := os.Remove(u.path(key)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("error removing upload %s: %s", key, err)
+	}
+}
+
+// sweep deletes uploads which have not progressed within objectUploadExpiry
+func (u *objectUploads) sweep() {
+	infos, err := ioutil.ReadDir(u.dir)
+	if err != nil {
+		log.Errorf("error reading uploads: %s", err)
+		return
+	}
+	for _, info := range infos {
+		if time.Since(info.ModTime()) > objectUploadExpiry {
+			os.Remove(filepath.Join(u.dir, info.Name()))
+		}
+	}
+}
+
+// count returns the number of incomplete uploads started by a client
+func (u *objectUploads) count(client string) int {
+	infos, err := ioutil.ReadDir(u.dir)
+	if err != nil {
+		log.Errorf("error reading uploads: %s", err)
+		return 0
+	}
+	var count int
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name(), client+"-") {
+			count++
+		}
+	}
+	return count
+}
+
+func (u *objectUploads) path(key string) string {
+	return filepath.Join(u.dir, key)
+}
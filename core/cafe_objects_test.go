@@ -0,0 +1,214 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"testing"
+	"time"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+)
+
+var objectsCafePath = "testdata/.textile-objects-cafe"
+var objectsCafe *Textile
+var objectsClientPath = "testdata/.textile-objects-client"
+var objectsClient *Textile
+
+var objectsSession *pb.CafeSession
+var objectsCafeId peer.ID
+
+func startObjectsNode(t *testing.T, repoPath string, cafeAddr string) *Textile {
+	os.RemoveAll(repoPath)
+	if err := InitRepo(InitConfig{
+		Account:     keypair.Random(),
+		RepoPath:    repoPath,
+		CafeApiAddr: cafeAddr,
+		CafeOpen:    cafeAddr != "",
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+	node, err := NewTextile(RunConfig{
+		RepoPath: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+	return node
+}
+
+// addObjectData adds random data of size to the client, returning it and its cid
+func addObjectData(t *testing.T, size int) ([]byte, cid.Cid) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	id, err := ipfs.AddData(objectsClient.node, bytes.NewReader(data), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, *id
+}
+
+// sendChunk sends a single chunk of data to the cafe
+func sendChunk(id cid.Cid, data []byte, size int64, offset int64) (*pb.CafeObjectChunkAck, error) {
+	return objectsClient.cafe.sendObjectChunk(objectsCafeId, getCafeHTTPAddr(objectsSession), &pb.CafeObjectChunk{
+		Token:  objectsSession.Access,
+		Cid:    id.Hash().B58String(),
+		Size:   size,
+		Offset: offset,
+		Data:   data,
+	})
+}
+
+func cafePinned(t *testing.T, id cid.Cid) bool {
+	_, pinned, err := objectsCafe.node.Pinning.IsPinned(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pinned
+}
+
+func TestCafeObjects_Setup(t *testing.T) {
+	objectsCafe = startObjectsNode(t, objectsCafePath, "127.0.0.1:5300")
+	objectsClient = startObjectsNode(t, objectsClientPath, "")
+
+	var err error
+	objectsSession, err = objectsClient.RegisterCafe("http://127.0.0.1:5300")
+	if err != nil {
+		t.Fatalf("register cafe failed: %s", err)
+	}
+	objectsCafeId, err = peer.IDB58Decode(objectsSession.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCafeObjects_Resume(t *testing.T) {
+	data, id := addObjectData(t, objectChunkSize*3+100)
+	size := int64(len(data))
+
+	// a first transfer is interrupted after one chunk
+	ack, err := sendChunk(id, data[:objectChunkSize], size, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.Stored || ack.Offset != objectChunkSize {
+		t.Fatalf("wrong ack for first chunk: %+v", ack)
+	}
+
+	// a chunk from the wrong offset returns where to resume
+	ack, err = sendChunk(id, data[objectChunkSize*2:objectChunkSize*3], size, objectChunkSize*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.Offset != objectChunkSize {
+		t.Fatalf("expected resume offset %d, got %d", objectChunkSize, ack.Offset)
+	}
+
+	if err := objectsClient.cafe.sendObjectChunks(id, objectsCafeId, getCafeHTTPAddr(objectsSession), objectsSession.Access); err != nil {
+		t.Fatalf("resumed transfer failed: %s", err)
+	}
+	if !cafePinned(t, id) {
+		t.Error("object should be pinned on the cafe")
+	}
+}
+
+func TestCafeObjects_DuplicateFinalChunk(t *testing.T) {
+	data, id := addObjectData(t, objectChunkSize+100)
+	size := int64(len(data))
+	if err := objectsClient.cafe.sendObjectChunks(id, objectsCafeId, getCafeHTTPAddr(objectsSession), objectsSession.Access); err != nil {
+		t.Fatal(err)
+	}
+
+	// a retried final chunk is acked as stored
+	ack, err := sendChunk(id, data[objectChunkSize:], size, objectChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ack.Stored || ack.Offset != size {
+		t.Errorf("duplicate final chunk should be acked as stored: %+v", ack)
+	}
+}
+
+func TestCafeObjects_TooLarge(t *testing.T) {
+	_, id := addObjectData(t, 100)
+	if _, err := sendChunk(id, nil, objectMaxSize+1, 0); err == nil || err.Error() != errObjectTooLarge {
+		t.Errorf("expected %s, got %v", errObjectTooLarge, err)
+	}
+}
+
+func TestCafeObjects_Mismatch(t *testing.T) {
+	_, id := addObjectData(t, 100)
+	other := make([]byte, 100)
+	if _, err := rand.Read(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sendChunk(id, other, int64(len(other)), 0); err == nil || err.Error() != errObjectMismatch {
+		t.Errorf("expected %s, got %v", errObjectMismatch, err)
+	}
+	if cafePinned(t, id) {
+		t.Error("mismatched object should not be pinned")
+	}
+}
+
+func TestCafeObjects_HTTP(t *testing.T) {
+	_, id := addObjectData(t, objectChunkSize*2+100)
+
+	// chunks go over HTTP if the cafe is not connected
+	if err := objectsClient.node.PeerHost.Network().ClosePeer(objectsCafeId); err != nil {
+		t.Fatal(err)
+	}
+	if objectsClient.node.PeerHost.Network().Connectedness(objectsCafeId) == inet.Connected {
+		t.Fatal("cafe should be disconnected")
+	}
+
+	if err := objectsClient.cafe.sendObjectChunks(id, objectsCafeId, getCafeHTTPAddr(objectsSession), objectsSession.Access); err != nil {
+		t.Fatalf("transfer over HTTP failed: %s", err)
+	}
+	if !cafePinned(t, id) {
+		t.Error("object should be pinned on the cafe")
+	}
+}
+
+func TestCafeObjects_UploadLimit(t *testing.T) {
+	for i := 0; i < objectUploadsPerClient; i++ {
+		data, id := addObjectData(t, objectChunkSize*2)
+		if _, err := sendChunk(id, data[:objectChunkSize], int64(len(data)), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// another upload is refused without retrying
+	_, id := addObjectData(t, objectChunkSize*2)
+	if _, err := sendChunk(id, nil, objectChunkSize*2, 0); err == nil || err.Error() != errObjectUploads {
+		t.Errorf("expected %s, got %v", errObjectUploads, err)
+	}
+	start := time.Now()
+	err := objectsClient.cafe.sendObjectChunks(id, objectsCafeId, getCafeHTTPAddr(objectsSession), objectsSession.Access)
+	if err == nil || err.Error() != errObjectUploads {
+		t.Errorf("expected %s, got %v", errObjectUploads, err)
+	}
+	if time.Since(start) > objectChunkBackoff {
+		t.Error("refused upload should not be retried")
+	}
+}
+
+func TestCafeObjects_Teardown(t *testing.T) {
+	objectsClient.Stop()
+	objectsCafe.Stop()
+	objectsClient = nil
+	objectsCafe = nil
+	os.RemoveAll(objectsClientPath)
+	os.RemoveAll(objectsCafePath)
+}
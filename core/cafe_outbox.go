@@ -90,7 +90,10 @@ func (q *CafeOutbox) Flush() {
 		return
 	}
 
-	if err := q.batch(q.datastore.CafeRequests().List("", cafeOutFlushGroupSize)); err != nil {
+	start := time.Now()
+	err := q.batch(q.datastore.CafeRequests().List("", cafeOutFlushGroupSize))
+	observeFlush(cafeOutboxLabel, start, err)
+	if err != nil {
 		log.Errorf("cafe outbox batch error: %s", err)
 		return
 	}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/textileio/textile-go/crypto"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+)
+
+// cafeEncryptedQueriesCapability is advertised by peers which answer encrypted contact queries
+const cafeEncryptedQueriesCapability = "encrypted-contact-queries"
+
+// defaultPubSubContactLimit is the result cap used if not set in the config
+const defaultPubSubContactLimit = 20
+
+// maxQueryRecipients is the max number of cafes an encrypted query is sent to
+const maxQueryRecipients = 32
+
+// queryIdExpiry is how long an answered query id is remembered
+const queryIdExpiry = time.Minute * 5
+
+// errNotQueryRecipient indicates an encrypted query was not meant for the local peer
+var errNotQueryRecipient = errors.New("not a query recipient")
+
+// queryRecipients returns connected peers on the cafe topic which are known to answer
+// encrypted queries. Peers whose capabilities are unknown are left out, their versions
+// are exchanged in the background for later queries.
+func (h *CafeService) queryRecipients() []peer.ID {
+	pids, err := ipfs.TopicPeers(h.service.Node(), string(cafeServiceProtocol))
+	if err != nil {
+		log.Errorf("error listing cafe topic peers: %s", err)
+		return nil
+	}
+
+	var recipients []peer.ID
+	var unknown []peer.ID
+	for _, pid := range pids {
+		if !h.service.Supports(pid, cafeEncryptedQueriesCapability) {
+			unknown = append(unknown, pid)
+			continue
+		}
+		if len(recipients) < maxQueryRecipients {
+			recipients = append(recipients, pid)
+		}
+	}
+	h.refreshQueryRecipients(unknown)
+	return recipients
+}
+
+// refreshQueryRecipients exchanges versions with peers in the background,
+// at most once per peer within queryIdExpiry
+func (h *CafeService) refreshQueryRecipients(pids []peer.ID) {
+	for _, pid := range pids {
+		if !h.versionRefreshes.add(pid.Pretty()) {
+			continue
+		}
+		go func(pid peer.ID) {
+			if _, err := h.service.Version(pid); err != nil {
+				log.Debugf("error exchanging version with %s: %s", pid.Pretty(), err)
+			}
+		}(pid)
+	}
+}
+
+// sealContactQuery encrypts a query so that only the recipients can read it.
+// The id is left in the clear for deduplication.
+func (h *CafeService) sealContactQuery(query *pb.CafePubSubContactQuery, recipients []peer.ID) (*pb.CafePubSubContactQuery, error) {
+	plaintext, err := proto.Marshal(&pb.CafePubSubContactQuery{
+		FindId:       query.FindId,
+		FindAddress:  query.FindAddress,
+		FindUsername: query.FindUsername,
+		Limit:        query.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.GenerateAESKey()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := crypto.EncryptAES(plaintext, key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := &pb.CafePubSubContactQuery{
+		Id:         query.Id,
+		Ciphertext: ciphertext,
+	}
+	for _, pid := range recipients {
+		pk, err := pid.ExtractPublicKey()
+		if err != nil {
+			continue
+		}
+		ekey, err := crypto.Encrypt(pk, key)
+		if err != nil {
+			log.Debugf("error encrypting query key for %s: %s", pid.Pretty(), err)
+			continue
+		}
+		sealed.Keys = append(sealed.Keys, &pb.CafePubSubQueryKey{
+			Peer: pid.Pretty(),
+			Key:  ekey,
+		})
+	}
+	if len(sealed.Keys) == 0 {
+		return nil, errors.New("no query recipients")
+	}
+	return sealed, nil
+}
+
+// openContactQuery decrypts a query sealed for the local peer
+func (h *CafeService) openContactQuery(query *pb.CafePubSubContactQuery) (*pb.CafePubSubContactQuery, error) {
+	self := h.service.Node().Identity.Pretty()
+	var ekey []byte
+	for _, k := range query.Keys {
+		if k.Peer == self {
+			ekey = k.Key
+			break
+		}
+	}
+	if ekey == nil {
+		return nil, errNotQueryRecipient
+	}
+
+	key, err := crypto.Decrypt(h.service.Node().PrivateKey, ekey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := crypto.DecryptAES(query.Ciphertext, key)
+	if err != nil {
+		return nil, err
+	}
+	opened := new(pb.CafePubSubContactQuery)
+	if err := proto.Unmarshal(plaintext, opened); err != nil {
+		return nil, err
+	}
+	opened.Id = query.Id
+	return opened, nil
+}
+
+// pubSubContactLimit returns the number of results to answer a query with
+func (h *CafeService) pubSubContactLimit(query *pb.CafePubSubContactQuery) int {
+	limit := h.pubsub.Limit
+	if limit <= 0 {
+		limit = defaultPubSubContactLimit
+	}
+	if query.Limit > 0 && int(query.Limit) < limit {
+		limit = int(query.Limit)
+	}
+	return limit
+}
+
+// queryIds remembers recently added ids so that repeated deliveries
+// of the same query are only answered once, or work is only done once per id
+type queryIds struct {
+	ids map[string]time.Time
+	mux sync.Mutex
+}
+
+func newQueryIds() *queryIds {
+	return &queryIds{ids: make(map[string]time.Time)}
+}
+
+// add adds a query id, returning false if it was already added
+func (q *queryIds) add(id string) bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	now := time.Now()
+	for i, added := range q.ids {
+		if now.Sub(added) > queryIdExpiry {
+			delete(q.ids, i)
+		}
+	}
+	if _, ok := q.ids[id]; ok {
+		return false
+	}
+	q.ids[id] = now
+	return true
+}
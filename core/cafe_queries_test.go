@@ -0,0 +1,135 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/core"
+
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo/config"
+	"github.com/textileio/textile-go/service"
+)
+
+// queryCafe returns a cafe service backed by a bare ipfs node identity
+func queryCafe(t *testing.T, conf config.CafePubSub) (*CafeService, peer.ID) {
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &core.IpfsNode{Identity: pid, PrivateKey: sk}
+	h := &CafeService{pubsub: conf, queryIds: newQueryIds()}
+	h.service = service.NewService(keypair.Random(), h, func() *core.IpfsNode { return node }, nil)
+	return h, pid
+}
+
+func TestContactQuery_SealOpen(t *testing.T) {
+	sender, _ := queryCafe(t, config.CafePubSub{})
+	cafe1, pid1 := queryCafe(t, config.CafePubSub{})
+	cafe2, pid2 := queryCafe(t, config.CafePubSub{})
+
+	query := &pb.CafePubSubContactQuery{
+		Id:           "query",
+		FindUsername: "clyde",
+		Limit:        5,
+	}
+	sealed, err := sender.sealContactQuery(query, []peer.ID{pid1, pid2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.Id != query.Id {
+		t.Error("sealed query should keep its id")
+	}
+	if sealed.FindUsername != "" || bytes.Contains(sealed.Ciphertext, []byte("clyde")) {
+		t.Error("sealed query should not contain the query in the clear")
+	}
+
+	for _, cafe := range []*CafeService{cafe1, cafe2} {
+		opened, err := cafe.openContactQuery(sealed)
+		if err != nil {
+			t.Fatalf("recipient could not open query: %s", err)
+		}
+		if opened.Id != query.Id || opened.FindUsername != query.FindUsername || opened.Limit != query.Limit {
+			t.Errorf("opened query does not match: %+v", opened)
+		}
+	}
+}
+
+func TestContactQuery_NotRecipient(t *testing.T) {
+	sender, _ := queryCafe(t, config.CafePubSub{})
+	_, pid := queryCafe(t, config.CafePubSub{})
+	other, _ := queryCafe(t, config.CafePubSub{})
+
+	sealed, err := sender.sealContactQuery(&pb.CafePubSubContactQuery{
+		Id:           "query",
+		FindUsername: "clyde",
+	}, []peer.ID{pid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.openContactQuery(sealed); err != errNotQueryRecipient {
+		t.Errorf("expected not a query recipient, got %v", err)
+	}
+
+	// a key listed under another peer's id can't be opened either
+	sealed.Keys[0].Peer = other.service.Node().Identity.Pretty()
+	if _, err := other.openContactQuery(sealed); err == nil {
+		t.Error("query key for another peer should not open")
+	}
+
+	if _, err := sender.sealContactQuery(&pb.CafePubSubContactQuery{Id: "query"}, nil); err == nil {
+		t.Error("query without recipients should not seal")
+	}
+}
+
+func TestQueryIds(t *testing.T) {
+	ids := newQueryIds()
+	if !ids.add("peer/query") {
+		t.Error("new id should be added")
+	}
+	if ids.add("peer/query") {
+		t.Error("repeated id should not be added")
+	}
+	if !ids.add("other/query") {
+		t.Error("same query from another peer should be added")
+	}
+
+	// expired ids are forgotten
+	ids.ids["peer/query"] = time.Now().Add(-queryIdExpiry * 2)
+	if !ids.add("peer/query") {
+		t.Error("expired id should be added again")
+	}
+}
+
+func TestPubSubContactLimit(t *testing.T) {
+	def, _ := queryCafe(t, config.CafePubSub{})
+	capped, _ := queryCafe(t, config.CafePubSub{Limit: 10})
+
+	cases := []struct {
+		cafe  *CafeService
+		query int32
+		limit int
+	}{
+		{def, 0, defaultPubSubContactLimit},
+		{def, 5, 5},
+		{def, defaultPubSubContactLimit + 1, defaultPubSubContactLimit},
+		{capped, 0, 10},
+		{capped, 5, 5},
+		{capped, 50, 10},
+	}
+	for _, c := range cases {
+		limit := c.cafe.pubSubContactLimit(&pb.CafePubSubContactQuery{Limit: c.query})
+		if limit != c.limit {
+			t.Errorf("expected limit %d for query limit %d, got %d", c.limit, c.query, limit)
+		}
+	}
+}
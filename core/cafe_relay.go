@@ -0,0 +1,378 @@
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+	protocol "gx/ipfs/QmZNkThpqfVXs9GNbexPrfBbXSLNYeKrE7jwFM2oqHbyqN/go-libp2p-protocol"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
+)
+
+// relayProtocol is the libp2p circuit relay v1 protocol
+const relayProtocol = protocol.ID("/libp2p/circuit/relay/0.1.0")
+
+// relayAddrSuffix ends cafe swarm addresses which relay to clients
+const relayAddrSuffix = "/p2p-circuit"
+
+// relayMaxMessageSize is the max size of a relay control message
+const relayMaxMessageSize = 4096
+
+// relayStopTimeout is the timeout for opening a relayed stream to a client
+const relayStopTimeout = time.Second * 30
+
+// relay limits used if not set in the config
+const (
+	defaultRelayMaxClientCircuits = 16
+	defaultRelayMaxCircuits       = 1024
+	defaultRelayIdleTimeout       = time.Minute * 2
+)
+
+// startRelay handles circuit relay requests, replacing the ipfs node's relay handler.
+// Only registered clients are reachable through the relay.
+func (h *CafeService) startRelay(conf config.CafeHost) {
+	h.circuits = newRelayCircuits(conf)
+	h.service.Node().PeerHost.SetStreamHandler(relayProtocol, h.handleRelayStream)
+	log.Info("cafe relay started")
+}
+
+// handleRelayStream receives a circuit relay request
+func (h *CafeService) handleRelayStream(s inet.Stream) {
+	msg := new(pb.CircuitRelay)
+	if err := readRelayMsg(s, msg); err != nil {
+		relayError(s, pb.CircuitRelay_MALFORMED_MESSAGE)
+		return
+	}
+
+	switch msg.Type {
+	case pb.CircuitRelay_HOP:
+		h.handleRelayHop(s, msg)
+	case pb.CircuitRelay_CAN_HOP:
+		if err := writeRelayStatus(s, pb.CircuitRelay_SUCCESS); err != nil {
+			s.Reset()
+			return
+		}
+		s.Close()
+	default:
+		// relayed connections to the cafe itself are not accepted
+		relayError(s, pb.CircuitRelay_STOP_RELAY_REFUSED)
+	}
+}
+
+// handleRelayHop relays a stream from a peer to a connected client
+func (h *CafeService) handleRelayHop(s inet.Stream, msg *pb.CircuitRelay) {
+	src := s.Conn().RemotePeer()
+	if msg.SrcPeer == nil || peer.ID(msg.SrcPeer.Id) != src {
+		relayError(s, pb.CircuitRelay_HOP_SRC_MULTIADDR_INVALID)
+		return
+	}
+	if msg.DstPeer == nil {
+		relayError(s, pb.CircuitRelay_HOP_DST_MULTIADDR_INVALID)
+		return
+	}
+	dst, err := peer.IDFromBytes(msg.DstPeer.Id)
+	if err != nil {
+		relayError(s, pb.CircuitRelay_HOP_DST_MULTIADDR_INVALID)
+		return
+	}
+	if dst == h.service.Node().Identity {
+		relayError(s, pb.CircuitRelay_HOP_CANT_RELAY_TO_SELF)
+		return
+	}
+
+	// only relay to registered clients, which keep a connection open
+	if h.datastore.CafeClients().Get(dst.Pretty()) == nil {
+		log.Debugf("refused relay from %s to non-client %s", src.Pretty(), dst.Pretty())
+		relayError(s, pb.CircuitRelay_HOP_NO_CONN_TO_DST)
+		return
+	}
+	host := h.service.Node().PeerHost
+	if host.Network().Connectedness(dst) != inet.Connected {
+		relayError(s, pb.CircuitRelay_HOP_NO_CONN_TO_DST)
+		return
+	}
+
+	if !h.circuits.open(dst) {
+		log.Debugf("refused relay from %s to %s: too many circuits", src.Pretty(), dst.Pretty())
+		relayError(s, pb.CircuitRelay_HOP_CANT_SPEAK_RELAY)
+		return
+	}
+	var relaying bool
+	defer func() {
+		if !relaying {
+			h.circuits.close(dst)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(h.service.Node().Context(), relayStopTimeout)
+	defer cancel()
+	ds, err := host.NewStream(ctx, dst, relayProtocol)
+	if err != nil {
+		log.Debugf("error opening relay stream to %s: %s", dst.Pretty(), err)
+		relayError(s, pb.CircuitRelay_HOP_CANT_OPEN_DST_STREAM)
+		return
+	}
+
+	// ask the client to accept the stream
+	stop := &pb.CircuitRelay{
+		Type:    pb.CircuitRelay_STOP,
+		SrcPeer: msg.SrcPeer,
+		DstPeer: msg.DstPeer,
+	}
+	res := new(pb.CircuitRelay)
+	if err := writeRelayMsg(ds, stop); err == nil {
+		err = readRelayMsg(ds, res)
+	}
+	if err != nil {
+		ds.Reset()
+		relayError(s, pb.CircuitRelay_HOP_CANT_OPEN_DST_STREAM)
+		return
+	}
+	if res.Type != pb.CircuitRelay_STATUS || res.Code != pb.CircuitRelay_SUCCESS {
+		ds.Reset()
+		code := res.Code
+		if code == pb.CircuitRelay_UNKNOWN {
+			code = pb.CircuitRelay_HOP_CANT_OPEN_DST_STREAM
+		}
+		relayError(s, code)
+		return
+	}
+
+	if err := writeRelayStatus(s, pb.CircuitRelay_SUCCESS); err != nil {
+		s.Reset()
+		ds.Reset()
+		return
+	}
+	log.Debugf("relaying %s to client %s", src.Pretty(), dst.Pretty())
+
+	relaying = true
+	relayStreams(s, ds, h.circuits.idle, func() {
+		h.circuits.close(dst)
+	})
+}
+
+// relayAddrs returns addresses which reach a peer through the relays of its cafes
+func relayAddrs(pid peer.ID, cafes []repo.Cafe) []string {
+	var addrs []string
+	for _, cafe := range cafes {
+		for _, addr := range cafe.Swarm {
+			if strings.HasSuffix(addr, relayAddrSuffix) {
+				addrs = append(addrs, addr+"/ipfs/"+pid.Pretty())
+			}
+		}
+	}
+	return addrs
+}
+
+// connectCafeRelays connects to session cafes which relay for this peer.
+// Cafes only relay to connected clients.
+func (t *Textile) connectCafeRelays() {
+	for _, session := range t.datastore.CafeSessions().List() {
+		if session.Cafe == nil {
+			continue
+		}
+		var addrs []string
+		var relays bool
+		for _, addr := range session.Cafe.Swarm {
+			if strings.HasSuffix(addr, relayAddrSuffix) {
+				relays = true
+			} else {
+				addrs = append(addrs, addr+"/ipfs/"+session.Id)
+			}
+		}
+		if !relays || len(addrs) == 0 {
+			continue
+		}
+
+		pid, err := peer.IDB58Decode(session.Id)
+		if err != nil {
+			continue
+		}
+		if t.node.PeerHost.Network().Connectedness(pid) == inet.Connected {
+			continue
+		}
+		if _, err := ipfs.SwarmConnect(t.node, addrs); err != nil {
+			log.Debugf("error connecting to cafe relay %s: %s", session.Id, err)
+		}
+	}
+}
+
+// relayError responds to a relay request with an error status
+func relayError(s inet.Stream, code pb.CircuitRelay_Status) {
+	log.Debugf("relay error from %s: %s", s.Conn().RemotePeer().Pretty(), code.String())
+	if err := writeRelayStatus(s, code); err != nil {
+		s.Reset()
+		return
+	}
+	s.Close()
+}
+
+// relayStreams copies data both ways between two streams, resetting them if neither
+// side sends anything within idle. done is called once both directions are finished.
+func relayStreams(s inet.Stream, ds inet.Stream, idle time.Duration, done func()) {
+	timer := time.AfterFunc(idle, func() {
+		s.Reset()
+		ds.Reset()
+	})
+	active := func() {
+		timer.Reset(idle)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		relayCopy(s, ds, active)
+		wg.Done()
+	}()
+	go func() {
+		relayCopy(ds, s, active)
+		wg.Done()
+	}()
+	go func() {
+		wg.Wait()
+		timer.Stop()
+		done()
+	}()
+}
+
+// relayCopy copies relayed data until either side is done
+func relayCopy(dst inet.Stream, src inet.Stream, active func()) {
+	if _, err := io.Copy(dst, &activeReader{Reader: src, active: active}); err != nil {
+		dst.Reset()
+		src.Reset()
+		return
+	}
+	dst.Close()
+}
+
+// activeReader calls active whenever data is read
+type activeReader struct {
+	io.Reader
+	active func()
+}
+
+func (a *activeReader) Read(p []byte) (int, error) {
+	n, err := a.Reader.Read(p)
+	if n > 0 {
+		a.active()
+	}
+	return n, err
+}
+
+// relayCircuits counts open relayed circuits in total and per client
+type relayCircuits struct {
+	maxClient int
+	max       int
+	idle      time.Duration
+	total     int
+	clients   map[peer.ID]int
+	mux       sync.Mutex
+}
+
+func newRelayCircuits(conf config.CafeHost) *relayCircuits {
+	c := &relayCircuits{
+		maxClient: conf.RelayMaxClientCircuits,
+		max:       conf.RelayMaxCircuits,
+		idle:      time.Duration(conf.RelayIdleTimeout) * time.Second,
+		clients:   make(map[peer.ID]int),
+	}
+	if c.maxClient <= 0 {
+		c.maxClient = defaultRelayMaxClientCircuits
+	}
+	if c.max <= 0 {
+		c.max = defaultRelayMaxCircuits
+	}
+	if c.idle <= 0 {
+		c.idle = defaultRelayIdleTimeout
+	}
+	return c
+}
+
+// open adds a circuit to a client, returning false if a limit is reached
+func (c *relayCircuits) open(pid peer.ID) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.total >= c.max || c.clients[pid] >= c.maxClient {
+		return false
+	}
+	c.total++
+	c.clients[pid]++
+	return true
+}
+
+// close removes a circuit to a client
+func (c *relayCircuits) close(pid peer.ID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.clients[pid] <= 0 {
+		return
+	}
+	c.total--
+	c.clients[pid]--
+	if c.clients[pid] == 0 {
+		delete(c.clients, pid)
+	}
+}
+
+// count returns the number of open circuits to a client
+func (c *relayCircuits) count(pid peer.ID) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.clients[pid]
+}
+
+func writeRelayStatus(w io.Writer, code pb.CircuitRelay_Status) error {
+	return writeRelayMsg(w, &pb.CircuitRelay{
+		Type: pb.CircuitRelay_STATUS,
+		Code: code,
+	})
+}
+
+// writeRelayMsg writes a varint length delimited message
+func writeRelayMsg(w io.Writer, msg *pb.CircuitRelay) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(proto.EncodeVarint(uint64(len(data))), data...))
+	return err
+}
+
+// readRelayMsg reads a varint length delimited message without reading
+// past it, relayed data follows on the same stream
+func readRelayMsg(r io.Reader, msg *pb.CircuitRelay) error {
+	size, err := binary.ReadUvarint(&byteReader{r})
+	if err != nil {
+		return err
+	}
+	if size > relayMaxMessageSize {
+		return fmt.Errorf("relay message too large: %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf, msg)
+}
+
+// byteReader reads one byte at a time
+type byteReader struct {
+	io.Reader
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	_, err := io.ReadFull(b.Reader, buf[:])
+	return buf[0], err
+}
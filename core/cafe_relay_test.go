@@ -0,0 +1,338 @@
+package core
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
+)
+
+var relayCafePath = "testdata/.textile-relay-cafe"
+var relayCafe *Textile
+var relayClientPath = "testdata/.textile-relay-client"
+var relayClient *Textile
+var relaySenderPath = "testdata/.textile-relay-sender"
+var relaySender *Textile
+
+var relaySession *pb.CafeSession
+var relayCafeId peer.ID
+
+// startRelayNode starts a node without mDNS, so that peers are only reached through the relay
+func startRelayNode(t *testing.T, repoPath string, cafeAddr string) *Textile {
+	os.RemoveAll(repoPath)
+	if err := InitRepo(InitConfig{
+		Account:     keypair.Random(),
+		RepoPath:    repoPath,
+		CafeApiAddr: cafeAddr,
+		CafeOpen:    cafeAddr != "",
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+	conf, err := config.Read(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Threads.LAN.DisableMDNS = true
+	conf.Cafe.Host.Relay = cafeAddr != ""
+	conf.Cafe.Host.RelayMaxClientCircuits = 1
+	conf.Cafe.Host.RelayIdleTimeout = 1
+	if err := config.Write(repoPath, conf); err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := NewTextile(RunConfig{
+		RepoPath: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+	return node
+}
+
+// connectRelayCafe connects a node to the cafe over a direct address
+func connectRelayCafe(t *testing.T, node *Textile) {
+	for _, addr := range relaySession.Cafe.Swarm {
+		if strings.HasSuffix(addr, relayAddrSuffix) || strings.HasSuffix(addr, "/ws") {
+			continue
+		}
+		if _, err := ipfs.SwarmConnect(node.node, []string{addr + "/ipfs/" + relaySession.Id}); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	t.Fatal("cafe has no direct address")
+}
+
+// relayRequest sends a relay message from the sender to the cafe and reads the status
+func relayRequest(t *testing.T, msg *pb.CircuitRelay) (inet.Stream, *pb.CircuitRelay) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	s, err := relaySender.node.PeerHost.NewStream(ctx, relayCafeId, relayProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRelayMsg(s, msg); err != nil {
+		t.Fatal(err)
+	}
+	res := new(pb.CircuitRelay)
+	if err := readRelayMsg(s, res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != pb.CircuitRelay_STATUS {
+		t.Fatalf("expected status, got %s", res.Type.String())
+	}
+	return s, res
+}
+
+// hopTo returns a hop request from the sender to dst
+func hopTo(dst peer.ID) *pb.CircuitRelay {
+	return &pb.CircuitRelay{
+		Type:    pb.CircuitRelay_HOP,
+		SrcPeer: &pb.CircuitRelay_Peer{Id: []byte(relaySender.node.Identity)},
+		DstPeer: &pb.CircuitRelay_Peer{Id: []byte(dst)},
+	}
+}
+
+// waitForCircuits waits until the cafe has n open circuits to the client
+func waitForCircuits(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second * 10)
+	for relayCafe.cafe.circuits.count(relayClient.node.Identity) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d circuits, got %d", n, relayCafe.cafe.circuits.count(relayClient.node.Identity))
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+func TestRelayAddrs(t *testing.T) {
+	pid := randomPeer(t)
+	cafes := []repo.Cafe{
+		{Swarm: []string{"/ip4/1.2.3.4/tcp/4001", "/ip4/1.2.3.4/tcp/4001/ipfs/QmCafe1" + relayAddrSuffix}},
+		{Swarm: []string{"/ip4/5.6.7.8/tcp/4001"}},
+		{Swarm: []string{"/ip4/9.9.9.9/tcp/4001/ipfs/QmCafe3" + relayAddrSuffix}},
+	}
+	addrs := relayAddrs(pid, cafes)
+	if len(addrs) != 2 {
+		t.Fatalf("expected 2 relay addresses, got %v", addrs)
+	}
+	if addrs[0] != "/ip4/1.2.3.4/tcp/4001/ipfs/QmCafe1/p2p-circuit/ipfs/"+pid.Pretty() {
+		t.Errorf("wrong relay address: %s", addrs[0])
+	}
+	if len(relayAddrs(pid, cafes[1:2])) != 0 {
+		t.Error("cafes without relays should have no relay addresses")
+	}
+}
+
+func TestRelayCircuits(t *testing.T) {
+	c := newRelayCircuits(config.CafeHost{})
+	if c.maxClient != defaultRelayMaxClientCircuits || c.max != defaultRelayMaxCircuits || c.idle != defaultRelayIdleTimeout {
+		t.Error("unset limits should use the defaults")
+	}
+
+	c = newRelayCircuits(config.CafeHost{
+		RelayMaxClientCircuits: 2,
+		RelayMaxCircuits:       3,
+		RelayIdleTimeout:       5,
+	})
+	if c.idle != time.Second*5 {
+		t.Errorf("expected 5s idle timeout, got %s", c.idle)
+	}
+	pid1 := randomPeer(t)
+	pid2 := randomPeer(t)
+	if !c.open(pid1) || !c.open(pid1) {
+		t.Fatal("circuits under the client limit should open")
+	}
+	if c.open(pid1) {
+		t.Error("circuit over the client limit should not open")
+	}
+	if !c.open(pid2) {
+		t.Fatal("circuit to another client should open")
+	}
+	if c.open(pid2) {
+		t.Error("circuit over the total limit should not open")
+	}
+
+	c.close(pid1)
+	if c.count(pid1) != 1 {
+		t.Errorf("expected 1 circuit, got %d", c.count(pid1))
+	}
+	if !c.open(pid2) {
+		t.Error("closed circuit should make room")
+	}
+	c.close(randomPeer(t))
+	if c.total != 3 {
+		t.Errorf("closing an unknown circuit should not change the total, got %d", c.total)
+	}
+}
+
+func TestCafeRelay_Setup(t *testing.T) {
+	relayCafe = startRelayNode(t, relayCafePath, "127.0.0.1:5302")
+	relayClient = startRelayNode(t, relayClientPath, "")
+	relaySender = startRelayNode(t, relaySenderPath, "")
+
+	var err error
+	relaySession, err = relayClient.RegisterCafe("http://127.0.0.1:5302")
+	if err != nil {
+		t.Fatalf("register cafe failed: %s", err)
+	}
+	relayCafeId, err = peer.IDB58Decode(relaySession.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connectRelayCafe(t, relayClient)
+	connectRelayCafe(t, relaySender)
+}
+
+func TestCafeRelay_SendRelayed(t *testing.T) {
+	client := relayClient.node.Identity
+	inboxes := []repo.Cafe{protoCafeToRepo(relaySession.Cafe)}
+	if len(relayAddrs(client, inboxes)) == 0 {
+		t.Fatal("cafe should advertise relay addresses")
+	}
+
+	env, err := relaySender.threads.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{
+		Thread: "thread",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := relaySender.threadsOutbox.sendRelayed(client, nil, env); err != errNoRelays {
+		t.Errorf("expected no relays, got %v", err)
+	}
+	if err := relaySender.threadsOutbox.sendRelayed(client, inboxes, env); err != nil {
+		t.Fatalf("send over relay failed: %s", err)
+	}
+
+	var relayed bool
+	for _, c := range relaySender.node.PeerHost.Network().ConnsToPeer(client) {
+		if strings.Contains(c.RemoteMultiaddr().String(), relayAddrSuffix) {
+			relayed = true
+		}
+	}
+	if !relayed {
+		t.Error("sender should be connected to the client over the relay")
+	}
+}
+
+func TestCafeRelay_Refused(t *testing.T) {
+	// only registered clients are reachable
+	_, res := relayRequest(t, hopTo(randomPeer(t)))
+	if res.Code != pb.CircuitRelay_HOP_NO_CONN_TO_DST {
+		t.Errorf("hop to a non-client should be refused, got %s", res.Code.String())
+	}
+	_, res = relayRequest(t, hopTo(relaySender.node.Identity))
+	if res.Code != pb.CircuitRelay_HOP_NO_CONN_TO_DST {
+		t.Errorf("hop to a connected non-client should be refused, got %s", res.Code.String())
+	}
+	_, res = relayRequest(t, hopTo(relayCafeId))
+	if res.Code != pb.CircuitRelay_HOP_CANT_RELAY_TO_SELF {
+		t.Errorf("hop to the cafe should be refused, got %s", res.Code.String())
+	}
+
+	// the source must be the sending peer
+	spoofed := hopTo(relayClient.node.Identity)
+	spoofed.SrcPeer.Id = []byte(randomPeer(t))
+	_, res = relayRequest(t, spoofed)
+	if res.Code != pb.CircuitRelay_HOP_SRC_MULTIADDR_INVALID {
+		t.Errorf("hop with a spoofed source should be refused, got %s", res.Code.String())
+	}
+
+	// the cafe itself is not reachable over relays
+	_, res = relayRequest(t, &pb.CircuitRelay{Type: pb.CircuitRelay_STOP})
+	if res.Code != pb.CircuitRelay_STOP_RELAY_REFUSED {
+		t.Errorf("stop to the cafe should be refused, got %s", res.Code.String())
+	}
+	_, res = relayRequest(t, &pb.CircuitRelay{Type: pb.CircuitRelay_CAN_HOP})
+	if res.Code != pb.CircuitRelay_SUCCESS {
+		t.Errorf("cafe should be able to hop, got %s", res.Code.String())
+	}
+}
+
+func TestCafeRelay_Handshake(t *testing.T) {
+	// the circuit from the relayed send is closed once idle
+	waitForCircuits(t, 0)
+
+	// the client accepts relayed streams and echoes them
+	stops := make(chan *pb.CircuitRelay, 1)
+	relayClient.node.PeerHost.SetStreamHandler(relayProtocol, func(s inet.Stream) {
+		msg := new(pb.CircuitRelay)
+		if err := readRelayMsg(s, msg); err != nil {
+			s.Reset()
+			return
+		}
+		stops <- msg
+		if err := writeRelayStatus(s, pb.CircuitRelay_SUCCESS); err != nil {
+			s.Reset()
+			return
+		}
+		io.Copy(s, s)
+		s.Close()
+	})
+
+	s, res := relayRequest(t, hopTo(relayClient.node.Identity))
+	if res.Code != pb.CircuitRelay_SUCCESS {
+		t.Fatalf("hop to the client failed: %s", res.Code.String())
+	}
+	stop := <-stops
+	if stop.Type != pb.CircuitRelay_STOP || peer.ID(stop.SrcPeer.Id) != relaySender.node.Identity {
+		t.Errorf("client should receive a stop from the sender: %+v", stop)
+	}
+
+	if _, err := s.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Errorf("expected relayed echo, got %s", string(buf))
+	}
+	waitForCircuits(t, 1)
+
+	// the client limit is reached
+	_, res = relayRequest(t, hopTo(relayClient.node.Identity))
+	if res.Code != pb.CircuitRelay_HOP_CANT_SPEAK_RELAY {
+		t.Errorf("hop over the client limit should be refused, got %s", res.Code.String())
+	}
+
+	// the idle circuit is reset
+	errc := make(chan error, 1)
+	go func() {
+		_, err := s.Read(buf)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("idle circuit should be reset")
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal("idle circuit was not reset")
+	}
+	waitForCircuits(t, 0)
+}
+
+func TestCafeRelay_Teardown(t *testing.T) {
+	relaySender.Stop()
+	relayClient.Stop()
+	relayCafe.Stop()
+	os.RemoveAll(relayCafePath)
+	os.RemoveAll(relayClientPath)
+	os.RemoveAll(relaySenderPath)
+}
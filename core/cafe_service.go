@@ -13,7 +13,6 @@ import (
 	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
 	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/core"
 	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/pin"
-	files "gx/ipfs/QmZMWMvWMVKCbHetJ4RgndbuEF1io2UpUxwQwtNjtYPzSC/go-ipfs-files"
 	protocol "gx/ipfs/QmZNkThpqfVXs9GNbexPrfBbXSLNYeKrE7jwFM2oqHbyqN/go-libp2p-protocol"
 
 	njwt "github.com/dgrijalva/jwt-go"
@@ -22,6 +21,7 @@ import (
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/segmentio/ksuid"
 	"github.com/textileio/textile-go/broadcast"
+	"github.com/textileio/textile-go/crypto"
 	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/jwt"
 	"github.com/textileio/textile-go/keypair"
@@ -37,6 +37,15 @@ const defaultSessionDuration = time.Hour * 24 * 7 * 4
 // inboxMessagePageSize is the page size used when checking messages
 const inboxMessagePageSize = 10
 
+// inboxMessageQuota is the max number of messages held for a client
+const inboxMessageQuota = 256
+
+// inboxMessageExpiry is how long a message is held for a client which doesn't check its inbox
+const inboxMessageExpiry = time.Hour * 24 * 30
+
+// errInboxFull indicates a client's inbox has reached inboxMessageQuota
+var errInboxFull = errors.New("inbox full")
+
 // validation errors
 const (
 	errInvalidAddress = "invalid address"
@@ -44,18 +53,31 @@ const (
 	errForbidden      = "forbidden"
 )
 
-// cafeServiceProtocol is the current protocol tag
+// cafeServiceProtocol is the base protocol tag, also used as the pubsub topic
 const cafeServiceProtocol = protocol.ID("/textile/cafe/1.0.0")
 
+// cafeServiceProtocols are the supported stream protocols, newest first
+var cafeServiceProtocols = []protocol.ID{
+	cafeObjectChunksProtocol,
+	protocol.ID("/textile/cafe/1.1.0"),
+	cafeServiceProtocol,
+}
+
 // CafeService is a libp2p pinning and offline message service
 type CafeService struct {
-	service        *service.Service
-	datastore      repo.Datastore
-	inbox          *CafeInbox
-	info           *repo.Cafe
-	online         bool
-	open           bool
-	contactResults *broadcast.Broadcaster
+	service          *service.Service
+	datastore        repo.Datastore
+	inbox            *CafeInbox
+	uploads          *objectUploads
+	sizeLimit        int64
+	info             *repo.Cafe
+	online           bool
+	open             bool
+	pubsub           config.CafePubSub
+	queryIds         *queryIds
+	versionRefreshes *queryIds
+	circuits         *relayCircuits
+	contactResults   *broadcast.Broadcaster
 }
 
 // NewCafeService returns a new threads service
@@ -64,21 +86,44 @@ func NewCafeService(
 	node func() *core.IpfsNode,
 	datastore repo.Datastore,
 	inbox *CafeInbox,
+	uploadsPath string,
+	sizeLimit int64,
+	pubsub config.CafePubSub,
+	limiter *service.Limiter,
 ) *CafeService {
 	handler := &CafeService{
-		datastore:      datastore,
-		inbox:          inbox,
-		contactResults: broadcast.NewBroadcaster(10),
-	}
-	handler.service = service.NewService(account, handler, node)
+		datastore:        datastore,
+		inbox:            inbox,
+		uploads:          newObjectUploads(uploadsPath),
+		sizeLimit:        sizeLimit,
+		pubsub:           pubsub,
+		queryIds:         newQueryIds(),
+		versionRefreshes: newQueryIds(),
+		contactResults:   broadcast.NewBroadcaster(10),
+	}
+	handler.service = service.NewService(account, handler, node, limiter)
 	return handler
 }
 
-// Protocol returns the handler protocol
+// Protocol returns the base handler protocol
 func (h *CafeService) Protocol() protocol.ID {
 	return cafeServiceProtocol
 }
 
+// Protocols returns the supported stream protocols, newest first
+func (h *CafeService) Protocols() []protocol.ID {
+	return cafeServiceProtocols
+}
+
+// Capabilities returns optional features supported by the handler
+func (h *CafeService) Capabilities() []string {
+	caps := []string{cafeObjectChunksCapability}
+	if !h.pubsub.Refuse {
+		caps = append(caps, cafeEncryptedQueriesCapability)
+	}
+	return caps
+}
+
 // Ping pings another peer
 func (h *CafeService) Ping(pid peer.ID) (service.PeerStatus, error) {
 	return h.service.Ping(pid)
@@ -97,6 +142,8 @@ func (h *CafeService) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error
 		return h.handleStore(pid, env)
 	case pb.Message_CAFE_OBJECT:
 		return h.handleObject(pid, env)
+	case pb.Message_CAFE_OBJECT_CHUNK:
+		return h.handleObjectChunk(pid, env)
 	case pb.Message_CAFE_STORE_THREAD:
 		return h.handleStoreThread(pid, env)
 	case pb.Message_CAFE_DELIVER_MESSAGE:
@@ -116,7 +163,7 @@ func (h *CafeService) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error
 	case pb.Message_CAFE_PUBSUB_CONTACT_QUERY_RES:
 		return h.handlePubSubContactQueryResult(pid, env)
 	default:
-		return nil, nil
+		return nil, service.ErrUnsupportedMessage
 	}
 }
 
@@ -127,16 +174,16 @@ func (h *CafeService) PublishContact(contact *repo.Contact, cafe peer.ID) error
 		return h.service.NewEnvelope(pb.Message_CAFE_PUBLISH_CONTACT, &pb.CafePublishContact{
 			Token:   session.Access,
 			Contact: repoContactToProto(contact),
-		}, nil, false)
+		}, nil)
 	}); err != nil {
 		return err
 	}
 	return nil
 }
 
-// FindContactByUsername asks a cafe for a contact match by username
-func (h *CafeService) FindContact(query *ContactInfoQuery, cafe peer.ID) ([]*pb.Contact, error) {
-	renv, err := h.sendCafeRequest(cafe, func(session *pb.CafeSession) (*pb.Envelope, error) {
+// FindContact asks a cafe for contact matches, aborting when ctx is done
+func (h *CafeService) FindContact(ctx context.Context, query *ContactInfoQuery, cafe peer.ID) ([]*pb.Contact, error) {
+	renv, err := h.sendCafeRequestContext(ctx, cafe, func(session *pb.CafeSession) (*pb.Envelope, error) {
 		return h.service.NewEnvelope(pb.Message_CAFE_CONTACT_QUERY, &pb.CafeContactQuery{
 			Token:        session.Access,
 			FindId:       query.Id,
@@ -144,7 +191,7 @@ func (h *CafeService) FindContact(query *ContactInfoQuery, cafe peer.ID) ([]*pb.
 			FindUsername: query.Username,
 			Limit:        int32(query.Limit),
 			Wait:         int32(query.Wait),
-		}, nil, false)
+		}, nil)
 	})
 	if err != nil {
 		return nil, err
@@ -160,7 +207,7 @@ func (h *CafeService) FindContact(query *ContactInfoQuery, cafe peer.ID) ([]*pb.
 
 // PublishContactRequest publishes a contact request to the network
 func (h *CafeService) PublishContactRequest(req *pb.CafePubSubContactQuery) error {
-	env, err := h.service.NewEnvelope(pb.Message_CAFE_PUBSUB_CONTACT_QUERY, req, nil, false)
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_PUBSUB_CONTACT_QUERY, req, nil)
 	if err != nil {
 		return err
 	}
@@ -199,7 +246,7 @@ func (h *CafeService) Register(host string) (*pb.CafeSession, error) {
 		Sig:     sig,
 	}
 
-	env, err := h.service.NewEnvelope(pb.Message_CAFE_REGISTRATION, reg, nil, false)
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_REGISTRATION, reg, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +273,7 @@ func (h *CafeService) Store(cids []string, cafe peer.ID) ([]string, error) {
 
 	var accessToken string
 	var addr string
+	var chunks bool
 	renv, err := h.sendCafeRequest(cafe, func(session *pb.CafeSession) (*pb.Envelope, error) {
 		store := &pb.CafeStore{
 			Token: session.Access,
@@ -233,7 +281,8 @@ func (h *CafeService) Store(cids []string, cafe peer.ID) ([]string, error) {
 		}
 		accessToken = session.Access
 		addr = getCafeHTTPAddr(session)
-		return h.service.NewEnvelope(pb.Message_CAFE_STORE, store, nil, false)
+		chunks = h.supportsObjectChunks(cafe, session.Cafe)
+		return h.service.NewEnvelope(pb.Message_CAFE_STORE, store, nil)
 	})
 	if err != nil {
 		return stored, err
@@ -268,7 +317,12 @@ loop:
 		if err != nil {
 			return stored, err
 		}
-		if err := h.sendObject(decoded, addr, accessToken); err != nil {
+		if chunks {
+			err = h.sendObjectChunks(decoded, cafe, addr, accessToken)
+		} else {
+			err = h.sendObject(decoded, addr, accessToken)
+		}
+		if err != nil {
 			return stored, err
 		}
 		stored = append(stored, id)
@@ -301,7 +355,7 @@ func (h *CafeService) StoreThread(thrd *repo.Thread, cafe peer.ID) error {
 			Token:      session.Access,
 			Id:         thrd.Id,
 			Ciphertext: ciphertext,
-		}, nil, false)
+		}, nil)
 	}); err != nil {
 		return err
 	}
@@ -314,7 +368,7 @@ func (h *CafeService) DeliverMessage(mid string, pid peer.ID, cafe repo.Cafe) er
 	env, err := h.service.NewEnvelope(pb.Message_CAFE_DELIVER_MESSAGE, &pb.CafeDeliverMessage{
 		Id:       mid,
 		ClientId: pid.Pretty(),
-	}, nil, false)
+	}, nil)
 	if err != nil {
 		return err
 	}
@@ -328,7 +382,7 @@ func (h *CafeService) CheckMessages(cafe peer.ID) error {
 	renv, err := h.sendCafeRequest(cafe, func(session *pb.CafeSession) (*pb.Envelope, error) {
 		return h.service.NewEnvelope(pb.Message_CAFE_CHECK_MESSAGES, &pb.CafeCheckMessages{
 			Token: session.Access,
-		}, nil, false)
+		}, nil)
 	})
 	if err != nil {
 		return err
@@ -360,7 +414,7 @@ func (h *CafeService) DeleteMessages(cafe peer.ID) error {
 	renv, err := h.sendCafeRequest(cafe, func(session *pb.CafeSession) (*pb.Envelope, error) {
 		return h.service.NewEnvelope(pb.Message_CAFE_DELETE_MESSAGES, &pb.CafeDeleteMessages{
 			Token: session.Access,
-		}, nil, false)
+		}, nil)
 	})
 	if err != nil {
 		return err
@@ -380,7 +434,7 @@ func (h *CafeService) DeleteMessages(cafe peer.ID) error {
 
 // notifyClient attempts to ping a client that has messages waiting to download
 func (h *CafeService) notifyClient(pid peer.ID) error {
-	env, err := h.service.NewEnvelope(pb.Message_CAFE_YOU_HAVE_MAIL, nil, nil, false)
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_YOU_HAVE_MAIL, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -391,6 +445,12 @@ func (h *CafeService) notifyClient(pid peer.ID) error {
 
 // sendCafeRequest sends an authenticated request, retrying once after a session refresh
 func (h *CafeService) sendCafeRequest(
+	cafe peer.ID, envFactory func(*pb.CafeSession) (*pb.Envelope, error)) (*pb.Envelope, error) {
+	return h.sendCafeRequestContext(context.Background(), cafe, envFactory)
+}
+
+// sendCafeRequestContext is sendCafeRequest, aborting when ctx is done
+func (h *CafeService) sendCafeRequestContext(ctx context.Context,
 	cafe peer.ID, envFactory func(*pb.CafeSession) (*pb.Envelope, error)) (*pb.Envelope, error) {
 	session := h.datastore.CafeSessions().Get(cafe.Pretty())
 	if session == nil {
@@ -402,7 +462,7 @@ func (h *CafeService) sendCafeRequest(
 		return nil, err
 	}
 
-	renv, err := h.service.SendHTTPRequest(getCafeHTTPAddr(session), env)
+	renv, err := h.service.SendHTTPRequestContext(ctx, getCafeHTTPAddr(session), env)
 	if err != nil {
 		if err.Error() == errUnauthorized {
 			refreshed, err := h.refresh(session)
@@ -415,7 +475,7 @@ func (h *CafeService) sendCafeRequest(
 				return nil, err
 			}
 
-			renv, err = h.service.SendHTTPRequest(getCafeHTTPAddr(session), env)
+			renv, err = h.service.SendHTTPRequestContext(ctx, getCafeHTTPAddr(session), env)
 			if err != nil {
 				return nil, err
 			}
@@ -435,7 +495,7 @@ func getCafeHTTPAddr(session *pb.CafeSession) string {
 func (h *CafeService) challenge(cafeAddr string, kp *keypair.Full) (*pb.CafeNonce, error) {
 	env, err := h.service.NewEnvelope(pb.Message_CAFE_CHALLENGE, &pb.CafeChallenge{
 		Address: kp.Address(),
-	}, nil, false)
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -456,7 +516,7 @@ func (h *CafeService) refresh(session *pb.CafeSession) (*pb.CafeSession, error)
 		Access:  session.Access,
 		Refresh: session.Refresh,
 	}
-	env, err := h.service.NewEnvelope(pb.Message_CAFE_REFRESH_SESSION, refresh, nil, false)
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_REFRESH_SESSION, refresh, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -477,30 +537,26 @@ func (h *CafeService) refresh(session *pb.CafeSession) (*pb.CafeSession, error)
 	return refreshed, nil
 }
 
-// sendObject sends data or an object by cid to a peer
+// sendObject sends data or an object by cid to a peer in a single message.
+// Cafes which accept chunked objects are sent them with sendObjectChunks.
 func (h *CafeService) sendObject(id cid.Cid, addr string, token string) error {
 	obj := &pb.CafeObject{
 		Token: token,
 		Cid:   id.Hash().B58String(),
 	}
 
-	data, err := ipfs.DataAtPath(h.service.Node(), id.Hash().B58String())
+	data, node, err := h.readObject(id)
 	if err != nil {
-		if err == files.ErrNotReader {
-			data, err := ipfs.GetObjectAtPath(h.service.Node(), id.Hash().B58String())
-			if err != nil {
-				return err
-			}
-			obj.Node = data
-		} else {
-			return err
-		}
+		return err
+	}
+	if node {
+		obj.Node = data
 	} else {
 		obj.Data = data
 	}
 
 	// send over the raw object data
-	env, err := h.service.NewEnvelope(pb.Message_CAFE_OBJECT, obj, nil, false)
+	env, err := h.service.NewEnvelope(pb.Message_CAFE_OBJECT, obj, nil)
 	if err != nil {
 		return err
 	}
@@ -523,7 +579,7 @@ func (h *CafeService) handleChallenge(pid peer.ID, env *pb.Envelope) (*pb.Envelo
 	}
 	if _, err := accnt.Sign([]byte{0x00}); err == nil {
 		// we don't want to handle account seeds, just addresses
-		return h.service.NewError(400, errInvalidAddress, env.Message.RequestId)
+		return h.service.NewError(400, errInvalidAddress, env)
 	}
 
 	// generate a new random nonce
@@ -533,12 +589,12 @@ func (h *CafeService) handleChallenge(pid peer.ID, env *pb.Envelope) (*pb.Envelo
 		Date:    time.Now(),
 	}
 	if err := h.datastore.CafeClientNonces().Add(nonce); err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_NONCE, &pb.CafeNonce{
+	return h.service.NewResponse(pb.Message_CAFE_NONCE, &pb.CafeNonce{
 		Value: nonce.Value,
-	}, &env.Message.RequestId, true)
+	}, env)
 }
 
 // handleRegistration receives a registration request
@@ -550,15 +606,15 @@ func (h *CafeService) handleRegistration(pid peer.ID, env *pb.Envelope) (*pb.Env
 
 	// are we open?
 	if !h.open {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	snonce := h.datastore.CafeClientNonces().Get(reg.Value)
 	if snonce == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	if snonce.Address != reg.Address {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	accnt, err := keypair.Parse(reg.Address)
@@ -567,12 +623,12 @@ func (h *CafeService) handleRegistration(pid peer.ID, env *pb.Envelope) (*pb.Env
 	}
 	if _, err := accnt.Sign([]byte{0x00}); err == nil {
 		// we don't want to handle account seeds, just addresses
-		return h.service.NewError(400, errInvalidAddress, env.Message.RequestId)
+		return h.service.NewError(400, errInvalidAddress, env)
 	}
 
 	payload := []byte(reg.Value + reg.Nonce)
 	if err := accnt.Verify(payload, reg.Sig); err != nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	now := time.Now()
@@ -586,7 +642,7 @@ func (h *CafeService) handleRegistration(pid peer.ID, env *pb.Envelope) (*pb.Env
 		// check if already exists
 		client = h.datastore.CafeClients().Get(pid.Pretty())
 		if client == nil {
-			return h.service.NewError(500, "get or create client failed", env.Message.RequestId)
+			return h.service.NewError(500, "get or create client failed", env)
 		}
 	}
 
@@ -598,14 +654,14 @@ func (h *CafeService) handleRegistration(pid peer.ID, env *pb.Envelope) (*pb.Env
 		*h.info,
 	)
 	if err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
 	if err := h.datastore.CafeClientNonces().Delete(snonce.Value); err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_SESSION, session, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_SESSION, session, env)
 }
 
 // handleRefreshSession receives a refresh session request
@@ -617,10 +673,10 @@ func (h *CafeService) handleRefreshSession(pid peer.ID, env *pb.Envelope) (*pb.E
 
 	// are we _still_ open?
 	if !h.open {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
-	rerr, err := h.authToken(pid, ref.Refresh, true, env.Message.RequestId)
+	rerr, err := h.authToken(pid, ref.Refresh, true, env)
 	if err != nil {
 		return nil, err
 	}
@@ -631,31 +687,31 @@ func (h *CafeService) handleRefreshSession(pid peer.ID, env *pb.Envelope) (*pb.E
 	// ensure access and refresh are a valid pair
 	access, _ := njwt.Parse(ref.Access, h.verifyKeyFunc)
 	if access == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	refresh, _ := njwt.Parse(ref.Refresh, h.verifyKeyFunc)
 	if refresh == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	accessClaims, err := jwt.ParseClaims(access.Claims)
 	if err != nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	refreshClaims, err := jwt.ParseClaims(refresh.Claims)
 	if err != nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	if refreshClaims.Id[1:] != accessClaims.Id {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 	if refreshClaims.Subject != accessClaims.Subject {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	// get a new session
 	spid, err := peer.IDB58Decode(accessClaims.Subject)
 	if err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 	session, err := jwt.NewSession(
 		h.service.Node().PrivateKey,
@@ -665,10 +721,10 @@ func (h *CafeService) handleRefreshSession(pid peer.ID, env *pb.Envelope) (*pb.E
 		*h.info,
 	)
 	if err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_SESSION, session, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_SESSION, session, env)
 }
 
 // handleSession receives a store request
@@ -678,7 +734,7 @@ func (h *CafeService) handleStore(pid peer.ID, env *pb.Envelope) (*pb.Envelope,
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, store.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, store.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -709,7 +765,7 @@ func (h *CafeService) handleStore(pid peer.ID, env *pb.Envelope) (*pb.Envelope,
 	}
 
 	res := &pb.CafeObjectList{Cids: need}
-	return h.service.NewEnvelope(pb.Message_CAFE_OBJECT_LIST, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_OBJECT_LIST, res, env)
 }
 
 // handleObject receives an object request
@@ -719,7 +775,7 @@ func (h *CafeService) handleObject(pid peer.ID, env *pb.Envelope) (*pb.Envelope,
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, obj.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, obj.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -752,7 +808,7 @@ func (h *CafeService) handleObject(pid peer.ID, env *pb.Envelope) (*pb.Envelope,
 	}
 
 	res := &pb.CafeStored{Id: obj.Cid}
-	return h.service.NewEnvelope(pb.Message_CAFE_STORED, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_STORED, res, env)
 }
 
 // handleStoreThread receives a thread request
@@ -762,7 +818,7 @@ func (h *CafeService) handleStoreThread(pid peer.ID, env *pb.Envelope) (*pb.Enve
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, store.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, store.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -772,7 +828,7 @@ func (h *CafeService) handleStoreThread(pid peer.ID, env *pb.Envelope) (*pb.Enve
 
 	client := h.datastore.CafeClients().Get(pid.Pretty())
 	if client == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	thrd := &repo.CafeClientThread{
@@ -781,11 +837,11 @@ func (h *CafeService) handleStoreThread(pid peer.ID, env *pb.Envelope) (*pb.Enve
 		Ciphertext: store.Ciphertext,
 	}
 	if err := h.datastore.CafeClientThreads().AddOrUpdate(thrd); err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
 	res := &pb.CafeStored{Id: store.Id}
-	return h.service.NewEnvelope(pb.Message_CAFE_STORED, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_STORED, res, env)
 }
 
 // handleDeliverMessage receives an inbox message for a client
@@ -801,15 +857,54 @@ func (h *CafeService) handleDeliverMessage(pid peer.ID, env *pb.Envelope) (*pb.E
 		return nil, nil
 	}
 
+	if err := h.addClientMessage(client, msg.Id, pid); err != nil {
+		log.Errorf("error adding message: %s", err)
+	}
+	return nil, nil
+}
+
+// deliverEnvelope holds an envelope sent over HTTP in a client's inbox, encrypted for the client.
+// Senders are not authenticated, so held envelopes are limited by inboxMessageQuota.
+func (h *CafeService) deliverEnvelope(pid peer.ID, client *repo.CafeClient, env *pb.Envelope) error {
+	h.expireClientMessages(client.Id)
+	if h.datastore.CafeClientMessages().CountByClient(client.Id) >= inboxMessageQuota {
+		return errInboxFull
+	}
+
+	cpid, err := peer.IDB58Decode(client.Id)
+	if err != nil {
+		return err
+	}
+	pk, err := cpid.ExtractPublicKey()
+	if err != nil {
+		return err
+	}
+	envb, err := proto.Marshal(env)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := crypto.Encrypt(pk, envb)
+	if err != nil {
+		return err
+	}
+
+	id, err := ipfs.AddData(h.service.Node(), bytes.NewReader(ciphertext), true)
+	if err != nil {
+		return err
+	}
+	return h.addClientMessage(client, id.Hash().B58String(), pid)
+}
+
+// addClientMessage adds a message from pid to a client's inbox and notifies the client
+func (h *CafeService) addClientMessage(client *repo.CafeClient, id string, pid peer.ID) error {
 	message := &repo.CafeClientMessage{
-		Id:       msg.Id,
+		Id:       id,
 		PeerId:   pid.Pretty(),
 		ClientId: client.Id,
 		Date:     time.Now(),
 	}
 	if err := h.datastore.CafeClientMessages().AddOrUpdate(message); err != nil {
-		log.Errorf("error adding message: %s", err)
-		return nil, nil
+		return err
 	}
 
 	go func() {
@@ -822,7 +917,7 @@ func (h *CafeService) handleDeliverMessage(pid peer.ID, env *pb.Envelope) (*pb.E
 			log.Debugf("unable to notify offline client: %s", client.Id)
 		}
 	}()
-	return nil, nil
+	return nil
 }
 
 // handleCheckMessages receives a check inbox messages request
@@ -832,7 +927,7 @@ func (h *CafeService) handleCheckMessages(pid peer.ID, env *pb.Envelope) (*pb.En
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, check.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, check.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -842,11 +937,11 @@ func (h *CafeService) handleCheckMessages(pid peer.ID, env *pb.Envelope) (*pb.En
 
 	client := h.datastore.CafeClients().Get(pid.Pretty())
 	if client == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	if err := h.datastore.CafeClients().UpdateLastSeen(client.Id, time.Now()); err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
 	}
 
 	res := &pb.CafeMessages{
@@ -856,7 +951,7 @@ func (h *CafeService) handleCheckMessages(pid peer.ID, env *pb.Envelope) (*pb.En
 	for _, msg := range msgs {
 		date, err := ptypes.TimestampProto(msg.Date)
 		if err != nil {
-			return h.service.NewError(500, err.Error(), env.Message.RequestId)
+			return h.service.NewError(500, err.Error(), env)
 		}
 		res.Messages = append(res.Messages, &pb.CafeMessage{
 			Id:     msg.Id,
@@ -865,7 +960,7 @@ func (h *CafeService) handleCheckMessages(pid peer.ID, env *pb.Envelope) (*pb.En
 		})
 	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_MESSAGES, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_MESSAGES, res, env)
 }
 
 // handleDeleteMessages receives a message delete request
@@ -875,7 +970,7 @@ func (h *CafeService) handleDeleteMessages(pid peer.ID, env *pb.Envelope) (*pb.E
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, del.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, del.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -885,19 +980,58 @@ func (h *CafeService) handleDeleteMessages(pid peer.ID, env *pb.Envelope) (*pb.E
 
 	client := h.datastore.CafeClients().Get(pid.Pretty())
 	if client == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
 	// delete the most recent page
+	msgs := h.datastore.CafeClientMessages().ListByClient(client.Id, inboxMessagePageSize)
 	if err := h.datastore.CafeClientMessages().DeleteByClient(client.Id, inboxMessagePageSize); err != nil {
-		return h.service.NewError(500, err.Error(), env.Message.RequestId)
+		return h.service.NewError(500, err.Error(), env)
+	}
+	for _, msg := range msgs {
+		h.unpinClientMessage(msg.Id)
 	}
 
 	// check for more
 	remaining := h.datastore.CafeClientMessages().CountByClient(client.Id)
 
 	res := &pb.CafeDeleteMessagesAck{More: remaining > 0}
-	return h.service.NewEnvelope(pb.Message_CAFE_DELETE_MESSAGES_ACK, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_DELETE_MESSAGES_ACK, res, env)
+}
+
+// expireClientMessages deletes messages held for a client longer than inboxMessageExpiry
+func (h *CafeService) expireClientMessages(clientId string) {
+	for {
+		msgs := h.datastore.CafeClientMessages().ListByClient(clientId, inboxMessagePageSize)
+		var expired int
+		for _, msg := range msgs {
+			if time.Since(msg.Date) < inboxMessageExpiry {
+				break
+			}
+			if err := h.datastore.CafeClientMessages().Delete(msg.Id, clientId); err != nil {
+				log.Errorf("error deleting expired message %s: %s", msg.Id, err)
+				return
+			}
+			h.unpinClientMessage(msg.Id)
+			expired++
+		}
+		if expired < inboxMessagePageSize {
+			return
+		}
+	}
+}
+
+// unpinClientMessage unpins a message which is no longer held for a client.
+// Messages delivered by other cafes were not pinned here and are skipped.
+func (h *CafeService) unpinClientMessage(id string) {
+	mid, err := cid.Decode(id)
+	if err != nil {
+		log.Errorf("error decoding message id %s: %s", id, err)
+		return
+	}
+	if err := ipfs.UnpinCid(h.service.Node(), mid, true); err != nil {
+		log.Errorf("error unpinning message %s: %s", id, err)
+	}
 }
 
 // handleNotifyClient receives a message informing this peer that it has new messages waiting
@@ -922,7 +1056,7 @@ func (h *CafeService) handlePublishContact(pid peer.ID, env *pb.Envelope) (*pb.E
 		return nil, err
 	}
 
-	rerr, err := h.authToken(pid, pub.Token, false, env.Message.RequestId)
+	rerr, err := h.authToken(pid, pub.Token, false, env)
 	if err != nil {
 		return nil, err
 	}
@@ -932,17 +1066,17 @@ func (h *CafeService) handlePublishContact(pid peer.ID, env *pb.Envelope) (*pb.E
 
 	client := h.datastore.CafeClients().Get(pid.Pretty())
 	if client == nil {
-		return h.service.NewError(403, errForbidden, env.Message.RequestId)
+		return h.service.NewError(403, errForbidden, env)
 	}
 
-	if err := h.datastore.Contacts().AddOrUpdate(protoContactToRepo(pub.Contact)); err != nil {
+	if err := h.datastore.Contacts().AddOrUpdate(trustContactClaims(protoContactToRepo(pub.Contact))); err != nil {
 		return nil, err
 	}
 
 	res := &pb.CafePublishContactAck{
 		Id: pub.Contact.Id,
 	}
-	return h.service.NewEnvelope(pb.Message_CAFE_PUBLISH_CONTACT_ACK, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_PUBLISH_CONTACT_ACK, res, env)
 }
 
 // handleContactQuery searches the local contact index for a match
@@ -967,6 +1101,15 @@ func (h *CafeService) handleContactQuery(pid peer.ID, env *pb.Envelope) (*pb.Env
 			FindId:       query.FindId,
 			FindUsername: query.FindUsername,
 			FindAddress:  query.FindAddress,
+			Limit:        query.Limit - int32(len(res.Contacts)),
+		}
+		if h.pubsub.Encrypt {
+			sealed, err := h.sealContactQuery(preq, h.queryRecipients())
+			if err != nil {
+				log.Debugf("skipping network contact query: %s", err)
+				return h.service.NewResponse(pb.Message_CAFE_CONTACT_QUERY_RES, res, env)
+			}
+			preq = sealed
 		}
 		if err := h.PublishContactRequest(preq); err != nil {
 			return nil, err
@@ -1004,16 +1147,35 @@ func (h *CafeService) handleContactQuery(pid peer.ID, env *pb.Envelope) (*pb.Env
 		}
 	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_CONTACT_QUERY_RES, res, &env.Message.RequestId, true)
+	return h.service.NewResponse(pb.Message_CAFE_CONTACT_QUERY_RES, res, env)
 }
 
 // handlePubSubContactQuery receives a contact request over pubsub and responds with a direct message
 func (h *CafeService) handlePubSubContactQuery(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
+	if h.pubsub.Refuse {
+		return nil, nil
+	}
 	query := new(pb.CafePubSubContactQuery)
 	if err := ptypes.UnmarshalAny(env.Message.Payload, query); err != nil {
 		return nil, err
 	}
 
+	// answer each query once
+	if !h.queryIds.add(pid.Pretty() + "/" + query.Id) {
+		return nil, nil
+	}
+
+	if len(query.Keys) > 0 {
+		opened, err := h.openContactQuery(query)
+		if err == errNotQueryRecipient {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		query = opened
+	}
+
 	// return local results, if any
 	res := &pb.CafePubSubContactQueryResult{
 		Id: query.Id,
@@ -1021,12 +1183,16 @@ func (h *CafeService) handlePubSubContactQuery(pid peer.ID, env *pb.Envelope) (*
 	for _, c := range h.datastore.Contacts().Find(query.FindId, query.FindAddress, query.FindUsername) {
 		res.Contacts = append(res.Contacts, repoContactToProto(&c))
 	}
+	res.Contacts = deduplicateContactResults(res.Contacts)
 
 	if len(res.Contacts) == 0 {
 		return nil, nil
 	}
+	if limit := h.pubSubContactLimit(query); len(res.Contacts) > limit {
+		res.Contacts = res.Contacts[:limit]
+	}
 
-	return h.service.NewEnvelope(pb.Message_CAFE_PUBSUB_CONTACT_QUERY_RES, res, nil, false)
+	return h.service.NewResponse(pb.Message_CAFE_PUBSUB_CONTACT_QUERY_RES, res, env)
 }
 
 // handlePubSubContactQueryResult handles direct contact request results
@@ -1041,14 +1207,14 @@ func (h *CafeService) handlePubSubContactQueryResult(pid peer.ID, env *pb.Envelo
 }
 
 // authToken verifies a request token from a peer
-func (h *CafeService) authToken(pid peer.ID, token string, refreshing bool, requestId int32) (*pb.Envelope, error) {
+func (h *CafeService) authToken(pid peer.ID, token string, refreshing bool, req *pb.Envelope) (*pb.Envelope, error) {
 	subject := pid.Pretty()
 	if err := jwt.Validate(token, h.verifyKeyFunc, refreshing, string(h.Protocol()), &subject); err != nil {
 		switch err {
 		case jwt.ErrNoToken, jwt.ErrExpired:
-			return h.service.NewError(401, errUnauthorized, requestId)
+			return h.service.NewError(401, errUnauthorized, req)
 		case jwt.ErrInvalid:
-			return h.service.NewError(403, errForbidden, requestId)
+			return h.service.NewError(403, errForbidden, req)
 		}
 	}
 	return nil, nil
@@ -1081,13 +1247,18 @@ func (h *CafeService) setAddrs(conf *config.Config, swarmPorts config.SwarmPorts
 	if swarmPorts.WS != "" {
 		swarm = append(swarm, fmt.Sprintf("/ip4/%s/tcp/%s/ws", publicIP, swarmPorts.WS))
 	}
+	if conf.Cafe.Host.Relay {
+		for _, addr := range swarm {
+			swarm = append(swarm, addr+"/ipfs/"+h.service.Node().Identity.Pretty()+relayAddrSuffix)
+		}
+	}
 	log.Infof("cafe multiaddresses: %s", swarm)
 
 	h.info = &repo.Cafe{
 		Peer:     h.service.Node().Identity.Pretty(),
 		Address:  conf.Account.Address,
 		API:      cafeApiVersion,
-		Protocol: string(cafeServiceProtocol),
+		Protocol: string(cafeServiceProtocols[0]),
 		Node:     Version,
 		URL:      url,
 		Swarm:    swarm,
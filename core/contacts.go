@@ -1,15 +1,23 @@
 package core
 
 import (
-	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
 	"github.com/golang/protobuf/ptypes"
 	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/pb"
 	"github.com/textileio/textile-go/repo"
 )
 
+// ErrContactNotFound indicates a local contact was not found
+var ErrContactNotFound = errors.New("contact not found")
+
 // ContactInfo displays info about a contact
 type ContactInfo struct {
 	Id        string      `json:"id"`
@@ -19,6 +27,8 @@ type ContactInfo struct {
 	Inboxes   []repo.Cafe `json:"inboxes,omitempty"`
 	Created   time.Time   `json:"created"`
 	Updated   time.Time   `json:"updated"`
+	Verified  bool        `json:"verified,omitempty"`
+	Endpoint  string      `json:"endpoint,omitempty"`
 	ThreadIds []string    `json:"thread_ids,omitempty"`
 }
 
@@ -34,10 +44,24 @@ type ContactInfoQuery struct {
 
 // ContactInfoQueryResult displays info about a contact search result
 type ContactInfoQueryResult struct {
-	Local  []ContactInfo `json:"local,omitempty"`
-	Remote []ContactInfo `json:"remote,omitempty"`
+	Local  []ContactInfo     `json:"local,omitempty"`
+	Remote []ContactInfo     `json:"remote,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"` // keyed by cafe peer id
 }
 
+// ContactInfoQueryUpdate is a partial contact search result from a single source
+type ContactInfoQueryUpdate struct {
+	Source   string        `json:"source"` // "local" or a cafe peer id
+	Contacts []ContactInfo `json:"contacts,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// localContactQuerySource is the update source of local contact search results
+const localContactQuerySource = "local"
+
+// contactQueryGracePeriod is how long past a query's wait time cafes have to respond
+const contactQueryGracePeriod = time.Second * 5
+
 // AddContact adds a contact for the first time
 // Note: Existing contacts will not be overwritten
 func (t *Textile) AddContact(contact *repo.Contact) error {
@@ -132,51 +156,186 @@ func (t *Textile) UpdateContactInboxes() error {
 	return t.datastore.Contacts().UpdateInboxes(t.node.Identity.Pretty(), inboxes)
 }
 
-// FindContact searches the network for contacts
-func (t *Textile) FindContact(query *ContactInfoQuery) (*ContactInfoQueryResult, error) {
-	sessions := t.datastore.CafeSessions().List()
-	if len(sessions) == 0 {
-		return nil, nil
+// UpdateContactEndpoint sets this node's own contact's endpoint from the config.
+// An invalid endpoint is cleared so that it's no longer advertised.
+func (t *Textile) UpdateContactEndpoint() error {
+	endpoint := t.config.Threads.HTTP.Endpoint
+	var invalid error
+	if endpoint != "" && !validEndpoint(endpoint) {
+		invalid = fmt.Errorf("invalid threads http endpoint (https is required): %s", endpoint)
+		endpoint = ""
+	}
+	self := t.datastore.Contacts().Get(t.node.Identity.Pretty())
+	if self == nil || self.Endpoint == endpoint {
+		return invalid
+	}
+	if err := t.datastore.Contacts().UpdateEndpoint(self.Id, endpoint); err != nil {
+		return err
 	}
+	return invalid
+}
 
+// FindContact searches locally and across the cafe network for contacts.
+// Cafes that fail to respond are reported in the result's errors.
+func (t *Textile) FindContact(query *ContactInfoQuery) (*ContactInfoQueryResult, error) {
 	result := &ContactInfoQueryResult{
 		Local:  make([]ContactInfo, 0),
 		Remote: make([]ContactInfo, 0),
 	}
 
-	// search local
-	for _, c := range t.datastore.Contacts().Find(query.Id, query.Address, query.Username) {
-		i := t.contactInfo(&c, true)
-		if i != nil {
-			result.Local = append(result.Local, *i)
+	for update := range t.FindContactStream(context.Background(), query) {
+		switch {
+		case update.Error != "":
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[update.Source] = update.Error
+		case update.Source == localContactQuerySource:
+			result.Local = append(result.Local, update.Contacts...)
+		default:
+			for _, c := range update.Contacts {
+				result.Remote = mergeContactInfo(result.Remote, c)
+			}
 		}
 	}
 
-	// search the network
-	if !query.Local && len(result.Local) < query.Limit {
-		// TODO: do this in parallel
-		var inbound []*pb.Contact
-		for _, session := range sessions {
-			pid, err := peer.IDB58Decode(session.Id)
-			if err != nil {
-				return result, err
-			}
-			res, err := t.cafe.FindContact(query, pid)
-			if err != nil {
-				return result, err
+	return result, nil
+}
+
+// FindContactStream searches locally and then concurrently across all cafe sessions,
+// sending updates as results arrive. The returned channel is closed once all cafes
+// have responded, the limit has been reached, or the query's wait time has elapsed.
+// Remote contacts are only sent again if a newer version is found.
+func (t *Textile) FindContactStream(ctx context.Context, query *ContactInfoQuery) <-chan ContactInfoQueryUpdate {
+	updates := make(chan ContactInfoQueryUpdate)
+
+	go func() {
+		defer close(updates)
+		send := func(update ContactInfoQueryUpdate) bool {
+			select {
+			case updates <- update:
+				return true
+			case <-ctx.Done():
+				return false
 			}
-			inbound = deduplicateContactResults(append(inbound, res...))
 		}
 
-		for _, c := range inbound {
-			i := t.contactInfo(protoContactToRepo(c), false)
+		// search local
+		local := make([]ContactInfo, 0)
+		for _, c := range t.datastore.Contacts().Find(query.Id, query.Address, query.Username) {
+			i := t.contactInfo(&c, true)
 			if i != nil {
-				result.Remote = append(result.Remote, *i)
+				local = append(local, *i)
 			}
 		}
+		if !send(ContactInfoQueryUpdate{Source: localContactQuerySource, Contacts: local}) {
+			return
+		}
+		if query.Local || len(local) >= query.Limit {
+			return
+		}
+
+		// search the network
+		var cafes []string
+		for _, session := range t.datastore.CafeSessions().List() {
+			cafes = append(cafes, session.Id)
+		}
+		fanoutContactQuery(ctx, query, local, cafes, t.findContactAtCafe, send)
+	}()
+
+	return updates
+}
+
+// contactFinder runs a contact query against a single cafe
+type contactFinder func(ctx context.Context, query *ContactInfoQuery, cafeId string) ContactInfoQueryUpdate
+
+// fanoutContactQuery runs a contact query concurrently against each cafe, sending
+// results that are new or newer than those already sent (starting with local).
+// It returns once all cafes have responded, the limit has been reached,
+// the query's wait time has elapsed, or send fails.
+func fanoutContactQuery(ctx context.Context, query *ContactInfoQuery, local []ContactInfo, cafes []string, find contactFinder, send func(ContactInfoQueryUpdate) bool) {
+	if len(cafes) == 0 {
+		return
 	}
 
-	return result, nil
+	wait := time.Second*time.Duration(query.Wait) + contactQueryGracePeriod
+	qctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	results := make(chan ContactInfoQueryUpdate, len(cafes))
+	pending := make(map[string]struct{})
+	for _, id := range cafes {
+		pending[id] = struct{}{}
+		go func(id string) {
+			results <- find(qctx, query, id)
+		}(id)
+	}
+
+	found := make(map[string]time.Time)
+	for _, c := range local {
+		found[c.Id] = c.Updated
+	}
+	count := len(found)
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.Source)
+
+			var fresh []ContactInfo
+			for _, c := range res.Contacts {
+				updated, ok := found[c.Id]
+				if ok && !c.Updated.After(updated) {
+					continue
+				}
+				if !ok {
+					count++
+				}
+				found[c.Id] = c.Updated
+				fresh = append(fresh, c)
+			}
+			res.Contacts = fresh
+
+			if !send(res) {
+				return
+			}
+			if count >= query.Limit {
+				return
+			}
+
+		case <-qctx.Done():
+			for id := range pending {
+				if !send(ContactInfoQueryUpdate{Source: id, Error: qctx.Err().Error()}) {
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+// findContactAtCafe runs a contact query against a single cafe
+func (t *Textile) findContactAtCafe(ctx context.Context, query *ContactInfoQuery, cafeId string) ContactInfoQueryUpdate {
+	update := ContactInfoQueryUpdate{Source: cafeId}
+
+	pid, err := peer.IDB58Decode(cafeId)
+	if err != nil {
+		update.Error = err.Error()
+		return update
+	}
+	res, err := t.cafe.FindContact(ctx, query, pid)
+	if err != nil {
+		log.Warningf("contact query to %s failed: %s", cafeId, err)
+		update.Error = err.Error()
+		return update
+	}
+
+	for _, c := range deduplicateContactResults(res) {
+		i := t.contactInfo(trustContactClaims(protoContactToRepo(c)), false)
+		if i != nil {
+			update.Contacts = append(update.Contacts, *i)
+		}
+	}
+	return update
 }
 
 // contactInfo expands a contact into a more detailed view
@@ -201,10 +360,35 @@ func (t *Textile) contactInfo(model *repo.Contact, addThreads bool) *ContactInfo
 		Inboxes:   model.Inboxes,
 		Created:   model.Created,
 		Updated:   model.Updated,
+		Verified:  model.Verified,
+		Endpoint:  model.Endpoint,
 		ThreadIds: threads,
 	}
 }
 
+// mergeContactInfo adds a contact to the list, replacing an older version with the same id
+func mergeContactInfo(list []ContactInfo, info ContactInfo) []ContactInfo {
+	for i, c := range list {
+		if c.Id == info.Id {
+			if info.Updated.After(c.Updated) {
+				list[i] = info
+			}
+			return list
+		}
+	}
+	return append(list, info)
+}
+
+// validEndpoint returns whether or not an endpoint is an absolute HTTPS url.
+// Plain HTTP is refused, envelopes would be readable and alterable in transit.
+func validEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "https" && u.Host != ""
+}
+
 // toUsername returns a contact's username or trimmed peer id
 func toUsername(contact *repo.Contact) string {
 	if contact == nil || contact.Id == "" {
@@ -239,13 +423,15 @@ func protoContactToRepo(pro *pb.Contact) *repo.Contact {
 		updated = time.Now()
 	}
 	return &repo.Contact{
-		Id:       pro.Id,
-		Address:  pro.Address,
-		Username: pro.Username,
-		Avatar:   pro.Avatar,
-		Inboxes:  inboxes,
-		Created:  created,
-		Updated:  updated,
+		Id:        pro.Id,
+		Address:   pro.Address,
+		Username:  pro.Username,
+		Avatar:    pro.Avatar,
+		Inboxes:   inboxes,
+		Created:   created,
+		Updated:   updated,
+		Endpoint:  pro.Endpoint,
+		Signature: pro.Signature,
 	}
 }
 
@@ -267,12 +453,14 @@ func repoContactToProto(rep *repo.Contact) *pb.Contact {
 		updated = ptypes.TimestampNow()
 	}
 	return &pb.Contact{
-		Id:       rep.Id,
-		Address:  rep.Address,
-		Username: rep.Username,
-		Avatar:   rep.Avatar,
-		Inboxes:  inboxes,
-		Created:  created,
-		Updated:  updated,
+		Id:        rep.Id,
+		Address:   rep.Address,
+		Username:  rep.Username,
+		Avatar:    rep.Avatar,
+		Inboxes:   inboxes,
+		Created:   created,
+		Updated:   updated,
+		Endpoint:  rep.Endpoint,
+		Signature: rep.Signature,
 	}
 }
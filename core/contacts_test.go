@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubCafe is a fake cafe session answering contact queries
+type stubCafe struct {
+	contacts []ContactInfo
+	err      error
+	delay    time.Duration
+}
+
+// stubFinder returns a contact finder backed by stub cafes
+func stubFinder(cafes map[string]stubCafe) contactFinder {
+	return func(ctx context.Context, query *ContactInfoQuery, cafeId string) ContactInfoQueryUpdate {
+		cafe := cafes[cafeId]
+		select {
+		case <-time.After(cafe.delay):
+		case <-ctx.Done():
+			return ContactInfoQueryUpdate{Source: cafeId, Error: ctx.Err().Error()}
+		}
+		if cafe.err != nil {
+			return ContactInfoQueryUpdate{Source: cafeId, Error: cafe.err.Error()}
+		}
+		return ContactInfoQueryUpdate{Source: cafeId, Contacts: cafe.contacts}
+	}
+}
+
+// runContactQuery runs a fanout query over stub cafes, collecting all sent updates
+func runContactQuery(query *ContactInfoQuery, local []ContactInfo, cafes map[string]stubCafe) []ContactInfoQueryUpdate {
+	var ids []string
+	for id := range cafes {
+		ids = append(ids, id)
+	}
+
+	var mux sync.Mutex
+	var updates []ContactInfoQueryUpdate
+	fanoutContactQuery(context.Background(), query, local, ids, stubFinder(cafes),
+		func(update ContactInfoQueryUpdate) bool {
+			mux.Lock()
+			defer mux.Unlock()
+			updates = append(updates, update)
+			return true
+		})
+	return updates
+}
+
+// countContacts returns the number of times each contact was sent
+func countContacts(updates []ContactInfoQueryUpdate) map[string]int {
+	counts := make(map[string]int)
+	for _, u := range updates {
+		for _, c := range u.Contacts {
+			counts[c.Id]++
+		}
+	}
+	return counts
+}
+
+func TestFanoutContactQuery_Concurrent(t *testing.T) {
+	now := time.Now()
+	cafes := map[string]stubCafe{
+		"cafe1": {contacts: []ContactInfo{{Id: "a", Updated: now}}, delay: time.Millisecond * 300},
+		"cafe2": {contacts: []ContactInfo{{Id: "b", Updated: now}}, delay: time.Millisecond * 300},
+		"cafe3": {contacts: []ContactInfo{{Id: "c", Updated: now}}, delay: time.Millisecond * 300},
+	}
+
+	start := time.Now()
+	updates := runContactQuery(&ContactInfoQuery{Limit: 10, Wait: 5}, nil, cafes)
+	if time.Since(start) > time.Millisecond*800 {
+		t.Error("cafes were not queried concurrently")
+	}
+	if len(updates) != 3 {
+		t.Fatalf("expected 3 updates, got %d", len(updates))
+	}
+	if len(countContacts(updates)) != 3 {
+		t.Error("expected 3 contacts")
+	}
+}
+
+func TestFanoutContactQuery_FailingCafe(t *testing.T) {
+	now := time.Now()
+	cafes := map[string]stubCafe{
+		"cafe1": {contacts: []ContactInfo{{Id: "a", Updated: now}}},
+		"cafe2": {err: errors.New("boom")},
+	}
+
+	updates := runContactQuery(&ContactInfoQuery{Limit: 10, Wait: 5}, nil, cafes)
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	for _, u := range updates {
+		switch u.Source {
+		case "cafe1":
+			if u.Error != "" || len(u.Contacts) != 1 {
+				t.Error("expected a result from the working cafe")
+			}
+		case "cafe2":
+			if u.Error != "boom" {
+				t.Errorf("expected an error from the failing cafe, got %s", u.Error)
+			}
+		}
+	}
+}
+
+func TestFanoutContactQuery_Limit(t *testing.T) {
+	now := time.Now()
+	cafes := map[string]stubCafe{
+		"cafe1": {contacts: []ContactInfo{{Id: "a", Updated: now}, {Id: "b", Updated: now}}},
+		"cafe2": {contacts: []ContactInfo{{Id: "c", Updated: now}}, delay: time.Second * 2},
+	}
+
+	start := time.Now()
+	updates := runContactQuery(&ContactInfoQuery{Limit: 2, Wait: 5}, nil, cafes)
+	if time.Since(start) > time.Second {
+		t.Error("query did not stop at the limit")
+	}
+	if len(updates) != 1 || updates[0].Source != "cafe1" {
+		t.Fatalf("expected only the first cafe's update, got %d updates", len(updates))
+	}
+}
+
+func TestFanoutContactQuery_Dedup(t *testing.T) {
+	now := time.Now()
+	local := []ContactInfo{{Id: "a", Updated: now}}
+	cafes := map[string]stubCafe{
+		"cafe1": {contacts: []ContactInfo{{Id: "a", Updated: now}, {Id: "b", Updated: now}}},
+		"cafe2": {contacts: []ContactInfo{{Id: "b", Updated: now}}, delay: time.Millisecond * 100},
+		"cafe3": {contacts: []ContactInfo{{Id: "a", Updated: now.Add(time.Minute)}}, delay: time.Millisecond * 200},
+	}
+
+	// a local contact counts towards the limit once
+	updates := runContactQuery(&ContactInfoQuery{Limit: 3, Wait: 5}, local, cafes)
+	if len(updates) != 3 {
+		t.Fatalf("expected 3 updates, got %d", len(updates))
+	}
+	counts := countContacts(updates)
+	if counts["b"] != 1 {
+		t.Errorf("expected b to be sent once, got %d", counts["b"])
+	}
+	if counts["a"] != 1 {
+		t.Errorf("expected only the newer version of a to be sent, got %d", counts["a"])
+	}
+}
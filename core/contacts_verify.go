@@ -0,0 +1,144 @@
+package core
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrSafetyNumberMismatch indicates a contact was verified with the wrong safety number
+var ErrSafetyNumberMismatch = errors.New("safety number does not match")
+
+// safetyNumberQRPrefix prefixes the QR code payload of a safety number
+const safetyNumberQRPrefix = "textile-safety:"
+
+// ContactVerification displays the safety number shared with a contact
+type ContactVerification struct {
+	Id           string `json:"id"`
+	Address      string `json:"address"`
+	SafetyNumber string `json:"safety_number"`
+	QRCode       string `json:"qr_code"` // payload to render as a QR code
+	Verified     bool   `json:"verified"`
+}
+
+// ContactVerification returns the safety number shared with a contact.
+// Both parties derive the same number from their account addresses, which
+// can be compared out-of-band (in person, or by scanning the QR code).
+func (t *Textile) ContactVerification(id string) (*ContactVerification, error) {
+	contact := t.datastore.Contacts().Get(id)
+	if contact == nil {
+		return nil, ErrContactNotFound
+	}
+
+	number := safetyNumber(t.account.Address(), contact.Address)
+	return &ContactVerification{
+		Id:           contact.Id,
+		Address:      contact.Address,
+		SafetyNumber: number,
+		QRCode:       safetyNumberQRPrefix + strings.Replace(number, " ", "", -1),
+		Verified:     contact.Verified,
+	}, nil
+}
+
+// VerifyContact marks a contact as verified. If a safety number (or scanned QR code
+// payload) is given, it must match the one shared with the contact.
+// Note: Verification is dropped if the contact's account address changes
+func (t *Textile) VerifyContact(id string, number string) error {
+	info, err := t.ContactVerification(id)
+	if err != nil {
+		return err
+	}
+	if number != "" && normalizeSafetyNumber(number) != normalizeSafetyNumber(info.SafetyNumber) {
+		return ErrSafetyNumberMismatch
+	}
+	return t.datastore.Contacts().UpdateVerified(id, true)
+}
+
+// UnverifyContact clears a contact's verified flag
+func (t *Textile) UnverifyContact(id string) error {
+	if t.datastore.Contacts().Get(id) == nil {
+		return ErrContactNotFound
+	}
+	return t.datastore.Contacts().UpdateVerified(id, false)
+}
+
+// safetyNumber derives a displayable 60 digit number from two account addresses
+func safetyNumber(a string, b string) string {
+	addrs := []string{a, b}
+	sort.Strings(addrs)
+	digest := sha512.Sum512([]byte(strings.Join(addrs, "")))
+
+	groups := make([]string, 12)
+	for i := range groups {
+		var n uint64
+		for _, b := range digest[i*5 : i*5+5] {
+			n = n<<8 | uint64(b)
+		}
+		groups[i] = fmt.Sprintf("%05d", n%100000)
+	}
+	return strings.Join(groups, " ")
+}
+
+// normalizeSafetyNumber strips formatting from a safety number or QR code payload
+func normalizeSafetyNumber(number string) string {
+	number = strings.TrimPrefix(strings.TrimSpace(number), safetyNumberQRPrefix)
+	return strings.Replace(number, " ", "", -1)
+}
+
+// signProfile signs the profile claims of this node's own contact with the account key
+func (t *Textile) signProfile() error {
+	self := t.datastore.Contacts().Get(t.node.Identity.Pretty())
+	if self == nil || verifyContact(self) {
+		return nil
+	}
+	if err := signContact(t.account, self); err != nil {
+		return err
+	}
+	return t.datastore.Contacts().UpdateSignature(self.Id, self.Signature)
+}
+
+// contactClaims returns the contact fields covered by its account signature
+func contactClaims(contact *repo.Contact) []byte {
+	claims, _ := json.Marshal([]string{contact.Id, contact.Address, contact.Username, contact.Avatar})
+	return claims
+}
+
+// signContact signs a contact's profile claims with its account key
+func signContact(accnt *keypair.Full, contact *repo.Contact) error {
+	sig, err := accnt.Sign(contactClaims(contact))
+	if err != nil {
+		return err
+	}
+	contact.Signature = sig
+	return nil
+}
+
+// verifyContact returns whether or not a contact's profile claims are signed by its account
+func verifyContact(contact *repo.Contact) bool {
+	if len(contact.Signature) == 0 {
+		return false
+	}
+	accnt, err := keypair.Parse(contact.Address)
+	if err != nil {
+		return false
+	}
+	return accnt.Verify(contactClaims(contact), contact.Signature) == nil
+}
+
+// trustContactClaims drops the username and avatar of a contact received from
+// the network unless they are signed by the contact's account
+func trustContactClaims(contact *repo.Contact) *repo.Contact {
+	if contact == nil || verifyContact(contact) {
+		return contact
+	}
+	contact.Username = ""
+	contact.Avatar = ""
+	contact.Signature = nil
+	return contact
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"crypto/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/segmentio/ksuid"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/repo"
+)
+
+var verifyRepoPath = "testdata/.textile-verify"
+var verifyNode *Textile
+var verifyThread *Thread
+var verifyPeer *repo.Contact
+
+// randomPeerId returns a new random peer id
+func randomPeerId(t *testing.T) string {
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid.Pretty()
+}
+
+// captureNotifications collects the thread's contact change notifications
+func captureNotifications(thrd *Thread) *[]*repo.Notification {
+	notes := make([]*repo.Notification, 0)
+	thrd.sendNotification = func(note *repo.Notification) error {
+		if note.Type == repo.ContactChangedNotification {
+			notes = append(notes, note)
+		}
+		return nil
+	}
+	return &notes
+}
+
+func TestSafetyNumber_Symmetric(t *testing.T) {
+	a := keypair.Random().Address()
+	b := keypair.Random().Address()
+	number := safetyNumber(a, b)
+	if number != safetyNumber(b, a) {
+		t.Fatal("safety number is not symmetric")
+	}
+	if len(strings.Split(number, " ")) != 12 {
+		t.Errorf("expected 12 groups, got %s", number)
+	}
+	if number == safetyNumber(a, keypair.Random().Address()) {
+		t.Error("safety number should differ for another contact")
+	}
+}
+
+func TestSignContact(t *testing.T) {
+	accnt := keypair.Random()
+	contact := &repo.Contact{
+		Id:       randomPeerId(t),
+		Address:  accnt.Address(),
+		Username: "alice",
+		Avatar:   "avatar",
+	}
+	if err := signContact(accnt, contact); err != nil {
+		t.Fatal(err)
+	}
+	if !verifyContact(contact) {
+		t.Fatal("signed contact did not verify")
+	}
+	if trustContactClaims(contact).Username != "alice" {
+		t.Error("signed username was dropped")
+	}
+
+	// a cafe rewriting the username invalidates the claims
+	contact.Username = "mallory"
+	if verifyContact(contact) {
+		t.Fatal("tampered contact verified")
+	}
+	trusted := trustContactClaims(contact)
+	if trusted.Username != "" || trusted.Avatar != "" {
+		t.Error("unsigned profile claims were kept")
+	}
+}
+
+func TestContactVerify_Setup(t *testing.T) {
+	verifyNode = startObjectsNode(t, verifyRepoPath, "")
+
+	if !verifyContact(verifyNode.Profile()) {
+		t.Error("own profile is not signed")
+	}
+
+	accnt := keypair.Random()
+	verifyPeer = &repo.Contact{
+		Id:      randomPeerId(t),
+		Address: accnt.Address(),
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+	if err := verifyNode.AddContact(verifyPeer); err != nil {
+		t.Fatal(err)
+	}
+
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyThread, err = verifyNode.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "verify",
+		Initiator: verifyNode.account.Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContactVerify_Mismatch(t *testing.T) {
+	wrong := safetyNumber(verifyNode.account.Address(), keypair.Random().Address())
+	if err := verifyNode.VerifyContact(verifyPeer.Id, wrong); err != ErrSafetyNumberMismatch {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	if verifyNode.datastore.Contacts().Get(verifyPeer.Id).Verified {
+		t.Error("contact was verified with the wrong safety number")
+	}
+}
+
+func TestContactVerify_Match(t *testing.T) {
+	info, err := verifyNode.ContactVerification(verifyPeer.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyNode.VerifyContact(verifyPeer.Id, info.QRCode); err != nil {
+		t.Fatalf("verify failed: %s", err)
+	}
+	if !verifyNode.datastore.Contacts().Get(verifyPeer.Id).Verified {
+		t.Error("contact was not verified")
+	}
+}
+
+func TestContactVerify_NewPeer(t *testing.T) {
+	notes := captureNotifications(verifyThread)
+
+	// a new peer appears for a known address
+	if err := verifyThread.addOrUpdateContact(&repo.Contact{
+		Id:      randomPeerId(t),
+		Address: verifyPeer.Address,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*notes) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(*notes))
+	}
+	if !strings.Contains((*notes)[0].Body, "verify their safety number again") {
+		t.Errorf("expected a verified contact warning, got %s", (*notes)[0].Body)
+	}
+}
+
+func TestContactVerify_AddressChange(t *testing.T) {
+	notes := captureNotifications(verifyThread)
+
+	// a known peer binds to a different address
+	if err := verifyThread.addOrUpdateContact(&repo.Contact{
+		Id:      verifyPeer.Id,
+		Address: keypair.Random().Address(),
+		Created: time.Now(),
+		Updated: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*notes) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(*notes))
+	}
+	if verifyNode.datastore.Contacts().Get(verifyPeer.Id).Verified {
+		t.Error("verification was kept after address change")
+	}
+
+	// an unchanged binding is not reported
+	if err := verifyThread.addOrUpdateContact(verifyNode.datastore.Contacts().Get(verifyPeer.Id)); err != nil {
+		t.Fatal(err)
+	}
+	if len(*notes) != 1 {
+		t.Errorf("expected no new notification, got %d", len(*notes))
+	}
+}
+
+func TestContactVerify_Teardown(t *testing.T) {
+	verifyNode.Stop()
+	verifyNode = nil
+	os.RemoveAll(verifyRepoPath)
+}
@@ -0,0 +1,335 @@
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrDeviceSelf indicates a device operation was attempted on self
+var ErrDeviceSelf = errors.New("cannot link or remove self as a device")
+
+// ErrDeviceNotFound indicates a device is not in the registry
+var ErrDeviceNotFound = errors.New("device not found")
+
+// ErrAccountThreadNotFound indicates the account thread is not loaded
+var ErrAccountThreadNotFound = errors.New("account thread not found")
+
+// accountThreadName is the name given to the account thread
+const accountThreadName = "account"
+
+// accountThreadRotationKey prefixes the key of a replacement account thread until it's ready
+const accountThreadRotationKey = "rotating:"
+
+// DeviceInfo reports info about a device linked to the account
+type DeviceInfo struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Pending bool      `json:"pending,omitempty"`
+	Current bool      `json:"current,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+// AccountThread returns the thread representing the state of the account
+func (t *Textile) AccountThread() *Thread {
+	return t.ThreadByKey(t.account.Address())
+}
+
+// Devices lists the account device registry, including pending link requests
+func (t *Textile) Devices() []DeviceInfo {
+	self := t.node.Identity.Pretty()
+
+	infos := make([]DeviceInfo, 0)
+	for _, d := range t.datastore.Devices().List("") {
+		infos = append(infos, DeviceInfo{
+			Id:      d.Id,
+			Name:    d.Name,
+			Pending: d.Pending,
+			Current: d.Id == self,
+			Date:    d.Date,
+		})
+	}
+	return infos
+}
+
+// RequestDeviceLink asks an existing device of this account to link this one.
+// The request is signed with the account key, which only devices holding the wallet seed can produce.
+func (t *Textile) RequestDeviceLink(id string, name string) error {
+	self := t.node.Identity.Pretty()
+	if id == self {
+		return ErrDeviceSelf
+	}
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	thrd := t.AccountThread()
+	if thrd == nil {
+		return ErrAccountThreadNotFound
+	}
+
+	if _, err := thrd.sendDeviceInvite(pid, name, t.account.Sign, false); err != nil {
+		return err
+	}
+
+	log.Debugf("requested device link with %s", id)
+
+	return nil
+}
+
+// AddDevice approves a device link, registers the device in the account thread,
+// and sends it the account thread key
+func (t *Textile) AddDevice(id string, name string) error {
+	self := t.node.Identity.Pretty()
+	if id == self {
+		return ErrDeviceSelf
+	}
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	thrd := t.AccountThread()
+	if thrd == nil {
+		return ErrAccountThreadNotFound
+	}
+
+	if name == "" {
+		if pending := t.datastore.Devices().Get(id); pending != nil {
+			name = pending.Name
+		}
+	}
+
+	// the first approval also registers this device
+	if ex := t.datastore.Devices().Get(self); ex == nil || ex.Pending {
+		if _, err := thrd.addDevice(self, "", false); err != nil {
+			return err
+		}
+	}
+	if _, err := thrd.addDevice(id, name, false); err != nil {
+		return err
+	}
+
+	if _, err := thrd.sendDeviceInvite(pid, thrd.Name, t.account.Sign, true); err != nil {
+		return err
+	}
+
+	// the invite carries HEAD, no welcome needed
+	if err := t.addAccountPeer(thrd, id); err != nil {
+		return err
+	}
+
+	t.sendUpdate(Update{Id: id, Key: thrd.Key, Name: name, Type: AccountPeerAdded})
+
+	log.Debugf("added device %s", id)
+
+	return nil
+}
+
+// RemoveDevice removes a device from the registry (or declines a pending link request).
+// Removing a linked device rotates the account thread key.
+func (t *Textile) RemoveDevice(id string) error {
+	if id == t.node.Identity.Pretty() {
+		return ErrDeviceSelf
+	}
+	device := t.datastore.Devices().Get(id)
+	if device == nil {
+		return ErrDeviceNotFound
+	}
+	if device.Pending {
+		return t.datastore.Devices().Delete(id)
+	}
+
+	thrd := t.AccountThread()
+	if thrd == nil {
+		return ErrAccountThreadNotFound
+	}
+	if _, err := thrd.addDevice(id, "", true); err != nil {
+		return err
+	}
+	if err := t.datastore.ThreadPeers().Delete(id, thrd.Id); err != nil {
+		return err
+	}
+
+	if err := t.rotateAccountThread(); err != nil {
+		return err
+	}
+
+	t.sendUpdate(Update{Id: id, Key: thrd.Key, Name: device.Name, Type: AccountPeerRemoved})
+
+	log.Debugf("removed device %s", id)
+
+	return nil
+}
+
+// accountPeerCount returns the number of other linked devices
+func (t *Textile) accountPeerCount() int {
+	query := fmt.Sprintf("pending=0 and id!='%s'", t.node.Identity.Pretty())
+	return t.datastore.Devices().Count(query)
+}
+
+// rotateAccountThread replaces the account thread with a new one under a fresh key,
+// so that removed devices can no longer read it. The device registry is re-posted and
+// remaining devices are invited to the new thread. The old thread is only discarded
+// once the new one is populated.
+func (t *Textile) rotateAccountThread() error {
+	self := t.node.Identity.Pretty()
+	old := t.AccountThread()
+	if old == nil {
+		return ErrAccountThreadNotFound
+	}
+	devices := t.datastore.Devices().List("pending=0")
+
+	// the account key is unique, so the new thread is built under a temporary one
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return err
+	}
+	thrd, err := t.AddThread(sk, AddThreadConfig{
+		Key:       accountThreadRotationKey + t.account.Address(),
+		Name:      accountThreadName,
+		Initiator: t.account.Address(),
+		Type:      repo.PrivateThread,
+		Join:      true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// re-post the registry before any peers are added
+	for _, d := range devices {
+		if _, err := thrd.addDevice(d.Id, d.Name, false); err != nil {
+			if rerr := t.discardThread(thrd); rerr != nil {
+				log.Errorf("error removing new account thread %s: %s", thrd.Id, rerr)
+			}
+			return err
+		}
+	}
+
+	if err := t.discardThread(old); err != nil {
+		if rerr := t.discardThread(thrd); rerr != nil {
+			log.Errorf("error removing new account thread %s: %s", thrd.Id, rerr)
+		}
+		return err
+	}
+	if err := t.datastore.Threads().UpdateKey(thrd.Id, t.account.Address()); err != nil {
+		return err
+	}
+	thrd.Key = t.account.Address()
+
+	// a device that can't be invited now can re-request a link later
+	for _, d := range devices {
+		if d.Id == self {
+			continue
+		}
+		pid, err := peer.IDB58Decode(d.Id)
+		if err != nil {
+			log.Errorf("error decoding device %s: %s", d.Id, err)
+			continue
+		}
+		if _, err := thrd.sendDeviceInvite(pid, thrd.Name, t.account.Sign, true); err != nil {
+			log.Errorf("error inviting device %s to account thread: %s", d.Id, err)
+			continue
+		}
+		if err := t.addAccountPeer(thrd, d.Id); err != nil {
+			log.Errorf("error adding device %s to account thread: %s", d.Id, err)
+		}
+	}
+
+	log.Debugf("rotated account thread %s -> %s", old.Id, thrd.Id)
+
+	return nil
+}
+
+// handleAccountThreadInvite uses a verified device invite to adopt the account thread
+// of another device, replacing the local one if its key differs
+func (t *Textile) handleAccountThreadInvite(plaintext []byte) (mh.Multihash, error) {
+	block := new(pb.ThreadBlock)
+	if err := proto.Unmarshal(plaintext, block); err != nil {
+		return nil, err
+	}
+	if block.Type != pb.ThreadBlock_INVITE {
+		return nil, ErrInvalidThreadBlock
+	}
+	msg := new(pb.ThreadInvite)
+	if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+		return nil, err
+	}
+
+	sk, err := libp2pc.UnmarshalPrivateKey(msg.Sk)
+	if err != nil {
+		return nil, err
+	}
+	id, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		return nil, err
+	}
+
+	if current := t.AccountThread(); current != nil && current.Id != id.Pretty() {
+		if err := t.discardThread(current); err != nil {
+			return nil, err
+		}
+	}
+	thrd := t.Thread(id.Pretty())
+	if thrd == nil {
+		thrd, err = t.AddThread(sk, AddThreadConfig{
+			Key:       t.account.Address(),
+			Name:      msg.Name,
+			Initiator: msg.Initiator,
+			Type:      repo.PrivateThread,
+			Join:      false,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := thrd.addOrUpdateContact(protoContactToRepo(msg.Contact)); err != nil {
+		return nil, err
+	}
+
+	// follow parents (including the device registry), update head
+	if err := thrd.handleInviteMessage(block); err != nil {
+		return nil, err
+	}
+
+	if err := t.datastore.ThreadPeers().WelcomeByThread(thrd.Id); err != nil {
+		return nil, err
+	}
+
+	author, err := peer.IDB58Decode(block.Header.Author)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := thrd.join(author)
+	if err != nil {
+		return nil, err
+	}
+
+	t.sendUpdate(Update{Id: author.Pretty(), Key: thrd.Key, Name: thrd.Name, Type: AccountPeerAdded})
+
+	return hash, nil
+}
+
+// addAccountPeer adds a device as an already welcomed account thread peer
+func (t *Textile) addAccountPeer(thrd *Thread, id string) error {
+	if err := t.datastore.ThreadPeers().Add(&repo.ThreadPeer{
+		Id:       id,
+		ThreadId: thrd.Id,
+		Welcomed: true,
+	}); err != nil {
+		if !repo.ConflictError(err) {
+			return err
+		}
+	}
+	return nil
+}
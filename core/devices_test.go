@@ -0,0 +1,115 @@
+package core_test
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/keypair"
+)
+
+var deviceRepoPath1 = "testdata/.textile-device1"
+var deviceNode1 *Textile
+var deviceRepoPath2 = "testdata/.textile-device2"
+var deviceNode2 *Textile
+
+var linkedAccountThreadId string
+
+func TestDevices_Setup(t *testing.T) {
+	accnt := keypair.Random()
+	deviceNode1 = startLanAccountNode(t, deviceRepoPath1, accnt)
+	deviceNode2 = startLanAccountNode(t, deviceRepoPath2, accnt)
+}
+
+func TestTextile_RequestDeviceLink(t *testing.T) {
+	id1 := deviceNode1.Ipfs().Identity.Pretty()
+	id2 := deviceNode2.Ipfs().Identity.Pretty()
+	if err := deviceNode2.RequestDeviceLink(id1, "phone"); err != nil {
+		t.Fatalf("request device link failed: %s", err)
+	}
+	if !waitFor(lanTimeout, func() bool {
+		for _, d := range deviceNode1.Devices() {
+			if d.Id == id2 && d.Pending && d.Name == "phone" {
+				return true
+			}
+		}
+		return false
+	}) {
+		t.Fatal("link request was not received")
+	}
+}
+
+func TestTextile_AddDevice(t *testing.T) {
+	id2 := deviceNode2.Ipfs().Identity.Pretty()
+	if err := deviceNode1.AddDevice(id2, ""); err != nil {
+		t.Fatalf("add device failed: %s", err)
+	}
+	thrd1 := deviceNode1.AccountThread()
+	if thrd1 == nil {
+		t.Fatal("account thread not found")
+	}
+
+	// the other device should adopt the account thread
+	if !waitFor(lanTimeout, func() bool {
+		thrd2 := deviceNode2.AccountThread()
+		return thrd2 != nil && thrd2.Id == thrd1.Id
+	}) {
+		t.Fatal("account thread was not adopted")
+	}
+	linkedAccountThreadId = thrd1.Id
+
+	for _, d := range deviceNode1.Devices() {
+		if d.Id == id2 && d.Pending {
+			t.Error("device should no longer be pending")
+		}
+	}
+}
+
+func TestTextile_RemoveDevice(t *testing.T) {
+	id2 := deviceNode2.Ipfs().Identity.Pretty()
+	if err := deviceNode1.RemoveDevice(id2); err != nil {
+		t.Fatalf("remove device failed: %s", err)
+	}
+
+	// the account thread key should be rotated
+	thrd := deviceNode1.AccountThread()
+	if thrd == nil {
+		t.Fatal("account thread not found after rotation")
+	}
+	if thrd.Id == linkedAccountThreadId {
+		t.Error("account thread was not rotated")
+	}
+	if deviceNode1.Thread(linkedAccountThreadId) != nil {
+		t.Error("old account thread was not discarded")
+	}
+	for _, d := range deviceNode1.Devices() {
+		if d.Id == id2 {
+			t.Error("removed device should not be in the registry")
+		}
+	}
+	for _, p := range thrd.Peers() {
+		if p.Id == id2 {
+			t.Error("removed device should not be a peer of the rotated thread")
+		}
+	}
+
+	// removing again should fail
+	if err := deviceNode1.RemoveDevice(id2); err != ErrDeviceNotFound {
+		t.Errorf("expected device not found error, got %v", err)
+	}
+}
+
+func TestTextile_RemoveDeviceSelf(t *testing.T) {
+	if err := deviceNode1.RemoveDevice(deviceNode1.Ipfs().Identity.Pretty()); err != ErrDeviceSelf {
+		t.Errorf("expected self error, got %v", err)
+	}
+}
+
+func TestDevices_Teardown(t *testing.T) {
+	deviceNode1.Stop()
+	deviceNode2.Stop()
+	deviceNode1 = nil
+	deviceNode2 = nil
+	os.RemoveAll(deviceRepoPath1)
+	os.RemoveAll(deviceRepoPath2)
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"fmt"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+
+	"github.com/textileio/textile-go/ipfs"
+	m "github.com/textileio/textile-go/mill"
+	"github.com/textileio/textile-go/repo"
+)
+
+// fsck problem kinds
+const (
+	FsckOrphanBlock        = "orphan_block"
+	FsckOrphanThreadPeer   = "orphan_thread_peer"
+	FsckStaleFileTarget    = "stale_file_target"
+	FsckOrphanNotification = "orphan_notification"
+	FsckMissingThreadHead  = "missing_thread_head"
+	FsckUnpinnedFile       = "unpinned_file"
+)
+
+// FsckProblem describes a single datastore integrity problem
+type FsckProblem struct {
+	Kind     string `json:"kind"`
+	Id       string `json:"id"`
+	Detail   string `json:"detail"`
+	Repaired bool   `json:"repaired"`
+}
+
+// FsckReport lists the problems found by Fsck
+type FsckReport struct {
+	Problems []FsckProblem `json:"problems"`
+	Repair   bool          `json:"repair"`
+}
+
+// Fsck cross-checks the datastore tables against each other and against ipfs.
+// If repair is true, problems which can be fixed are fixed.
+func (t *Textile) Fsck(repair bool) (*FsckReport, error) {
+	if !t.started {
+		return nil, ErrStopped
+	}
+
+	report := &FsckReport{Problems: make([]FsckProblem, 0), Repair: repair}
+	threads := make(map[string]*repo.Thread)
+	for _, mod := range t.datastore.Threads().List() {
+		mod := mod
+		threads[mod.Id] = &mod
+	}
+
+	checks := []func(map[string]*repo.Thread, bool) ([]FsckProblem, error){
+		t.fsckBlocks,
+		t.fsckThreadPeers,
+		t.fsckThreadHeads,
+		t.fsckFiles,
+		t.fsckNotifications,
+	}
+	for _, check := range checks {
+		problems, err := check(threads, repair)
+		if err != nil {
+			return nil, err
+		}
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	log.Infof("fsck found %d problems (repair: %t)", len(report.Problems), repair)
+
+	return report, nil
+}
+
+// fsckBlocks finds blocks whose thread no longer exists
+func (t *Textile) fsckBlocks(threads map[string]*repo.Thread, repair bool) ([]FsckProblem, error) {
+	counts := make(map[string]int)
+	for _, block := range t.datastore.Blocks().List("", -1, "") {
+		if threads[block.ThreadId] == nil {
+			counts[block.ThreadId]++
+		}
+	}
+
+	var problems []FsckProblem
+	for threadId, count := range counts {
+		problem := FsckProblem{
+			Kind:   FsckOrphanBlock,
+			Id:     threadId,
+			Detail: fmt.Sprintf("%d blocks for missing thread", count),
+		}
+		if repair {
+			if err := t.datastore.Blocks().DeleteByThread(threadId); err != nil {
+				return nil, err
+			}
+			problem.Repaired = true
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// fsckThreadPeers finds thread peers whose thread no longer exists
+func (t *Textile) fsckThreadPeers(threads map[string]*repo.Thread, repair bool) ([]FsckProblem, error) {
+	counts := make(map[string]int)
+	for _, tp := range t.datastore.ThreadPeers().List() {
+		if threads[tp.ThreadId] == nil {
+			counts[tp.ThreadId]++
+		}
+	}
+
+	var problems []FsckProblem
+	for threadId, count := range counts {
+		problem := FsckProblem{
+			Kind:   FsckOrphanThreadPeer,
+			Id:     threadId,
+			Detail: fmt.Sprintf("%d peers for missing thread", count),
+		}
+		if repair {
+			if err := t.datastore.ThreadPeers().DeleteByThread(threadId); err != nil {
+				return nil, err
+			}
+			problem.Repaired = true
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// fsckThreadHeads finds thread heads which are not in the local blockstore
+func (t *Textile) fsckThreadHeads(threads map[string]*repo.Thread, repair bool) ([]FsckProblem, error) {
+	var problems []FsckProblem
+	for _, mod := range threads {
+		if mod.Head == "" {
+			continue
+		}
+		id, err := cid.Decode(mod.Head)
+		if err == nil {
+			var has bool
+			has, err = t.node.Blockstore.Has(id)
+			if err == nil && has {
+				continue
+			}
+		}
+		// nothing to repair with, the head has to be re-synced from peers
+		problems = append(problems, FsckProblem{
+			Kind:   FsckMissingThreadHead,
+			Id:     mod.Id,
+			Detail: fmt.Sprintf("head %s is not in the local blockstore", mod.Head),
+		})
+	}
+	return problems, nil
+}
+
+// fsckFiles finds file targets which no longer point at a (non-ignored) files block,
+// and files which should be pinned according to their mill but are not
+func (t *Textile) fsckFiles(threads map[string]*repo.Thread, repair bool) ([]FsckProblem, error) {
+	var problems []FsckProblem
+	for _, file := range t.datastore.Files().List() {
+		var stale []string
+		for _, target := range file.Targets {
+			if !t.liveFilesTarget(target) {
+				stale = append(stale, target)
+			}
+		}
+
+		for _, target := range stale {
+			problem := FsckProblem{
+				Kind:   FsckStaleFileTarget,
+				Id:     file.Hash,
+				Detail: fmt.Sprintf("target %s has no files block", target),
+			}
+			if repair {
+				if err := t.datastore.Files().RemoveTarget(file.Hash, target); err != nil {
+					return nil, err
+				}
+				problem.Repaired = true
+			}
+			problems = append(problems, problem)
+		}
+		if repair && len(stale) > 0 && len(stale) == len(file.Targets) {
+			// no targets left, safe to unpin and de-index
+			if err := t.removeFile(file.Hash); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !millPins(file.Mill) {
+			continue
+		}
+		id, err := cid.Decode(file.Hash)
+		if err != nil {
+			continue
+		}
+		_, pinned, err := t.node.Pinning.IsPinned(id)
+		if err != nil {
+			return nil, err
+		}
+		if pinned {
+			continue
+		}
+		problem := FsckProblem{
+			Kind:   FsckUnpinnedFile,
+			Id:     file.Hash,
+			Detail: fmt.Sprintf("%s output is not pinned", file.Mill),
+		}
+		if repair {
+			node, err := ipfs.NodeAtCid(t.node, id)
+			if err != nil {
+				problem.Detail += fmt.Sprintf(" (pin failed: %s)", err)
+			} else if err := ipfs.PinNode(t.node, node, false); err != nil {
+				return nil, err
+			} else {
+				problem.Repaired = true
+			}
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// fsckNotifications finds notifications for blocks which no longer exist
+func (t *Textile) fsckNotifications(threads map[string]*repo.Thread, repair bool) ([]FsckProblem, error) {
+	var problems []FsckProblem
+	for _, note := range t.datastore.Notifications().List("", -1) {
+		if note.BlockId == "" || t.datastore.Blocks().Get(note.BlockId) != nil {
+			continue
+		}
+		problem := FsckProblem{
+			Kind:   FsckOrphanNotification,
+			Id:     note.Id,
+			Detail: fmt.Sprintf("block %s is missing", note.BlockId),
+		}
+		if repair {
+			if err := t.datastore.Notifications().Delete(note.Id); err != nil {
+				return nil, err
+			}
+			problem.Repaired = true
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// liveFilesTarget returns whether or not a files block with target exists and is not ignored
+func (t *Textile) liveFilesTarget(target string) bool {
+	query := fmt.Sprintf("target='%s' and type=%d", target, repo.FilesBlock)
+	for _, block := range t.datastore.Blocks().List("", -1, query) {
+		if t.datastore.Blocks().Count("target='ignore-"+block.Id+"'") == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFile unpins and de-indexes a file
+func (t *Textile) removeFile(hash string) error {
+	id, err := cid.Decode(hash)
+	if err != nil {
+		return err
+	}
+	if err := t.node.Pinning.Unpin(t.node.Context(), id, true); err != nil {
+		log.Debugf("error unpinning %s: %s", hash, err)
+	}
+	if err := t.node.Pinning.Flush(); err != nil {
+		return err
+	}
+	return t.datastore.Files().Delete(hash)
+}
+
+// millPins returns whether or not the mill with id pins its output
+func millPins(id string) bool {
+	mills := []m.Mill{
+		&m.Blob{},
+		&m.ImageExif{},
+		&m.ImageResize{},
+		&m.Json{},
+		&m.Schema{},
+	}
+	for _, mill := range mills {
+		if mill.ID() == id {
+			return mill.Pin()
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	ipfsconfig "gx/ipfs/QmPEpj17FDRpc7K1aArKZp3RsHtzRMKykeK9GVgn4WQGPR/go-ipfs-config"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/repo"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+
+	"github.com/textileio/textile-go/repo/config"
+)
+
+// mdnsInterval is the mDNS query interval in seconds, used if not set in the ipfs config
+const mdnsInterval = 10
+
+// lanNetworks are the address ranges considered to be on the local network
+var lanNetworks = []string{
+	"127.0.0.0/8",
+	"::1/128",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// LanPeers lists the ids of peers connected over the local network
+func (t *Textile) LanPeers() []string {
+	ids := make([]string, 0)
+	for _, pid := range t.lan.list() {
+		ids = append(ids, pid.Pretty())
+	}
+	return ids
+}
+
+// configureMDNS applies the textile mDNS setting to the ipfs repo used to build the node so
+// that peers on the local network find each other without bootstrap peers or cafes.
+// mDNS is only turned off here, never on, so that it stays off if disabled in the ipfs config.
+// The textile switch is not written to the ipfs config, so turning it back on restores mDNS.
+// Servers don't announce themselves on the local network.
+func configureMDNS(rep repo.Repo, conf config.ThreadsLAN, isServer bool) (repo.Repo, error) {
+	ipfsConf, err := rep.Config()
+	if err != nil {
+		return rep, err
+	}
+	if isServer || !ipfsConf.Discovery.MDNS.Enabled {
+		return rep, nil
+	}
+	if conf.DisableMDNS {
+		return &noMDNSRepo{rep}, nil
+	}
+	if ipfsConf.Discovery.MDNS.Interval <= 0 {
+		return rep, config.UpdateIpfs(rep, "Discovery.MDNS.Interval", mdnsInterval)
+	}
+	return rep, nil
+}
+
+// noMDNSRepo is an ipfs repo which reports mDNS as disabled without changing its config
+type noMDNSRepo struct {
+	repo.Repo
+}
+
+// Config returns a copy of the repo config with mDNS disabled
+func (r *noMDNSRepo) Config() (*ipfsconfig.Config, error) {
+	conf, err := r.Repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	cp := *conf
+	cp.Discovery.MDNS.Enabled = false
+	return &cp, nil
+}
+
+// lanNotifiee tracks connections to peers on the local network.
+// Pending thread messages are flushed as soon as a new local peer is connected.
+func (t *Textile) lanNotifiee() inet.Notifiee {
+	return &inet.NotifyBundle{
+		ConnectedF: func(n inet.Network, c inet.Conn) {
+			if !isLanAddr(c.RemoteMultiaddr().String()) {
+				return
+			}
+			if !t.lan.add(c.RemotePeer()) {
+				return
+			}
+			log.Debugf("found lan peer %s", c.RemotePeer().Pretty())
+			if t.threadsOutbox != nil {
+				go t.threadsOutbox.Flush()
+			}
+		},
+		DisconnectedF: func(n inet.Network, c inet.Conn) {
+			if len(n.ConnsToPeer(c.RemotePeer())) == 0 {
+				t.lan.remove(c.RemotePeer())
+			}
+		},
+	}
+}
+
+// lanPeers is the set of peers connected over the local network
+type lanPeers struct {
+	peers map[peer.ID]struct{}
+	mux   sync.Mutex
+}
+
+func newLanPeers() *lanPeers {
+	return &lanPeers{peers: make(map[peer.ID]struct{})}
+}
+
+// add adds a peer, returning false if it was already known
+func (l *lanPeers) add(pid peer.ID) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if _, ok := l.peers[pid]; ok {
+		return false
+	}
+	l.peers[pid] = struct{}{}
+	return true
+}
+
+func (l *lanPeers) remove(pid peer.ID) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	delete(l.peers, pid)
+}
+
+func (l *lanPeers) list() []peer.ID {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	var list []peer.ID
+	for pid := range l.peers {
+		list = append(list, pid)
+	}
+	return list
+}
+
+func (l *lanPeers) reset() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.peers = make(map[peer.ID]struct{})
+}
+
+// isLanAddr returns whether or not a multiaddr string has a private or link-local ip
+func isLanAddr(addr string) bool {
+	parts := strings.Split(addr, "/")
+	if len(parts) < 3 || (parts[1] != "ip4" && parts[1] != "ip6") {
+		return false
+	}
+	ip := net.ParseIP(parts[2])
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range lanNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
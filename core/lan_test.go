@@ -0,0 +1,198 @@
+package core_test
+
+import (
+	"crypto/rand"
+	"os"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/repo/fsrepo"
+
+	"github.com/segmentio/ksuid"
+	. "github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
+)
+
+var lanRepoPath1 = "testdata/.textile-lan1"
+var lanNode1 *Textile
+var lanRepoPath2 = "testdata/.textile-lan2"
+var lanNode2 *Textile
+
+var lanThread *Thread
+
+// lanTimeout is how long to wait for local discovery and delivery
+const lanTimeout = time.Minute
+
+func startLanNode(t *testing.T, repoPath string) *Textile {
+	return startLanAccountNode(t, repoPath, keypair.Random())
+}
+
+// startLanAccountNode starts a node for an existing account, i.e., another device
+func startLanAccountNode(t *testing.T, repoPath string, accnt *keypair.Full) *Textile {
+	os.RemoveAll(repoPath)
+	if err := InitRepo(InitConfig{
+		Account:  accnt,
+		RepoPath: repoPath,
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+
+	// no bootstrap peers, only local discovery
+	rep, err := fsrepo.Open(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.UpdateIpfs(rep, "Bootstrap", []string{}); err != nil {
+		t.Fatal(err)
+	}
+	rep.Close()
+
+	node, err := NewTextile(RunConfig{
+		RepoPath: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+	return node
+}
+
+// waitFor polls cond until it returns true or the timeout is reached
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 250)
+	}
+	return cond()
+}
+
+func TestLan_Setup(t *testing.T) {
+	lanNode1 = startLanNode(t, lanRepoPath1)
+	lanNode2 = startLanNode(t, lanRepoPath2)
+}
+
+func TestLan_Discovery(t *testing.T) {
+	id2 := lanNode2.Ipfs().Identity.Pretty()
+	found := waitFor(lanTimeout, func() bool {
+		for _, id := range lanNode1.LanPeers() {
+			if id == id2 {
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		t.Fatal("peer was not discovered on the local network")
+	}
+}
+
+func TestLan_SyncThread(t *testing.T) {
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lanThread, err = lanNode1.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "lan",
+		Initiator: lanNode1.Account().Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatalf("add thread failed: %s", err)
+	}
+	if _, err := lanThread.AddInvite(lanNode2.Ipfs().Identity); err != nil {
+		t.Fatalf("add invite failed: %s", err)
+	}
+
+	// accept the invite on the other node
+	var invites []ThreadInviteInfo
+	if !waitFor(lanTimeout, func() bool {
+		invites = lanNode2.ThreadInvites()
+		return len(invites) > 0
+	}) {
+		t.Fatal("invite was not received")
+	}
+	if _, err := lanNode2.AcceptThreadInvite(invites[0].Id); err != nil {
+		t.Fatalf("accept invite failed: %s", err)
+	}
+	if !waitFor(lanTimeout, func() bool {
+		return len(lanThread.Peers()) > 0
+	}) {
+		t.Fatal("join was not received")
+	}
+
+	// messages should now sync without cafes
+	if _, err := lanThread.AddMessage("hello lan"); err != nil {
+		t.Fatalf("add message failed: %s", err)
+	}
+	if !waitFor(lanTimeout, func() bool {
+		page, err := lanNode2.ThreadMessages("", -1, lanThread.Id)
+		if err != nil {
+			return false
+		}
+		msgs := page.Items.([]ThreadMessageInfo)
+		return len(msgs) == 1 && msgs[0].Body == "hello lan"
+	}) {
+		t.Fatal("message was not received")
+	}
+}
+
+func TestLan_DisableMDNS(t *testing.T) {
+	repoPath := "testdata/.textile-lan3"
+	defer os.RemoveAll(repoPath)
+	os.RemoveAll(repoPath)
+	if err := InitRepo(InitConfig{
+		Account:  keypair.Random(),
+		RepoPath: repoPath,
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+	conf, err := config.Read(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Threads.LAN.DisableMDNS = true
+	if err := config.Write(repoPath, conf); err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := NewTextile(RunConfig{
+		RepoPath: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+	node.Stop()
+
+	// turning the switch back on must restore mDNS, so it's not written to the ipfs config
+	ipfsConf, err := fsrepo.ConfigAt(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ipfsConf.Discovery.MDNS.Enabled {
+		t.Error("disabling mdns should not change the ipfs config")
+	}
+}
+
+func TestLan_Teardown(t *testing.T) {
+	lanNode1.Stop()
+	lanNode2.Stop()
+	lanNode1 = nil
+	lanNode2 = nil
+	os.RemoveAll(lanRepoPath1)
+	os.RemoveAll(lanRepoPath2)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"time"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo/config"
+	"github.com/textileio/textile-go/service"
+)
+
+// Limits returns inbound message limit counters and current bans
+func (t *Textile) Limits() service.LimiterStats {
+	return t.limiter.Stats()
+}
+
+// Unban lifts a temporary ban on a peer
+func (t *Textile) Unban(id string) error {
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	t.limiter.Unban(pid)
+	return nil
+}
+
+// limiterConfig converts limits settings to a service limiter config
+func limiterConfig(conf config.Limits) service.LimiterConfig {
+	lconf := service.LimiterConfig{
+		Default: service.LimitRate{
+			Rate:  conf.Rate,
+			Burst: conf.Burst,
+		},
+		Types:        make(map[pb.Message_Type]service.LimitRate),
+		BanThreshold: conf.BanThreshold,
+		BanWindow:    time.Duration(conf.BanWindow) * time.Second,
+		BanDuration:  time.Duration(conf.BanDuration) * time.Second,
+	}
+	for name, rate := range conf.Types {
+		mtype, ok := pb.Message_Type_value[name]
+		if !ok {
+			log.Warningf("unknown message type in limits config: %s", name)
+			continue
+		}
+		lconf.Types[pb.Message_Type(mtype)] = service.LimitRate{
+			Rate:  rate.Rate,
+			Burst: rate.Burst,
+		}
+	}
+	return lconf
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 	"github.com/textileio/textile-go/repo/config"
 	"github.com/textileio/textile-go/repo/db"
+	"github.com/textileio/textile-go/repo/db/postgres"
 	"github.com/textileio/textile-go/service"
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 )
@@ -88,6 +89,14 @@ type InitConfig struct {
 type MigrateConfig struct {
 	PinCode  string
 	RepoPath string
+	To       *int // target repo version, nil means latest
+}
+
+// RekeyConfig is used to change the datastore pin code
+type RekeyConfig struct {
+	RepoPath   string
+	PinCode    string // current pin code, empty if not encrypted
+	NewPinCode string // new pin code, empty to remove encryption
 }
 
 // RunConfig is used to define run options for a textile node
@@ -101,6 +110,7 @@ type RunConfig struct {
 type Textile struct {
 	context       oldcmds.Context
 	repoPath      string
+	pinCode       string
 	config        *config.Config
 	account       *keypair.Full
 	cancel        context.CancelFunc
@@ -118,7 +128,10 @@ type Textile struct {
 	cafe          *CafeService
 	cafeOutbox    *CafeOutbox
 	cafeInbox     *CafeInbox
+	limiter       *service.Limiter
+	lan           *lanPeers
 	mux           sync.Mutex
+	directMux     sync.Mutex
 	writer        io.Writer
 }
 
@@ -186,17 +199,21 @@ func InitRepo(conf InitConfig) error {
 	if err != nil {
 		return err
 	}
-	if err := sqliteDb.Contacts().Add(&repo.Contact{
+	self := &repo.Contact{
 		Id:      ipfsConf.Identity.PeerID,
 		Address: conf.Account.Address(),
-	}); err != nil {
+	}
+	if err := signContact(conf.Account, self); err != nil {
+		return err
+	}
+	if err := sqliteDb.Contacts().Add(self); err != nil {
 		return err
 	}
 
 	return applyTextileConfigOptions(conf)
 }
 
-// MigrateRepo runs _all_ repo migrations, including major
+// MigrateRepo runs _all_ repo migrations, including major, up (or down) to the target version
 func MigrateRepo(conf MigrateConfig) error {
 	if !fsrepo.IsInitialized(conf.RepoPath) {
 		return repo.ErrRepoDoesNotExist
@@ -206,7 +223,35 @@ func MigrateRepo(conf MigrateConfig) error {
 	removeLocks(conf.RepoPath)
 
 	// run _all_ repo migrations if needed
-	return repo.MigrateUp(conf.RepoPath, conf.PinCode, false)
+	return repo.MigrateTo(conf.RepoPath, conf.PinCode, false, migrationTarget(conf))
+}
+
+// PlanRepoMigration lists the migrations MigrateRepo would run, without running them
+func PlanRepoMigration(conf MigrateConfig) ([]repo.MigrationStep, error) {
+	if !fsrepo.IsInitialized(conf.RepoPath) {
+		return nil, repo.ErrRepoDoesNotExist
+	}
+	return repo.PlanMigration(conf.RepoPath, migrationTarget(conf))
+}
+
+// migrationTarget returns the target repo version of a migration config
+func migrationTarget(conf MigrateConfig) int {
+	if conf.To == nil {
+		return repo.LatestVersion()
+	}
+	return *conf.To
+}
+
+// RekeyRepo changes the datastore pin code of a repo which is not in use
+func RekeyRepo(conf RekeyConfig) error {
+	if !fsrepo.IsInitialized(conf.RepoPath) {
+		return repo.ErrRepoDoesNotExist
+	}
+
+	// force open the repo and datastore
+	removeLocks(conf.RepoPath)
+
+	return db.Rekey(conf.RepoPath, conf.PinCode, conf.NewPinCode)
 }
 
 // NewTextile runs a node out of an initialized repo
@@ -225,9 +270,11 @@ func NewTextile(conf RunConfig) (*Textile, error) {
 
 	node := &Textile{
 		repoPath:      conf.RepoPath,
+		pinCode:       conf.PinCode,
 		updates:       make(chan Update, 10),
 		threadUpdates: broadcast.NewBroadcaster(10),
 		notifications: make(chan NotificationInfo, 10),
+		lan:           newLanPeers(),
 	}
 
 	var err error
@@ -250,11 +297,10 @@ func NewTextile(conf RunConfig) (*Textile, error) {
 		return nil, err
 	}
 
-	sqliteDb, err := db.Create(conf.RepoPath, conf.PinCode)
+	node.datastore, err = openDatastore(conf.RepoPath, conf.PinCode, node.config.Datastore)
 	if err != nil {
 		return nil, err
 	}
-	node.datastore = sqliteDb
 
 	accnt, err := node.datastore.Config().GetAccount()
 	if err != nil {
@@ -288,11 +334,32 @@ func (t *Textile) Start() error {
 
 	t.online = make(chan struct{})
 
-	t.cafeInbox = NewCafeInbox(t.cafeService, t.threadsService, t.Ipfs, t.datastore)
+	t.limiter = service.NewLimiter(limiterConfig(t.config.Limits))
+	t.cafeInbox = NewCafeInbox(t.cafeService, t.threadsService, t.Ipfs, t.datastore, t.refuseInboxMessage)
 	t.cafeOutbox = NewCafeOutbox(t.cafeService, t.Ipfs, t.datastore)
-	t.threadsOutbox = NewThreadsOutbox(t.threadsService, t.Ipfs, t.datastore, t.cafeOutbox)
-	t.threads = NewThreadsService(t.account, t.Ipfs, t.datastore, t.Thread, t.AddThread, t.sendNotification)
-	t.cafe = NewCafeService(t.account, t.Ipfs, t.datastore, t.cafeInbox)
+	t.threadsOutbox = NewThreadsOutbox(t.threadsService, t.Ipfs, t.datastore, t.cafeOutbox, t.lan.list)
+	t.threads = NewThreadsService(
+		t.account,
+		t.Ipfs,
+		t.datastore,
+		t.Thread,
+		t.AddThread,
+		t.handleThreadInvite,
+		t.handleAccountThreadInvite,
+		t.sendNotification,
+		t.config.Threads.Pubsub,
+		t.limiter,
+	)
+	t.cafe = NewCafeService(
+		t.account,
+		t.Ipfs,
+		t.datastore,
+		t.cafeInbox,
+		filepath.Join(t.repoPath, "uploads"),
+		t.config.Cafe.Host.SizeLimit,
+		t.config.Cafe.Host.PubSub,
+		t.limiter,
+	)
 
 	// start the ipfs node
 	log.Debug("creating an ipfs node...")
@@ -300,6 +367,12 @@ func (t *Textile) Start() error {
 		log.Errorf("error creating offline ipfs node: %s", err)
 		return err
 	}
+	if err := t.UpdateContactEndpoint(); err != nil {
+		log.Errorf("error updating contact endpoint: %s", err)
+	}
+	if err := t.signProfile(); err != nil {
+		log.Errorf("error signing profile: %s", err)
+	}
 	go func() {
 		defer close(t.online)
 		if err := t.createIPFS(true); err != nil {
@@ -309,6 +382,7 @@ func (t *Textile) Start() error {
 
 		t.threads.service.Start()
 		t.threads.online = true
+		t.threads.startTopics()
 
 		t.cafe.service.Start()
 		t.cafe.online = true
@@ -323,6 +397,10 @@ func (t *Textile) Start() error {
 
 			t.cafe.open = true
 			t.startCafeApi(t.config.Addresses.CafeAPI)
+
+			if t.config.Cafe.Host.Relay {
+				t.cafe.startRelay(t.config.Cafe.Host)
+			}
 		}
 
 		go t.runQueues()
@@ -446,7 +524,14 @@ func (t *Textile) DoneCh() <-chan struct{} {
 
 // Ping pings another peer
 func (t *Textile) Ping(pid peer.ID) (service.PeerStatus, error) {
-	return t.cafe.Ping(pid)
+	status, err := t.cafe.Ping(pid)
+	if err != nil {
+		return status, err
+	}
+	if status == service.PeerOnline {
+		t.threads.seePeer(pid)
+	}
+	return status, nil
 }
 
 // UpdateCh returns the node update channel
@@ -515,6 +600,13 @@ func (t *Textile) createIPFS(online bool) error {
 		routing = core.DHTClientOption
 	}
 
+	if online {
+		rep, err = configureMDNS(rep, t.config.Threads.LAN, t.config.IsServer)
+		if err != nil {
+			log.Errorf("error configuring mdns: %s", err)
+		}
+	}
+
 	cfg := &core.BuildCfg{
 		Repo:      rep,
 		Permanent: true, // temporary way to signify that node is permanent
@@ -558,6 +650,11 @@ func (t *Textile) createIPFS(online bool) error {
 	t.cancel = cancel
 	t.node = nd
 
+	t.lan.reset()
+	if online {
+		nd.PeerHost.Network().Notify(t.lanNotifiee())
+	}
+
 	return nil
 }
 
@@ -593,14 +690,28 @@ func (t *Textile) flushQueues() {
 	}
 
 	go func() {
+		t.connectCafeRelays()
 		t.threadsOutbox.Flush()
 		if err := t.cafeInbox.CheckMessages(); err != nil {
 			log.Errorf("error checking messages: %s", err)
 		}
 		t.cafeOutbox.Flush()
+		t.snapshotThreads()
 	}()
 }
 
+// snapshotThreads adds a snapshot to each thread which has grown past the snapshot interval
+func (t *Textile) snapshotThreads() {
+	for _, thrd := range t.Threads() {
+		if !thrd.snapshotDue() {
+			continue
+		}
+		if _, err := thrd.Snapshot(); err != nil {
+			log.Errorf("error adding snapshot to %s: %s", thrd.Id, err)
+		}
+	}
+}
+
 // threadByBlock returns the thread owning the given block
 func (t *Textile) threadByBlock(block *repo.Block) (*Thread, error) {
 	if block == nil {
@@ -635,6 +746,7 @@ func (t *Textile) loadThread(mod *repo.Thread) (*Thread, error) {
 		ThreadsOutbox:      t.threadsOutbox,
 		CafeOutbox:         t.cafeOutbox,
 		SendUpdate:         t.sendThreadUpdate,
+		SendNotification:   t.sendNotification,
 		ContactDisplayInfo: t.ContactDisplayInfo,
 	}
 
@@ -643,6 +755,7 @@ func (t *Textile) loadThread(mod *repo.Thread) (*Thread, error) {
 		return nil, err
 	}
 	t.loadedThreads = append(t.loadedThreads, thrd)
+	t.threads.subscribeThread(thrd.Id)
 
 	return thrd, nil
 }
@@ -669,6 +782,10 @@ func (t *Textile) sendThreadUpdate(update ThreadUpdate) {
 
 // sendNotification adds a notification to the notification channel
 func (t *Textile) sendNotification(notification *repo.Notification) error {
+	if isMuted(t.datastore, notification.ActorId) {
+		return nil
+	}
+
 	if err := t.datastore.Notifications().Add(notification); err != nil {
 		return err
 	}
@@ -677,22 +794,80 @@ func (t *Textile) sendNotification(notification *repo.Notification) error {
 	return nil
 }
 
+// ChangePin changes the datastore pin code. The node must be stopped.
+func (t *Textile) ChangePin(oldPin string, newPin string) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.started {
+		return ErrStarted
+	}
+	if oldPin != t.pinCode {
+		return db.ErrInvalidPin
+	}
+
+	t.datastore.Close()
+	if err := db.Rekey(t.repoPath, oldPin, newPin); err != nil {
+		// re-open with the old pin
+		datastore, oerr := openDatastore(t.repoPath, oldPin, t.config.Datastore)
+		if oerr != nil {
+			log.Errorf("error re-opening datastore: %s", oerr)
+		} else {
+			t.datastore = datastore
+		}
+		return err
+	}
+	t.pinCode = newPin
+
+	datastore, err := openDatastore(t.repoPath, newPin, t.config.Datastore)
+	if err != nil {
+		return err
+	}
+	t.datastore = datastore
+
+	return nil
+}
+
 // touchDatastore ensures that we have a good db connection
 func (t *Textile) touchDatastore() error {
 	if err := t.datastore.Ping(); err != nil {
 		log.Debug("re-opening datastore...")
 
-		sqliteDB, err := db.Create(t.repoPath, "")
+		datastore, err := openDatastore(t.repoPath, t.pinCode, t.config.Datastore)
 		if err != nil {
 			log.Errorf("error re-opening datastore: %s", err)
 			return err
 		}
-		t.datastore = sqliteDB
+		t.datastore = datastore
 	}
 
 	return nil
 }
 
+// openDatastore opens the datastore backend selected in config
+func openDatastore(repoPath string, pin string, conf config.Datastore) (repo.Datastore, error) {
+	db.SetSlowQueryThreshold(time.Duration(conf.SlowQueryThreshold) * time.Millisecond)
+
+	sqliteDb, err := db.Create(repoPath, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	switch conf.Type {
+	case "", "sqlite":
+		return sqliteDb, nil
+	case "postgres":
+		pgDb, err := postgres.Create(conf.Postgres.URL, sqliteDb)
+		if err != nil {
+			sqliteDb.Close()
+			return nil, err
+		}
+		return pgDb, nil
+	default:
+		sqliteDb.Close()
+		return nil, fmt.Errorf("unknown datastore type: %s", conf.Type)
+	}
+}
+
 // setLogLevels hijacks the ipfs logging system, putting output to files
 func setLogLevels(repoPath string, logLevels map[string]string, disk bool) (io.Writer, error) {
 	var writer io.Writer
@@ -1,11 +1,13 @@
 package core_test
 
 import (
+	"bytes"
 	"crypto/rand"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
 	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
 	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
 
@@ -160,6 +162,106 @@ func TestTextile_AddFile(t *testing.T) {
 	}
 }
 
+func TestThread_Snapshot(t *testing.T) {
+	thrd := node.Threads()[0]
+	hash, err := thrd.Snapshot()
+	if err != nil {
+		t.Errorf("snapshot failed: %s", err)
+		return
+	}
+	head, err := thrd.Head()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if head != hash.B58String() {
+		t.Error("snapshot is not thread head")
+	}
+	info, err := thrd.Info()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if info.Head == nil || info.Head.Type != "SNAPSHOT" {
+		t.Error("wrong head block type")
+	}
+}
+
+func TestTextile_Fsck(t *testing.T) {
+	report, err := node.Fsck(false)
+	if err != nil {
+		t.Errorf("fsck failed: %s", err)
+		return
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("expected no problems, got %d", len(report.Problems))
+	}
+}
+
+func TestTextile_FsckRepair(t *testing.T) {
+	// corrupt the schema file pin
+	id, err := cid.Decode(schemaHash.B58String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Ipfs().Pinning.Unpin(node.Ipfs().Context(), id, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Ipfs().Pinning.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := node.Fsck(false)
+	if err != nil {
+		t.Fatalf("fsck failed: %s", err)
+	}
+	if len(report.Problems) != 1 {
+		t.Fatalf("expected 1 problem, got %d", len(report.Problems))
+	}
+	problem := report.Problems[0]
+	if problem.Kind != FsckUnpinnedFile || problem.Id != schemaHash.B58String() || problem.Repaired {
+		t.Errorf("wrong problem: %+v", problem)
+	}
+
+	report, err = node.Fsck(true)
+	if err != nil {
+		t.Fatalf("fsck repair failed: %s", err)
+	}
+	if len(report.Problems) != 1 || !report.Problems[0].Repaired {
+		t.Error("problem was not repaired")
+	}
+	_, pinned, err := node.Ipfs().Pinning.IsPinned(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned {
+		t.Error("schema file should be pinned again")
+	}
+
+	report, err = node.Fsck(false)
+	if err != nil {
+		t.Fatalf("fsck failed: %s", err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("expected no problems after repair, got %d", len(report.Problems))
+	}
+}
+
+func TestTextile_ExportImportThread(t *testing.T) {
+	thrd := node.Threads()[0]
+	var buf bytes.Buffer
+	if err := node.ExportThread(thrd.Id, &buf); err != nil {
+		t.Errorf("export thread failed: %s", err)
+		return
+	}
+	if _, err := node.ImportThread(bytes.NewReader(buf.Bytes())); err != ErrThreadExists {
+		t.Errorf("import of existing thread should fail with %s, got %v", ErrThreadExists, err)
+	}
+	if _, err := node.ImportThread(bytes.NewReader([]byte("garbage"))); err != ErrInvalidThreadArchive {
+		t.Errorf("import of garbage should fail with %s, got %v", ErrInvalidThreadArchive, err)
+	}
+}
+
 func TestTextile_Stop(t *testing.T) {
 	if err := node.Stop(); err != nil {
 		t.Errorf("stop node failed: %s", err)
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"time"
+
+	"github.com/textileio/textile-go/metrics"
+)
+
+var (
+	outboxDepth = metrics.NewGauge(
+		"textile_outbox_depth",
+		"Number of messages waiting in an outbox.",
+		"outbox")
+	outboxFlushSeconds = metrics.NewSummary(
+		"textile_outbox_flush_seconds",
+		"Time spent flushing an outbox.",
+		"outbox")
+	outboxFailuresTotal = metrics.NewCounter(
+		"textile_outbox_failures_total",
+		"Number of failed outbox flushes.",
+		"outbox")
+)
+
+// outbox metric labels
+const (
+	threadsOutboxLabel = "threads"
+	cafeOutboxLabel    = "cafe"
+)
+
+// observeFlush records the duration and outcome of an outbox flush started at start
+func observeFlush(outbox string, start time.Time, err error) {
+	outboxFlushSeconds.Observe(time.Since(start).Seconds(), outbox)
+	if err != nil {
+		outboxFailuresTotal.Inc(outbox)
+	}
+}
+
+// observeOutboxes records the current outbox queue depths
+func (t *Textile) observeOutboxes() {
+	outboxDepth.Set(float64(t.datastore.ThreadMessages().Count()), threadsOutboxLabel)
+	outboxDepth.Set(float64(t.datastore.CafeRequests().Count()), cafeOutboxLabel)
+}
@@ -25,13 +25,20 @@ type NotificationInfo struct {
 	Read      bool      `json:"read"`
 }
 
-// Notifications lists notifications
-func (t *Textile) Notifications(offset string, limit int) []NotificationInfo {
+// Notifications pages notifications
+func (t *Textile) Notifications(cursor string, limit int) (*ListPage, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	infos := make([]NotificationInfo, 0)
-	for _, note := range t.datastore.Notifications().List(offset, limit) {
+	notes := t.datastore.Notifications().List(offset, storeLimit(limit))
+	_, end, next, _ := PageBounds(len(notes), func(i int) string { return notes[i].Id }, "", limit)
+	for _, note := range notes[:end] {
 		infos = append(infos, t.NotificationInfo(note))
 	}
-	return infos
+	return &ListPage{Items: infos, Next: next}, nil
 }
 
 // NotificationInfo returns the notification info object
@@ -21,7 +21,7 @@ func (t *Textile) Overview() (*Overview, error) {
 	contacts := t.datastore.Contacts().Count()
 
 	return &Overview{
-		AccountPeerCount: 0,
+		AccountPeerCount: t.accountPeerCount(),
 		ThreadCount:      threads,
 		FileCount:        files,
 		ContactCount:     contacts - 1, // remove the contact for self
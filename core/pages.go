@@ -0,0 +1,76 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+// ErrInvalidCursor indicates a list cursor was not returned by a previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListPage is a page of list items.
+// Next is an opaque cursor to the following page, empty when there are no more items.
+type ListPage struct {
+	Items interface{} `json:"items"`
+	Next  string      `json:"next,omitempty"`
+}
+
+// PageBounds returns the bounds of the page following cursor in a list of n items,
+// along with the cursor to the next page. A negative limit returns all remaining items.
+func PageBounds(n int, id func(i int) string, cursor string, limit int) (int, int, string, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	var start int
+	if offset != "" {
+		start = -1
+		for i := 0; i < n; i++ {
+			if id(i) == offset {
+				start = i + 1
+				break
+			}
+		}
+		if start == -1 {
+			return 0, 0, "", ErrInvalidCursor
+		}
+	}
+
+	end := n
+	if limit >= 0 && start+limit < n {
+		end = start + limit
+	}
+
+	var next string
+	if end < n && end > start {
+		next = encodeCursor(id(end - 1))
+	}
+	return start, end, next, nil
+}
+
+// storeLimit returns the limit used to query a store for a page,
+// one extra item tells whether or not there is a following page
+func storeLimit(limit int) int {
+	if limit < 0 {
+		return -1
+	}
+	return limit + 1
+}
+
+// encodeCursor wraps the id of the last item in a page
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodeCursor returns the item id wrapped by a cursor
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(id) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(id), nil
+}
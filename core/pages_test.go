@@ -0,0 +1,41 @@
+package core_test
+
+import (
+	"testing"
+
+	. "github.com/textileio/textile-go/core"
+)
+
+func TestPageBounds(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e"}
+	id := func(i int) string { return ids[i] }
+
+	var got []string
+	var cursor string
+	for {
+		start, end, next, err := PageBounds(len(ids), id, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ids[start:end]...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(got) != len(ids) {
+		t.Errorf("expected %d items, got %d", len(ids), len(got))
+	}
+
+	if _, _, _, err := PageBounds(len(ids), id, "bogus", 2); err != ErrInvalidCursor {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+
+	start, end, next, err := PageBounds(len(ids), id, "", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 0 || end != len(ids) || next != "" {
+		t.Error("negative limit should list all items")
+	}
+}
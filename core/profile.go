@@ -34,6 +34,9 @@ func (t *Textile) SetUsername(username string) error {
 	if err := t.datastore.Contacts().UpdateUsername(t.node.Identity.Pretty(), username); err != nil {
 		return err
 	}
+	if err := t.signProfile(); err != nil {
+		return err
+	}
 
 	for _, thrd := range t.loadedThreads {
 		if _, err := thrd.annouce(); err != nil {
@@ -140,6 +143,9 @@ func (t *Textile) SetAvatar(hash string) error {
 	if err := t.datastore.Contacts().UpdateAvatar(t.node.Identity.Pretty(), avatar); err != nil {
 		return err
 	}
+	if err := t.signProfile(); err != nil {
+		return err
+	}
 
 	return t.PublishContact()
 }
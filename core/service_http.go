@@ -0,0 +1,100 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/golang/protobuf/proto"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/service"
+)
+
+// serviceHandler handles an admitted service envelope
+type serviceHandler func(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error)
+
+// serveServiceHTTP is an HTTP entry point for a service. Envelopes are
+// admitted (verified and rate limited) as they are for streams, but the
+// sender is only claimed by a header, so verify failures don't count toward a ban.
+// Bodies larger than maxSize are refused.
+func serveServiceHTTP(g *gin.Context, node *Textile, srv *service.Service, maxSize int64, handle serviceHandler) {
+	if !node.Online() {
+		g.String(http.StatusInternalServerError, "node is offline")
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(g.Writer, g.Request.Body, maxSize))
+	if err != nil {
+		if int64(len(body)) >= maxSize {
+			g.String(http.StatusRequestEntityTooLarge, err.Error())
+		} else {
+			g.String(http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	// parse body as a service envelope
+	pmes := new(pb.Envelope)
+	if err := proto.Unmarshal(body, pmes); err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	peerId := g.Request.Header.Get("X-Textile-Peer")
+	if peerId == "" {
+		g.String(http.StatusBadRequest, "missing peer ID")
+		return
+	}
+	mPeer, err := peer.IDB58Decode(peerId)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch err := srv.AdmitClaimed(pmes, mPeer); err {
+	case nil:
+	case service.ErrRateLimited:
+		g.String(http.StatusTooManyRequests, err.Error())
+		return
+	case service.ErrPeerBanned:
+		g.String(http.StatusForbidden, err.Error())
+		return
+	default:
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// handle the message as normal
+	log.Debugf("received %s from %s (trace %s)", pmes.Message.Type.String(), mPeer.Pretty(), pmes.Message.TraceId)
+	done := service.StartSpan(service.SpanHandleHTTP, pmes, mPeer.Pretty())
+	rpmes, err := handle(mPeer, pmes)
+	done(err)
+	if err == service.ErrUnsupportedMessage {
+		g.String(http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if rpmes == nil {
+		g.Status(200)
+		return
+	}
+
+	// send out response msg
+	log.Debugf("responding with %s to %s (trace %s)", rpmes.Message.Type.String(), mPeer.Pretty(), rpmes.Message.TraceId)
+
+	res, err := proto.Marshal(rpmes)
+	if err != nil {
+		g.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// ship it
+	g.Render(200, render.Data{Data: res})
+}
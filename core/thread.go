@@ -16,6 +16,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/segmentio/ksuid"
 	"github.com/textileio/textile-go/crypto"
 	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/pb"
@@ -100,6 +101,7 @@ type ThreadConfig struct {
 	ThreadsOutbox      *ThreadsOutbox
 	CafeOutbox         *CafeOutbox
 	SendUpdate         func(update ThreadUpdate)
+	SendNotification   func(note *repo.Notification) error
 	ContactDisplayInfo func(id string) (string, string)
 }
 
@@ -121,6 +123,7 @@ type Thread struct {
 	threadsOutbox      *ThreadsOutbox
 	cafeOutbox         *CafeOutbox
 	sendUpdate         func(update ThreadUpdate)
+	sendNotification   func(note *repo.Notification) error
 	contactDisplayInfo func(id string) (string, string)
 	mux                sync.Mutex
 }
@@ -157,6 +160,7 @@ func NewThread(model *repo.Thread, conf *ThreadConfig) (*Thread, error) {
 		threadsOutbox:      conf.ThreadsOutbox,
 		cafeOutbox:         conf.CafeOutbox,
 		sendUpdate:         conf.SendUpdate,
+		sendNotification:   conf.SendNotification,
 		contactDisplayInfo: conf.ContactDisplayInfo,
 	}, nil
 }
@@ -269,21 +273,29 @@ func (t *Thread) followParents(parents []string) error {
 	return nil
 }
 
-// followParent tries to follow a chain of block ids, processing along the way
+// followParent tries to follow a chain of block ids, processing along the way.
+// The chain ends at known blocks and at blocks older than our own pruning snapshot.
 func (t *Thread) followParent(parent mh.Multihash) error {
+	if t.datastore.Blocks().Get(parent.B58String()) != nil {
+		// exists, abort
+		return nil
+	}
 	ciphertext, err := ipfs.DataAtPath(t.node(), parent.B58String())
 	if err != nil {
 		return err
 	}
 
-	block, err := t.handleBlock(parent, ciphertext)
+	block, err := t.decodeBlock(ciphertext)
 	if err != nil {
 		return err
 	}
-	if block == nil {
-		// exists, abort
+	if t.pruned(block.Header) {
+		// already pruned, abort
 		return nil
 	}
+	if _, err := t.addBlock(ciphertext); err != nil {
+		return err
+	}
 
 	switch block.Type {
 	case pb.ThreadBlock_MERGE:
@@ -306,6 +318,19 @@ func (t *Thread) followParent(parent mh.Multihash) error {
 		_, err = t.handleCommentBlock(parent, block)
 	case pb.ThreadBlock_LIKE:
 		_, err = t.handleLikeBlock(parent, block)
+	case pb.ThreadBlock_DEVICE:
+		_, err = t.handleDeviceBlock(parent, block)
+	case pb.ThreadBlock_SNAPSHOT:
+		var bootstrap bool
+		bootstrap, err = t.bootstrapping()
+		if err != nil {
+			return err
+		}
+		_, err = t.handleSnapshotBlock(parent, block, bootstrap)
+		if err == nil && bootstrap {
+			// snapshot state replaces older blocks, stop here
+			return nil
+		}
 	default:
 		return errors.New(fmt.Sprintf("invalid message type: %s", block.Type))
 	}
@@ -318,6 +343,8 @@ func (t *Thread) followParent(parent mh.Multihash) error {
 
 // addOrUpdateContact collects thread peers and saves them as contacts
 func (t *Thread) addOrUpdateContact(contact *repo.Contact) error {
+	contact = trustContactClaims(contact)
+	added := true
 	if err := t.datastore.ThreadPeers().Add(&repo.ThreadPeer{
 		Id:       contact.Id,
 		ThreadId: t.Id,
@@ -326,13 +353,68 @@ func (t *Thread) addOrUpdateContact(contact *repo.Contact) error {
 		if !repo.ConflictError(err) {
 			return err
 		}
+		added = false
 	}
 
+	// only existing contacts are updated, unknown peers are not added
 	ex := t.datastore.Contacts().Get(contact.Id)
-	if ex != nil && contact.Updated.UnixNano() >= ex.Updated.UnixNano() {
-		return t.datastore.Contacts().AddOrUpdate(contact)
+	if ex == nil {
+		if !added {
+			return nil
+		}
+		return t.notifyContactChange(nil, contact)
 	}
-	return nil
+	if contact.Updated.UnixNano() < ex.Updated.UnixNano() {
+		return nil
+	}
+	if err := t.notifyContactChange(ex, contact); err != nil {
+		return err
+	}
+	return t.datastore.Contacts().AddOrUpdate(contact)
+}
+
+// notifyContactChange warns when a known peer binds to a different account address,
+// or when a new peer appears for an already known account address
+func (t *Thread) notifyContactChange(ex *repo.Contact, contact *repo.Contact) error {
+	if contact.Id == t.node().Identity.Pretty() {
+		return nil
+	}
+	// our own devices are tracked by the account thread device registry
+	if contact.Address == t.config.Account.Address {
+		return nil
+	}
+
+	var body string
+	var verified bool
+	if ex != nil {
+		if ex.Address == contact.Address {
+			return nil
+		}
+		body = "changed account address"
+		verified = ex.Verified
+	} else {
+		known := t.datastore.Contacts().Find("", contact.Address, "")
+		if len(known) == 0 {
+			return nil
+		}
+		body = "added a new peer to their account"
+		for _, k := range known {
+			verified = verified || k.Verified
+		}
+	}
+	if verified {
+		body += ", verify their safety number again"
+	}
+
+	return t.sendNotification(&repo.Notification{
+		Id:        ksuid.New().String(),
+		Date:      time.Now(),
+		ActorId:   contact.Id,
+		Subject:   t.Name,
+		SubjectId: t.Id,
+		Type:      repo.ContactChangedNotification,
+		Body:      body,
+	})
 }
 
 // newBlockHeader creates a new header
@@ -427,6 +509,19 @@ func (t *Thread) handleBlock(hash mh.Multihash, ciphertext []byte) (*pb.ThreadBl
 		return nil, nil
 	}
 
+	block, err := t.decodeBlock(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := t.addBlock(ciphertext); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// decodeBlock decrypts and unmarshals a block
+func (t *Thread) decodeBlock(ciphertext []byte) (*pb.ThreadBlock, error) {
 	block := new(pb.ThreadBlock)
 	plaintext, err := t.Decrypt(ciphertext)
 	if err != nil {
@@ -445,10 +540,6 @@ func (t *Thread) handleBlock(hash mh.Multihash, ciphertext []byte) (*pb.ThreadBl
 	if block.Payload == nil && block.Type != pb.ThreadBlock_MERGE && block.Type != pb.ThreadBlock_LEAVE {
 		return nil, errors.New("nil message payload")
 	}
-
-	if _, err := t.addBlock(ciphertext); err != nil {
-		return nil, err
-	}
 	return block, nil
 }
 
@@ -582,6 +673,20 @@ func (t *Thread) post(commit *commitResult, peers []repo.ThreadPeer) error {
 	if err != nil {
 		return err
 	}
+
+	// large threads announce over pubsub, peers not seen on the topic get direct delivery
+	if t.service().fanout(len(peers)) {
+		if err := t.service().publishThread(t.Id, env); err != nil {
+			log.Warningf("error publishing to thread topic %s: %s", t.Id, err)
+		} else {
+			peers = t.service().unseenPeers(t.Id, peers)
+			if len(peers) == 0 {
+				go t.cafeOutbox.Flush()
+				return nil
+			}
+		}
+	}
+
 	for _, tp := range peers {
 		pid, err := peer.IDB58Decode(tp.Id)
 		if err != nil {
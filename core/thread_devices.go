@@ -0,0 +1,220 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/crypto"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+// deviceInviteMaxAge is how long an account signed device invite is accepted for
+const deviceInviteMaxAge = time.Hour * 24
+
+// deviceInviteMaxSkew is how far in the future an account signed device invite may be dated
+const deviceInviteMaxSkew = time.Minute * 5
+
+// ErrDeviceNotAccountPeer indicates a device block was authored outside of the account
+var ErrDeviceNotAccountPeer = errors.New("device blocks must be authored by an account peer")
+
+// addDevice adds an outgoing device block, which registers (or removes) a device
+// in the account thread device registry
+func (t *Thread) addDevice(id string, name string, removed bool) (mh.Multihash, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	msg := &pb.ThreadDevice{
+		Id:      id,
+		Name:    name,
+		Removed: removed,
+	}
+
+	res, err := t.commitBlock(msg, pb.ThreadBlock_DEVICE, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.indexBlock(res, repo.DeviceBlock, id, name); err != nil {
+		return nil, err
+	}
+
+	if err := t.applyDevice(msg, res.header); err != nil {
+		return nil, err
+	}
+
+	if err := t.updateHead(res.hash); err != nil {
+		return nil, err
+	}
+
+	if err := t.post(res, t.Peers()); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("added DEVICE to %s: %s", t.Id, res.hash.B58String())
+
+	return res.hash, nil
+}
+
+// accountThread returns whether or not this is the account thread, or its replacement
+func (t *Thread) accountThread() bool {
+	address := t.config.Account.Address
+	return t.Key == address || t.Key == accountThreadRotationKey+address
+}
+
+// handleDeviceBlock handles an incoming device block
+func (t *Thread) handleDeviceBlock(hash mh.Multihash, block *pb.ThreadBlock) (*pb.ThreadDevice, error) {
+	if block.Header.Address != t.config.Account.Address {
+		return nil, ErrDeviceNotAccountPeer
+	}
+
+	msg := new(pb.ThreadDevice)
+	if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+		return nil, err
+	}
+
+	if err := t.indexBlock(&commitResult{
+		hash:   hash,
+		header: block.Header,
+	}, repo.DeviceBlock, msg.Id, msg.Name); err != nil {
+		return nil, err
+	}
+
+	if err := t.applyDevice(msg, block.Header); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// applyDevice updates the local device registry
+func (t *Thread) applyDevice(msg *pb.ThreadDevice, header *pb.ThreadBlockHeader) error {
+	if msg.Removed {
+		return t.datastore.Devices().Delete(msg.Id)
+	}
+
+	date, err := ptypes.Timestamp(header.Date)
+	if err != nil {
+		return err
+	}
+	return t.datastore.Devices().AddOrUpdate(&repo.Device{
+		Id:   msg.Id,
+		Name: msg.Name,
+		Date: date,
+	})
+}
+
+// sendDeviceInvite sends an account-signed invite directly to another device of this account.
+// Link requests carry no thread key, approvals carry the account thread key.
+// The envelope is addressed to the thread key (account address) so that the recipient,
+// which may already have a thread with the same id, handles it as an invite.
+func (t *Thread) sendDeviceInvite(deviceId peer.ID, name string, sign func([]byte) ([]byte, error), withKey bool) (mh.Multihash, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var threadSk []byte
+	var threadId string
+	if withKey {
+		var err error
+		threadSk, err = t.privKey.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		threadId = t.Id
+	}
+
+	date := time.Now()
+	pdate, err := ptypes.TimestampProto(date)
+	if err != nil {
+		return nil, err
+	}
+	self := t.node().Identity.Pretty()
+	sig, err := sign(deviceInvitePayload(self, deviceId.Pretty(), threadId, date))
+	if err != nil {
+		return nil, err
+	}
+
+	contact := t.datastore.Contacts().Get(self)
+	msg := &pb.ThreadInvite{
+		Sk:          threadSk,
+		Name:        name,
+		Initiator:   t.initiator,
+		Contact:     repoContactToProto(contact),
+		AccountSig:  sig,
+		AccountDate: pdate,
+	}
+
+	devicePk, err := deviceId.ExtractPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.commitBlock(msg, pb.ThreadBlock_INVITE, func(plaintext []byte) ([]byte, error) {
+		return crypto.Encrypt(devicePk, plaintext)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := t.service().NewEnvelope(t.Key, res.hash, res.ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.threadsOutbox.Add(deviceId, env); err != nil {
+		return nil, err
+	}
+
+	go t.threadsOutbox.Flush()
+
+	log.Debugf("sent device INVITE to %s for %s", deviceId.Pretty(), t.Id)
+
+	return res.hash, nil
+}
+
+// deviceInvitePayload returns the bytes covered by the account signature of a device invite.
+// The signature is bound to the sending and receiving devices, the shared thread (if any)
+// and a date, so that it can't be replayed to other devices or indefinitely.
+func deviceInvitePayload(author string, device string, threadId string, date time.Time) []byte {
+	return []byte(strings.Join([]string{
+		author,
+		device,
+		threadId,
+		strconv.FormatInt(date.UnixNano(), 10),
+	}, "/"))
+}
+
+// verifyDeviceInvite checks the account signature and freshness of a device invite
+// received by the local device
+func verifyDeviceInvite(verify func(data []byte, sig []byte) error, self string, author string, msg *pb.ThreadInvite) error {
+	if msg.AccountDate == nil {
+		return errors.New("missing account signature date")
+	}
+	date, err := ptypes.Timestamp(msg.AccountDate)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if now.Sub(date) > deviceInviteMaxAge || date.Sub(now) > deviceInviteMaxSkew {
+		return errors.New("stale account signature")
+	}
+
+	var threadId string
+	if len(msg.Sk) > 0 {
+		sk, err := libp2pc.UnmarshalPrivateKey(msg.Sk)
+		if err != nil {
+			return err
+		}
+		id, err := peer.IDFromPrivateKey(sk)
+		if err != nil {
+			return err
+		}
+		threadId = id.Pretty()
+	}
+	return verify(deviceInvitePayload(author, self, threadId, date), msg.AccountSig)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+)
+
+func signedDeviceInvite(t *testing.T, accnt *keypair.Full, author string, device string, withKey bool, date time.Time) *pb.ThreadInvite {
+	var sk []byte
+	var threadId string
+	if withKey {
+		tsk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk, err = tsk.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := peer.IDFromPrivateKey(tsk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		threadId = id.Pretty()
+	}
+	sig, err := accnt.Sign(deviceInvitePayload(author, device, threadId, date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdate, err := ptypes.TimestampProto(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.ThreadInvite{Sk: sk, AccountSig: sig, AccountDate: pdate}
+}
+
+func TestVerifyDeviceInvite(t *testing.T) {
+	accnt := keypair.Random()
+	for _, withKey := range []bool{false, true} {
+		msg := signedDeviceInvite(t, accnt, "author", "device", withKey, time.Now())
+		if err := verifyDeviceInvite(accnt.Verify, "device", "author", msg); err != nil {
+			t.Errorf("valid invite (with key %v) failed verification: %s", withKey, err)
+		}
+	}
+}
+
+func TestVerifyDeviceInvite_OtherDevice(t *testing.T) {
+	accnt := keypair.Random()
+	msg := signedDeviceInvite(t, accnt, "author", "device", false, time.Now())
+	if err := verifyDeviceInvite(accnt.Verify, "other", "author", msg); err == nil {
+		t.Error("invite replayed to another device should fail verification")
+	}
+	if err := verifyDeviceInvite(accnt.Verify, "device", "other", msg); err == nil {
+		t.Error("invite replayed by another author should fail verification")
+	}
+}
+
+func TestVerifyDeviceInvite_OtherKey(t *testing.T) {
+	accnt := keypair.Random()
+	msg := signedDeviceInvite(t, accnt, "author", "device", true, time.Now())
+	other := signedDeviceInvite(t, accnt, "author", "device", true, time.Now())
+	msg.Sk = other.Sk
+	if err := verifyDeviceInvite(accnt.Verify, "device", "author", msg); err == nil {
+		t.Error("invite with a swapped thread key should fail verification")
+	}
+}
+
+func TestVerifyDeviceInvite_Stale(t *testing.T) {
+	accnt := keypair.Random()
+	old := signedDeviceInvite(t, accnt, "author", "device", false, time.Now().Add(-deviceInviteMaxAge*2))
+	if err := verifyDeviceInvite(accnt.Verify, "device", "author", old); err == nil {
+		t.Error("stale invite should fail verification")
+	}
+	future := signedDeviceInvite(t, accnt, "author", "device", false, time.Now().Add(time.Hour))
+	if err := verifyDeviceInvite(accnt.Verify, "device", "author", future); err == nil {
+		t.Error("future dated invite should fail verification")
+	}
+	missing := signedDeviceInvite(t, accnt, "author", "device", false, time.Now())
+	missing.AccountDate = nil
+	if err := verifyDeviceInvite(accnt.Verify, "device", "author", missing); err == nil {
+		t.Error("invite without a date should fail verification")
+	}
+}
+
+func TestVerifyDeviceInvite_OtherAccount(t *testing.T) {
+	msg := signedDeviceInvite(t, keypair.Random(), "author", "device", false, time.Now())
+	if err := verifyDeviceInvite(keypair.Random().Verify, "device", "author", msg); err == nil {
+		t.Error("invite signed by another account should fail verification")
+	}
+}
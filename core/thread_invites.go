@@ -19,6 +19,20 @@ func (t *Thread) AddInvite(inviteeId peer.ID) (mh.Multihash, error) {
 		return nil, ErrInvitesNotAllowed
 	}
 
+	return t.sendInvite(inviteeId, false)
+}
+
+// addDirectInvite creates an outgoing invite for the other party of a direct thread.
+// Unlike AddInvite, this is allowed for private threads.
+func (t *Thread) addDirectInvite(inviteeId peer.ID) (mh.Multihash, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return t.sendInvite(inviteeId, true)
+}
+
+// sendInvite encrypts an invite block with the invitee's public key and posts it
+func (t *Thread) sendInvite(inviteeId peer.ID, direct bool) (mh.Multihash, error) {
 	threadSk, err := t.privKey.Bytes()
 	if err != nil {
 		return nil, err
@@ -30,6 +44,7 @@ func (t *Thread) AddInvite(inviteeId peer.ID) (mh.Multihash, error) {
 		Schema:    t.schemaId,
 		Initiator: t.initiator,
 		Contact:   repoContactToProto(contact),
+		Direct:    direct,
 	}
 
 	inviteePk, err := inviteeId.ExtractPublicKey()
@@ -0,0 +1,334 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrInvalidSnapshot indicates a snapshot block signature did not match its author
+var ErrInvalidSnapshot = errors.New("invalid snapshot signature")
+
+// ErrSnapshotAccountThread indicates a snapshot was requested for the account thread
+var ErrSnapshotAccountThread = errors.New("account thread can't be snapshotted")
+
+// ErrSnapshotUnsupported indicates a thread peer is not known to handle snapshot blocks
+var ErrSnapshotUnsupported = errors.New("thread peers don't all handle snapshots")
+
+// Snapshot adds an outgoing snapshot block, which summarizes current thread state
+// (members, live files, and ignores) so that new peers don't need to walk the whole chain
+func (t *Thread) Snapshot() (mh.Multihash, error) {
+	// checked before locking, peers may need to be contacted
+	if err := t.snapshotAllowed(); err != nil {
+		return nil, err
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	msg, err := t.buildSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.commitBlock(msg, pb.ThreadBlock_SNAPSHOT, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.indexBlock(res, repo.SnapshotBlock, "", ""); err != nil {
+		return nil, err
+	}
+
+	if err := t.updateHead(res.hash); err != nil {
+		return nil, err
+	}
+
+	if err := t.post(res, t.Peers()); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("added SNAPSHOT to %s: %s", t.Id, res.hash.B58String())
+
+	if t.config.Threads.Snapshots.Prune {
+		if err := t.pruneBefore(res.hash.B58String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return res.hash, nil
+}
+
+// handleSnapshotBlock handles an incoming snapshot block.
+// Snapshot state is only applied when bootstrapping, i.e., when there's no local history,
+// in which case callers should not follow the parents of the snapshot.
+// Otherwise, the snapshot is just indexed and callers keep following the chain.
+// Snapshots from other peers never prune local history, their dates can't be trusted.
+func (t *Thread) handleSnapshotBlock(hash mh.Multihash, block *pb.ThreadBlock, bootstrap bool) (*pb.ThreadSnapshotState, error) {
+	msg := new(pb.ThreadSnapshot)
+	if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+		return nil, err
+	}
+	state, err := verifySnapshot(msg, block.Header.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	if bootstrap {
+		if err := t.applySnapshot(state, block.Header.Author); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.indexBlock(&commitResult{
+		hash:   hash,
+		header: block.Header,
+	}, repo.SnapshotBlock, "", ""); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// snapshotAllowed returns an error if the thread can't be snapshotted.
+// The account thread is never snapshotted, its device registry is not part of snapshot state.
+// Every thread peer must handle snapshot blocks, otherwise peers which don't would stop
+// following the chain at the snapshot, which is a parent of all later blocks.
+func (t *Thread) snapshotAllowed() error {
+	if t.accountThread() {
+		return ErrSnapshotAccountThread
+	}
+	for _, tp := range t.Peers() {
+		pid, err := peer.IDB58Decode(tp.Id)
+		if err != nil {
+			return err
+		}
+		if !t.service().supportsSnapshots(pid) {
+			return ErrSnapshotUnsupported
+		}
+	}
+	return nil
+}
+
+// bootstrapping returns whether or not the thread has no local history yet
+func (t *Thread) bootstrapping() (bool, error) {
+	head, err := t.Head()
+	if err != nil {
+		return false, err
+	}
+	return head == "", nil
+}
+
+// applySnapshot adds the members, ignores, and files of a snapshot.
+// Only the author's own contact info is trusted, other members are just added as peers.
+// Ignores and files are handled from their original blocks.
+func (t *Thread) applySnapshot(state *pb.ThreadSnapshotState, author string) error {
+	self := t.node().Identity.Pretty()
+	for _, member := range state.Members {
+		if member == nil || member.Id == self {
+			continue
+		}
+		if member.Id == author {
+			if err := t.addOrUpdateContact(protoContactToRepo(member)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.datastore.ThreadPeers().Add(&repo.ThreadPeer{
+			Id:       member.Id,
+			ThreadId: t.Id,
+			Welcomed: false,
+		}); err != nil && !repo.ConflictError(err) {
+			return err
+		}
+	}
+
+	for _, id := range state.Ignores {
+		if err := t.handleSnapshotRef(id, pb.ThreadBlock_IGNORE); err != nil {
+			log.Warningf("failed to handle snapshot ignore %s: %s", id, err)
+		}
+	}
+	for _, id := range state.Files {
+		if err := t.handleSnapshotRef(id, pb.ThreadBlock_FILES); err != nil {
+			log.Warningf("failed to handle snapshot files %s: %s", id, err)
+		}
+	}
+	return nil
+}
+
+// handleSnapshotRef handles a block referenced by a snapshot, without following its parents
+func (t *Thread) handleSnapshotRef(id string, btype pb.ThreadBlock_Type) error {
+	hash, err := mh.FromB58String(id)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := ipfs.DataAtPath(t.node(), id)
+	if err != nil {
+		return err
+	}
+	block, err := t.handleBlock(hash, ciphertext)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		// exists
+		return nil
+	}
+	if block.Type != btype {
+		return ErrBlockWrongType
+	}
+
+	switch btype {
+	case pb.ThreadBlock_IGNORE:
+		_, err = t.handleIgnoreBlock(hash, block)
+	case pb.ThreadBlock_FILES:
+		_, err = t.handleFilesBlock(hash, block)
+	}
+	return err
+}
+
+// buildSnapshot builds up a signed snapshot block from the local index
+func (t *Thread) buildSnapshot() (*pb.ThreadSnapshot, error) {
+	state := &pb.ThreadSnapshotState{}
+
+	self := t.datastore.Contacts().Get(t.node().Identity.Pretty())
+	if self != nil {
+		state.Members = append(state.Members, repoContactToProto(self))
+	}
+	for _, tp := range t.Peers() {
+		contact := t.datastore.Contacts().Get(tp.Id)
+		if contact == nil {
+			contact = &repo.Contact{Id: tp.Id}
+		}
+		state.Members = append(state.Members, repoContactToProto(contact))
+	}
+
+	query := fmt.Sprintf("threadId='%s' and type=%d", t.Id, repo.IgnoreBlock)
+	for _, block := range t.datastore.Blocks().List("", -1, query) {
+		state.Ignores = append(state.Ignores, block.Id)
+	}
+
+	query = fmt.Sprintf("threadId='%s' and type=%d", t.Id, repo.FilesBlock)
+	for _, block := range t.datastore.Blocks().List("", -1, query) {
+		if t.datastore.Blocks().Count("target='ignore-"+block.Id+"'") > 0 {
+			continue
+		}
+		state.Files = append(state.Files, block.Id)
+	}
+
+	// sign the exact bytes which are sent, proto encoding is not deterministic
+	data, err := proto.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := t.node().PrivateKey.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ThreadSnapshot{
+		State: data,
+		Sig:   sig,
+	}, nil
+}
+
+// snapshotDue returns whether or not enough blocks were added since the latest snapshot
+func (t *Thread) snapshotDue() bool {
+	interval := t.config.Threads.Snapshots.Interval
+	if interval <= 0 {
+		return false
+	}
+
+	query := fmt.Sprintf("threadId='%s'", t.Id)
+	latest := t.datastore.Blocks().List("", 1, fmt.Sprintf("%s and type=%d", query, repo.SnapshotBlock))
+	if len(latest) > 0 {
+		query += fmt.Sprintf(" and date>%d", latest[0].Date.UnixNano())
+	}
+	if t.datastore.Blocks().Count(query) < interval {
+		return false
+	}
+	if err := t.snapshotAllowed(); err != nil {
+		log.Debugf("skipping snapshot of %s: %s", t.Id, err)
+		return false
+	}
+	return true
+}
+
+// pruneBefore removes local blocks older than the given snapshot.
+// Files and ignore blocks are kept, they are needed for the next snapshot.
+func (t *Thread) pruneBefore(snapshotId string) error {
+	snapshot := t.datastore.Blocks().Get(snapshotId)
+	if snapshot == nil {
+		return nil
+	}
+
+	query := fmt.Sprintf("threadId='%s' and date<%d and type not in (%d,%d,%d)",
+		t.Id, snapshot.Date.UnixNano(), repo.FilesBlock, repo.IgnoreBlock, repo.SnapshotBlock)
+	blocks := t.datastore.Blocks().List("", -1, query)
+	for _, block := range blocks {
+		if node, err := ipfs.NodeAtPath(t.node(), block.Id); err == nil {
+			if err := ipfs.UnpinNode(t.node(), node, false); err != nil {
+				return err
+			}
+		}
+		if err := t.datastore.Notifications().DeleteByBlock(block.Id); err != nil {
+			return err
+		}
+		if err := t.datastore.Blocks().Delete(block.Id); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("pruned %d blocks from %s before %s", len(blocks), t.Id, snapshotId)
+
+	return nil
+}
+
+// pruned returns whether or not a block is older than the latest snapshot this node
+// pruned its history before. Such blocks are not fetched again when following parents.
+func (t *Thread) pruned(header *pb.ThreadBlockHeader) bool {
+	if !t.config.Threads.Snapshots.Prune {
+		return false
+	}
+	query := fmt.Sprintf("threadId='%s' and type=%d and authorId='%s'",
+		t.Id, repo.SnapshotBlock, t.node().Identity.Pretty())
+	latest := t.datastore.Blocks().List("", 1, query)
+	if len(latest) == 0 {
+		return false
+	}
+	date, err := ptypes.Timestamp(header.Date)
+	if err != nil {
+		return false
+	}
+	return date.Before(latest[0].Date)
+}
+
+// verifySnapshot checks a snapshot signature against its author, returning its state
+func verifySnapshot(msg *pb.ThreadSnapshot, author string) (*pb.ThreadSnapshotState, error) {
+	pid, err := peer.IDB58Decode(author)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := pid.ExtractPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := pk.Verify(msg.State, msg.Sig)
+	if err != nil || !ok {
+		return nil, ErrInvalidSnapshot
+	}
+
+	state := new(pb.ThreadSnapshotState)
+	if err := proto.Unmarshal(msg.State, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,328 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/segmentio/ksuid"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/mill"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+var pruneRepoPath = "testdata/.textile-prune"
+
+var snapshotRepoPaths = []string{
+	"testdata/.textile-snapshot1",
+	"testdata/.textile-snapshot2",
+	"testdata/.textile-snapshot3",
+}
+
+var snapshotSchema = `
+{
+  "name": "blob",
+  "pin": true,
+  "mill": "/blob"
+}
+`
+
+// waitForSnapshot polls cond until it's true, failing after a minute
+func waitForSnapshot(t *testing.T, desc string, cond func() bool) {
+	deadline := time.Now().Add(time.Minute)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(time.Millisecond * 250)
+	}
+}
+
+// connectSnapshotNodes connects node to other over the local swarm
+func connectSnapshotNodes(t *testing.T, node *Textile, other *Textile) {
+	addr := fmt.Sprintf("%s/ipfs/%s", other.node.PeerHost.Addrs()[0], other.node.Identity.Pretty())
+	if _, err := ipfs.SwarmConnect(node.node, []string{addr}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// joinSnapshotThread joins node to thrd with an external invite
+func joinSnapshotThread(t *testing.T, node *Textile, thrd *Thread) {
+	hash, key, err := thrd.AddExternalInvite()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.AcceptExternalThreadInvite(hash.B58String(), key); err != nil {
+		t.Fatalf("accept invite failed: %s", err)
+	}
+	id := node.node.Identity.Pretty()
+	waitForSnapshot(t, "join", func() bool {
+		for _, tp := range thrd.Peers() {
+			if tp.Id == id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// addSnapshotFile adds a files block with a single file to thrd
+func addSnapshotFile(t *testing.T, node *Textile, thrd *Thread, data string) mh.Multihash {
+	file, err := node.AddFile(&mill.Blob{}, AddFileConfig{
+		Input: []byte(data),
+		Name:  data + ".txt",
+		Media: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("add file failed: %s", err)
+	}
+	dir, keys, err := node.AddNodeFromFiles([]repo.File{*file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := thrd.AddFiles(dir, data, keys)
+	if err != nil {
+		t.Fatalf("add files failed: %s", err)
+	}
+	return hash
+}
+
+// snapshotMembers returns the sorted ids of the local peer and the thread peers
+func snapshotMembers(node *Textile, thrd *Thread) []string {
+	ids := []string{node.node.Identity.Pretty()}
+	for _, tp := range thrd.Peers() {
+		ids = append(ids, tp.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// snapshotBlocks returns the sorted ids of a thread's indexed blocks of a type,
+// leaving out ignored blocks
+func snapshotBlocks(node *Textile, thrd *Thread, btype repo.BlockType) []string {
+	var ids []string
+	query := fmt.Sprintf("threadId='%s' and type=%d", thrd.Id, btype)
+	for _, block := range node.datastore.Blocks().List("", -1, query) {
+		if node.datastore.Blocks().Count("target='ignore-"+block.Id+"'") > 0 {
+			continue
+		}
+		ids = append(ids, block.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sameIds(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func signedSnapshot(t *testing.T, state *pb.ThreadSnapshotState) (*pb.ThreadSnapshot, string) {
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sk.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.ThreadSnapshot{State: data, Sig: sig}, pid.Pretty()
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	msg, author := signedSnapshot(t, &pb.ThreadSnapshotState{
+		Files:   []string{"files"},
+		Ignores: []string{"ignore"},
+	})
+	state, err := verifySnapshot(msg, author)
+	if err != nil {
+		t.Fatalf("valid snapshot failed verification: %s", err)
+	}
+	if len(state.Files) != 1 || state.Files[0] != "files" || len(state.Ignores) != 1 {
+		t.Error("verified snapshot state does not match")
+	}
+}
+
+func TestVerifySnapshot_Tampered(t *testing.T) {
+	msg, author := signedSnapshot(t, &pb.ThreadSnapshotState{Files: []string{"files"}})
+	msg.State[len(msg.State)-1] ^= 1
+	if _, err := verifySnapshot(msg, author); err != ErrInvalidSnapshot {
+		t.Errorf("expected invalid snapshot for tampered state, got %v", err)
+	}
+}
+
+func TestVerifySnapshot_OtherAuthor(t *testing.T) {
+	msg, _ := signedSnapshot(t, &pb.ThreadSnapshotState{Files: []string{"files"}})
+	_, other := signedSnapshot(t, &pb.ThreadSnapshotState{})
+	if _, err := verifySnapshot(msg, other); err != ErrInvalidSnapshot {
+		t.Errorf("expected invalid snapshot for another author, got %v", err)
+	}
+}
+
+func TestThread_PruneFollowParents(t *testing.T) {
+	node := startObjectsNode(t, pruneRepoPath, "")
+	defer func() {
+		node.Stop()
+		os.RemoveAll(pruneRepoPath)
+	}()
+	node.config.Threads.Snapshots.Prune = true
+
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thrd, err := node.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "prune",
+		Initiator: node.account.Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := thrd.AddMessage("pruned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := thrd.Snapshot(); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if node.datastore.Blocks().Get(msg.B58String()) != nil {
+		t.Fatal("message before snapshot was not pruned")
+	}
+
+	// a later block pointing at pruned history should not bring it back
+	if err := thrd.followParents([]string{msg.B58String()}); err != nil {
+		t.Fatal(err)
+	}
+	if node.datastore.Blocks().Get(msg.B58String()) != nil {
+		t.Error("pruned block was indexed again")
+	}
+
+	// blocks newer than the snapshot are still followed
+	future, err := ptypes.TimestampProto(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thrd.pruned(&pb.ThreadBlockHeader{Date: future}) {
+		t.Error("block newer than the snapshot should not be pruned")
+	}
+}
+
+func TestThread_SnapshotBootstrap(t *testing.T) {
+	var nodes []*Textile
+	for _, pth := range snapshotRepoPaths {
+		nodes = append(nodes, startObjectsNode(t, pth, ""))
+	}
+	defer func() {
+		for i, node := range nodes {
+			node.Stop()
+			os.RemoveAll(snapshotRepoPaths[i])
+		}
+	}()
+	author, member, joiner := nodes[0], nodes[1], nodes[2]
+	connectSnapshotNodes(t, member, author)
+	connectSnapshotNodes(t, joiner, author)
+
+	sch, err := author.AddSchema(snapshotSchema, "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaId, err := mh.FromB58String(sch.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thrd, err := author.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "snapshot",
+		Schema:    schemaId,
+		Initiator: author.account.Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// history which the snapshot replaces
+	old, err := thrd.AddMessage("before snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	joinSnapshotThread(t, member, thrd)
+	addSnapshotFile(t, author, thrd, "kept")
+	ignored := addSnapshotFile(t, author, thrd, "ignored")
+	if _, err := thrd.AddIgnore(ignored.B58String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := thrd.Snapshot(); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if _, err := thrd.AddMessage("after snapshot"); err != nil {
+		t.Fatal(err)
+	}
+
+	joinSnapshotThread(t, joiner, thrd)
+	joined := joiner.Thread(thrd.Id)
+	if joined == nil {
+		t.Fatal("joined thread not found")
+	}
+
+	if !sameIds(snapshotMembers(joiner, joined), snapshotMembers(author, thrd)) {
+		t.Error("joined thread members do not match")
+	}
+	if !sameIds(snapshotBlocks(joiner, joined, repo.FilesBlock), snapshotBlocks(author, thrd, repo.FilesBlock)) {
+		t.Error("joined thread files do not match")
+	}
+	if !sameIds(snapshotBlocks(joiner, joined, repo.IgnoreBlock), snapshotBlocks(author, thrd, repo.IgnoreBlock)) {
+		t.Error("joined thread ignores do not match")
+	}
+
+	// blocks before the snapshot were never fetched
+	if joiner.datastore.Blocks().Get(old.B58String()) != nil {
+		t.Error("block before the snapshot was indexed")
+	}
+	id, err := cid.Decode(old.B58String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	has, err := joiner.node.Blockstore.Has(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Error("block before the snapshot was fetched")
+	}
+}
@@ -117,19 +117,61 @@ func (t *Textile) RemoveThread(id string) (mh.Multihash, error) {
 		return nil, err
 	}
 
-	if err := t.datastore.Threads().Delete(thrd.Id); err != nil {
+	if err := t.unloadThread(index); err != nil {
 		return nil, err
 	}
 
+	log.Infof("removed thread %s with name %s", thrd.Id, thrd.Name)
+
+	return addr, nil
+}
+
+// discardThread removes a thread and its local index without notifying peers
+func (t *Textile) discardThread(thrd *Thread) error {
+	index := -1
+	for i, th := range t.loadedThreads {
+		if th.Id == thrd.Id {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return ErrThreadNotFound
+	}
+
+	if err := t.datastore.Blocks().DeleteByThread(thrd.Id); err != nil {
+		return err
+	}
+	if err := t.datastore.ThreadPeers().DeleteByThread(thrd.Id); err != nil {
+		return err
+	}
+	if err := t.datastore.Notifications().DeleteBySubject(thrd.Id); err != nil {
+		return err
+	}
+	if err := t.unloadThread(index); err != nil {
+		return err
+	}
+
+	log.Infof("discarded thread %s with name %s", thrd.Id, thrd.Name)
+
+	return nil
+}
+
+// unloadThread deletes the thread model at a loaded index and drops it from memory
+func (t *Textile) unloadThread(index int) error {
+	thrd := t.loadedThreads[index]
+	if err := t.datastore.Threads().Delete(thrd.Id); err != nil {
+		return err
+	}
+
 	copy(t.loadedThreads[index:], t.loadedThreads[index+1:])
 	t.loadedThreads[len(t.loadedThreads)-1] = nil
 	t.loadedThreads = t.loadedThreads[:len(t.loadedThreads)-1]
+	t.threads.unsubscribeThread(thrd.Id)
 
 	t.sendUpdate(Update{Id: thrd.Id, Key: thrd.Key, Name: thrd.Name, Type: ThreadRemoved})
 
-	log.Infof("removed thread %s with name %s", thrd.Id, thrd.Name)
-
-	return addr, nil
+	return nil
 }
 
 // Threads lists loaded threads
@@ -286,6 +328,27 @@ func (t *Textile) handleThreadInvite(plaintext []byte) (mh.Multihash, error) {
 		return nil, nil
 	}
 
+	key := ksuid.New().String()
+	ttype := repo.OpenThread
+	var lost *Thread
+	if msg.Direct {
+		t.directMux.Lock()
+		defer t.directMux.Unlock()
+
+		key = directThreadKey(block.Header.Author, t.node.Identity.Pretty())
+		if ex := t.ThreadByKey(key); ex != nil {
+			// both parties may have created the thread at the same time,
+			// the thread with the lowest id wins on both sides
+			if ex.Id <= id.Pretty() {
+				return nil, nil
+			}
+			// the losing thread is kept until its content is moved
+			lost = ex
+			key = directThreadPendingKey + key
+		}
+		ttype = repo.PrivateThread
+	}
+
 	var sch mh.Multihash
 	if msg.Schema != "" {
 		sch, err = mh.FromB58String(msg.Schema)
@@ -294,11 +357,11 @@ func (t *Textile) handleThreadInvite(plaintext []byte) (mh.Multihash, error) {
 		}
 	}
 	config := AddThreadConfig{
-		Key:       ksuid.New().String(),
+		Key:       key,
 		Name:      msg.Name,
 		Schema:    sch,
 		Initiator: msg.Initiator,
-		Type:      repo.OpenThread,
+		Type:      ttype,
 		Join:      false,
 	}
 	thrd, err := t.AddThread(sk, config)
@@ -330,6 +393,12 @@ func (t *Textile) handleThreadInvite(plaintext []byte) (mh.Multihash, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if lost != nil {
+		if err := t.replaceDirectThread(lost, thrd); err != nil {
+			return nil, err
+		}
+	}
 	return hash, nil
 }
 
@@ -345,7 +414,7 @@ func (t *Textile) addAccountThread() error {
 
 	config := AddThreadConfig{
 		Key:       t.account.Address(),
-		Name:      "account",
+		Name:      accountThreadName,
 		Initiator: t.account.Address(),
 		Type:      repo.PrivateThread,
 		Join:      true,
@@ -0,0 +1,372 @@
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/segmentio/ksuid"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrInvalidThreadArchive indicates a thread archive is malformed
+var ErrInvalidThreadArchive = errors.New("invalid thread archive")
+
+// ErrThreadExists indicates an imported thread is already present
+var ErrThreadExists = errors.New("thread exists")
+
+// threadArchiveMagic starts every thread archive
+const threadArchiveMagic = "textile-thread/1\n"
+
+// maxArchiveSection bounds a single archive section (manifest or block)
+const maxArchiveSection = 1 << 26
+
+// ThreadArchiveManifest describes a thread archive.
+// Note that the archive includes the thread secret key.
+type ThreadArchiveManifest struct {
+	Id        string          `json:"id"`
+	Key       string          `json:"key"`
+	Name      string          `json:"name"`
+	Schema    string          `json:"schema,omitempty"`
+	Initiator string          `json:"initiator"`
+	Type      repo.ThreadType `json:"type"`
+	Head      string          `json:"head"`
+	Sk        []byte          `json:"sk"`
+	Date      time.Time       `json:"date"`
+}
+
+// ExportThread writes a portable archive of a thread: a manifest followed by all
+// ipfs blocks reachable from the thread head (thread blocks, file DAGs, and schema).
+// File nodes are written as-is (encrypted); their keys travel in the thread's files blocks.
+func (t *Textile) ExportThread(id string, w io.Writer) error {
+	thrd := t.Thread(id)
+	if thrd == nil {
+		return ErrThreadNotFound
+	}
+	mod := t.datastore.Threads().Get(thrd.Id)
+	if mod == nil {
+		return ErrThreadNotFound
+	}
+
+	roots, targets, err := thrd.archiveRoots(mod.Head)
+	if err != nil {
+		return err
+	}
+	roots = append(roots, targets...)
+	if mod.Schema != "" {
+		roots = append(roots, mod.Schema)
+	}
+
+	manifest, err := json.Marshal(&ThreadArchiveManifest{
+		Id:        mod.Id,
+		Key:       mod.Key,
+		Name:      mod.Name,
+		Schema:    mod.Schema,
+		Initiator: mod.Initiator,
+		Type:      mod.Type,
+		Head:      mod.Head,
+		Sk:        mod.PrivKey,
+		Date:      time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(threadArchiveMagic); err != nil {
+		return err
+	}
+	if err := writeArchiveSection(bw, manifest); err != nil {
+		return err
+	}
+
+	visited := make(map[string]struct{})
+	for _, root := range roots {
+		id, err := cid.Decode(root)
+		if err != nil {
+			return err
+		}
+		if err := t.archiveDag(bw, id, visited); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("exported thread %s with %d blocks", thrd.Id, len(visited))
+
+	return bw.Flush()
+}
+
+// ImportThread adds a thread from an archive written by ExportThread.
+// Archive blocks are added locally, then replayed from the head as though
+// they were received from a peer. The file DAGs the archive carries are pinned.
+func (t *Textile) ImportThread(r io.Reader) (*Thread, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(threadArchiveMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != threadArchiveMagic {
+		return nil, ErrInvalidThreadArchive
+	}
+	data, err := readArchiveSection(br)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ThreadArchiveManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, ErrInvalidThreadArchive
+	}
+
+	sk, err := libp2pc.UnmarshalPrivateKey(manifest.Sk)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		return nil, err
+	}
+	if pid.Pretty() != manifest.Id {
+		return nil, ErrInvalidThreadArchive
+	}
+	if t.Thread(manifest.Id) != nil {
+		return nil, ErrThreadExists
+	}
+
+	// add blocks
+	var count int
+	for {
+		key, err := readArchiveSection(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := readArchiveSection(br)
+		if err != nil {
+			return nil, err
+		}
+		id, err := cid.Cast(key)
+		if err != nil {
+			return nil, ErrInvalidThreadArchive
+		}
+		if err := ipfs.PutBlock(t.node, id, data); err != nil {
+			return nil, err
+		}
+		count++
+	}
+
+	var sch mh.Multihash
+	if manifest.Schema != "" {
+		sch, err = mh.FromB58String(manifest.Schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key := manifest.Key
+	if key == "" || t.ThreadByKey(key) != nil {
+		key = ksuid.New().String()
+	}
+	thrd, err := t.AddThread(sk, AddThreadConfig{
+		Key:       key,
+		Name:      manifest.Name,
+		Schema:    sch,
+		Initiator: manifest.Initiator,
+		Type:      manifest.Type,
+		Join:      false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// follow parents, update head, pin files
+	if manifest.Head != "" {
+		if err := thrd.replayArchive(manifest.Head); err != nil {
+			// leave nothing behind so that the import can be retried
+			if derr := t.discardThread(thrd); derr != nil {
+				log.Errorf("error discarding thread %s: %s", thrd.Id, derr)
+			}
+			return nil, err
+		}
+	}
+
+	log.Debugf("imported thread %s with %d blocks", thrd.Id, count)
+
+	return thrd, nil
+}
+
+// replayArchive follows the imported chain from head and pins the file targets it references.
+// Replay alone only pins what the thread schema asks for, which would leave
+// the rest of the imported file DAGs to be garbage collected.
+func (t *Thread) replayArchive(head string) error {
+	if err := t.followParents([]string{head}); err != nil {
+		return err
+	}
+	hash, err := mh.FromB58String(head)
+	if err != nil {
+		return err
+	}
+	if err := t.updateHead(hash); err != nil {
+		return err
+	}
+
+	_, targets, err := t.archiveRoots(head)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		node, err := ipfs.NodeAtPath(t.node(), target)
+		if err != nil {
+			return err
+		}
+		if err := ipfs.PinNode(t.node(), node, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveRoots walks the chain from head (stopping at snapshots), returning thread block ids
+// and the file targets they reference
+func (t *Thread) archiveRoots(head string) ([]string, []string, error) {
+	var roots, targets []string
+	visited := make(map[string]struct{})
+	queue := []string{head}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if hash == "" {
+			continue
+		}
+		if _, ok := visited[hash]; ok {
+			continue
+		}
+		visited[hash] = struct{}{}
+		roots = append(roots, hash)
+
+		ciphertext, err := ipfs.DataAtPath(t.node(), hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		block, err := t.decodeBlock(ciphertext)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch block.Type {
+		case pb.ThreadBlock_FILES:
+			msg := new(pb.ThreadFiles)
+			if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+				return nil, nil, err
+			}
+			if msg.Target != "" {
+				targets = append(targets, msg.Target)
+			}
+		case pb.ThreadBlock_SNAPSHOT:
+			// older blocks may be pruned, the importer bootstraps from the snapshot,
+			// which references the blocks it needs
+			msg := new(pb.ThreadSnapshot)
+			if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+				return nil, nil, err
+			}
+			state := new(pb.ThreadSnapshotState)
+			if err := proto.Unmarshal(msg.State, state); err != nil {
+				return nil, nil, err
+			}
+			roots = append(roots, state.Ignores...)
+			for _, id := range state.Files {
+				roots = append(roots, id)
+				target, err := t.filesTarget(id)
+				if err != nil {
+					return nil, nil, err
+				}
+				if target != "" {
+					targets = append(targets, target)
+				}
+			}
+			continue
+		}
+		queue = append(queue, block.Header.Parents...)
+	}
+	return roots, targets, nil
+}
+
+// filesTarget returns the target of a files block
+func (t *Thread) filesTarget(id string) (string, error) {
+	ciphertext, err := ipfs.DataAtPath(t.node(), id)
+	if err != nil {
+		return "", err
+	}
+	block, err := t.decodeBlock(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if block.Type != pb.ThreadBlock_FILES {
+		return "", ErrBlockWrongType
+	}
+	msg := new(pb.ThreadFiles)
+	if err := ptypes.UnmarshalAny(block.Payload, msg); err != nil {
+		return "", err
+	}
+	return msg.Target, nil
+}
+
+// archiveDag writes a node and all of its descendants
+func (t *Textile) archiveDag(w io.Writer, id cid.Cid, visited map[string]struct{}) error {
+	if _, ok := visited[id.KeyString()]; ok {
+		return nil
+	}
+	visited[id.KeyString()] = struct{}{}
+
+	node, err := ipfs.NodeAtCid(t.node, id)
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveSection(w, id.Bytes()); err != nil {
+		return err
+	}
+	if err := writeArchiveSection(w, node.RawData()); err != nil {
+		return err
+	}
+	for _, link := range node.Links() {
+		if err := t.archiveDag(w, link.Cid, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeArchiveSection writes uvarint length prefixed data
+func writeArchiveSection(w io.Writer, data []byte) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(len(data)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readArchiveSection reads uvarint length prefixed data, returning io.EOF at a clean end
+func readArchiveSection(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil || size > maxArchiveSection {
+		return nil, ErrInvalidThreadArchive
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, ErrInvalidThreadArchive
+	}
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package core_test
+
+import (
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	mh "gx/ipfs/QmPnFwZ2JXKnXgMw8CdBPxn7FWh6LLdjUjxV1fKHuJnkr8/go-multihash"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+
+	"github.com/segmentio/ksuid"
+	. "github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/mill"
+	"github.com/textileio/textile-go/repo"
+)
+
+var archiveRepoPath1 = "testdata/.textile-archive1"
+var archiveNode1 *Textile
+var archiveRepoPath2 = "testdata/.textile-archive2"
+var archiveNode2 *Textile
+
+var archiveThreadId string
+var archiveFileHash string
+
+var archiveSchema = `
+{
+  "name": "blob",
+  "pin": true,
+  "mill": "/blob"
+}
+`
+
+func TestArchive_Setup(t *testing.T) {
+	archiveNode1 = startLanNode(t, archiveRepoPath1)
+	archiveNode2 = startLanNode(t, archiveRepoPath2)
+
+	sch, err := archiveNode1.AddSchema(archiveSchema, "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaId, err := mh.FromB58String(sch.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thrd, err := archiveNode1.AddThread(sk, AddThreadConfig{
+		Key:       ksuid.New().String(),
+		Name:      "archive",
+		Schema:    schemaId,
+		Initiator: archiveNode1.Account().Address(),
+		Type:      repo.OpenThread,
+		Join:      true,
+	})
+	if err != nil {
+		t.Fatalf("add thread failed: %s", err)
+	}
+	archiveThreadId = thrd.Id
+
+	if _, err := thrd.AddMessage("hello archive"); err != nil {
+		t.Fatalf("add message failed: %s", err)
+	}
+	file, err := archiveNode1.AddFile(&mill.Blob{}, AddFileConfig{
+		Input: []byte("archived data"),
+		Name:  "archive.txt",
+		Media: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("add file failed: %s", err)
+	}
+	archiveFileHash = file.Hash
+	node, keys, err := archiveNode1.AddNodeFromFiles([]repo.File{*file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := thrd.AddFiles(node, "archived", keys); err != nil {
+		t.Fatalf("add files failed: %s", err)
+	}
+}
+
+func TestTextile_ImportThreadArchive(t *testing.T) {
+	// stream the export directly into the import
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(archiveNode1.ExportThread(archiveThreadId, pw))
+	}()
+	thrd, err := archiveNode2.ImportThread(pr)
+	if err != nil {
+		t.Fatalf("import thread failed: %s", err)
+	}
+	if thrd.Id != archiveThreadId {
+		t.Fatalf("imported thread id does not match")
+	}
+
+	query := "threadId='" + archiveThreadId + "'"
+	exported := archiveNode1.Blocks("", -1, query)
+	imported := archiveNode2.Blocks("", -1, query)
+	if len(imported) != len(exported) {
+		t.Fatalf("expected %d imported blocks, got %d", len(exported), len(imported))
+	}
+	for i := range exported {
+		if imported[i].Id != exported[i].Id {
+			t.Errorf("imported block %d does not match", i)
+		}
+	}
+
+	page, err := archiveNode2.ThreadMessages("", -1, archiveThreadId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := page.Items.([]ThreadMessageInfo)
+	if len(msgs) != 1 || msgs[0].Body != "hello archive" {
+		t.Error("imported thread messages do not match")
+	}
+
+	page, err = archiveNode2.ThreadFiles("", -1, archiveThreadId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := page.Items.([]ThreadFilesInfo)
+	if len(files) != 1 || files[0].Caption != "archived" {
+		t.Fatal("imported thread files do not match")
+	}
+	if len(files[0].Files) != 1 || files[0].Files[0].File == nil || files[0].Files[0].File.Hash != archiveFileHash {
+		t.Fatal("imported file was not indexed")
+	}
+	target, err := cid.Decode(files[0].Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reason, pinned, err := archiveNode2.Ipfs().Pinning.IsPinned(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned || reason != "recursive" {
+		t.Error("imported file dag should be pinned")
+	}
+	reader, _, err := archiveNode2.FileData(archiveFileHash)
+	if err != nil {
+		t.Fatalf("imported file data not found: %s", err)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "archived data" {
+		t.Error("imported file data does not match")
+	}
+}
+
+func TestArchive_Teardown(t *testing.T) {
+	archiveNode1.Stop()
+	archiveNode2.Stop()
+	archiveNode1 = nil
+	archiveNode2 = nil
+	os.RemoveAll(archiveRepoPath1)
+	os.RemoveAll(archiveRepoPath2)
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+// ErrDirectThreadSelf indicates a direct thread was requested with self
+var ErrDirectThreadSelf = errors.New("cannot create a direct thread with self")
+
+// directThreadKeyPrefix prefixes the keys of all direct threads
+const directThreadKeyPrefix = "direct:"
+
+// directThreadPendingKey prefixes the key of a winning direct thread until the losing one is replaced
+const directThreadPendingKey = "pending:"
+
+// directThreadName is the name given to direct threads
+const directThreadName = "direct"
+
+// DirectThreadInfo reports info about a direct thread and its other party
+type DirectThreadInfo struct {
+	Contact *ContactInfo `json:"contact,omitempty"`
+	Thread  *ThreadInfo  `json:"thread"`
+}
+
+// DirectThread finds or creates a private two-party thread with a contact.
+// The other party is invited directly or via their cafe inbox(es).
+// If both parties create the thread at once, the one with the lowest id is kept by both.
+// Local calls are serialized so concurrent requests return the same thread.
+func (t *Textile) DirectThread(contactId string) (*Thread, error) {
+	self := t.node.Identity.Pretty()
+	if contactId == self {
+		return nil, ErrDirectThreadSelf
+	}
+	pid, err := peer.IDB58Decode(contactId)
+	if err != nil {
+		return nil, err
+	}
+	if t.datastore.Contacts().Get(contactId) == nil {
+		return nil, ErrContactNotFound
+	}
+
+	t.directMux.Lock()
+	defer t.directMux.Unlock()
+
+	key := directThreadKey(self, contactId)
+	if thrd := t.ThreadByKey(key); thrd != nil {
+		return thrd, nil
+	}
+
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	config := AddThreadConfig{
+		Key:       key,
+		Name:      directThreadName,
+		Initiator: t.account.Address(),
+		Type:      repo.PrivateThread,
+		Join:      true,
+	}
+	thrd, err := t.AddThread(sk, config)
+	if err != nil {
+		// the other party's invite may have been joined in the meantime
+		if repo.ConflictError(err) {
+			if thrd := t.ThreadByKey(key); thrd != nil {
+				return thrd, nil
+			}
+		}
+		return nil, err
+	}
+
+	if _, err := thrd.addDirectInvite(pid); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("added direct thread %s with %s", thrd.Id, contactId)
+
+	return thrd, nil
+}
+
+// replaceDirectThread moves this peer's messages and files from a direct thread which lost
+// a creation race into the thread kept by both parties, then discards the losing thread.
+// The other party never sees the losing thread's content otherwise.
+func (t *Textile) replaceDirectThread(lost *Thread, thrd *Thread) error {
+	key := lost.Key
+	query := fmt.Sprintf("threadId='%s' and authorId='%s' and type in (%d,%d)",
+		lost.Id, t.node.Identity.Pretty(), repo.MessageBlock, repo.FilesBlock)
+	blocks := t.datastore.Blocks().List("", -1, query)
+
+	// oldest first
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if err := t.moveDirectBlock(lost, thrd, blocks[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := t.discardThread(lost); err != nil {
+		return err
+	}
+	if err := t.datastore.Threads().UpdateKey(thrd.Id, key); err != nil {
+		return err
+	}
+	thrd.Key = key
+
+	log.Debugf("replaced direct thread %s with %s (moved %d blocks)", lost.Id, thrd.Id, len(blocks))
+
+	return nil
+}
+
+// moveDirectBlock re-posts a message or files block in another thread
+func (t *Textile) moveDirectBlock(from *Thread, to *Thread, block repo.Block) error {
+	if block.Type == repo.MessageBlock {
+		_, err := to.AddMessage(block.Body)
+		return err
+	}
+
+	ciphertext, err := ipfs.DataAtPath(t.node, block.Id)
+	if err != nil {
+		return err
+	}
+	tblock, err := from.decodeBlock(ciphertext)
+	if err != nil {
+		return err
+	}
+	msg := new(pb.ThreadFiles)
+	if err := ptypes.UnmarshalAny(tblock.Payload, msg); err != nil {
+		return err
+	}
+	node, err := ipfs.NodeAtPath(t.node, msg.Target)
+	if err != nil {
+		return err
+	}
+	_, err = to.AddFiles(node, msg.Body, msg.Keys)
+	return err
+}
+
+// DirectThreads lists info on all direct threads
+func (t *Textile) DirectThreads() ([]DirectThreadInfo, error) {
+	self := t.node.Identity.Pretty()
+
+	infos := make([]DirectThreadInfo, 0)
+	for _, thrd := range t.loadedThreads {
+		other := directThreadPeer(thrd.Key, self)
+		if other == "" {
+			continue
+		}
+		info, err := thrd.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, DirectThreadInfo{
+			Contact: t.Contact(other),
+			Thread:  info,
+		})
+	}
+
+	return infos, nil
+}
+
+// directThreadKey returns the deterministic key shared by both parties of a direct thread
+func directThreadKey(a string, b string) string {
+	ids := []string{a, b}
+	sort.Strings(ids)
+	return directThreadKeyPrefix + strings.Join(ids, ":")
+}
+
+// directThreadPeer returns the other party of a direct thread key, if any
+func directThreadPeer(key string, self string) string {
+	if !strings.HasPrefix(key, directThreadKeyPrefix) {
+		return ""
+	}
+	parts := strings.Split(strings.TrimPrefix(key, directThreadKeyPrefix), ":")
+	if len(parts) != 2 {
+		return ""
+	}
+	switch self {
+	case parts[0]:
+		return parts[1]
+	case parts[1]:
+		return parts[0]
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,175 @@
+package core_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/repo"
+)
+
+var dmRepoPath1 = "testdata/.textile-dm1"
+var dmNode1 *Textile
+var dmRepoPath2 = "testdata/.textile-dm2"
+var dmNode2 *Textile
+
+// addContactOf saves the other node as a contact
+func addContactOf(t *testing.T, node *Textile, other *Textile) {
+	now := time.Now()
+	if err := node.AddContact(&repo.Contact{
+		Id:      other.Ipfs().Identity.Pretty(),
+		Address: other.Account().Address(),
+		Created: now,
+		Updated: now,
+	}); err != nil {
+		t.Fatalf("add contact failed: %s", err)
+	}
+}
+
+func TestDirectThread_Setup(t *testing.T) {
+	dmNode1 = startLanNode(t, dmRepoPath1)
+	dmNode2 = startLanNode(t, dmRepoPath2)
+}
+
+func TestDirectThread_Self(t *testing.T) {
+	if _, err := dmNode1.DirectThread(dmNode1.Ipfs().Identity.Pretty()); err != ErrDirectThreadSelf {
+		t.Errorf("expected self error, got %v", err)
+	}
+}
+
+func TestDirectThread_NotContact(t *testing.T) {
+	if _, err := dmNode1.DirectThread(dmNode2.Ipfs().Identity.Pretty()); err != ErrContactNotFound {
+		t.Errorf("expected contact not found error, got %v", err)
+	}
+}
+
+func TestDirectThread_UnknownInviter(t *testing.T) {
+	addContactOf(t, dmNode1, dmNode2)
+	thrd, err := dmNode1.DirectThread(dmNode2.Ipfs().Identity.Pretty())
+	if err != nil {
+		t.Fatalf("direct thread failed: %s", err)
+	}
+
+	// the other party doesn't know us, so the invite is not auto-accepted
+	var invites []ThreadInviteInfo
+	if !waitFor(lanTimeout, func() bool {
+		invites = dmNode2.ThreadInvites()
+		return len(invites) > 0
+	}) {
+		t.Fatal("invite was not received")
+	}
+	infos, err := dmNode2.DirectThreads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 0 {
+		t.Error("direct thread from unknown peer should not be joined")
+	}
+
+	// reset for the next test
+	if err := dmNode2.IgnoreThreadInvite(invites[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dmNode1.RemoveThread(thrd.Id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectThread_ConcurrentLocal(t *testing.T) {
+	// concurrent local calls should return the same thread
+	var wg sync.WaitGroup
+	ids := make([]string, 2)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			thrd, err := dmNode1.DirectThread(dmNode2.Ipfs().Identity.Pretty())
+			if err != nil {
+				t.Errorf("direct thread failed: %s", err)
+				return
+			}
+			ids[i] = thrd.Id
+		}(i)
+	}
+	wg.Wait()
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("expected the same thread, got %s and %s", ids[0], ids[1])
+	}
+
+	// reset for the next test
+	var invites []ThreadInviteInfo
+	if !waitFor(lanTimeout, func() bool {
+		invites = dmNode2.ThreadInvites()
+		return len(invites) > 0
+	}) {
+		t.Fatal("invite was not received")
+	}
+	if err := dmNode2.IgnoreThreadInvite(invites[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dmNode1.RemoveThread(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectThread_Concurrent(t *testing.T) {
+	addContactOf(t, dmNode2, dmNode1)
+
+	// both parties create the thread at the same time and send a message
+	var wg sync.WaitGroup
+	for _, pair := range [][2]*Textile{{dmNode1, dmNode2}, {dmNode2, dmNode1}} {
+		wg.Add(1)
+		go func(node *Textile, other *Textile) {
+			defer wg.Done()
+			thrd, err := node.DirectThread(other.Ipfs().Identity.Pretty())
+			if err != nil {
+				t.Errorf("direct thread failed: %s", err)
+				return
+			}
+			if _, err := thrd.AddMessage("hi from " + node.Ipfs().Identity.Pretty()); err != nil {
+				t.Errorf("add message failed: %s", err)
+			}
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+
+	// both should end up in the same thread
+	var id string
+	if !waitFor(lanTimeout, func() bool {
+		infos1, err := dmNode1.DirectThreads()
+		if err != nil || len(infos1) != 1 {
+			return false
+		}
+		infos2, err := dmNode2.DirectThreads()
+		if err != nil || len(infos2) != 1 {
+			return false
+		}
+		id = infos1[0].Thread.Id
+		return id == infos2[0].Thread.Id
+	}) {
+		t.Fatal("direct threads did not converge")
+	}
+
+	// messages sent to the losing thread are moved, not dropped
+	query := fmt.Sprintf("threadId='%s' and type=%d", id, repo.MessageBlock)
+	for _, node := range []*Textile{dmNode1, dmNode2} {
+		if !waitFor(lanTimeout, func() bool {
+			return len(node.Blocks("", -1, query)) == 2
+		}) {
+			t.Errorf("expected both messages on %s, got %d",
+				node.Ipfs().Identity.Pretty(), len(node.Blocks("", -1, query)))
+		}
+	}
+}
+
+func TestDirectThread_Teardown(t *testing.T) {
+	dmNode1.Stop()
+	dmNode2.Stop()
+	dmNode1 = nil
+	dmNode2 = nil
+	os.RemoveAll(dmRepoPath1)
+	os.RemoveAll(dmRepoPath2)
+}
@@ -46,7 +46,13 @@ type ThreadLikeInfo struct {
 	Avatar   string    `json:"avatar,omitempty"`
 }
 
-func (t *Textile) ThreadFiles(offset string, limit int, threadId string) ([]ThreadFilesInfo, error) {
+// ThreadFiles pages files blocks, optionally within a thread
+func (t *Textile) ThreadFiles(cursor string, limit int, threadId string) (*ListPage, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	var query string
 	if threadId != "" {
 		if t.Thread(threadId) == nil {
@@ -59,8 +65,9 @@ func (t *Textile) ThreadFiles(offset string, limit int, threadId string) ([]Thre
 
 	list := make([]ThreadFilesInfo, 0)
 
-	blocks := t.Blocks(offset, limit, query)
-	for _, block := range blocks {
+	blocks := t.Blocks(offset, storeLimit(limit), query)
+	_, end, next, _ := PageBounds(len(blocks), func(i int) string { return blocks[i].Id }, "", limit)
+	for _, block := range blocks[:end] {
 		file, err := t.threadFile(block)
 		if err != nil {
 			return nil, err
@@ -68,7 +75,7 @@ func (t *Textile) ThreadFiles(offset string, limit int, threadId string) ([]Thre
 		list = append(list, *file)
 	}
 
-	return list, nil
+	return &ListPage{Items: list, Next: next}, nil
 }
 
 func (t *Textile) ThreadFile(blockId string) (*ThreadFilesInfo, error) {
@@ -16,7 +16,13 @@ type ThreadMessageInfo struct {
 	Body     string    `json:"body"`
 }
 
-func (t *Textile) ThreadMessages(offset string, limit int, threadId string) ([]ThreadMessageInfo, error) {
+// ThreadMessages pages message blocks, optionally within a thread
+func (t *Textile) ThreadMessages(cursor string, limit int, threadId string) (*ListPage, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	var query string
 	if threadId != "" {
 		if t.Thread(threadId) == nil {
@@ -29,8 +35,9 @@ func (t *Textile) ThreadMessages(offset string, limit int, threadId string) ([]T
 
 	list := make([]ThreadMessageInfo, 0)
 
-	blocks := t.Blocks(offset, limit, query)
-	for _, block := range blocks {
+	blocks := t.Blocks(offset, storeLimit(limit), query)
+	_, end, next, _ := PageBounds(len(blocks), func(i int) string { return blocks[i].Id }, "", limit)
+	for _, block := range blocks[:end] {
 		msg, err := t.ThreadMessage(block)
 		if err != nil {
 			return nil, err
@@ -38,7 +45,7 @@ func (t *Textile) ThreadMessages(offset string, limit int, threadId string) ([]T
 		list = append(list, *msg)
 	}
 
-	return list, nil
+	return &ListPage{Items: list, Next: next}, nil
 }
 
 func (t *Textile) ThreadMessage(block repo.Block) (*ThreadMessageInfo, error) {
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/core"
 
 	"github.com/segmentio/ksuid"
+	"github.com/textileio/textile-go/ipfs"
 	"github.com/textileio/textile-go/pb"
 	"github.com/textileio/textile-go/repo"
 	"github.com/textileio/textile-go/service"
@@ -18,12 +20,19 @@ import (
 // note: msgs from this group are batched to each receiver
 const threadsFlushGroupSize = 16
 
+// threadsHTTPTimeout is the timeout for sending a message to a peer's HTTP endpoint
+const threadsHTTPTimeout = time.Second * 10
+
+// errNoRelays indicates none of a peer's cafes relay
+var errNoRelays = errors.New("no relays")
+
 // ThreadsOutbox queues and processes outbound thread messages
 type ThreadsOutbox struct {
 	service    func() *ThreadsService
 	node       func() *core.IpfsNode
 	datastore  repo.Datastore
 	cafeOutbox *CafeOutbox
+	lanPeers   func() []peer.ID
 	mux        sync.Mutex
 }
 
@@ -33,12 +42,14 @@ func NewThreadsOutbox(
 	node func() *core.IpfsNode,
 	datastore repo.Datastore,
 	cafeOutbox *CafeOutbox,
+	lanPeers func() []peer.ID,
 ) *ThreadsOutbox {
 	return &ThreadsOutbox{
 		service:    service,
 		node:       node,
 		datastore:  datastore,
 		cafeOutbox: cafeOutbox,
+		lanPeers:   lanPeers,
 	}
 }
 
@@ -53,7 +64,7 @@ func (q *ThreadsOutbox) Add(pid peer.ID, env *pb.Envelope) error {
 	})
 }
 
-// Flush processes pending messages
+// Flush processes pending messages, starting with peers on the local network
 func (q *ThreadsOutbox) Flush() {
 	q.mux.Lock()
 	defer q.mux.Unlock()
@@ -63,14 +74,34 @@ func (q *ThreadsOutbox) Flush() {
 		return
 	}
 
-	if err := q.batch(q.datastore.ThreadMessages().List("", threadsFlushGroupSize)); err != nil {
+	start := time.Now()
+
+	// local peers are reachable without cafes, don't let other peers hold them up
+	for _, pid := range q.lanPeers() {
+		list := q.peerMessages(pid)
+		if err := q.batch(list("", threadsFlushGroupSize), list); err != nil {
+			log.Errorf("thread outbox batch error for lan peer %s: %s", pid.Pretty(), err)
+		}
+	}
+
+	list := q.datastore.ThreadMessages().List
+	err := q.batch(list("", threadsFlushGroupSize), list)
+	observeFlush(threadsOutboxLabel, start, err)
+	if err != nil {
 		log.Errorf("thread outbox batch error: %s", err)
 		return
 	}
 }
 
-// batch flushes a batch of messages
-func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage) error {
+// peerMessages returns a func which lists pending messages for a single peer
+func (q *ThreadsOutbox) peerMessages(pid peer.ID) func(string, int) []repo.ThreadMessage {
+	return func(offset string, limit int) []repo.ThreadMessage {
+		return q.datastore.ThreadMessages().ListByPeer(pid.Pretty(), offset, limit)
+	}
+}
+
+// batch flushes a batch of messages, then the next batch returned by list
+func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage, list func(string, int) []repo.ThreadMessage) error {
 	log.Debugf("handling %d thread messages", len(msgs))
 	if len(msgs) == 0 {
 		return nil
@@ -84,6 +115,7 @@ func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage) error {
 
 	var berr error
 	var toDelete []string
+	var rlk sync.Mutex
 	wg := sync.WaitGroup{}
 	for id, group := range groups {
 		pid, err := peer.IDB58Decode(id)
@@ -92,14 +124,20 @@ func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage) error {
 		}
 		wg.Add(1)
 		go func(pid peer.ID, msgs []repo.ThreadMessage) {
+			defer wg.Done()
 			for _, msg := range msgs {
-				if err := q.handle(pid, msg); err != nil {
+				err := q.handle(pid, msg)
+				rlk.Lock()
+				if err != nil {
 					berr = err
+				} else {
+					toDelete = append(toDelete, msg.Id)
+				}
+				rlk.Unlock()
+				if err != nil {
 					return
 				}
-				toDelete = append(toDelete, msg.Id)
 			}
-			wg.Done()
 		}(pid, group)
 	}
 	wg.Wait()
@@ -109,7 +147,7 @@ func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage) error {
 
 	// next batch
 	offset := msgs[len(msgs)-1].Id
-	next := q.datastore.ThreadMessages().List(offset, threadsFlushGroupSize)
+	next := list(offset, threadsFlushGroupSize)
 
 	var deleted []string
 	for _, id := range toDelete {
@@ -123,13 +161,22 @@ func (q *ThreadsOutbox) batch(msgs []repo.ThreadMessage) error {
 
 	// keep going unless an error occurred
 	if berr == nil {
-		return q.batch(next)
+		return q.batch(next, list)
 	}
 	return berr
 }
 
 // handle handles a single message
 func (q *ThreadsOutbox) handle(pid peer.ID, msg repo.ThreadMessage) error {
+	contact := q.datastore.Contacts().Get(pid.Pretty())
+
+	// peers which haven't been seen for a long time go straight to their inbox(es),
+	// except for an occasional direct attempt
+	if contact != nil && len(contact.Inboxes) > 0 && q.service().skipDirect(pid) {
+		log.Debugf("sending thread message for long offline %s to inbox(es)", pid.Pretty())
+		return q.cafeOutbox.InboxRequest(pid, msg.Envelope, contact.Inboxes)
+	}
+
 	// first, attempt to send the message directly to the recipient
 	ctx, cancel := context.WithTimeout(context.Background(), service.DirectTimeout)
 	defer cancel()
@@ -137,14 +184,39 @@ func (q *ThreadsOutbox) handle(pid peer.ID, msg repo.ThreadMessage) error {
 	var err error
 	if q.service().online {
 		err = q.service().SendMessage(ctx, pid, msg.Envelope)
+		if err == nil {
+			q.service().seePeer(pid)
+		}
 	}
 	if !q.service().online || err != nil {
 		if err != nil {
 			log.Debugf("send thread message direct to %s failed: %s", pid.Pretty(), err)
 		}
 
+		// next, attempt to reach the peer through its cafes' relays
+		if q.service().online && contact != nil {
+			if err := q.sendRelayed(pid, contact.Inboxes, msg.Envelope); err == nil {
+				log.Debugf("sent thread message to %s over relay", pid.Pretty())
+				q.service().seePeer(pid)
+				return nil
+			} else if err != errNoRelays {
+				log.Debugf("send thread message over relay to %s failed: %s", pid.Pretty(), err)
+			}
+		}
+
+		// next, attempt the peer's HTTP endpoint, which works when swarm ports are blocked
+		if contact != nil && validEndpoint(contact.Endpoint) {
+			hctx, hcancel := context.WithTimeout(context.Background(), threadsHTTPTimeout)
+			err := q.service().service.SendHTTPMessageContext(hctx, contact.Endpoint, msg.Envelope)
+			hcancel()
+			if err == nil {
+				log.Debugf("sent thread message to %s over http", pid.Pretty())
+				return nil
+			}
+			log.Debugf("send thread message over http to %s failed: %s", pid.Pretty(), err)
+		}
+
 		// peer is offline, queue an outbound cafe request for the peer's inbox(es)
-		contact := q.datastore.Contacts().Get(pid.Pretty())
 		if contact != nil && len(contact.Inboxes) > 0 {
 			log.Debugf("sending thread message for %s to inbox(es)", pid.Pretty())
 
@@ -156,3 +228,18 @@ func (q *ThreadsOutbox) handle(pid peer.ID, msg repo.ThreadMessage) error {
 	}
 	return nil
 }
+
+// sendRelayed connects to a peer through the relays of its cafes and sends a message
+func (q *ThreadsOutbox) sendRelayed(pid peer.ID, inboxes []repo.Cafe, env *pb.Envelope) error {
+	addrs := relayAddrs(pid, inboxes)
+	if len(addrs) == 0 {
+		return errNoRelays
+	}
+	if _, err := ipfs.SwarmConnect(q.node(), addrs); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), service.DirectTimeout)
+	defer cancel()
+	return q.service().SendMessage(ctx, pid, env)
+}
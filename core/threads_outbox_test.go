@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"gx/ipfs/QmPSQnBKM9g7BaUcZCvswUJVscQ1ipjmwxN5PXCjkp9EQ7/go-cid"
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/textileio/textile-go/crypto"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+var outboxCafePath = "testdata/.textile-outbox-cafe"
+var outboxCafe *Textile
+var outboxClientPath = "testdata/.textile-outbox-client"
+var outboxClient *Textile
+var outboxSenderPath = "testdata/.textile-outbox-sender"
+var outboxSender *Textile
+
+var outboxClientId peer.ID
+var outboxClientKey libp2pc.PrivKey
+var outboxClientToken string
+
+// outboxTLS terminates https in front of the cafe, endpoints must be https
+var outboxTLS *httptest.Server
+var outboxTransport http.RoundTripper
+
+// outboxEndpoint returns the cafe's threads endpoint for a client
+func outboxEndpoint(client peer.ID) string {
+	return fmt.Sprintf("%s/cafe/v0/service/threads/%s", outboxTLS.URL, client.Pretty())
+}
+
+// outboxEnvelope returns a new thread envelope from the sender
+func outboxEnvelope(t *testing.T) *pb.Envelope {
+	env, err := outboxSender.threads.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{
+		Thread:     "thread",
+		Hash:       "hash",
+		Ciphertext: []byte(time.Now().String()),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func TestThreadsOutbox_Setup(t *testing.T) {
+	outboxCafe = startObjectsNode(t, outboxCafePath, "127.0.0.1:5301")
+
+	target, err := url.Parse("http://127.0.0.1:5301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outboxTLS = httptest.NewTLSServer(httputil.NewSingleHostReverseProxy(target))
+	outboxTransport = http.DefaultTransport
+	http.DefaultTransport = outboxTLS.Client().Transport
+	outboxClient = startObjectsNode(t, outboxClientPath, "")
+	outboxSender = startObjectsNode(t, outboxSenderPath, "")
+
+	session, err := outboxClient.RegisterCafe("http://127.0.0.1:5301")
+	if err != nil {
+		t.Fatalf("register cafe failed: %s", err)
+	}
+	outboxClientToken = session.Access
+	outboxClientId = outboxClient.node.Identity
+	outboxClientKey = outboxClient.node.PrivateKey
+
+	// the client can only be reached over its cafe's endpoint
+	if err := outboxClient.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := outboxSender.datastore.Contacts().Add(&repo.Contact{
+		Id:       outboxClientId.Pretty(),
+		Address:  keypair.Random().Address(),
+		Endpoint: outboxEndpoint(outboxClientId),
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestThreadsOutbox_HTTP(t *testing.T) {
+	env, err := outboxSender.threads.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{
+		Thread:     "thread",
+		Hash:       "hash",
+		Ciphertext: []byte("ciphertext"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := outboxSender.threadsOutbox.handle(outboxClientId, repo.ThreadMessage{
+		Id:       "outbox",
+		PeerId:   outboxClientId.Pretty(),
+		Envelope: env,
+		Date:     time.Now(),
+	}); err != nil {
+		t.Fatalf("handle failed: %s", err)
+	}
+
+	msgs := outboxCafe.datastore.CafeClientMessages().ListByClient(outboxClientId.Pretty(), 10)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message in the client's inbox, got %d", len(msgs))
+	}
+	if msgs[0].PeerId != outboxSender.node.Identity.Pretty() {
+		t.Errorf("message should be from the sender, got %s", msgs[0].PeerId)
+	}
+
+	// the held envelope is only readable by the client
+	ciphertext, err := ipfs.DataAtPath(outboxCafe.node, msgs[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envb, err := crypto.Decrypt(outboxClientKey, ciphertext)
+	if err != nil {
+		t.Fatalf("client could not decrypt message: %s", err)
+	}
+	held := new(pb.Envelope)
+	if err := proto.Unmarshal(envb, held); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(held, env) {
+		t.Error("held envelope does not match")
+	}
+}
+
+func TestThreadsOutbox_HTTPNotClient(t *testing.T) {
+	env, err := outboxSender.threads.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{
+		Thread: "thread",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cafe is not an inbox for peers which aren't its clients, including itself
+	for _, pid := range []peer.ID{outboxSender.node.Identity, outboxCafe.node.Identity} {
+		err := outboxSender.threads.service.SendHTTPMessageContext(context.Background(), outboxEndpoint(pid), env)
+		if err == nil {
+			t.Errorf("envelope for non-client %s should be refused", pid.Pretty())
+		}
+	}
+
+	// only thread envelopes are held
+	other, err := outboxSender.threads.service.NewEnvelope(pb.Message_PING, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outboxSender.threads.service.SendHTTPMessageContext(context.Background(), outboxEndpoint(outboxClientId), other); err == nil {
+		t.Error("non thread envelope should be refused")
+	}
+	if n := outboxCafe.datastore.CafeClientMessages().CountByClient(outboxClientId.Pretty()); n != 1 {
+		t.Errorf("refused envelopes should not be held, found %d messages", n)
+	}
+}
+
+func TestThreadsOutbox_PlainHTTP(t *testing.T) {
+	contact := outboxSender.datastore.Contacts().Get(outboxClientId.Pretty())
+	endpoint := "http://127.0.0.1:5301/cafe/v0/service/threads/" + outboxClientId.Pretty()
+	if validEndpoint(endpoint) {
+		t.Fatal("plain http endpoint should be invalid")
+	}
+	if err := outboxSender.datastore.Contacts().UpdateEndpoint(contact.Id, endpoint); err != nil {
+		t.Fatal(err)
+	}
+	defer outboxSender.datastore.Contacts().UpdateEndpoint(contact.Id, contact.Endpoint)
+
+	before := outboxCafe.datastore.CafeClientMessages().CountByClient(outboxClientId.Pretty())
+	if err := outboxSender.threadsOutbox.handle(outboxClientId, repo.ThreadMessage{
+		Id:       "plain",
+		PeerId:   outboxClientId.Pretty(),
+		Envelope: outboxEnvelope(t),
+		Date:     time.Now(),
+	}); err != nil {
+		t.Fatalf("handle failed: %s", err)
+	}
+	if n := outboxCafe.datastore.CafeClientMessages().CountByClient(outboxClientId.Pretty()); n != before {
+		t.Error("envelope should not be posted to a plain http endpoint")
+	}
+}
+
+func TestThreadsOutbox_Quota(t *testing.T) {
+	client := outboxCafe.datastore.CafeClients().Get(outboxClientId.Pretty())
+	for outboxCafe.datastore.CafeClientMessages().CountByClient(client.Id) < inboxMessageQuota {
+		if err := outboxCafe.cafe.deliverEnvelope(outboxSender.node.Identity, client, outboxEnvelope(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := outboxCafe.cafe.deliverEnvelope(outboxSender.node.Identity, client, outboxEnvelope(t)); err != errInboxFull {
+		t.Fatalf("expected %s, got %v", errInboxFull, err)
+	}
+
+	// expired messages make room for new ones
+	oldest := outboxCafe.datastore.CafeClientMessages().ListByClient(client.Id, 1)[0]
+	oldest.Date = time.Now().Add(-inboxMessageExpiry)
+	if err := outboxCafe.datastore.CafeClientMessages().AddOrUpdate(&oldest); err != nil {
+		t.Fatal(err)
+	}
+	if err := outboxCafe.cafe.deliverEnvelope(outboxSender.node.Identity, client, outboxEnvelope(t)); err != nil {
+		t.Fatalf("expired message was not removed: %s", err)
+	}
+	if outboxHeld(t, oldest.Id) {
+		t.Error("expired message should be unpinned")
+	}
+}
+
+func TestThreadsOutbox_DeleteUnpins(t *testing.T) {
+	client := outboxCafe.datastore.CafeClients().Get(outboxClientId.Pretty())
+	msgs := outboxCafe.datastore.CafeClientMessages().ListByClient(client.Id, inboxMessagePageSize)
+	if len(msgs) == 0 {
+		t.Fatal("expected held messages")
+	}
+
+	del, err := outboxCafe.cafe.service.NewEnvelope(pb.Message_CAFE_DELETE_MESSAGES, &pb.CafeDeleteMessages{
+		Token: outboxClientToken,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outboxCafe.cafe.handleDeleteMessages(outboxClientId, del); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		if outboxHeld(t, msg.Id) {
+			t.Errorf("deleted message %s should be unpinned", msg.Id)
+		}
+	}
+}
+
+// outboxHeld returns whether or not a message is pinned on the cafe
+func outboxHeld(t *testing.T, id string) bool {
+	mid, err := cid.Decode(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pinned, err := outboxCafe.node.Pinning.IsPinned(mid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pinned
+}
+
+func TestThreadsOutbox_Teardown(t *testing.T) {
+	http.DefaultTransport = outboxTransport
+	outboxTLS.Close()
+	outboxSender.Stop()
+	outboxCafe.Stop()
+	os.RemoveAll(outboxCafePath)
+	os.RemoveAll(outboxClientPath)
+	os.RemoveAll(outboxSenderPath)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	inet "gx/ipfs/QmXuRkCR7BNQa9uqfpTiFWsTQLzmTWYg91Ja1w95gnqb6u/go-libp2p-net"
+)
+
+// threadsPeerOfflineAge is how long a peer must go unseen before messages
+// skip direct delivery and go straight to its inboxes
+const threadsPeerOfflineAge = time.Hour * 24
+
+// threadsPeerProbeInterval is how often direct delivery is still attempted
+// for a long offline peer, so that it's seen again once it's back
+const threadsPeerProbeInterval = time.Hour
+
+// ThreadPeerInfo is a thread peer's contact with its presence
+type ThreadPeerInfo struct {
+	ContactInfo
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+	Addrs    []string   `json:"addrs,omitempty"`
+}
+
+// seePeer records that a connected peer was just seen, along with its addresses.
+// Messages relayed by cafes don't count, the sender isn't connected.
+func (h *ThreadsService) seePeer(pid peer.ID) {
+	if !h.online {
+		return
+	}
+	network := h.service.Node().PeerHost.Network()
+	if network.Connectedness(pid) != inet.Connected {
+		return
+	}
+	var addrs []string
+	for _, c := range network.ConnsToPeer(pid) {
+		addrs = append(addrs, c.RemoteMultiaddr().String())
+	}
+	if err := h.datastore.ThreadPeers().UpdateLastSeen(pid.Pretty(), time.Now(), addrs); err != nil {
+		log.Errorf("error updating last seen for %s: %s", pid.Pretty(), err)
+	}
+}
+
+// lastSeen returns the last time a peer was seen in any thread
+func (h *ThreadsService) lastSeen(pid peer.ID) time.Time {
+	var last time.Time
+	for _, tp := range h.datastore.ThreadPeers().ListById(pid.Pretty()) {
+		if tp.LastSeen.After(last) {
+			last = tp.LastSeen
+		}
+	}
+	return last
+}
+
+// longOffline returns whether or not a peer hasn't been seen for threadsPeerOfflineAge.
+// Peers which were never seen, or are connected right now, are not considered offline.
+func (h *ThreadsService) longOffline(pid peer.ID) bool {
+	if h.online && h.service.Node().PeerHost.Network().Connectedness(pid) == inet.Connected {
+		return false
+	}
+	last := h.lastSeen(pid)
+	return !last.IsZero() && time.Since(last) > threadsPeerOfflineAge
+}
+
+// skipDirect returns whether or not messages for a peer should go straight to its inboxes.
+// Long offline peers are still tried directly once every threadsPeerProbeInterval.
+func (h *ThreadsService) skipDirect(pid peer.ID) bool {
+	if !h.longOffline(pid) {
+		return false
+	}
+	return !h.probes.due(pid, threadsPeerProbeInterval)
+}
+
+// peerProbes tracks the last direct delivery attempt to long offline peers
+type peerProbes struct {
+	last map[peer.ID]time.Time
+	mux  sync.Mutex
+}
+
+func newPeerProbes() *peerProbes {
+	return &peerProbes{last: make(map[peer.ID]time.Time)}
+}
+
+// due returns whether or not a peer wasn't probed within interval, recording a probe if so
+func (p *peerProbes) due(pid peer.ID, interval time.Duration) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if last, ok := p.last[pid]; ok && time.Since(last) < interval {
+		return false
+	}
+	p.last[pid] = time.Now()
+	return true
+}
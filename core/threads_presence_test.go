@@ -0,0 +1,123 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+)
+
+var presenceRepoPath = "testdata/.textile-presence"
+var presenceNode *Textile
+var presencePeer peer.ID
+
+// inboxRequests counts the pending inbox requests for a peer
+func inboxRequests(pid peer.ID) int {
+	var count int
+	for _, req := range presenceNode.datastore.CafeRequests().List("", -1) {
+		if req.PeerId == pid.Pretty() && req.Type == repo.CafePeerInboxRequest {
+			count++
+		}
+	}
+	return count
+}
+
+func TestThreadsPresence_Setup(t *testing.T) {
+	presenceNode = startObjectsNode(t, presenceRepoPath, "")
+
+	var err error
+	presencePeer, err = peer.IDB58Decode(randomPeerId(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := presenceNode.datastore.Contacts().Add(&repo.Contact{
+		Id:      presencePeer.Pretty(),
+		Address: keypair.Random().Address(),
+		Inboxes: []repo.Cafe{{
+			Peer: randomPeerId(t),
+			API:  "v0",
+			URL:  "http://127.0.0.1:1",
+		}},
+		Created: time.Now(),
+		Updated: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := presenceNode.datastore.ThreadPeers().Add(&repo.ThreadPeer{
+		Id:       presencePeer.Pretty(),
+		ThreadId: "thread",
+		Welcomed: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	last := time.Now().Add(-threadsPeerOfflineAge * 2)
+	if err := presenceNode.datastore.ThreadPeers().UpdateLastSeen(presencePeer.Pretty(), last, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestThreadsPresence_Probe(t *testing.T) {
+	threads := presenceNode.threads
+	if !threads.longOffline(presencePeer) {
+		t.Fatal("peer should be long offline")
+	}
+
+	// a direct attempt is due once per interval
+	if threads.skipDirect(presencePeer) {
+		t.Error("first message should be tried directly")
+	}
+	if !threads.skipDirect(presencePeer) {
+		t.Error("next message should skip direct delivery")
+	}
+	threads.probes.last[presencePeer] = time.Now().Add(-threadsPeerProbeInterval)
+	if threads.skipDirect(presencePeer) {
+		t.Error("message should be tried directly after the probe interval")
+	}
+}
+
+func TestThreadsPresence_Inbox(t *testing.T) {
+	env, err := presenceNode.threads.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{
+		Thread:     "thread",
+		Hash:       "hash",
+		Ciphertext: []byte("ciphertext"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := inboxRequests(presencePeer)
+	if err := presenceNode.threadsOutbox.handle(presencePeer, repo.ThreadMessage{
+		Id:       "presence",
+		PeerId:   presencePeer.Pretty(),
+		Envelope: env,
+		Date:     time.Now(),
+	}); err != nil {
+		t.Fatalf("handle failed: %s", err)
+	}
+	if n := inboxRequests(presencePeer); n != before+1 {
+		t.Errorf("expected an inbox request for a long offline peer, got %d", n-before)
+	}
+}
+
+func TestThreadsPresence_Seen(t *testing.T) {
+	if err := presenceNode.datastore.ThreadPeers().UpdateLastSeen(presencePeer.Pretty(), time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if presenceNode.threads.longOffline(presencePeer) {
+		t.Error("seen peer should not be long offline")
+	}
+	if presenceNode.threads.skipDirect(presencePeer) {
+		t.Error("seen peer should be tried directly")
+	}
+}
+
+func TestThreadsPresence_Teardown(t *testing.T) {
+	presenceNode.Stop()
+	presenceNode = nil
+	os.RemoveAll(presenceRepoPath)
+}
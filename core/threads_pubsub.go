@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	iface "gx/ipfs/QmUJYo4etAQqFfSS2rarFAE97eNGB8ej64YkRT2SmsYD4r/go-ipfs/core/coreapi/interface"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/ipfs"
+	"github.com/textileio/textile-go/pb"
+	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
+	"github.com/textileio/textile-go/service"
+)
+
+// defaultTopicSeenWindow is used if the seen window is not set in the config
+const defaultTopicSeenWindow = time.Minute * 10
+
+// threadTopic returns the pubsub topic for a thread
+func threadTopic(id string) string {
+	return string(threadsServiceProtocol) + "/" + id
+}
+
+// fanout returns whether or not blocks sent to a number of peers should be
+// announced over the thread topic. Posts to a single peer, like invites, are always direct.
+func (h *ThreadsService) fanout(peers int) bool {
+	return h.online && h.topics.minPeers > 0 && peers >= h.topics.minPeers && peers > 1
+}
+
+// publishThread announces an envelope over the thread topic.
+// Topic subscribers aren't marked as seen here, being subscribed doesn't mean they received it.
+func (h *ThreadsService) publishThread(id string, env *pb.Envelope) error {
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return ipfs.Publish(h.service.Node(), threadTopic(id), data)
+}
+
+// unseenPeers filters out peers which recently sent blocks over the thread topic,
+// which don't need direct delivery
+func (h *ThreadsService) unseenPeers(id string, peers []repo.ThreadPeer) []repo.ThreadPeer {
+	var unseen []repo.ThreadPeer
+	for _, tp := range peers {
+		pid, err := peer.IDB58Decode(tp.Id)
+		if err != nil || !h.topics.seen(id, pid) {
+			unseen = append(unseen, tp)
+		}
+	}
+	return unseen
+}
+
+// subscribeThread listens on the thread topic if pubsub fanout is enabled.
+// Subscriptions added before the service is online start with startTopics.
+func (h *ThreadsService) subscribeThread(id string) {
+	if h.topics.minPeers <= 0 {
+		return
+	}
+	if !h.topics.add(id) || !h.online {
+		return
+	}
+	h.listenThread(id)
+}
+
+// unsubscribeThread stops listening on the thread topic
+func (h *ThreadsService) unsubscribeThread(id string) {
+	h.topics.remove(id)
+}
+
+// startTopics listens on the topics of threads loaded before the service was online
+func (h *ThreadsService) startTopics() {
+	for _, id := range h.topics.pending() {
+		h.listenThread(id)
+	}
+}
+
+// listenThread handles thread blocks announced over the thread topic
+func (h *ThreadsService) listenThread(id string) {
+	ctx, cancel := context.WithCancel(h.service.Node().Context())
+	if !h.topics.start(id, cancel) {
+		cancel()
+		return
+	}
+
+	msgs := make(chan iface.PubSubMessage, 10)
+	go func() {
+		defer close(msgs)
+		if err := ipfs.SubscribeContext(ctx, h.service.Node(), threadTopic(id), msgs); err != nil {
+			log.Errorf("pubsub listener for thread %s stopped with error: %s", id, err)
+		}
+	}()
+	log.Debugf("pubsub listener started for thread %s", id)
+
+	go func() {
+		for msg := range msgs {
+			h.handleTopicMessage(id, msg)
+		}
+	}()
+}
+
+// handleTopicMessage receives an envelope over a thread topic
+func (h *ThreadsService) handleTopicMessage(id string, msg iface.PubSubMessage) {
+	mPeer := msg.From()
+	if mPeer.Pretty() == h.service.Node().Identity.Pretty() {
+		return
+	}
+
+	env := new(pb.Envelope)
+	if err := proto.Unmarshal(msg.Data(), env); err != nil {
+		log.Errorf("error unmarshaling thread topic message from %s: %s", mPeer.Pretty(), err)
+		return
+	}
+	if err := h.service.AdmitClaimed(env, mPeer); err != nil {
+		log.Warningf("refused thread topic message from %s: %s", mPeer.Pretty(), err)
+		return
+	}
+	if env.Message.Type != pb.Message_THREAD_ENVELOPE {
+		return
+	}
+
+	// only blocks for this thread belong on its topic
+	tenv := new(pb.ThreadEnvelope)
+	if err := ptypes.UnmarshalAny(env.Message.Payload, tenv); err != nil {
+		log.Errorf("error unmarshaling thread envelope from %s: %s", mPeer.Pretty(), err)
+		return
+	}
+	if tenv.Thread != id {
+		log.Warningf("thread topic %s received envelope for %s from %s", id, tenv.Thread, mPeer.Pretty())
+		return
+	}
+	h.topics.see(id, mPeer)
+
+	log.Debugf("received thread topic %s from %s (trace %s)", id, mPeer.Pretty(), env.Message.TraceId)
+	done := service.StartSpan(service.SpanHandlePub, env, mPeer.Pretty())
+	_, err := h.Handle(mPeer, env)
+	done(err)
+	if err != nil {
+		log.Errorf("error handling thread topic message from %s: %s", mPeer.Pretty(), err)
+	}
+}
+
+// threadTopics tracks thread topic subscriptions and the peers recently seen on them
+type threadTopics struct {
+	minPeers int
+	window   time.Duration
+	subs     map[string]context.CancelFunc
+	peers    map[string]map[peer.ID]time.Time
+	mux      sync.Mutex
+}
+
+func newThreadTopics(conf config.ThreadsPubsub) *threadTopics {
+	window := time.Duration(conf.SeenWindow) * time.Second
+	if window <= 0 {
+		window = defaultTopicSeenWindow
+	}
+	return &threadTopics{
+		minPeers: conf.MinPeers,
+		window:   window,
+		subs:     make(map[string]context.CancelFunc),
+		peers:    make(map[string]map[peer.ID]time.Time),
+	}
+}
+
+// add adds a thread topic, returning false if it was already added
+func (t *threadTopics) add(id string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if _, ok := t.subs[id]; ok {
+		return false
+	}
+	t.subs[id] = nil
+	return true
+}
+
+// start records the cancel func of a started subscription,
+// returning false if the topic was removed or is already started
+func (t *threadTopics) start(id string, cancel context.CancelFunc) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if c, ok := t.subs[id]; !ok || c != nil {
+		return false
+	}
+	t.subs[id] = cancel
+	return true
+}
+
+// pending lists thread topics which are not yet started
+func (t *threadTopics) pending() []string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	var ids []string
+	for id, cancel := range t.subs {
+		if cancel == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// remove cancels a thread topic subscription and forgets its peers
+func (t *threadTopics) remove(id string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if cancel := t.subs[id]; cancel != nil {
+		cancel()
+	}
+	delete(t.subs, id)
+	delete(t.peers, id)
+}
+
+// see marks a peer as seen on a thread topic
+func (t *threadTopics) see(id string, pid peer.ID) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if _, ok := t.subs[id]; !ok {
+		return
+	}
+	if t.peers[id] == nil {
+		t.peers[id] = make(map[peer.ID]time.Time)
+	}
+	t.peers[id][pid] = time.Now()
+}
+
+// seen returns whether or not a peer was seen on a thread topic within the window
+func (t *threadTopics) seen(id string, pid peer.ID) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	last, ok := t.peers[id][pid]
+	if !ok {
+		return false
+	}
+	if time.Since(last) > t.window {
+		delete(t.peers[id], pid)
+		return false
+	}
+	return true
+}
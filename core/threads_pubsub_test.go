@@ -0,0 +1,127 @@
+package core
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	peer "gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
+)
+
+func randomPeer(t *testing.T) peer.ID {
+	sk, _, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+func TestThreadTopics_Window(t *testing.T) {
+	topics := newThreadTopics(config.ThreadsPubsub{})
+	if topics.window != defaultTopicSeenWindow {
+		t.Errorf("expected default window, got %s", topics.window)
+	}
+	topics = newThreadTopics(config.ThreadsPubsub{SeenWindow: 30})
+	if topics.window != time.Second*30 {
+		t.Errorf("expected 30s window, got %s", topics.window)
+	}
+}
+
+func TestThreadTopics_See(t *testing.T) {
+	topics := newThreadTopics(config.ThreadsPubsub{MinPeers: 2})
+	pid := randomPeer(t)
+
+	// peers aren't tracked on topics which aren't subscribed
+	topics.see("thread", pid)
+	if topics.seen("thread", pid) {
+		t.Error("peer should not be seen on an unsubscribed topic")
+	}
+
+	topics.add("thread")
+	topics.see("thread", pid)
+	if !topics.seen("thread", pid) {
+		t.Error("peer should be seen")
+	}
+	if topics.seen("other", pid) {
+		t.Error("peer should not be seen on another topic")
+	}
+	if topics.seen("thread", randomPeer(t)) {
+		t.Error("other peer should not be seen")
+	}
+
+	// outside the window the peer is forgotten
+	topics.window = time.Millisecond
+	time.Sleep(time.Millisecond * 5)
+	if topics.seen("thread", pid) {
+		t.Error("peer should not be seen after the window")
+	}
+	if _, ok := topics.peers["thread"][pid]; ok {
+		t.Error("expired peer should be removed")
+	}
+}
+
+func TestThreadTopics_Remove(t *testing.T) {
+	topics := newThreadTopics(config.ThreadsPubsub{MinPeers: 2})
+	pid := randomPeer(t)
+
+	if !topics.add("thread") || topics.add("thread") {
+		t.Fatal("topic should only be added once")
+	}
+	if len(topics.pending()) != 1 {
+		t.Fatal("added topic should be pending")
+	}
+	var cancelled bool
+	if !topics.start("thread", func() { cancelled = true }) {
+		t.Fatal("pending topic should start")
+	}
+	if topics.start("thread", func() {}) {
+		t.Error("started topic should not start again")
+	}
+	topics.see("thread", pid)
+
+	topics.remove("thread")
+	if !cancelled {
+		t.Error("removed topic subscription should be cancelled")
+	}
+	if topics.seen("thread", pid) {
+		t.Error("peers of a removed topic should be forgotten")
+	}
+	topics.see("thread", pid)
+	if topics.seen("thread", pid) {
+		t.Error("peers should not be tracked on a removed topic")
+	}
+	if topics.start("thread", func() {}) {
+		t.Error("removed topic should not start")
+	}
+}
+
+func TestThreadsService_UnseenPeers(t *testing.T) {
+	h := &ThreadsService{topics: newThreadTopics(config.ThreadsPubsub{MinPeers: 2})}
+	seen := randomPeer(t)
+	unseen := randomPeer(t)
+	h.topics.add("thread")
+	h.topics.see("thread", seen)
+
+	peers := []repo.ThreadPeer{
+		{Id: seen.Pretty(), ThreadId: "thread"},
+		{Id: unseen.Pretty(), ThreadId: "thread"},
+		{Id: "invalid", ThreadId: "thread"},
+	}
+	res := h.unseenPeers("thread", peers)
+	if len(res) != 2 || res[0].Id != unseen.Pretty() || res[1].Id != "invalid" {
+		t.Errorf("expected unseen and invalid peers, got %v", res)
+	}
+
+	// peers seen on another thread's topic still need direct delivery
+	if res := h.unseenPeers("other", peers); len(res) != 3 {
+		t.Errorf("expected all peers for another thread, got %v", res)
+	}
+}
@@ -18,21 +18,39 @@ import (
 	"github.com/textileio/textile-go/keypair"
 	"github.com/textileio/textile-go/pb"
 	"github.com/textileio/textile-go/repo"
+	"github.com/textileio/textile-go/repo/config"
 	"github.com/textileio/textile-go/service"
 )
 
 // ErrInvalidThreadBlock is a catch all error for malformed / invalid blocks
 var ErrInvalidThreadBlock = errors.New("invalid thread block")
 
+// threadsServiceProtocol is the base protocol tag, also used as the pubsub topic
+const threadsServiceProtocol = protocol.ID("/textile/threads/2.0.0")
+
+// threadsServiceProtocols are the supported stream protocols, newest first
+var threadsServiceProtocols = []protocol.ID{
+	protocol.ID("/textile/threads/2.1.0"),
+	threadsServiceProtocol,
+}
+
+// threadSnapshotsCapability is advertised by peers which handle SNAPSHOT blocks
+const threadSnapshotsCapability = "thread-snapshots"
+
 // ThreadService is a libp2p service for orchestrating a collection of files
 // with annotations amongst a group of peers
 type ThreadsService struct {
-	service          *service.Service
-	datastore        repo.Datastore
-	getThread        func(id string) *Thread
-	addThread        func(sk libp2pc.PrivKey, conf AddThreadConfig) (*Thread, error)
-	sendNotification func(note *repo.Notification) error
-	online           bool
+	service            *service.Service
+	account            *keypair.Full
+	datastore          repo.Datastore
+	getThread          func(id string) *Thread
+	addThread          func(sk libp2pc.PrivKey, conf AddThreadConfig) (*Thread, error)
+	acceptInvite       func(plaintext []byte) (mh.Multihash, error)
+	acceptDeviceInvite func(plaintext []byte) (mh.Multihash, error)
+	sendNotification   func(note *repo.Notification) error
+	topics             *threadTopics
+	probes             *peerProbes
+	online             bool
 }
 
 // NewThreadsService returns a new threads service
@@ -42,21 +60,52 @@ func NewThreadsService(
 	datastore repo.Datastore,
 	getThread func(id string) *Thread,
 	addThread func(sk libp2pc.PrivKey, conf AddThreadConfig) (*Thread, error),
+	acceptInvite func(plaintext []byte) (mh.Multihash, error),
+	acceptDeviceInvite func(plaintext []byte) (mh.Multihash, error),
 	sendNotification func(note *repo.Notification) error,
+	pubsub config.ThreadsPubsub,
+	limiter *service.Limiter,
 ) *ThreadsService {
 	handler := &ThreadsService{
-		datastore:        datastore,
-		getThread:        getThread,
-		addThread:        addThread,
-		sendNotification: sendNotification,
-	}
-	handler.service = service.NewService(account, handler, node)
+		account:            account,
+		datastore:          datastore,
+		getThread:          getThread,
+		addThread:          addThread,
+		acceptInvite:       acceptInvite,
+		acceptDeviceInvite: acceptDeviceInvite,
+		sendNotification:   sendNotification,
+		topics:             newThreadTopics(pubsub),
+		probes:             newPeerProbes(),
+	}
+	handler.service = service.NewService(account, handler, node, limiter)
 	return handler
 }
 
-// Protocol returns the handler protocol
+// Protocol returns the base handler protocol
 func (h *ThreadsService) Protocol() protocol.ID {
-	return protocol.ID("/textile/threads/2.0.0")
+	return threadsServiceProtocol
+}
+
+// Protocols returns the supported stream protocols, newest first
+func (h *ThreadsService) Protocols() []protocol.ID {
+	return threadsServiceProtocols
+}
+
+// Capabilities returns optional features supported by the handler
+func (h *ThreadsService) Capabilities() []string {
+	return []string{threadSnapshotsCapability}
+}
+
+// supportsSnapshots returns whether or not a peer handles SNAPSHOT blocks.
+// Versions are exchanged with peers whose capabilities are not yet known,
+// peers which can't be reached are treated as not handling them.
+func (h *ThreadsService) supportsSnapshots(pid peer.ID) bool {
+	v, err := h.service.Version(pid)
+	if err != nil {
+		log.Debugf("error exchanging version with %s: %s", pid.Pretty(), err)
+		return false
+	}
+	return v.Supports(threadSnapshotsCapability)
 }
 
 // Ping pings another peer
@@ -67,8 +116,10 @@ func (h *ThreadsService) Ping(pid peer.ID) (service.PeerStatus, error) {
 // Handle is called by the underlying service handler method
 func (h *ThreadsService) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
 	if env.Message.Type != pb.Message_THREAD_ENVELOPE {
-		return nil, nil
+		return nil, service.ErrUnsupportedMessage
 	}
+	h.seePeer(pid)
+
 	tenv := new(pb.ThreadEnvelope)
 	if err := ptypes.UnmarshalAny(env.Message.Payload, tenv); err != nil {
 		return nil, err
@@ -80,7 +131,7 @@ func (h *ThreadsService) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, er
 
 	thrd := h.getThread(tenv.Thread)
 	if thrd == nil {
-		// this might be a direct invite
+		// this might be a direct invite or a device invite
 		if err := h.handleInvite(hash, tenv); err != nil {
 			return nil, err
 		}
@@ -127,6 +178,12 @@ func (h *ThreadsService) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, er
 	case pb.ThreadBlock_LIKE:
 		log.Debugf("handling LIKE from %s", block.Header.Author)
 		err = h.handleLike(thrd, hash, block)
+	case pb.ThreadBlock_DEVICE:
+		log.Debugf("handling DEVICE from %s", block.Header.Author)
+		err = h.handleDevice(thrd, hash, block)
+	case pb.ThreadBlock_SNAPSHOT:
+		log.Debugf("handling SNAPSHOT from %s", block.Header.Author)
+		err = h.handleSnapshot(thrd, hash, block)
 	default:
 		return nil, nil
 	}
@@ -165,7 +222,7 @@ func (h *ThreadsService) NewEnvelope(threadId string, hash mh.Multihash, ciphert
 		Hash:       hash.B58String(),
 		Ciphertext: ciphertext,
 	}
-	return h.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, tenv, nil, false)
+	return h.service.NewEnvelope(pb.Message_THREAD_ENVELOPE, tenv, nil)
 }
 
 // handleInvite receives an invite message
@@ -189,6 +246,25 @@ func (h *ThreadsService) handleInvite(hash mh.Multihash, tenv *pb.ThreadEnvelope
 
 	log.Debugf("handling THREAD_INVITE from %s", block.Header.Author)
 
+	if isBlocked(h.datastore, block.Header.Author) {
+		log.Debugf("dropping THREAD_INVITE from blocked peer %s", block.Header.Author)
+		return nil
+	}
+
+	// device link requests and approvals are signed with the account key
+	if len(msg.AccountSig) > 0 {
+		return h.handleDeviceInvite(hash, block, msg, plaintext)
+	}
+
+	// direct threads from known contacts are joined right away,
+	// others go through the normal invite flow
+	if msg.Direct && h.knownContact(block.Header) {
+		if _, err := h.acceptInvite(plaintext); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	date, err := ptypes.Timestamp(block.Header.Date)
 	if err != nil {
 		return err
@@ -198,7 +274,7 @@ func (h *ThreadsService) handleInvite(hash mh.Multihash, tenv *pb.ThreadEnvelope
 		Id:      hash.B58String(),
 		Block:   plaintext,
 		Name:    msg.Name,
-		Contact: protoContactToRepo(msg.Contact),
+		Contact: trustContactClaims(protoContactToRepo(msg.Contact)),
 		Date:    date,
 	}); err != nil {
 		if !repo.ConflictError(err) {
@@ -219,6 +295,61 @@ func (h *ThreadsService) handleInvite(hash mh.Multihash, tenv *pb.ThreadEnvelope
 	return h.sendNotification(notification)
 }
 
+// knownContact returns whether or not a block author is an existing contact
+// still bound to the same account address
+func (h *ThreadsService) knownContact(header *pb.ThreadBlockHeader) bool {
+	contact := h.datastore.Contacts().Get(header.Author)
+	return contact != nil && contact.Address == header.Address
+}
+
+// handleDeviceInvite receives a device link request or approval from another device of this account
+func (h *ThreadsService) handleDeviceInvite(hash mh.Multihash, block *pb.ThreadBlock, msg *pb.ThreadInvite, plaintext []byte) error {
+	if block.Header.Address != h.account.Address() {
+		log.Warningf("dropping device INVITE from foreign account %s", block.Header.Address)
+		return nil
+	}
+	self := h.service.Node().Identity.Pretty()
+	if err := verifyDeviceInvite(h.account.Verify, self, block.Header.Author, msg); err != nil {
+		log.Warningf("dropping device INVITE from %s: %s", block.Header.Author, err)
+		return nil
+	}
+
+	// approvals carry the account thread key
+	if len(msg.Sk) > 0 {
+		if _, err := h.acceptDeviceInvite(plaintext); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	date, err := ptypes.Timestamp(block.Header.Date)
+	if err != nil {
+		return err
+	}
+	if ex := h.datastore.Devices().Get(block.Header.Author); ex != nil && !ex.Pending {
+		// already linked
+		return nil
+	}
+	if err := h.datastore.Devices().AddOrUpdate(&repo.Device{
+		Id:      block.Header.Author,
+		Name:    msg.Name,
+		Pending: true,
+		Date:    date,
+	}); err != nil {
+		return err
+	}
+
+	notification, err := h.newNotification(block.Header, repo.DeviceLinkRequestNotification)
+	if err != nil {
+		return err
+	}
+	notification.Subject = msg.Name
+	notification.SubjectId = block.Header.Author
+	notification.BlockId = hash.B58String() // invite block
+	notification.Body = "requested to link a new device"
+	return h.sendNotification(notification)
+}
+
 // handleMerge receives a merge message
 func (h *ThreadsService) handleMerge(thrd *Thread, hash mh.Multihash, block *pb.ThreadBlock) error {
 	return thrd.handleMergeBlock(hash, block)
@@ -377,6 +508,23 @@ func (h *ThreadsService) handleLike(thrd *Thread, hash mh.Multihash, block *pb.T
 	return h.sendNotification(notification)
 }
 
+// handleDevice receives a device message
+func (h *ThreadsService) handleDevice(thrd *Thread, hash mh.Multihash, block *pb.ThreadBlock) error {
+	if _, err := thrd.handleDeviceBlock(hash, block); err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleSnapshot receives a snapshot message.
+// The thread already has local history, so snapshot state is not applied.
+func (h *ThreadsService) handleSnapshot(thrd *Thread, hash mh.Multihash, block *pb.ThreadBlock) error {
+	if _, err := thrd.handleSnapshotBlock(hash, block, false); err != nil {
+		return err
+	}
+	return nil
+}
+
 // newNotification returns new thread notification
 func (h *ThreadsService) newNotification(header *pb.ThreadBlockHeader, ntype repo.NotificationType) (*repo.Notification, error) {
 	date, err := ptypes.Timestamp(header.Date)
@@ -0,0 +1,179 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// AESChunkSize is the max plaintext size of each chunk in an AES stream
+const AESChunkSize = 64 * 1024
+
+// ErrInvalidStream indicates an AES stream is malformed, truncated, or could not be decrypted
+var ErrInvalidStream = errors.New("invalid encrypted stream")
+
+const (
+	chunkMore  = byte(0)
+	chunkFinal = byte(1)
+)
+
+// aesWriter encrypts a stream in AES-256 GCM chunks
+type aesWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	nonce  []byte
+	buf    []byte
+	count  uint64
+	closed bool
+}
+
+// NewAESWriter returns a writer which encrypts everything written to it with key
+// (:32 key, 32:12 nonce) and writes it to w in chunks. Each chunk is sealed with a nonce
+// derived from its position, and the last one is marked so that truncation is detected.
+// Close must be called to write the last chunk. It does not close w.
+func NewAESWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesWriter{
+		w:     w,
+		aead:  aead,
+		nonce: key[32:],
+		buf:   make([]byte, 0, AESChunkSize),
+	}, nil
+}
+
+func (a *aesWriter) Write(p []byte) (int, error) {
+	if a.closed {
+		return 0, errors.New("write to closed stream")
+	}
+	var n int
+	for len(p) > 0 {
+		// only seal a full chunk once more data arrives, the last chunk is sealed on close
+		if len(a.buf) == AESChunkSize {
+			if err := a.seal(chunkMore); err != nil {
+				return n, err
+			}
+		}
+		c := copy(a.buf[len(a.buf):AESChunkSize], p)
+		a.buf = a.buf[:len(a.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close seals the last chunk
+func (a *aesWriter) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	return a.seal(chunkFinal)
+}
+
+func (a *aesWriter) seal(flag byte) error {
+	header := make([]byte, 5)
+	header[0] = flag
+	ciph := a.aead.Seal(nil, chunkNonce(a.nonce, a.count), a.buf, header[:1])
+	binary.BigEndian.PutUint32(header[1:], uint32(len(ciph)))
+	if _, err := a.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := a.w.Write(ciph); err != nil {
+		return err
+	}
+	a.buf = a.buf[:0]
+	a.count++
+	return nil
+}
+
+// aesReader decrypts a stream written by an aesWriter
+type aesReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	buf   []byte
+	count uint64
+	done  bool
+}
+
+// NewAESReader returns a reader which decrypts a stream written with NewAESWriter.
+// Reads fail with ErrInvalidStream if a chunk fails authentication or the stream ends
+// before the last chunk.
+func NewAESReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesReader{
+		r:     r,
+		aead:  aead,
+		nonce: key[32:],
+	}, nil
+}
+
+func (a *aesReader) Read(p []byte) (int, error) {
+	for len(a.buf) == 0 {
+		if a.done {
+			return 0, io.EOF
+		}
+		if err := a.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, a.buf)
+	a.buf = a.buf[n:]
+	return n, nil
+}
+
+func (a *aesReader) open() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(a.r, header); err != nil {
+		return ErrInvalidStream
+	}
+	flag := header[0]
+	size := binary.BigEndian.Uint32(header[1:])
+	if (flag != chunkMore && flag != chunkFinal) || size > AESChunkSize+uint32(a.aead.Overhead()) {
+		return ErrInvalidStream
+	}
+	ciph := make([]byte, size)
+	if _, err := io.ReadFull(a.r, ciph); err != nil {
+		return ErrInvalidStream
+	}
+	plain, err := a.aead.Open(nil, chunkNonce(a.nonce, a.count), ciph, header[:1])
+	if err != nil {
+		return ErrInvalidStream
+	}
+	a.buf = plain
+	a.count++
+	a.done = flag == chunkFinal
+	return nil
+}
+
+// newAESGCM returns an AES-256 GCM cipher for key (:32 key, 32:12 nonce)
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 44 {
+		return nil, errors.New("invalid key")
+	}
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce derives the nonce of a chunk by xor-ing its position into the base nonce
+func chunkNonce(base []byte, count uint64) []byte {
+	nonce := make([]byte, len(base))
+	copy(nonce, base)
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], count)
+	for i := range ctr {
+		nonce[len(nonce)-8+i] ^= ctr[i]
+	}
+	return nonce
+}
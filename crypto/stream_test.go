@@ -0,0 +1,102 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/textileio/textile-go/crypto"
+)
+
+func encryptStream(t *testing.T, plaintext []byte, key []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewAESWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write in odd sizes to cross chunk boundaries
+	for len(plaintext) > 0 {
+		n := 1000
+		if n > len(plaintext) {
+			n = len(plaintext)
+		}
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(ciphertext []byte, key []byte) ([]byte, error) {
+	r, err := NewAESReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestAESStream(t *testing.T) {
+	key, err := GenerateAESKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, AESChunkSize, AESChunkSize + 1, AESChunkSize*3 + 17} {
+		plaintext := make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+			t.Fatal(err)
+		}
+		ciphertext := encryptStream(t, plaintext, key)
+		decrypted, err := decryptStream(ciphertext, key)
+		if err != nil {
+			t.Fatalf("decrypt %d bytes failed: %s", size, err)
+		}
+		if !bytes.Equal(plaintext, decrypted) {
+			t.Errorf("decrypted %d bytes do not match", size)
+		}
+	}
+}
+
+func TestAESStream_Truncated(t *testing.T) {
+	key, err := GenerateAESKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := make([]byte, AESChunkSize*2+10)
+	ciphertext := encryptStream(t, plaintext, key)
+
+	// cut right after the first chunk, which is a valid chunk boundary
+	first := 5 + AESChunkSize + 16
+	if _, err := decryptStream(ciphertext[:first], key); err != ErrInvalidStream {
+		t.Errorf("expected invalid stream for truncation at chunk boundary, got %v", err)
+	}
+	if _, err := decryptStream(ciphertext[:len(ciphertext)-1], key); err != ErrInvalidStream {
+		t.Errorf("expected invalid stream for truncation, got %v", err)
+	}
+}
+
+func TestAESStream_Tampered(t *testing.T) {
+	key, err := GenerateAESKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := encryptStream(t, []byte("yoyoyoyo!"), key)
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := decryptStream(ciphertext, key); err != ErrInvalidStream {
+		t.Errorf("expected invalid stream for tampered data, got %v", err)
+	}
+
+	other, err := GenerateAESKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext = encryptStream(t, []byte("yoyoyoyo!"), key)
+	if _, err := decryptStream(ciphertext, other); err != ErrInvalidStream {
+		t.Errorf("expected invalid stream for wrong key, got %v", err)
+	}
+}
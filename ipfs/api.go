@@ -1,6 +1,7 @@
 package ipfs
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -162,6 +163,16 @@ func AddObject(node *core.IpfsNode, reader io.Reader, pin bool) (*cid.Cid, error
 	return &id, nil
 }
 
+// DataReaderAtCid returns a seekable reader for the data of a unixfs file,
+// which only fetches the blocks needed for each read
+func DataReaderAtCid(node *core.IpfsNode, id cid.Cid) (uio.DagReader, error) {
+	nd, err := NodeAtCid(node, id)
+	if err != nil {
+		return nil, err
+	}
+	return uio.NewDagReader(node.Context(), nd, node.DAG)
+}
+
 // NodeAtLink returns the node behind an ipld link
 func NodeAtLink(node *core.IpfsNode, link *ipld.Link) (ipld.Node, error) {
 	ctx, cancel := context.WithTimeout(node.Context(), catTimeout)
@@ -176,6 +187,33 @@ func NodeAtCid(node *core.IpfsNode, id cid.Cid) (ipld.Node, error) {
 	return node.DAG.Get(ctx, id)
 }
 
+// PutBlock adds a raw block, checking that it hashes to id
+func PutBlock(node *core.IpfsNode, id cid.Cid, data []byte) error {
+	format := "v0"
+	if id.Version() != 0 {
+		switch id.Type() {
+		case cid.Raw:
+			format = "raw"
+		case cid.DagCBOR:
+			format = "cbor"
+		default:
+			format = "protobuf"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(node.Context(), pinTimeout)
+	defer cancel()
+
+	stat, err := coreapi.NewCoreAPI(node).Block().Put(ctx, bytes.NewReader(data), options.Block.Format(format))
+	if err != nil {
+		return err
+	}
+	if !stat.Path().Cid().Equals(id) {
+		return fmt.Errorf("block data does not match %s", id.String())
+	}
+	return nil
+}
+
 // NodeAtPath returns the last node under path
 func NodeAtPath(node *core.IpfsNode, pth string) (ipld.Node, error) {
 	p, err := path.ParsePath(pth)
@@ -270,6 +308,19 @@ func UnpinNode(node *core.IpfsNode, nd ipld.Node, recursive bool) error {
 	return node.Pinning.Flush()
 }
 
+// UnpinCid unpins a cid without resolving its node, which may not be local
+func UnpinCid(node *core.IpfsNode, id cid.Cid, recursive bool) error {
+	ctx, cancel := context.WithTimeout(node.Context(), pinTimeout)
+	defer cancel()
+
+	err := node.Pinning.Unpin(ctx, id, recursive)
+	if err != nil && err != pin.ErrNotPinned {
+		return err
+	}
+
+	return node.Pinning.Flush()
+}
+
 // Publish publishes data to a topic
 func Publish(node *core.IpfsNode, topic string, data []byte) error {
 	ctx, cancel := context.WithTimeout(node.Context(), publishTimeout)
@@ -280,15 +331,20 @@ func Publish(node *core.IpfsNode, topic string, data []byte) error {
 
 // Subscribe subscribes to a topic
 func Subscribe(node *core.IpfsNode, topic string, msgs chan iface.PubSubMessage) error {
+	return SubscribeContext(node.Context(), node, topic, msgs)
+}
+
+// SubscribeContext subscribes to a topic until the context is done
+func SubscribeContext(ctx context.Context, node *core.IpfsNode, topic string, msgs chan iface.PubSubMessage) error {
 	api := coreapi.NewCoreAPI(node)
-	sub, err := api.PubSub().Subscribe(node.Context(), topic)
+	sub, err := api.PubSub().Subscribe(ctx, topic)
 	if err != nil {
 		return err
 	}
 	defer sub.Close()
 
 	for {
-		msg, err := sub.Next(node.Context())
+		msg, err := sub.Next(ctx)
 		if err == io.EOF || err == context.Canceled {
 			return nil
 		} else if err != nil {
@@ -298,6 +354,11 @@ func Subscribe(node *core.IpfsNode, topic string, msgs chan iface.PubSubMessage)
 	}
 }
 
+// TopicPeers lists the connected peers subscribed to a topic
+func TopicPeers(node *core.IpfsNode, topic string) ([]peer.ID, error) {
+	return coreapi.NewCoreAPI(node).PubSub().Peers(node.Context(), options.PubSub.Topic(topic))
+}
+
 // PublishIPNS publishes a content id to ipns
 func PublishIPNS(node *core.IpfsNode, id string) (iface.IpnsEntry, error) {
 	opts := []options.NamePublishOption{
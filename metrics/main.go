@@ -0,0 +1,189 @@
+// Package metrics is a minimal registry of counters, gauges, and summaries
+// which can be written out in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric types
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+	summaryType = "summary"
+)
+
+// Registry holds a set of metrics
+type Registry struct {
+	metrics map[string]*Metric
+	mux     sync.Mutex
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*Metric)}
+}
+
+// DefaultRegistry is used by the package level constructors
+var DefaultRegistry = NewRegistry()
+
+// NewCounter registers a counter with the default registry
+func NewCounter(name string, help string, labels ...string) *Metric {
+	return DefaultRegistry.register(name, help, counterType, labels)
+}
+
+// NewGauge registers a gauge with the default registry
+func NewGauge(name string, help string, labels ...string) *Metric {
+	return DefaultRegistry.register(name, help, gaugeType, labels)
+}
+
+// NewSummary registers a summary (sum and count) with the default registry
+func NewSummary(name string, help string, labels ...string) *Metric {
+	return DefaultRegistry.register(name, help, summaryType, labels)
+}
+
+// WriteText writes the default registry in the Prometheus text format
+func WriteText(w io.Writer) error {
+	return DefaultRegistry.WriteText(w)
+}
+
+// register adds a metric, returning the existing one if the name is taken
+func (r *Registry) register(name string, help string, typ string, labels []string) *Metric {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		return m
+	}
+	m := &Metric{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		values: make(map[string]*value),
+	}
+	r.metrics[name] = m
+	return m
+}
+
+// WriteText writes all metrics in the Prometheus text format, sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mux.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	list := make([]*Metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		list = append(list, r.metrics[name])
+	}
+	r.mux.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range list {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Metric is a named metric with zero or more labels
+type Metric struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+	values map[string]*value
+	mux    sync.Mutex
+}
+
+// value is a single labeled series
+type value struct {
+	labels string
+	sum    float64
+	count  uint64
+}
+
+// Add adds v to a counter or gauge
+func (m *Metric) Add(v float64, labels ...string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.get(labels).sum += v
+}
+
+// Inc adds one to a counter or gauge
+func (m *Metric) Inc(labels ...string) {
+	m.Add(1, labels...)
+}
+
+// Set sets a gauge to v
+func (m *Metric) Set(v float64, labels ...string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.get(labels).sum = v
+}
+
+// Observe adds an observation to a summary
+func (m *Metric) Observe(v float64, labels ...string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	val := m.get(labels)
+	val.sum += v
+	val.count++
+}
+
+// get returns the series for label values, creating it if needed.
+// Missing label values are left empty, extra values are ignored.
+func (m *Metric) get(labels []string) *value {
+	key := strings.Join(labels, "\xff")
+	if val, ok := m.values[key]; ok {
+		return val
+	}
+	var pairs []string
+	for i, name := range m.labels {
+		var lv string
+		if i < len(labels) {
+			lv = labels[i]
+		}
+		pairs = append(pairs, name+"="+strconv.Quote(lv))
+	}
+	val := &value{}
+	if len(pairs) > 0 {
+		val.labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	m.values[key] = val
+	return val
+}
+
+// write writes the metric's help, type, and series
+func (m *Metric) write(w io.Writer) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.typ)
+
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := m.values[key]
+		if m.typ == summaryType {
+			fmt.Fprintf(w, "%s_sum%s %s\n", m.name, val.labels, formatFloat(val.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", m.name, val.labels, val.count)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s %s\n", m.name, val.labels, formatFloat(val.sum))
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := NewRegistry()
+	calls := reg.register("test_calls_seconds", "Call latency.", summaryType, []string{"method"})
+	rows := reg.register("test_rows_total", "Rows returned.", counterType, nil)
+	depth := reg.register("test_depth", "Queue depth.", gaugeType, []string{"queue"})
+
+	calls.Observe(0.5, "Get")
+	calls.Observe(1.5, "Get")
+	calls.Observe(0.25, "Add")
+	rows.Add(3)
+	rows.Inc()
+	depth.Set(4, "a")
+	depth.Set(2, "a")
+
+	if reg.register("test_rows_total", "", counterType, nil) != rows {
+		t.Error("register should return existing metric")
+	}
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_calls_seconds Call latency.
+# TYPE test_calls_seconds summary
+test_calls_seconds_sum{method="Add"} 0.25
+test_calls_seconds_count{method="Add"} 1
+test_calls_seconds_sum{method="Get"} 2
+test_calls_seconds_count{method="Get"} 2
+# HELP test_depth Queue depth.
+# TYPE test_depth gauge
+test_depth{queue="a"} 2
+# HELP test_rows_total Rows returned.
+# TYPE test_rows_total counter
+test_rows_total 4
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,29 @@
+package mobile
+
+import (
+	"github.com/textileio/textile-go/core"
+)
+
+// BlockPeer calls core BlockPeer
+func (m *Mobile) BlockPeer(id string, muted bool) error {
+	return m.node.BlockPeer(id, muted)
+}
+
+// UnblockPeer calls core UnblockPeer
+func (m *Mobile) UnblockPeer(id string) error {
+	return m.node.UnblockPeer(id)
+}
+
+// BlockedPeers calls core BlockedPeers, offset is the next cursor of a previous page
+func (m *Mobile) BlockedPeers(offset string, limit int) (string, error) {
+	if !m.node.Started() {
+		return "", core.ErrStopped
+	}
+
+	infos := m.node.BlockedPeers()
+	start, end, next, err := core.PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(&core.ListPage{Items: infos[start:end], Next: next})
+}
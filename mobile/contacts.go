@@ -31,8 +31,8 @@ func (m *Mobile) Contact(id string) (string, error) {
 	return "", errors.New("contact not found")
 }
 
-// Contacts calls core Contacts
-func (m *Mobile) Contacts() (string, error) {
+// Contacts calls core Contacts, offset is the next cursor of a previous page
+func (m *Mobile) Contacts(offset string, limit int) (string, error) {
 	if !m.node.Started() {
 		return "", core.ErrStopped
 	}
@@ -44,7 +44,11 @@ func (m *Mobile) Contacts() (string, error) {
 	if len(contacts) == 0 {
 		contacts = make([]core.ContactInfo, 0)
 	}
-	return toJSON(contacts)
+	start, end, next, err := core.PageBounds(len(contacts), func(i int) string { return contacts[i].Id }, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(&core.ListPage{Items: contacts[start:end], Next: next})
 }
 
 // RemoveContact calls core RemoveContact
@@ -52,8 +56,8 @@ func (m *Mobile) RemoveContact(id string) error {
 	return m.node.RemoveContact(id)
 }
 
-// ContactThreads calls core ContactThreads
-func (m *Mobile) ContactThreads(id string) (string, error) {
+// ContactThreads calls core ContactThreads, offset is the next cursor of a previous page
+func (m *Mobile) ContactThreads(id string, offset string, limit int) (string, error) {
 	if !m.node.Started() {
 		return "", core.ErrStopped
 	}
@@ -65,7 +69,42 @@ func (m *Mobile) ContactThreads(id string) (string, error) {
 	if len(infos) == 0 {
 		infos = make([]core.ThreadInfo, 0)
 	}
-	return toJSON(infos)
+	start, end, next, err := core.PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(&core.ListPage{Items: infos[start:end], Next: next})
+}
+
+// ContactVerification calls core ContactVerification
+func (m *Mobile) ContactVerification(id string) (string, error) {
+	if !m.node.Started() {
+		return "", core.ErrStopped
+	}
+
+	info, err := m.node.ContactVerification(id)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(info)
+}
+
+// VerifyContact calls core VerifyContact
+func (m *Mobile) VerifyContact(id string, number string) error {
+	if !m.node.Started() {
+		return core.ErrStopped
+	}
+
+	return m.node.VerifyContact(id, number)
+}
+
+// UnverifyContact calls core UnverifyContact
+func (m *Mobile) UnverifyContact(id string) error {
+	if !m.node.Started() {
+		return core.ErrStopped
+	}
+
+	return m.node.UnverifyContact(id)
 }
 
 // FindContact calls core FindContact
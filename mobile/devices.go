@@ -0,0 +1,34 @@
+package mobile
+
+import (
+	"github.com/textileio/textile-go/core"
+)
+
+// Devices calls core Devices, offset is the next cursor of a previous page
+func (m *Mobile) Devices(offset string, limit int) (string, error) {
+	if !m.node.Started() {
+		return "", core.ErrStopped
+	}
+
+	infos := m.node.Devices()
+	start, end, next, err := core.PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(&core.ListPage{Items: infos[start:end], Next: next})
+}
+
+// RequestDeviceLink calls core RequestDeviceLink
+func (m *Mobile) RequestDeviceLink(id string, name string) error {
+	return m.node.RequestDeviceLink(id, name)
+}
+
+// AddDevice calls core AddDevice
+func (m *Mobile) AddDevice(id string, name string) error {
+	return m.node.AddDevice(id, name)
+}
+
+// RemoveDevice calls core RemoveDevice
+func (m *Mobile) RemoveDevice(id string) error {
+	return m.node.RemoveDevice(id)
+}
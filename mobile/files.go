@@ -256,18 +256,18 @@ func (m *Mobile) AddThreadFilesByTarget(target string, threadId string, caption
 	return m.blockInfo(hash)
 }
 
-// ThreadFiles calls core ThreadFiles
+// ThreadFiles calls core ThreadFiles, offset is the next cursor of a previous page
 func (m *Mobile) ThreadFiles(offset string, limit int, threadId string) (string, error) {
 	if !m.node.Started() {
 		return "", core.ErrStopped
 	}
 
-	files, err := m.node.ThreadFiles(offset, limit, threadId)
+	page, err := m.node.ThreadFiles(offset, limit, threadId)
 	if err != nil {
 		return "", err
 	}
 
-	return toJSON(files)
+	return toJSON(page)
 }
 
 // FileData returns a data url of a raw file under a path
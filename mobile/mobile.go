@@ -75,11 +75,13 @@ type InitConfig struct {
 // MigrateConfig is used to define options during a major migration
 type MigrateConfig struct {
 	RepoPath string
+	PinCode  string
 }
 
 // RunConfig is used to define run options for a mobile node
 type RunConfig struct {
 	RepoPath string
+	PinCode  string
 	Debug    bool
 }
 
@@ -118,6 +120,7 @@ func InitRepo(config *InitConfig) error {
 func MigrateRepo(config *MigrateConfig) error {
 	return core.MigrateRepo(core.MigrateConfig{
 		RepoPath: config.RepoPath,
+		PinCode:  config.PinCode,
 	})
 }
 
@@ -125,6 +128,7 @@ func MigrateRepo(config *MigrateConfig) error {
 func NewTextile(config *RunConfig, messenger Messenger) (*Mobile, error) {
 	node, err := core.NewTextile(core.RunConfig{
 		RepoPath: config.RepoPath,
+		PinCode:  config.PinCode,
 		Debug:    config.Debug,
 	})
 	if err != nil {
@@ -238,6 +242,12 @@ func (m *Mobile) Stop() error {
 	return nil
 }
 
+// ChangePin changes the datastore pin code (use empty strings for no pin).
+// The node must be stopped. Pass the new pin in RunConfig from now on.
+func (m *Mobile) ChangePin(oldPin string, newPin string) error {
+	return m.node.ChangePin(oldPin, newPin)
+}
+
 // Version returns core Version
 func (m *Mobile) Version() string {
 	return core.Version
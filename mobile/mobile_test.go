@@ -173,21 +173,46 @@ func TestMobile_AddThread(t *testing.T) {
 }
 
 func TestMobile_Threads(t *testing.T) {
-	res, err := mobile1.Threads()
+	res, err := mobile1.Threads("", -1)
 	if err != nil {
 		t.Errorf("get threads failed: %s", err)
 		return
 	}
-	var threads []core.ThreadInfo
-	if err := json.Unmarshal([]byte(res), &threads); err != nil {
+	var page struct {
+		Items []core.ThreadInfo `json:"items"`
+		Next  string            `json:"next"`
+	}
+	if err := json.Unmarshal([]byte(res), &page); err != nil {
 		t.Error(err)
 		return
 	}
-	if len(threads) != 1 {
+	if len(page.Items) != 1 || page.Next != "" {
 		t.Error("get threads bad result")
 	}
 }
 
+func TestMobile_ThreadsPage(t *testing.T) {
+	res, err := mobile1.Threads("", 0)
+	if err != nil {
+		t.Errorf("get threads failed: %s", err)
+		return
+	}
+	var page struct {
+		Items []core.ThreadInfo `json:"items"`
+		Next  string            `json:"next"`
+	}
+	if err := json.Unmarshal([]byte(res), &page); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(page.Items) != 0 {
+		t.Error("get threads page bad result")
+	}
+	if _, err := mobile1.Threads("bogus", 1); err != core.ErrInvalidCursor {
+		t.Error("get threads with a bad cursor should fail")
+	}
+}
+
 func TestMobile_RemoveThread(t *testing.T) {
 	res, err := mobile1.AddThread(ksuid.New().String(), "another", false)
 	if err != nil {
@@ -294,10 +319,14 @@ func TestMobile_ThreadFiles(t *testing.T) {
 		t.Errorf("get thread files failed: %s", err)
 		return
 	}
-	if err := json.Unmarshal([]byte(res), &files); err != nil {
+	var page struct {
+		Items []core.ThreadFilesInfo `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(res), &page); err != nil {
 		t.Error(err)
 		return
 	}
+	files = page.Items
 	if len(files) != 2 {
 		t.Errorf("get thread files bad result")
 	}
@@ -335,12 +364,14 @@ func TestMobile_AddThreadIgnore(t *testing.T) {
 		t.Errorf("get thread files failed: %s", err)
 		return
 	}
-	var files []core.ThreadFilesInfo
-	if err := json.Unmarshal([]byte(res), &files); err != nil {
+	var page struct {
+		Items []core.ThreadFilesInfo `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(res), &page); err != nil {
 		t.Error(err)
 		return
 	}
-	if len(files) != 1 {
+	if len(page.Items) != 1 {
 		t.Errorf("thread ignore bad result")
 	}
 }
@@ -549,8 +580,10 @@ func TestMobile_Notifications(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	var notes []core.NotificationInfo
-	if err := json.Unmarshal([]byte(res), &notes); err != nil {
+	var page struct {
+		Items []core.NotificationInfo `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(res), &page); err != nil {
 		t.Error(err)
 	}
 }
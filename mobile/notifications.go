@@ -4,17 +4,17 @@ import (
 	"github.com/textileio/textile-go/core"
 )
 
-// Notifications call core Notifications
+// Notifications call core Notifications, offset is the next cursor of a previous page
 func (m *Mobile) Notifications(offset string, limit int) (string, error) {
 	if !m.node.Started() {
 		return "", core.ErrStopped
 	}
 
-	notes := m.node.Notifications(offset, limit)
-	if len(notes) == 0 {
-		notes = make([]core.NotificationInfo, 0)
+	page, err := m.node.Notifications(offset, limit)
+	if err != nil {
+		return "", err
 	}
-	return toJSON(notes)
+	return toJSON(page)
 }
 
 // CountUnreadNotifications calls core CountUnreadNotifications
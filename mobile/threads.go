@@ -20,8 +20,8 @@ type ExternalInvite struct {
 	Inviter string `json:"inviter"`
 }
 
-// Threads lists all threads
-func (m *Mobile) Threads() (string, error) {
+// Threads pages threads, offset is the next cursor of a previous page
+func (m *Mobile) Threads(offset string, limit int) (string, error) {
 	if !m.node.Started() {
 		return "", core.ErrStopped
 	}
@@ -35,7 +35,11 @@ func (m *Mobile) Threads() (string, error) {
 		infos = append(infos, *info)
 	}
 
-	return toJSON(infos)
+	start, end, next, err := core.PageBounds(len(infos), func(i int) string { return infos[i].Id }, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(&core.ListPage{Items: infos[start:end], Next: next})
 }
 
 // AddThread adds a new thread with the given name
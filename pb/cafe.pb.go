@@ -30,7 +30,7 @@ func (m *CafeChallenge) Reset()         { *m = CafeChallenge{} }
 func (m *CafeChallenge) String() string { return proto.CompactTextString(m) }
 func (*CafeChallenge) ProtoMessage()    {}
 func (*CafeChallenge) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{0}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{0}
 }
 func (m *CafeChallenge) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeChallenge.Unmarshal(m, b)
@@ -68,7 +68,7 @@ func (m *CafeNonce) Reset()         { *m = CafeNonce{} }
 func (m *CafeNonce) String() string { return proto.CompactTextString(m) }
 func (*CafeNonce) ProtoMessage()    {}
 func (*CafeNonce) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{1}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{1}
 }
 func (m *CafeNonce) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeNonce.Unmarshal(m, b)
@@ -109,7 +109,7 @@ func (m *CafeRegistration) Reset()         { *m = CafeRegistration{} }
 func (m *CafeRegistration) String() string { return proto.CompactTextString(m) }
 func (*CafeRegistration) ProtoMessage()    {}
 func (*CafeRegistration) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{2}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{2}
 }
 func (m *CafeRegistration) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeRegistration.Unmarshal(m, b)
@@ -175,7 +175,7 @@ func (m *CafeSession) Reset()         { *m = CafeSession{} }
 func (m *CafeSession) String() string { return proto.CompactTextString(m) }
 func (*CafeSession) ProtoMessage()    {}
 func (*CafeSession) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{3}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{3}
 }
 func (m *CafeSession) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeSession.Unmarshal(m, b)
@@ -262,7 +262,7 @@ func (m *CafeSessions) Reset()         { *m = CafeSessions{} }
 func (m *CafeSessions) String() string { return proto.CompactTextString(m) }
 func (*CafeSessions) ProtoMessage()    {}
 func (*CafeSessions) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{4}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{4}
 }
 func (m *CafeSessions) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeSessions.Unmarshal(m, b)
@@ -301,7 +301,7 @@ func (m *CafeRefreshSession) Reset()         { *m = CafeRefreshSession{} }
 func (m *CafeRefreshSession) String() string { return proto.CompactTextString(m) }
 func (*CafeRefreshSession) ProtoMessage()    {}
 func (*CafeRefreshSession) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{5}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{5}
 }
 func (m *CafeRefreshSession) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeRefreshSession.Unmarshal(m, b)
@@ -347,7 +347,7 @@ func (m *CafePublishContact) Reset()         { *m = CafePublishContact{} }
 func (m *CafePublishContact) String() string { return proto.CompactTextString(m) }
 func (*CafePublishContact) ProtoMessage()    {}
 func (*CafePublishContact) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{6}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{6}
 }
 func (m *CafePublishContact) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafePublishContact.Unmarshal(m, b)
@@ -392,7 +392,7 @@ func (m *CafePublishContactAck) Reset()         { *m = CafePublishContactAck{} }
 func (m *CafePublishContactAck) String() string { return proto.CompactTextString(m) }
 func (*CafePublishContactAck) ProtoMessage()    {}
 func (*CafePublishContactAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{7}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{7}
 }
 func (m *CafePublishContactAck) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafePublishContactAck.Unmarshal(m, b)
@@ -435,7 +435,7 @@ func (m *CafeContactQuery) Reset()         { *m = CafeContactQuery{} }
 func (m *CafeContactQuery) String() string { return proto.CompactTextString(m) }
 func (*CafeContactQuery) ProtoMessage()    {}
 func (*CafeContactQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{8}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{8}
 }
 func (m *CafeContactQuery) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeContactQuery.Unmarshal(m, b)
@@ -508,7 +508,7 @@ func (m *CafeContactQueryResult) Reset()         { *m = CafeContactQueryResult{}
 func (m *CafeContactQueryResult) String() string { return proto.CompactTextString(m) }
 func (*CafeContactQueryResult) ProtoMessage()    {}
 func (*CafeContactQueryResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{9}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{9}
 }
 func (m *CafeContactQueryResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeContactQueryResult.Unmarshal(m, b)
@@ -547,7 +547,7 @@ func (m *CafeStore) Reset()         { *m = CafeStore{} }
 func (m *CafeStore) String() string { return proto.CompactTextString(m) }
 func (*CafeStore) ProtoMessage()    {}
 func (*CafeStore) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{10}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{10}
 }
 func (m *CafeStore) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeStore.Unmarshal(m, b)
@@ -592,7 +592,7 @@ func (m *CafeObjectList) Reset()         { *m = CafeObjectList{} }
 func (m *CafeObjectList) String() string { return proto.CompactTextString(m) }
 func (*CafeObjectList) ProtoMessage()    {}
 func (*CafeObjectList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{11}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{11}
 }
 func (m *CafeObjectList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeObjectList.Unmarshal(m, b)
@@ -633,7 +633,7 @@ func (m *CafeObject) Reset()         { *m = CafeObject{} }
 func (m *CafeObject) String() string { return proto.CompactTextString(m) }
 func (*CafeObject) ProtoMessage()    {}
 func (*CafeObject) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{12}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{12}
 }
 func (m *CafeObject) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeObject.Unmarshal(m, b)
@@ -681,6 +681,138 @@ func (m *CafeObject) GetNode() []byte {
 	return nil
 }
 
+type CafeObjectChunk struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Cid                  string   `protobuf:"bytes,2,opt,name=cid,proto3" json:"cid,omitempty"`
+	Node                 bool     `protobuf:"varint,3,opt,name=node,proto3" json:"node,omitempty"`
+	Size                 int64    `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Offset               int64    `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data                 []byte   `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CafeObjectChunk) Reset()         { *m = CafeObjectChunk{} }
+func (m *CafeObjectChunk) String() string { return proto.CompactTextString(m) }
+func (*CafeObjectChunk) ProtoMessage()    {}
+func (*CafeObjectChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{13}
+}
+func (m *CafeObjectChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CafeObjectChunk.Unmarshal(m, b)
+}
+func (m *CafeObjectChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CafeObjectChunk.Marshal(b, m, deterministic)
+}
+func (dst *CafeObjectChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CafeObjectChunk.Merge(dst, src)
+}
+func (m *CafeObjectChunk) XXX_Size() int {
+	return xxx_messageInfo_CafeObjectChunk.Size(m)
+}
+func (m *CafeObjectChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_CafeObjectChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CafeObjectChunk proto.InternalMessageInfo
+
+func (m *CafeObjectChunk) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *CafeObjectChunk) GetCid() string {
+	if m != nil {
+		return m.Cid
+	}
+	return ""
+}
+
+func (m *CafeObjectChunk) GetNode() bool {
+	if m != nil {
+		return m.Node
+	}
+	return false
+}
+
+func (m *CafeObjectChunk) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *CafeObjectChunk) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *CafeObjectChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type CafeObjectChunkAck struct {
+	Cid                  string   `protobuf:"bytes,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	Offset               int64    `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Stored               bool     `protobuf:"varint,3,opt,name=stored,proto3" json:"stored,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CafeObjectChunkAck) Reset()         { *m = CafeObjectChunkAck{} }
+func (m *CafeObjectChunkAck) String() string { return proto.CompactTextString(m) }
+func (*CafeObjectChunkAck) ProtoMessage()    {}
+func (*CafeObjectChunkAck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{14}
+}
+func (m *CafeObjectChunkAck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CafeObjectChunkAck.Unmarshal(m, b)
+}
+func (m *CafeObjectChunkAck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CafeObjectChunkAck.Marshal(b, m, deterministic)
+}
+func (dst *CafeObjectChunkAck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CafeObjectChunkAck.Merge(dst, src)
+}
+func (m *CafeObjectChunkAck) XXX_Size() int {
+	return xxx_messageInfo_CafeObjectChunkAck.Size(m)
+}
+func (m *CafeObjectChunkAck) XXX_DiscardUnknown() {
+	xxx_messageInfo_CafeObjectChunkAck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CafeObjectChunkAck proto.InternalMessageInfo
+
+func (m *CafeObjectChunkAck) GetCid() string {
+	if m != nil {
+		return m.Cid
+	}
+	return ""
+}
+
+func (m *CafeObjectChunkAck) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *CafeObjectChunkAck) GetStored() bool {
+	if m != nil {
+		return m.Stored
+	}
+	return false
+}
+
 type CafeStoreThread struct {
 	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
@@ -694,7 +826,7 @@ func (m *CafeStoreThread) Reset()         { *m = CafeStoreThread{} }
 func (m *CafeStoreThread) String() string { return proto.CompactTextString(m) }
 func (*CafeStoreThread) ProtoMessage()    {}
 func (*CafeStoreThread) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{13}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{15}
 }
 func (m *CafeStoreThread) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeStoreThread.Unmarshal(m, b)
@@ -753,7 +885,7 @@ func (m *CafeThread) Reset()         { *m = CafeThread{} }
 func (m *CafeThread) String() string { return proto.CompactTextString(m) }
 func (*CafeThread) ProtoMessage()    {}
 func (*CafeThread) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{14}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{16}
 }
 func (m *CafeThread) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeThread.Unmarshal(m, b)
@@ -840,7 +972,7 @@ func (m *CafeStored) Reset()         { *m = CafeStored{} }
 func (m *CafeStored) String() string { return proto.CompactTextString(m) }
 func (*CafeStored) ProtoMessage()    {}
 func (*CafeStored) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{15}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{17}
 }
 func (m *CafeStored) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeStored.Unmarshal(m, b)
@@ -879,7 +1011,7 @@ func (m *CafeDeliverMessage) Reset()         { *m = CafeDeliverMessage{} }
 func (m *CafeDeliverMessage) String() string { return proto.CompactTextString(m) }
 func (*CafeDeliverMessage) ProtoMessage()    {}
 func (*CafeDeliverMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{16}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{18}
 }
 func (m *CafeDeliverMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeDeliverMessage.Unmarshal(m, b)
@@ -924,7 +1056,7 @@ func (m *CafeCheckMessages) Reset()         { *m = CafeCheckMessages{} }
 func (m *CafeCheckMessages) String() string { return proto.CompactTextString(m) }
 func (*CafeCheckMessages) ProtoMessage()    {}
 func (*CafeCheckMessages) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{17}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{19}
 }
 func (m *CafeCheckMessages) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeCheckMessages.Unmarshal(m, b)
@@ -964,7 +1096,7 @@ func (m *CafeMessage) Reset()         { *m = CafeMessage{} }
 func (m *CafeMessage) String() string { return proto.CompactTextString(m) }
 func (*CafeMessage) ProtoMessage()    {}
 func (*CafeMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{18}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{20}
 }
 func (m *CafeMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeMessage.Unmarshal(m, b)
@@ -1016,7 +1148,7 @@ func (m *CafeMessages) Reset()         { *m = CafeMessages{} }
 func (m *CafeMessages) String() string { return proto.CompactTextString(m) }
 func (*CafeMessages) ProtoMessage()    {}
 func (*CafeMessages) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{19}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{21}
 }
 func (m *CafeMessages) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeMessages.Unmarshal(m, b)
@@ -1054,7 +1186,7 @@ func (m *CafeDeleteMessages) Reset()         { *m = CafeDeleteMessages{} }
 func (m *CafeDeleteMessages) String() string { return proto.CompactTextString(m) }
 func (*CafeDeleteMessages) ProtoMessage()    {}
 func (*CafeDeleteMessages) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{20}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{22}
 }
 func (m *CafeDeleteMessages) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeDeleteMessages.Unmarshal(m, b)
@@ -1092,7 +1224,7 @@ func (m *CafeDeleteMessagesAck) Reset()         { *m = CafeDeleteMessagesAck{} }
 func (m *CafeDeleteMessagesAck) String() string { return proto.CompactTextString(m) }
 func (*CafeDeleteMessagesAck) ProtoMessage()    {}
 func (*CafeDeleteMessagesAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_4333874df816a892, []int{21}
+	return fileDescriptor_cafe_0f8caf6af7a1ba58, []int{23}
 }
 func (m *CafeDeleteMessagesAck) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafeDeleteMessagesAck.Unmarshal(m, b)
@@ -1133,6 +1265,8 @@ func init() {
 	proto.RegisterType((*CafeStore)(nil), "CafeStore")
 	proto.RegisterType((*CafeObjectList)(nil), "CafeObjectList")
 	proto.RegisterType((*CafeObject)(nil), "CafeObject")
+	proto.RegisterType((*CafeObjectChunk)(nil), "CafeObjectChunk")
+	proto.RegisterType((*CafeObjectChunkAck)(nil), "CafeObjectChunkAck")
 	proto.RegisterType((*CafeStoreThread)(nil), "CafeStoreThread")
 	proto.RegisterType((*CafeThread)(nil), "CafeThread")
 	proto.RegisterType((*CafeStored)(nil), "CafeStored")
@@ -1144,58 +1278,63 @@ func init() {
 	proto.RegisterType((*CafeDeleteMessagesAck)(nil), "CafeDeleteMessagesAck")
 }
 
-func init() { proto.RegisterFile("cafe.proto", fileDescriptor_cafe_4333874df816a892) }
-
-var fileDescriptor_cafe_4333874df816a892 = []byte{
-	// 799 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6b, 0x1b, 0x47,
-	0x10, 0xe6, 0xf4, 0xcb, 0xd2, 0x48, 0x75, 0xd5, 0xc5, 0x15, 0xaa, 0x31, 0xad, 0xbb, 0x18, 0x2a,
-	0xb7, 0xe5, 0x0c, 0x6e, 0x0b, 0x7d, 0x2a, 0x75, 0x1c, 0x02, 0x81, 0xc4, 0x49, 0xd6, 0x0e, 0x81,
-	0x90, 0x97, 0xd5, 0xdd, 0x48, 0x5a, 0xeb, 0x7e, 0x88, 0xdb, 0x95, 0x63, 0xff, 0x57, 0x79, 0xca,
-	0x9f, 0x96, 0xe7, 0x30, 0xbb, 0x7b, 0x27, 0xc9, 0x8e, 0xf0, 0xdb, 0xcc, 0xdc, 0xe8, 0x9b, 0xd9,
-	0xef, 0x9b, 0x19, 0x01, 0x44, 0x72, 0x82, 0xe1, 0xa2, 0xc8, 0x4d, 0xbe, 0xff, 0xcb, 0x34, 0xcf,
-	0xa7, 0x09, 0x9e, 0x58, 0x6f, 0xbc, 0x9c, 0x9c, 0x18, 0x95, 0xa2, 0x36, 0x32, 0x5d, 0xf8, 0x84,
-	0x6e, 0x9a, 0xc7, 0x98, 0x38, 0x87, 0x1f, 0xc3, 0x77, 0xe7, 0x72, 0x82, 0xe7, 0x33, 0x99, 0x24,
-	0x98, 0x4d, 0x91, 0x0d, 0x61, 0x47, 0xc6, 0x71, 0x81, 0x5a, 0x0f, 0x83, 0xc3, 0x60, 0xd4, 0x11,
-	0xa5, 0xcb, 0x7f, 0x85, 0x0e, 0xa5, 0x5e, 0xe4, 0x59, 0x84, 0x6c, 0x0f, 0x9a, 0x37, 0x32, 0x59,
-	0xa2, 0x4f, 0x72, 0x0e, 0xbf, 0x86, 0x3e, 0xa5, 0x08, 0x9c, 0x2a, 0x6d, 0x0a, 0x69, 0x54, 0x9e,
-	0x6d, 0x07, 0x5c, 0x61, 0xd4, 0xd6, 0x30, 0x28, 0x9a, 0x51, 0x89, 0x61, 0xdd, 0x45, 0xad, 0xc3,
-	0xfa, 0x50, 0xd7, 0x6a, 0x3a, 0x6c, 0x1c, 0x06, 0xa3, 0x9e, 0x20, 0x93, 0x7f, 0x09, 0xa0, 0x4b,
-	0xc5, 0x2e, 0x51, 0x6b, 0xaa, 0xb3, 0x0b, 0x35, 0x15, 0xfb, 0x12, 0x35, 0x15, 0xb3, 0x01, 0xb4,
-	0x64, 0x14, 0x51, 0x59, 0x07, 0xef, 0x3d, 0xf6, 0x27, 0xd4, 0xf1, 0x76, 0x61, 0xd1, 0xbb, 0xa7,
-	0xfb, 0xa1, 0x63, 0x2b, 0x2c, 0xd9, 0x0a, 0xaf, 0x4a, 0xb6, 0x04, 0xa5, 0x51, 0xf7, 0x05, 0x4e,
-	0x0a, 0xd4, 0x33, 0x5b, 0xbb, 0x23, 0x4a, 0x97, 0x85, 0xd0, 0x28, 0x08, 0xa8, 0xf9, 0x28, 0x90,
-	0xcd, 0x23, 0x24, 0xbd, 0x1c, 0x5f, 0x63, 0x64, 0x86, 0x2d, 0x87, 0xe4, 0x5d, 0xc6, 0xa0, 0x61,
-	0xee, 0x16, 0x38, 0xdc, 0xb1, 0x61, 0x6b, 0xb3, 0x9f, 0xa0, 0x41, 0x9a, 0x0e, 0xdb, 0x16, 0xbd,
-	0x19, 0x5a, 0x5a, 0x6d, 0x88, 0xff, 0x0d, 0xbd, 0xb5, 0x77, 0x6b, 0x76, 0x04, 0x2d, 0xcb, 0x1c,
-	0xf1, 0x5b, 0x1f, 0x75, 0x4f, 0x7b, 0xe1, 0xda, 0x67, 0xe1, 0xbf, 0xf1, 0x67, 0xc0, 0x9c, 0x34,
-	0xb6, 0xfb, 0x92, 0xb4, 0x15, 0x49, 0xc1, 0x06, 0x49, 0x6b, 0xcf, 0xae, 0x6d, 0x3c, 0x9b, 0x5f,
-	0x38, 0x9c, 0xd7, 0xcb, 0x71, 0xa2, 0xf4, 0xec, 0x3c, 0xcf, 0x8c, 0x8c, 0x0c, 0x89, 0x66, 0xf2,
-	0x39, 0x66, 0xe5, 0x38, 0x58, 0x87, 0x71, 0xd8, 0x89, 0x5c, 0x82, 0x45, 0xe9, 0x9e, 0xb6, 0x43,
-	0xff, 0x03, 0x51, 0x7e, 0xe0, 0xbf, 0xc1, 0x8f, 0x0f, 0xf1, 0xce, 0xa2, 0xf9, 0x7d, 0x3d, 0xf9,
-	0xa7, 0xc0, 0x0d, 0x97, 0x4f, 0x79, 0xb3, 0xc4, 0xe2, 0x6e, 0x4b, 0xdd, 0x01, 0xb4, 0x26, 0x2a,
-	0x8b, 0x9f, 0xc7, 0xa5, 0xf4, 0xce, 0x63, 0x87, 0xd0, 0x25, 0xeb, 0xcc, 0x8f, 0xa3, 0x1b, 0xb0,
-	0xf5, 0x10, 0xe3, 0xd0, 0x23, 0xf7, 0xad, 0xc6, 0x22, 0x93, 0x29, 0x7a, 0xcd, 0x37, 0x62, 0x54,
-	0x33, 0x51, 0xa9, 0x32, 0x56, 0xf9, 0xa6, 0x70, 0x0e, 0x89, 0xf8, 0x51, 0x2a, 0xa7, 0x6d, 0x53,
-	0x58, 0x9b, 0xff, 0x07, 0x83, 0xfb, 0x1d, 0x0b, 0xd4, 0xcb, 0xc4, 0xb0, 0x23, 0x68, 0x7b, 0x02,
-	0x4a, 0xd5, 0x56, 0xd4, 0x54, 0x5f, 0xf8, 0x3f, 0x6e, 0xe3, 0x2e, 0x4d, 0x5e, 0xe0, 0x96, 0xa7,
-	0x32, 0x68, 0x44, 0x2a, 0xa6, 0x19, 0xaf, 0xd3, 0xec, 0x90, 0xcd, 0x8f, 0x60, 0x97, 0x7e, 0xf6,
-	0xca, 0x4e, 0xd7, 0x0b, 0xa5, 0x4d, 0x95, 0x15, 0xac, 0x65, 0x7d, 0x00, 0x58, 0x65, 0x6d, 0x41,
-	0xef, 0x43, 0x3d, 0x52, 0x25, 0x8b, 0x64, 0x12, 0x52, 0x2c, 0x8d, 0xb4, 0xdc, 0xf5, 0x84, 0xb5,
-	0x29, 0x96, 0xe5, 0x31, 0xfa, 0xe5, 0xb4, 0x36, 0x7f, 0x07, 0xdf, 0x57, 0xad, 0x5f, 0xcd, 0x0a,
-	0x94, 0xf1, 0x96, 0x12, 0x4e, 0xe6, 0x5a, 0xb5, 0xb6, 0x3f, 0x03, 0x44, 0x6a, 0x31, 0xc3, 0xc2,
-	0xe0, 0xad, 0xf1, 0x65, 0xd6, 0x22, 0xfc, 0x73, 0xe0, 0xfa, 0xf6, 0xa0, 0x7d, 0xa8, 0xcf, 0xf1,
-	0xce, 0x43, 0x92, 0x49, 0x80, 0x7a, 0x6e, 0x01, 0x7b, 0xa2, 0xa6, 0xe7, 0xb6, 0x3b, 0x92, 0xd2,
-	0xa9, 0x6d, 0x6d, 0x1a, 0x10, 0x1d, 0xcd, 0x30, 0x95, 0x5e, 0x60, 0xef, 0xb1, 0x03, 0xe8, 0xa8,
-	0x4c, 0x19, 0x25, 0x4d, 0x5e, 0x58, 0x79, 0x3b, 0x62, 0x15, 0xa8, 0xf6, 0xd4, 0x4b, 0x6c, 0xf7,
-	0x74, 0x0f, 0x9a, 0xda, 0x48, 0xe3, 0x96, 0xb7, 0x29, 0x9c, 0x43, 0x99, 0x33, 0x94, 0xb1, 0xdd,
-	0xde, 0x8e, 0xb0, 0x36, 0x3f, 0x70, 0x7d, 0x5b, 0x46, 0xe2, 0x07, 0xd3, 0xfd, 0xbf, 0x5b, 0xab,
-	0xa7, 0x98, 0xa8, 0x1b, 0x2c, 0x5e, 0xa2, 0xd6, 0x72, 0x8a, 0x0f, 0x6e, 0xda, 0x3e, 0xb4, 0xa3,
-	0x44, 0x61, 0x66, 0xaa, 0xd1, 0xae, 0x7c, 0x7e, 0x0c, 0x3f, 0xb8, 0x4b, 0x8e, 0xd1, 0xdc, 0xff,
-	0x5e, 0x7f, 0x9b, 0x73, 0x8e, 0xee, 0x72, 0x6e, 0xab, 0x32, 0x80, 0xd6, 0x02, 0xb1, 0x58, 0xad,
-	0x8f, 0xf3, 0xe8, 0xe2, 0xc5, 0xf4, 0xd4, 0xc7, 0x4f, 0xa7, 0xcd, 0xe3, 0xff, 0xba, 0x43, 0x55,
-	0x35, 0x33, 0x82, 0x76, 0xea, 0xed, 0x8d, 0x53, 0xe5, 0x13, 0x44, 0xf5, 0x95, 0xff, 0x5e, 0xb1,
-	0x81, 0x06, 0x1f, 0x79, 0xcc, 0x1f, 0xee, 0x80, 0x6c, 0xe6, 0xd2, 0x01, 0x61, 0xd0, 0x48, 0xf3,
-	0xc2, 0xfd, 0x43, 0xb5, 0x85, 0xb5, 0x9f, 0x34, 0xde, 0xd7, 0x16, 0xe3, 0x71, 0xcb, 0xb6, 0xfc,
-	0xd7, 0xd7, 0x00, 0x00, 0x00, 0xff, 0xff, 0xeb, 0xd5, 0x92, 0x3b, 0x37, 0x07, 0x00, 0x00,
+func init() { proto.RegisterFile("cafe.proto", fileDescriptor_cafe_0f8caf6af7a1ba58) }
+
+var fileDescriptor_cafe_0f8caf6af7a1ba58 = []byte{
+	// 866 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xcd, 0x8e, 0x23, 0x35,
+	0x10, 0x56, 0xa7, 0x93, 0x4c, 0x52, 0x09, 0xbb, 0x83, 0xc5, 0x46, 0x61, 0xb4, 0x82, 0xc1, 0x1a,
+	0x89, 0x59, 0x40, 0x59, 0x69, 0x00, 0x89, 0x13, 0x62, 0x19, 0x84, 0x84, 0x04, 0x0b, 0x78, 0x17,
+	0x90, 0x10, 0x17, 0xa7, 0xbb, 0x92, 0x78, 0xfb, 0x2f, 0xb2, 0x9d, 0x65, 0x86, 0x77, 0xe0, 0x5d,
+	0x38, 0xf1, 0x68, 0x9c, 0x51, 0xd9, 0xee, 0x9f, 0x64, 0x88, 0x46, 0xdc, 0xea, 0x2b, 0x57, 0x7f,
+	0x55, 0xfe, 0xaa, 0x5c, 0x0d, 0x90, 0xc8, 0x15, 0x2e, 0xb6, 0xba, 0xb2, 0xd5, 0xd9, 0xbb, 0xeb,
+	0xaa, 0x5a, 0xe7, 0xf8, 0xd4, 0xa1, 0xe5, 0x6e, 0xf5, 0xd4, 0xaa, 0x02, 0x8d, 0x95, 0xc5, 0x36,
+	0x04, 0x4c, 0x8a, 0x2a, 0xc5, 0xdc, 0x03, 0xfe, 0x04, 0xde, 0xb8, 0x96, 0x2b, 0xbc, 0xde, 0xc8,
+	0x3c, 0xc7, 0x72, 0x8d, 0x6c, 0x0e, 0x27, 0x32, 0x4d, 0x35, 0x1a, 0x33, 0x8f, 0xce, 0xa3, 0xcb,
+	0xb1, 0xa8, 0x21, 0x7f, 0x0f, 0xc6, 0x14, 0xfa, 0xbc, 0x2a, 0x13, 0x64, 0x6f, 0xc1, 0xe0, 0xb5,
+	0xcc, 0x77, 0x18, 0x82, 0x3c, 0xe0, 0xaf, 0xe0, 0x94, 0x42, 0x04, 0xae, 0x95, 0xb1, 0x5a, 0x5a,
+	0x55, 0x95, 0xc7, 0x09, 0x5b, 0x8e, 0x5e, 0x87, 0x83, 0xbc, 0x25, 0xa5, 0x98, 0xc7, 0xde, 0xeb,
+	0x00, 0x3b, 0x85, 0xd8, 0xa8, 0xf5, 0xbc, 0x7f, 0x1e, 0x5d, 0x4e, 0x05, 0x99, 0xfc, 0x9f, 0x08,
+	0x26, 0x94, 0xec, 0x05, 0x1a, 0x43, 0x79, 0x1e, 0x40, 0x4f, 0xa5, 0x21, 0x45, 0x4f, 0xa5, 0x6c,
+	0x06, 0x43, 0x99, 0x24, 0x94, 0xd6, 0xd3, 0x07, 0xc4, 0x3e, 0x82, 0x18, 0x6f, 0xb6, 0x8e, 0x7d,
+	0x72, 0x75, 0xb6, 0xf0, 0x6a, 0x2d, 0x6a, 0xb5, 0x16, 0x2f, 0x6b, 0xb5, 0x04, 0x85, 0x51, 0xf5,
+	0x1a, 0x57, 0x1a, 0xcd, 0xc6, 0xe5, 0x1e, 0x8b, 0x1a, 0xb2, 0x05, 0xf4, 0x35, 0x11, 0x0d, 0xee,
+	0x25, 0xea, 0xeb, 0xc0, 0x64, 0x76, 0xcb, 0x57, 0x98, 0xd8, 0xf9, 0xd0, 0x33, 0x05, 0xc8, 0x18,
+	0xf4, 0xed, 0xed, 0x16, 0xe7, 0x27, 0xce, 0xed, 0x6c, 0xf6, 0x36, 0xf4, 0xa9, 0xa7, 0xf3, 0x91,
+	0x63, 0x1f, 0x2c, 0x9c, 0xac, 0xce, 0xc5, 0x3f, 0x81, 0x69, 0xe7, 0xde, 0x86, 0x5d, 0xc0, 0xd0,
+	0x29, 0x47, 0xfa, 0xc6, 0x97, 0x93, 0xab, 0xe9, 0xa2, 0x73, 0x2c, 0xc2, 0x19, 0xff, 0x1a, 0x98,
+	0x6f, 0x8d, 0xab, 0xbe, 0x16, 0xad, 0x15, 0x29, 0xda, 0x13, 0xa9, 0x73, 0xed, 0xde, 0xde, 0xb5,
+	0xf9, 0x73, 0xcf, 0xf3, 0xc3, 0x6e, 0x99, 0x2b, 0xb3, 0xb9, 0xae, 0x4a, 0x2b, 0x13, 0x4b, 0x4d,
+	0xb3, 0x55, 0x86, 0x65, 0x3d, 0x0e, 0x0e, 0x30, 0x0e, 0x27, 0x89, 0x0f, 0x70, 0x2c, 0x93, 0xab,
+	0xd1, 0x22, 0x7c, 0x20, 0xea, 0x03, 0xfe, 0x3e, 0x3c, 0xba, 0xcb, 0xf7, 0x2c, 0xc9, 0x0e, 0xfb,
+	0xc9, 0xff, 0x8a, 0xfc, 0x70, 0x85, 0x90, 0x1f, 0x77, 0xa8, 0x6f, 0x8f, 0xe4, 0x9d, 0xc1, 0x70,
+	0xa5, 0xca, 0xf4, 0x9b, 0xb4, 0x6e, 0xbd, 0x47, 0xec, 0x1c, 0x26, 0x64, 0x3d, 0x0b, 0xe3, 0xe8,
+	0x07, 0xac, 0xeb, 0x62, 0x1c, 0xa6, 0x04, 0x7f, 0x32, 0xa8, 0x4b, 0x59, 0x60, 0xe8, 0xf9, 0x9e,
+	0x8f, 0x72, 0xe6, 0xaa, 0x50, 0xd6, 0x75, 0x7e, 0x20, 0x3c, 0xa0, 0x26, 0xfe, 0x2e, 0x95, 0xef,
+	0xed, 0x40, 0x38, 0x9b, 0x7f, 0x0e, 0xb3, 0xc3, 0x8a, 0x05, 0x9a, 0x5d, 0x6e, 0xd9, 0x05, 0x8c,
+	0x82, 0x00, 0x75, 0xd7, 0x5a, 0x69, 0x9a, 0x13, 0xfe, 0xa9, 0x7f, 0x71, 0x2f, 0x6c, 0xa5, 0xf1,
+	0xc8, 0x55, 0x19, 0xf4, 0x13, 0x95, 0xd2, 0x8c, 0xc7, 0x34, 0x3b, 0x64, 0xf3, 0x0b, 0x78, 0x40,
+	0x9f, 0x7d, 0xef, 0xa6, 0xeb, 0x5b, 0x65, 0x6c, 0x13, 0x15, 0x75, 0xa2, 0x7e, 0x03, 0x68, 0xa3,
+	0x8e, 0xb0, 0x9f, 0x42, 0x9c, 0xa8, 0x5a, 0x45, 0x32, 0x89, 0x29, 0x95, 0x56, 0x3a, 0xed, 0xa6,
+	0xc2, 0xd9, 0xe4, 0x2b, 0xab, 0x14, 0xc3, 0xe3, 0x74, 0x36, 0xff, 0x33, 0x82, 0x87, 0x2d, 0xfd,
+	0xf5, 0x66, 0x57, 0x66, 0xff, 0x27, 0x87, 0xe3, 0xa3, 0x1c, 0x23, 0xcf, 0x47, 0x3e, 0xa3, 0xfe,
+	0xf0, 0x39, 0x62, 0xe1, 0x6c, 0x6a, 0x73, 0xb5, 0x5a, 0x19, 0xf4, 0x9d, 0x88, 0x45, 0x40, 0x4d,
+	0x8d, 0xc3, 0xb6, 0x46, 0xfe, 0x33, 0xb0, 0x83, 0x72, 0x68, 0xc6, 0x42, 0xee, 0xa8, 0xcd, 0xdd,
+	0x72, 0xf6, 0xf6, 0x38, 0x67, 0x30, 0x34, 0xd4, 0x86, 0x34, 0x54, 0x15, 0x10, 0xff, 0x05, 0x1e,
+	0x36, 0x2d, 0x7a, 0xb9, 0xd1, 0x28, 0xd3, 0x23, 0xd7, 0xf4, 0xe3, 0xdc, 0x6b, 0xd6, 0xd3, 0x3b,
+	0x00, 0x89, 0xda, 0x6e, 0x50, 0x5b, 0xbc, 0xb1, 0x41, 0xce, 0x8e, 0x87, 0xff, 0x1d, 0xf9, 0xfe,
+	0x04, 0xd2, 0x53, 0x88, 0x33, 0xbc, 0xad, 0x2b, 0xcd, 0xf0, 0x96, 0x08, 0x4d, 0xe6, 0x08, 0xa7,
+	0xa2, 0x67, 0x32, 0xa7, 0x1a, 0x8d, 0xac, 0x9f, 0x6a, 0x67, 0xbb, 0xaa, 0x93, 0x0d, 0x16, 0x32,
+	0x0c, 0x72, 0x40, 0xec, 0x31, 0x8c, 0x55, 0xa9, 0xac, 0x92, 0xb6, 0xd2, 0x4e, 0xbc, 0xb1, 0x68,
+	0x1d, 0xcd, 0x3e, 0x0a, 0xa3, 0x4c, 0x36, 0x5d, 0xca, 0x58, 0x69, 0xfd, 0x92, 0x1a, 0x08, 0x0f,
+	0x28, 0x72, 0x83, 0x32, 0x75, 0x5b, 0x6a, 0x2c, 0x9c, 0xcd, 0x1f, 0x03, 0x34, 0x8a, 0xa4, 0x77,
+	0x5e, 0xf1, 0x17, 0xbe, 0x0f, 0x5f, 0x61, 0xae, 0x5e, 0xa3, 0xfe, 0x0e, 0x8d, 0x91, 0x6b, 0x3c,
+	0x8c, 0x62, 0x67, 0x30, 0x4a, 0x72, 0x85, 0xa5, 0x6d, 0x9e, 0x70, 0x83, 0xf9, 0x13, 0x78, 0xd3,
+	0xff, 0xb1, 0x30, 0xc9, 0xc2, 0xf7, 0xe6, 0xbf, 0x35, 0xe7, 0xe8, 0xff, 0x10, 0xc7, 0xb2, 0xcc,
+	0x60, 0xb8, 0x45, 0xd4, 0xed, 0x9a, 0xf0, 0x88, 0x36, 0x7b, 0x2a, 0xad, 0x57, 0xf2, 0x9e, 0xcd,
+	0x4e, 0x71, 0xfc, 0x33, 0x98, 0x76, 0xd2, 0x18, 0x76, 0x09, 0xa3, 0x22, 0xd8, 0x7b, 0x2b, 0x39,
+	0x04, 0x88, 0xe6, 0x94, 0x7f, 0xd0, 0xa8, 0x81, 0x16, 0xef, 0xb9, 0xcc, 0x87, 0xf0, 0xe8, 0x6e,
+	0x2c, 0x0d, 0x31, 0x83, 0x7e, 0x51, 0x69, 0xff, 0x27, 0x1e, 0x09, 0x67, 0x7f, 0xd9, 0xff, 0xb5,
+	0xb7, 0x5d, 0x2e, 0x87, 0xae, 0xe4, 0x8f, 0xff, 0x1d, 0x00, 0x7a, 0x02, 0x97, 0x91, 0x1f, 0x08,
+	0x00, 0x00,
 }
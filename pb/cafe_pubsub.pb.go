@@ -19,20 +19,23 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type CafePubSubContactQuery struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	FindId               string   `protobuf:"bytes,2,opt,name=findId,proto3" json:"findId,omitempty"`
-	FindAddress          string   `protobuf:"bytes,3,opt,name=findAddress,proto3" json:"findAddress,omitempty"`
-	FindUsername         string   `protobuf:"bytes,4,opt,name=findUsername,proto3" json:"findUsername,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id                   string                `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FindId               string                `protobuf:"bytes,2,opt,name=findId,proto3" json:"findId,omitempty"`
+	FindAddress          string                `protobuf:"bytes,3,opt,name=findAddress,proto3" json:"findAddress,omitempty"`
+	FindUsername         string                `protobuf:"bytes,4,opt,name=findUsername,proto3" json:"findUsername,omitempty"`
+	Limit                int32                 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Keys                 []*CafePubSubQueryKey `protobuf:"bytes,6,rep,name=keys,proto3" json:"keys,omitempty"`
+	Ciphertext           []byte                `protobuf:"bytes,7,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
 func (m *CafePubSubContactQuery) Reset()         { *m = CafePubSubContactQuery{} }
 func (m *CafePubSubContactQuery) String() string { return proto.CompactTextString(m) }
 func (*CafePubSubContactQuery) ProtoMessage()    {}
 func (*CafePubSubContactQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_pubsub_05f629cd2b86bfba, []int{0}
+	return fileDescriptor_cafe_pubsub_fdeed9d95ebd0cd9, []int{0}
 }
 func (m *CafePubSubContactQuery) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafePubSubContactQuery.Unmarshal(m, b)
@@ -80,6 +83,73 @@ func (m *CafePubSubContactQuery) GetFindUsername() string {
 	return ""
 }
 
+func (m *CafePubSubContactQuery) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *CafePubSubContactQuery) GetKeys() []*CafePubSubQueryKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *CafePubSubContactQuery) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+type CafePubSubQueryKey struct {
+	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	Key                  []byte   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CafePubSubQueryKey) Reset()         { *m = CafePubSubQueryKey{} }
+func (m *CafePubSubQueryKey) String() string { return proto.CompactTextString(m) }
+func (*CafePubSubQueryKey) ProtoMessage()    {}
+func (*CafePubSubQueryKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cafe_pubsub_fdeed9d95ebd0cd9, []int{1}
+}
+func (m *CafePubSubQueryKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CafePubSubQueryKey.Unmarshal(m, b)
+}
+func (m *CafePubSubQueryKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CafePubSubQueryKey.Marshal(b, m, deterministic)
+}
+func (dst *CafePubSubQueryKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CafePubSubQueryKey.Merge(dst, src)
+}
+func (m *CafePubSubQueryKey) XXX_Size() int {
+	return xxx_messageInfo_CafePubSubQueryKey.Size(m)
+}
+func (m *CafePubSubQueryKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_CafePubSubQueryKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CafePubSubQueryKey proto.InternalMessageInfo
+
+func (m *CafePubSubQueryKey) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *CafePubSubQueryKey) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
 type CafePubSubContactQueryResult struct {
 	Id                   string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Contacts             []*Contact `protobuf:"bytes,2,rep,name=contacts,proto3" json:"contacts,omitempty"`
@@ -92,7 +162,7 @@ func (m *CafePubSubContactQueryResult) Reset()         { *m = CafePubSubContactQ
 func (m *CafePubSubContactQueryResult) String() string { return proto.CompactTextString(m) }
 func (*CafePubSubContactQueryResult) ProtoMessage()    {}
 func (*CafePubSubContactQueryResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cafe_pubsub_05f629cd2b86bfba, []int{1}
+	return fileDescriptor_cafe_pubsub_fdeed9d95ebd0cd9, []int{2}
 }
 func (m *CafePubSubContactQueryResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CafePubSubContactQueryResult.Unmarshal(m, b)
@@ -128,24 +198,30 @@ func (m *CafePubSubContactQueryResult) GetContacts() []*Contact {
 
 func init() {
 	proto.RegisterType((*CafePubSubContactQuery)(nil), "CafePubSubContactQuery")
+	proto.RegisterType((*CafePubSubQueryKey)(nil), "CafePubSubQueryKey")
 	proto.RegisterType((*CafePubSubContactQueryResult)(nil), "CafePubSubContactQueryResult")
 }
 
-func init() { proto.RegisterFile("cafe_pubsub.proto", fileDescriptor_cafe_pubsub_05f629cd2b86bfba) }
-
-var fileDescriptor_cafe_pubsub_05f629cd2b86bfba = []byte{
-	// 196 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4c, 0x4e, 0x4c, 0x4b,
-	0x8d, 0x2f, 0x28, 0x4d, 0x2a, 0x2e, 0x4d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x97, 0xe2, 0xce,
-	0xcd, 0x4f, 0x49, 0xcd, 0x81, 0x70, 0x94, 0xda, 0x18, 0xb9, 0xc4, 0x9c, 0x13, 0xd3, 0x52, 0x03,
-	0x4a, 0x93, 0x82, 0x4b, 0x93, 0x9c, 0xf3, 0xf3, 0x4a, 0x12, 0x93, 0x4b, 0x02, 0x4b, 0x53, 0x8b,
-	0x2a, 0x85, 0xf8, 0xb8, 0x98, 0x32, 0x53, 0x24, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0x98, 0x32,
-	0x53, 0x84, 0xc4, 0xb8, 0xd8, 0xd2, 0x32, 0xf3, 0x52, 0x3c, 0x53, 0x24, 0x98, 0xc0, 0x62, 0x50,
-	0x9e, 0x90, 0x02, 0x17, 0x37, 0x88, 0xe5, 0x98, 0x92, 0x52, 0x94, 0x5a, 0x5c, 0x2c, 0xc1, 0x0c,
-	0x96, 0x44, 0x16, 0x12, 0x52, 0xe2, 0xe2, 0x01, 0x71, 0x43, 0x8b, 0x53, 0x8b, 0xf2, 0x12, 0x73,
-	0x53, 0x25, 0x58, 0xc0, 0x4a, 0x50, 0xc4, 0x94, 0x42, 0xb8, 0x64, 0xb0, 0xbb, 0x23, 0x28, 0xb5,
-	0xb8, 0x34, 0xa7, 0x04, 0xc3, 0x35, 0x2a, 0x5c, 0x1c, 0xc9, 0x10, 0x55, 0xc5, 0x12, 0x4c, 0x0a,
-	0xcc, 0x1a, 0xdc, 0x46, 0x1c, 0x7a, 0x50, 0x6d, 0x41, 0x70, 0x19, 0x27, 0x96, 0x28, 0xa6, 0x82,
-	0xa4, 0x24, 0x36, 0xb0, 0x5f, 0x8d, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xda, 0x52, 0xfe, 0x8e,
-	0x0d, 0x01, 0x00, 0x00,
+func init() { proto.RegisterFile("cafe_pubsub.proto", fileDescriptor_cafe_pubsub_fdeed9d95ebd0cd9) }
+
+var fileDescriptor_cafe_pubsub_fdeed9d95ebd0cd9 = []byte{
+	// 276 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x51, 0xcb, 0x4a, 0xc4, 0x40,
+	0x10, 0x24, 0xd9, 0xec, 0xba, 0x76, 0x82, 0xe8, 0x28, 0xcb, 0x20, 0x22, 0x21, 0x08, 0xe6, 0x94,
+	0x83, 0xde, 0xbc, 0xe9, 0x9e, 0xc4, 0x8b, 0x8e, 0x7a, 0xf1, 0x22, 0x99, 0x4c, 0x07, 0x87, 0xcd,
+	0x8b, 0x79, 0x80, 0xf9, 0x60, 0xff, 0x43, 0x32, 0x09, 0xba, 0xb2, 0xde, 0xaa, 0xaa, 0xab, 0xa1,
+	0xba, 0x1a, 0x8e, 0x8a, 0xbc, 0xc4, 0xf7, 0xce, 0x72, 0x6d, 0x79, 0xd6, 0xa9, 0xd6, 0xb4, 0xa7,
+	0x61, 0xdd, 0x0a, 0xac, 0x46, 0x92, 0x7c, 0x79, 0xb0, 0x5a, 0xe7, 0x25, 0x3e, 0x5a, 0xfe, 0x6c,
+	0xf9, 0xba, 0x6d, 0x4c, 0x5e, 0x98, 0x27, 0x8b, 0xaa, 0x27, 0x07, 0xe0, 0x4b, 0x41, 0xbd, 0xd8,
+	0x4b, 0xf7, 0x99, 0x2f, 0x05, 0x59, 0xc1, 0xa2, 0x94, 0x8d, 0xb8, 0x17, 0xd4, 0x77, 0xda, 0xc4,
+	0x48, 0x0c, 0xe1, 0x80, 0x6e, 0x85, 0x50, 0xa8, 0x35, 0x9d, 0xb9, 0xe1, 0xb6, 0x44, 0x12, 0x88,
+	0x06, 0xfa, 0xaa, 0x51, 0x35, 0x79, 0x8d, 0x34, 0x70, 0x96, 0x3f, 0x1a, 0x39, 0x81, 0x79, 0x25,
+	0x6b, 0x69, 0xe8, 0x3c, 0xf6, 0xd2, 0x39, 0x1b, 0x09, 0xb9, 0x84, 0x60, 0x83, 0xbd, 0xa6, 0x8b,
+	0x78, 0x96, 0x86, 0x57, 0xc7, 0xd9, 0x6f, 0x54, 0x97, 0xf1, 0x01, 0x7b, 0xe6, 0x0c, 0xe4, 0x1c,
+	0xa0, 0x90, 0xdd, 0x07, 0x2a, 0x83, 0x9f, 0x86, 0xee, 0xc5, 0x5e, 0x1a, 0xb1, 0x2d, 0x25, 0xb9,
+	0x01, 0xb2, 0xbb, 0x4b, 0x08, 0x04, 0x1d, 0xa2, 0x9a, 0x8e, 0x74, 0x98, 0x1c, 0xc2, 0x6c, 0x83,
+	0xbd, 0xbb, 0x31, 0x62, 0x03, 0x4c, 0x5e, 0xe0, 0xec, 0xff, 0x8a, 0x18, 0x6a, 0x5b, 0x99, 0x9d,
+	0xa2, 0x2e, 0x60, 0x59, 0x8c, 0x2e, 0x4d, 0x7d, 0x17, 0x7c, 0x99, 0x4d, 0x6b, 0xec, 0x67, 0x72,
+	0x17, 0xbc, 0xf9, 0x1d, 0xe7, 0x0b, 0xf7, 0x86, 0xeb, 0xef, 0x01, 0x00, 0xe3, 0x3f, 0x96, 0xeb,
+	0xa8, 0x01, 0x00, 0x00,
 }
@@ -45,6 +45,8 @@ const (
 	Message_CAFE_PUBLISH_CONTACT_ACK      Message_Type = 67
 	Message_CAFE_CONTACT_QUERY            Message_Type = 68
 	Message_CAFE_CONTACT_QUERY_RES        Message_Type = 69
+	Message_CAFE_OBJECT_CHUNK             Message_Type = 70
+	Message_CAFE_OBJECT_CHUNK_ACK         Message_Type = 71
 	Message_CAFE_PUBSUB_CONTACT_QUERY     Message_Type = 100
 	Message_CAFE_PUBSUB_CONTACT_QUERY_RES Message_Type = 101
 	Message_ERROR                         Message_Type = 500
@@ -74,6 +76,8 @@ var Message_Type_name = map[int32]string{
 	67:  "CAFE_PUBLISH_CONTACT_ACK",
 	68:  "CAFE_CONTACT_QUERY",
 	69:  "CAFE_CONTACT_QUERY_RES",
+	70:  "CAFE_OBJECT_CHUNK",
+	71:  "CAFE_OBJECT_CHUNK_ACK",
 	100: "CAFE_PUBSUB_CONTACT_QUERY",
 	101: "CAFE_PUBSUB_CONTACT_QUERY_RES",
 	500: "ERROR",
@@ -102,6 +106,8 @@ var Message_Type_value = map[string]int32{
 	"CAFE_PUBLISH_CONTACT_ACK":      67,
 	"CAFE_CONTACT_QUERY":            68,
 	"CAFE_CONTACT_QUERY_RES":        69,
+	"CAFE_OBJECT_CHUNK":             70,
+	"CAFE_OBJECT_CHUNK_ACK":         71,
 	"CAFE_PUBSUB_CONTACT_QUERY":     100,
 	"CAFE_PUBSUB_CONTACT_QUERY_RES": 101,
 	"ERROR":                         500,
@@ -111,7 +117,7 @@ func (x Message_Type) String() string {
 	return proto.EnumName(Message_Type_name, int32(x))
 }
 func (Message_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_message_9f9a0b0ce024a622, []int{0, 0}
+	return fileDescriptor_message_7cd7ee7170c881c2, []int{0, 0}
 }
 
 type Message struct {
@@ -119,6 +125,7 @@ type Message struct {
 	Payload              *any.Any     `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
 	RequestId            int32        `protobuf:"varint,3,opt,name=requestId,proto3" json:"requestId,omitempty"`
 	IsResponse           bool         `protobuf:"varint,4,opt,name=isResponse,proto3" json:"isResponse,omitempty"`
+	TraceId              string       `protobuf:"bytes,5,opt,name=traceId,proto3" json:"traceId,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -128,7 +135,7 @@ func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_message_9f9a0b0ce024a622, []int{0}
+	return fileDescriptor_message_7cd7ee7170c881c2, []int{0}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Message.Unmarshal(m, b)
@@ -176,9 +183,17 @@ func (m *Message) GetIsResponse() bool {
 	return false
 }
 
+func (m *Message) GetTraceId() string {
+	if m != nil {
+		return m.TraceId
+	}
+	return ""
+}
+
 type Envelope struct {
 	Message              *Message `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	Sig                  []byte   `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	Request              bool     `protobuf:"varint,3,opt,name=request,proto3" json:"request,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -188,7 +203,7 @@ func (m *Envelope) Reset()         { *m = Envelope{} }
 func (m *Envelope) String() string { return proto.CompactTextString(m) }
 func (*Envelope) ProtoMessage()    {}
 func (*Envelope) Descriptor() ([]byte, []int) {
-	return fileDescriptor_message_9f9a0b0ce024a622, []int{1}
+	return fileDescriptor_message_7cd7ee7170c881c2, []int{1}
 }
 func (m *Envelope) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Envelope.Unmarshal(m, b)
@@ -222,6 +237,59 @@ func (m *Envelope) GetSig() []byte {
 	return nil
 }
 
+func (m *Envelope) GetRequest() bool {
+	if m != nil {
+		return m.Request
+	}
+	return false
+}
+
+type Capabilities struct {
+	Protocol             string   `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Flags                []string `protobuf:"bytes,2,rep,name=flags,proto3" json:"flags,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Capabilities) Reset()         { *m = Capabilities{} }
+func (m *Capabilities) String() string { return proto.CompactTextString(m) }
+func (*Capabilities) ProtoMessage()    {}
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return fileDescriptor_message_7cd7ee7170c881c2, []int{2}
+}
+func (m *Capabilities) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Capabilities.Unmarshal(m, b)
+}
+func (m *Capabilities) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Capabilities.Marshal(b, m, deterministic)
+}
+func (dst *Capabilities) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Capabilities.Merge(dst, src)
+}
+func (m *Capabilities) XXX_Size() int {
+	return xxx_messageInfo_Capabilities.Size(m)
+}
+func (m *Capabilities) XXX_DiscardUnknown() {
+	xxx_messageInfo_Capabilities.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Capabilities proto.InternalMessageInfo
+
+func (m *Capabilities) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *Capabilities) GetFlags() []string {
+	if m != nil {
+		return m.Flags
+	}
+	return nil
+}
+
 type Error struct {
 	Code                 uint32   `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -234,7 +302,7 @@ func (m *Error) Reset()         { *m = Error{} }
 func (m *Error) String() string { return proto.CompactTextString(m) }
 func (*Error) ProtoMessage()    {}
 func (*Error) Descriptor() ([]byte, []int) {
-	return fileDescriptor_message_9f9a0b0ce024a622, []int{2}
+	return fileDescriptor_message_7cd7ee7170c881c2, []int{3}
 }
 func (m *Error) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Error.Unmarshal(m, b)
@@ -271,46 +339,52 @@ func (m *Error) GetMessage() string {
 func init() {
 	proto.RegisterType((*Message)(nil), "Message")
 	proto.RegisterType((*Envelope)(nil), "Envelope")
+	proto.RegisterType((*Capabilities)(nil), "Capabilities")
 	proto.RegisterType((*Error)(nil), "Error")
 	proto.RegisterEnum("Message_Type", Message_Type_name, Message_Type_value)
 }
 
-func init() { proto.RegisterFile("message.proto", fileDescriptor_message_9f9a0b0ce024a622) }
-
-var fileDescriptor_message_9f9a0b0ce024a622 = []byte{
-	// 541 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x93, 0xdd, 0x6e, 0xda, 0x4c,
-	0x10, 0x86, 0x3f, 0x27, 0xe6, 0x0b, 0x99, 0x84, 0x64, 0x33, 0xa1, 0xa9, 0x13, 0x25, 0x15, 0xe1,
-	0x88, 0x23, 0x47, 0x22, 0x4d, 0xff, 0x7f, 0x62, 0xcc, 0x04, 0xdc, 0x18, 0x9b, 0xee, 0x1a, 0xa4,
-	0xf4, 0xc4, 0x82, 0xb2, 0x45, 0x91, 0x28, 0x76, 0x31, 0xa9, 0xc4, 0x05, 0xf6, 0x46, 0x7a, 0x0d,
-	0xbd, 0x80, 0x8a, 0x05, 0x3b, 0x28, 0x6d, 0xcf, 0x76, 0x9f, 0x77, 0xe6, 0x19, 0x5b, 0x9a, 0x85,
-	0xc2, 0x57, 0x99, 0x24, 0xbd, 0xa1, 0x34, 0xe3, 0x49, 0x34, 0x8d, 0x8e, 0x0e, 0x87, 0x51, 0x34,
-	0x1c, 0xc9, 0x33, 0x75, 0xeb, 0xdf, 0x7d, 0x39, 0xeb, 0x8d, 0x67, 0x8b, 0xa8, 0xfc, 0x33, 0x07,
-	0x1b, 0xad, 0x45, 0x31, 0x9e, 0x82, 0x3e, 0x9d, 0xc5, 0xd2, 0xd0, 0x4a, 0x5a, 0x65, 0xa7, 0x5a,
-	0x30, 0x97, 0xdc, 0x0c, 0x66, 0xb1, 0xe4, 0x2a, 0x42, 0x13, 0x36, 0xe2, 0xde, 0x6c, 0x14, 0xf5,
-	0x06, 0xc6, 0x5a, 0x49, 0xab, 0x6c, 0x55, 0x8b, 0xe6, 0xc2, 0x6d, 0xa6, 0x6e, 0xd3, 0x1a, 0xcf,
-	0x78, 0x5a, 0x84, 0xc7, 0xb0, 0x39, 0x91, 0xdf, 0xee, 0x64, 0x32, 0x75, 0x06, 0xc6, 0x7a, 0x49,
-	0xab, 0xe4, 0xf8, 0x3d, 0xc0, 0x27, 0x00, 0xb7, 0x09, 0x97, 0x49, 0x1c, 0x8d, 0x13, 0x69, 0xe8,
-	0x25, 0xad, 0x92, 0xe7, 0x2b, 0xa4, 0xfc, 0x43, 0x07, 0x7d, 0x3e, 0x1c, 0xf3, 0xa0, 0xb7, 0x1d,
-	0xaf, 0xc1, 0xfe, 0x53, 0x27, 0xdf, 0x6b, 0x30, 0x0d, 0xf7, 0x61, 0x37, 0x68, 0x72, 0xb2, 0xea,
-	0x21, 0x79, 0x5d, 0x72, 0xfd, 0x36, 0x31, 0x40, 0x84, 0x1d, 0xdb, 0xba, 0xa2, 0xd0, 0x6e, 0x5a,
-	0xae, 0x4b, 0x5e, 0x83, 0x58, 0x15, 0x77, 0x00, 0x14, 0xf3, 0x7c, 0xcf, 0x26, 0x76, 0x8e, 0x8f,
-	0x60, 0x4f, 0xdd, 0x39, 0x35, 0x1c, 0x11, 0x70, 0x2b, 0x70, 0x7c, 0x8f, 0x3d, 0x45, 0x06, 0xdb,
-	0x0a, 0x0b, 0x12, 0x62, 0x4e, 0x2e, 0xd0, 0x80, 0xe2, 0xb2, 0xf0, 0x8a, 0x93, 0x68, 0x66, 0xc9,
-	0xb3, 0x4c, 0x29, 0x02, 0x9f, 0x13, 0x7b, 0x8e, 0xbb, 0xb0, 0xa5, 0xee, 0x7e, 0xed, 0x03, 0xd9,
-	0x01, 0x7b, 0x81, 0x45, 0x60, 0x2b, 0x20, 0x74, 0x1d, 0x11, 0xb0, 0x97, 0xd9, 0x64, 0xd5, 0x16,
-	0x2e, 0xbe, 0x9e, 0xbd, 0xca, 0xba, 0x15, 0xae, 0xb3, 0xd7, 0xd9, 0xe0, 0x3a, 0xb9, 0x4e, 0x97,
-	0x78, 0xd8, 0x22, 0x21, 0xac, 0x06, 0xb1, 0x37, 0xf8, 0x18, 0xf6, 0x97, 0xff, 0x47, 0xf6, 0x75,
-	0xca, 0x05, 0x7b, 0x8b, 0x7b, 0x50, 0x50, 0x41, 0x86, 0xde, 0xad, 0x5a, 0x28, 0x58, 0x49, 0xde,
-	0xe3, 0x31, 0x18, 0x7f, 0x4b, 0x42, 0xcb, 0xbe, 0x66, 0x97, 0x78, 0x00, 0xa8, 0xd2, 0x1b, 0xbf,
-	0x13, 0x36, 0xad, 0x2e, 0x85, 0x2d, 0xcb, 0x71, 0x99, 0x95, 0xf9, 0xda, 0x9d, 0x9a, 0xeb, 0x88,
-	0x66, 0x68, 0xfb, 0x5e, 0x60, 0xd9, 0x01, 0xab, 0x65, 0xbe, 0x07, 0x89, 0xf2, 0xd9, 0x99, 0x2f,
-	0xa5, 0x1f, 0x3b, 0xc4, 0x6f, 0x58, 0x1d, 0x8f, 0xe0, 0xe0, 0x4f, 0x1e, 0x72, 0x12, 0x8c, 0xf0,
-	0x04, 0x0e, 0x53, 0xa3, 0xe8, 0xd4, 0x1e, 0xb4, 0x0e, 0xf0, 0x14, 0x4e, 0xfe, 0x19, 0x2b, 0x83,
-	0x44, 0x80, 0x1c, 0x71, 0xee, 0x73, 0xf6, 0x6b, 0xbd, 0x7c, 0x09, 0x79, 0x1a, 0x7f, 0x97, 0xa3,
-	0x28, 0x96, 0x58, 0x86, 0x8d, 0xe5, 0xe3, 0x50, 0x7b, 0xbe, 0x55, 0xcd, 0xa7, 0x7b, 0xce, 0xd3,
-	0x00, 0x19, 0xac, 0x27, 0xb7, 0x43, 0xb5, 0xe1, 0xdb, 0x7c, 0x7e, 0x2c, 0x5f, 0x40, 0x8e, 0x26,
-	0x93, 0x68, 0x82, 0x08, 0xfa, 0xe7, 0x68, 0xb0, 0xe8, 0x2d, 0x70, 0x75, 0x46, 0xe3, 0x5e, 0x39,
-	0x6f, 0xd9, 0xcc, 0x44, 0x35, 0xfd, 0xd3, 0x5a, 0xdc, 0xef, 0xff, 0xaf, 0xde, 0xc6, 0xf9, 0xef,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xb4, 0xb5, 0x6c, 0x95, 0x96, 0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("message.proto", fileDescriptor_message_7cd7ee7170c881c2) }
+
+var fileDescriptor_message_7cd7ee7170c881c2 = []byte{
+	// 619 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x53, 0xdf, 0x4f, 0xda, 0x50,
+	0x18, 0x5d, 0xa1, 0x08, 0x7c, 0x8a, 0x5e, 0x3f, 0xd1, 0x55, 0xa3, 0x0b, 0xf2, 0xc4, 0x53, 0x4d,
+	0x70, 0xee, 0xf7, 0x0f, 0x4b, 0xb9, 0x42, 0x67, 0x6d, 0xdd, 0x6d, 0x31, 0xd1, 0x97, 0xa6, 0xc0,
+	0x95, 0x90, 0x30, 0xda, 0x51, 0x5c, 0xc2, 0x3f, 0xb5, 0xbf, 0x6e, 0xc9, 0x5e, 0x17, 0x6e, 0x7f,
+	0x8c, 0xe8, 0xf6, 0x76, 0xcf, 0x39, 0xdf, 0x3d, 0xe7, 0xeb, 0x4d, 0x0f, 0x54, 0xbe, 0xf1, 0x28,
+	0xf2, 0x47, 0x5c, 0x0d, 0x67, 0xc1, 0x3c, 0x38, 0xd8, 0x1f, 0x05, 0xc1, 0x68, 0xc2, 0x4f, 0x04,
+	0xea, 0x3f, 0xdc, 0x9f, 0xf8, 0xd3, 0x45, 0x2c, 0xd5, 0x7f, 0xae, 0x41, 0xf1, 0x2a, 0x1e, 0xc6,
+	0x63, 0x90, 0xe7, 0x8b, 0x90, 0x2b, 0x52, 0x4d, 0x6a, 0x6c, 0x36, 0x2b, 0x6a, 0xc2, 0xab, 0xee,
+	0x22, 0xe4, 0x4c, 0x48, 0xa8, 0x42, 0x31, 0xf4, 0x17, 0x93, 0xc0, 0x1f, 0x2a, 0xb9, 0x9a, 0xd4,
+	0x58, 0x6f, 0x56, 0xd5, 0xd8, 0x5b, 0x4d, 0xbd, 0x55, 0x6d, 0xba, 0x60, 0xe9, 0x10, 0x1e, 0x42,
+	0x79, 0xc6, 0xbf, 0x3f, 0xf0, 0x68, 0x6e, 0x0c, 0x95, 0x7c, 0x4d, 0x6a, 0x14, 0xd8, 0x5f, 0x02,
+	0x5f, 0x00, 0x8c, 0x23, 0xc6, 0xa3, 0x30, 0x98, 0x46, 0x5c, 0x91, 0x6b, 0x52, 0xa3, 0xc4, 0x56,
+	0x18, 0x54, 0xa0, 0x38, 0x9f, 0xf9, 0x03, 0x6e, 0x0c, 0x95, 0x42, 0x4d, 0x6a, 0x94, 0x59, 0x0a,
+	0xeb, 0xbf, 0x65, 0x90, 0x97, 0x6b, 0x61, 0x09, 0xe4, 0x6b, 0xc3, 0xea, 0x90, 0x67, 0xe2, 0x64,
+	0x5b, 0x1d, 0x22, 0xe1, 0x0e, 0x6c, 0xb9, 0x5d, 0x46, 0xb5, 0xb6, 0x47, 0xad, 0x1b, 0x6a, 0xda,
+	0xd7, 0x94, 0x00, 0x22, 0x6c, 0xea, 0xda, 0x05, 0xf5, 0xf4, 0xae, 0x66, 0x9a, 0xd4, 0xea, 0x50,
+	0xd2, 0xc4, 0x4d, 0x00, 0xc1, 0x59, 0xb6, 0xa5, 0x53, 0x72, 0x8a, 0xbb, 0xb0, 0x2d, 0x30, 0xa3,
+	0x1d, 0xc3, 0x71, 0x99, 0xe6, 0x1a, 0xb6, 0x45, 0x5e, 0x22, 0x81, 0x0d, 0x41, 0x3b, 0xd4, 0x71,
+	0x96, 0xcc, 0x19, 0x2a, 0x50, 0x4d, 0x06, 0x2f, 0x18, 0x75, 0xba, 0x99, 0xf2, 0x2a, 0xb3, 0x74,
+	0x5c, 0x9b, 0x51, 0xf2, 0x1a, 0xb7, 0x60, 0x5d, 0x60, 0xbb, 0xf5, 0x85, 0xea, 0x2e, 0x79, 0x83,
+	0x55, 0x20, 0x2b, 0x84, 0x67, 0x1a, 0x8e, 0x4b, 0xde, 0x66, 0xc9, 0xe2, 0x9a, 0x17, 0x6f, 0x4f,
+	0xde, 0x65, 0xb7, 0x05, 0xdd, 0x26, 0xef, 0xb3, 0xe0, 0x36, 0x35, 0x8d, 0x1b, 0xca, 0xbc, 0x2b,
+	0xea, 0x38, 0x5a, 0x87, 0x92, 0x0f, 0xf8, 0x1c, 0x76, 0x92, 0xef, 0xa3, 0xfa, 0x65, 0xca, 0x3b,
+	0xe4, 0x23, 0x6e, 0x43, 0x45, 0x08, 0x19, 0xf5, 0x69, 0xd5, 0x85, 0xba, 0x2b, 0xca, 0x67, 0x3c,
+	0x04, 0xe5, 0x5f, 0x8a, 0xa7, 0xe9, 0x97, 0xe4, 0x1c, 0xf7, 0x00, 0x85, 0x7a, 0x6b, 0xf7, 0xbc,
+	0xae, 0x76, 0x43, 0xbd, 0x2b, 0xcd, 0x30, 0x89, 0x96, 0xf9, 0x5d, 0xf7, 0x5a, 0xa6, 0xe1, 0x74,
+	0x3d, 0xdd, 0xb6, 0x5c, 0x4d, 0x77, 0x49, 0x2b, 0xf3, 0x7b, 0xa4, 0x08, 0x3f, 0x3d, 0xf3, 0x4b,
+	0xd9, 0xaf, 0x3d, 0xca, 0x6e, 0x49, 0x1b, 0x0f, 0x60, 0xef, 0x29, 0xef, 0x31, 0xea, 0x10, 0x9a,
+	0xbd, 0x54, 0xf2, 0x7e, 0x7a, 0xb7, 0x67, 0x5d, 0x92, 0x0b, 0xdc, 0x87, 0xdd, 0x27, 0xb4, 0x48,
+	0xe9, 0xe0, 0x11, 0xec, 0xa7, 0x3b, 0x38, 0xbd, 0xd6, 0xa3, 0xb0, 0x21, 0x1e, 0xc3, 0xd1, 0x7f,
+	0x65, 0x91, 0xc9, 0x11, 0xa0, 0x40, 0x19, 0xb3, 0x19, 0xf9, 0x95, 0xaf, 0xdf, 0x41, 0x89, 0x4e,
+	0x7f, 0xf0, 0x49, 0x10, 0x72, 0xac, 0x43, 0x31, 0x29, 0x9a, 0xe8, 0xcc, 0x7a, 0xb3, 0x94, 0x76,
+	0x86, 0xa5, 0x02, 0x12, 0xc8, 0x47, 0xe3, 0x91, 0x68, 0xcb, 0x06, 0x5b, 0x1e, 0x97, 0x7f, 0x75,
+	0x52, 0x01, 0xd1, 0x88, 0x12, 0x4b, 0x61, 0xfd, 0x1c, 0x36, 0x74, 0x3f, 0xf4, 0xfb, 0xe3, 0xc9,
+	0x78, 0x3e, 0xe6, 0x11, 0x1e, 0x40, 0x49, 0xd4, 0x6a, 0x10, 0x4c, 0x44, 0x40, 0x99, 0x65, 0x18,
+	0xab, 0x50, 0xb8, 0x9f, 0xf8, 0xa3, 0x48, 0xc9, 0xd5, 0xf2, 0x8d, 0x32, 0x8b, 0x41, 0xfd, 0x0c,
+	0x0a, 0x74, 0x36, 0x0b, 0x66, 0x88, 0x20, 0x0f, 0x82, 0x61, 0xbc, 0x57, 0x85, 0x89, 0xf3, 0x32,
+	0x38, 0x5d, 0x37, 0x17, 0xd7, 0x29, 0x81, 0x2d, 0xf9, 0x2e, 0x17, 0xf6, 0xfb, 0x6b, 0xc2, 0xfc,
+	0xf4, 0xcf, 0x00, 0x6f, 0xa5, 0xf9, 0x64, 0x3e, 0x04, 0x00, 0x00,
 }
@@ -28,6 +28,8 @@ type Contact struct {
 	Inboxes              []*Cafe              `protobuf:"bytes,5,rep,name=inboxes,proto3" json:"inboxes,omitempty"`
 	Created              *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created,proto3" json:"created,omitempty"`
 	Updated              *timestamp.Timestamp `protobuf:"bytes,7,opt,name=updated,proto3" json:"updated,omitempty"`
+	Endpoint             string               `protobuf:"bytes,8,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Signature            []byte               `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -37,7 +39,7 @@ func (m *Contact) Reset()         { *m = Contact{} }
 func (m *Contact) String() string { return proto.CompactTextString(m) }
 func (*Contact) ProtoMessage()    {}
 func (*Contact) Descriptor() ([]byte, []int) {
-	return fileDescriptor_model_e116bf9ee909aa62, []int{0}
+	return fileDescriptor_model_a95ef89976930706, []int{0}
 }
 func (m *Contact) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Contact.Unmarshal(m, b)
@@ -106,6 +108,20 @@ func (m *Contact) GetUpdated() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Contact) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *Contact) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type File struct {
 	Mill                 string               `protobuf:"bytes,1,opt,name=mill,proto3" json:"mill,omitempty"`
 	Checksum             string               `protobuf:"bytes,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
@@ -127,7 +143,7 @@ func (m *File) Reset()         { *m = File{} }
 func (m *File) String() string { return proto.CompactTextString(m) }
 func (*File) ProtoMessage()    {}
 func (*File) Descriptor() ([]byte, []int) {
-	return fileDescriptor_model_e116bf9ee909aa62, []int{1}
+	return fileDescriptor_model_a95ef89976930706, []int{1}
 }
 func (m *File) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_File.Unmarshal(m, b)
@@ -241,7 +257,7 @@ func (m *Cafe) Reset()         { *m = Cafe{} }
 func (m *Cafe) String() string { return proto.CompactTextString(m) }
 func (*Cafe) ProtoMessage()    {}
 func (*Cafe) Descriptor() ([]byte, []int) {
-	return fileDescriptor_model_e116bf9ee909aa62, []int{2}
+	return fileDescriptor_model_a95ef89976930706, []int{2}
 }
 func (m *Cafe) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Cafe.Unmarshal(m, b)
@@ -316,35 +332,36 @@ func init() {
 	proto.RegisterType((*Cafe)(nil), "Cafe")
 }
 
-func init() { proto.RegisterFile("model.proto", fileDescriptor_model_e116bf9ee909aa62) }
-
-var fileDescriptor_model_e116bf9ee909aa62 = []byte{
-	// 419 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0xcb, 0x8a, 0xdc, 0x30,
-	0x10, 0xc4, 0xaf, 0xf1, 0x4e, 0x0f, 0x84, 0x45, 0x84, 0x44, 0x0c, 0x81, 0x35, 0x73, 0x1a, 0x08,
-	0x78, 0xc3, 0x26, 0x5f, 0x90, 0x85, 0x7c, 0x80, 0x93, 0x53, 0x6e, 0x1a, 0xab, 0x77, 0xc7, 0xac,
-	0x35, 0x32, 0x92, 0x9c, 0xd7, 0xc7, 0xe4, 0x96, 0x3f, 0xcc, 0x07, 0x84, 0x6e, 0x4b, 0x73, 0x48,
-	0x20, 0x73, 0xab, 0x6a, 0x55, 0xc9, 0x5d, 0x65, 0xc1, 0xc6, 0x58, 0x8d, 0x63, 0x3b, 0x39, 0x1b,
-	0xec, 0xf6, 0xe6, 0xd1, 0xda, 0xc7, 0x11, 0x6f, 0x99, 0x1d, 0xe6, 0x87, 0xdb, 0x30, 0x18, 0xf4,
-	0x41, 0x99, 0x29, 0x0a, 0x5e, 0xfd, 0x2d, 0xf0, 0xc1, 0xcd, 0x7d, 0x58, 0x4e, 0x77, 0xbf, 0x33,
-	0xa8, 0xef, 0xed, 0x29, 0xa8, 0x3e, 0x88, 0x67, 0x90, 0x0f, 0x5a, 0x66, 0x4d, 0xb6, 0x5f, 0x77,
-	0xf9, 0xa0, 0x85, 0x84, 0x5a, 0x69, 0xed, 0xd0, 0x7b, 0x99, 0xf3, 0x30, 0x51, 0xb1, 0x85, 0xab,
-	0xd9, 0xa3, 0x3b, 0x29, 0x83, 0xb2, 0xe0, 0xa3, 0x33, 0x17, 0x2f, 0x60, 0xa5, 0xbe, 0xa8, 0xa0,
-	0x9c, 0x2c, 0xf9, 0x24, 0x32, 0x71, 0x03, 0xf5, 0x70, 0x3a, 0xd8, 0x6f, 0xe8, 0x65, 0xd5, 0x14,
-	0xfb, 0xcd, 0x5d, 0xd5, 0xde, 0xab, 0x07, 0xec, 0xd2, 0x54, 0xbc, 0x83, 0xba, 0x77, 0xa8, 0x02,
-	0x6a, 0xb9, 0x6a, 0xb2, 0xfd, 0xe6, 0x6e, 0xdb, 0x2e, 0xab, 0xb7, 0x69, 0xf5, 0xf6, 0x53, 0xca,
-	0xd6, 0x25, 0x29, 0xb9, 0xe6, 0x49, 0xb3, 0xab, 0xbe, 0xec, 0x8a, 0xd2, 0xdd, 0xaf, 0x1c, 0xca,
-	0x0f, 0xc3, 0x88, 0x42, 0x40, 0x69, 0x86, 0x71, 0x8c, 0xa9, 0x19, 0x53, 0xba, 0xfe, 0x88, 0xfd,
-	0x93, 0x9f, 0x4d, 0x0c, 0x7e, 0xe6, 0x94, 0xce, 0xdb, 0xd9, 0xf5, 0x29, 0x77, 0x64, 0x74, 0x8f,
-	0x9d, 0x82, 0x8f, 0x99, 0x19, 0xd3, 0xec, 0xa8, 0xfc, 0x51, 0x56, 0xcb, 0x8c, 0xb0, 0xb8, 0x86,
-	0xe2, 0x09, 0xbf, 0x73, 0xc0, 0x75, 0x47, 0x50, 0x3c, 0x87, 0xca, 0xa0, 0x1e, 0x14, 0xaf, 0xbf,
-	0xee, 0x16, 0x42, 0x5e, 0x6e, 0xf7, 0x6a, 0xf1, 0x72, 0xb3, 0x02, 0x4a, 0x3f, 0xfc, 0x40, 0xb9,
-	0x6e, 0xb2, 0x7d, 0xd1, 0x31, 0x16, 0x6f, 0xa0, 0x52, 0x5a, 0xa3, 0x96, 0x70, 0x31, 0xfc, 0x22,
-	0x14, 0xaf, 0xa1, 0x34, 0x18, 0x94, 0xdc, 0xb0, 0xe1, 0xe5, 0x3f, 0x86, 0x8f, 0xfc, 0x3c, 0x3a,
-	0x16, 0xed, 0x7e, 0x66, 0x50, 0xd2, 0x5f, 0xa2, 0x6f, 0x4f, 0x88, 0x2e, 0xf5, 0x44, 0xf8, 0x3f,
-	0xef, 0xe3, 0x1a, 0x0a, 0x35, 0x0d, 0xb1, 0x22, 0x82, 0xd4, 0x29, 0x7f, 0xa1, 0xb7, 0x63, 0xec,
-	0xe8, 0xcc, 0x39, 0xab, 0xd5, 0x98, 0x7a, 0x22, 0x4c, 0x37, 0xcc, 0x6e, 0x4c, 0x3d, 0xcd, 0x6e,
-	0xa4, 0x9e, 0xfc, 0x57, 0xe5, 0x8c, 0xac, 0x9b, 0x82, 0x7a, 0x62, 0xf2, 0xbe, 0xfc, 0x9c, 0x4f,
-	0x87, 0xc3, 0x8a, 0xef, 0x7a, 0xfb, 0x27, 0x00, 0x00, 0xff, 0xff, 0x65, 0xc5, 0x98, 0x7b, 0x1a,
-	0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("model.proto", fileDescriptor_model_a95ef89976930706) }
+
+var fileDescriptor_model_a95ef89976930706 = []byte{
+	// 440 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x85, 0x93, 0xc1, 0x4e, 0xdc, 0x30,
+	0x10, 0x86, 0xb5, 0x49, 0x76, 0x43, 0xbc, 0xa8, 0x42, 0x16, 0xa2, 0xd6, 0x0a, 0x09, 0xb4, 0x27,
+	0x24, 0xa4, 0x50, 0x41, 0x9f, 0x00, 0x24, 0x1e, 0x20, 0x70, 0xea, 0xcd, 0x9b, 0x0c, 0xbb, 0x16,
+	0x49, 0x1c, 0xd9, 0x4e, 0x69, 0x79, 0x98, 0xde, 0x7a, 0xed, 0x33, 0xe2, 0x99, 0xd8, 0x5b, 0xa9,
+	0x48, 0xe5, 0xf6, 0xff, 0xe3, 0x19, 0xdb, 0xff, 0xe7, 0x84, 0x2d, 0x3b, 0xdd, 0x40, 0x5b, 0x0e,
+	0x46, 0x3b, 0xbd, 0x3a, 0xdb, 0x6a, 0xbd, 0x6d, 0xe1, 0x8a, 0xdc, 0x66, 0x7c, 0xba, 0x72, 0xaa,
+	0x03, 0xeb, 0x64, 0x37, 0x84, 0x86, 0xd3, 0x7f, 0x1b, 0xac, 0x33, 0x63, 0xed, 0xa6, 0xd5, 0xf5,
+	0x9f, 0x84, 0xe5, 0x77, 0xba, 0x77, 0xb2, 0x76, 0xfc, 0x13, 0x4b, 0x54, 0x23, 0x66, 0xe7, 0xb3,
+	0x8b, 0xa2, 0xf2, 0x8a, 0x0b, 0x96, 0xcb, 0xa6, 0x31, 0x60, 0xad, 0x48, 0xa8, 0x18, 0x2d, 0x5f,
+	0xb1, 0x83, 0xd1, 0x82, 0xe9, 0x65, 0x07, 0x22, 0xa5, 0xa5, 0xbd, 0xe7, 0x27, 0x6c, 0x21, 0xbf,
+	0x4b, 0x27, 0x8d, 0xc8, 0x68, 0x25, 0x38, 0x7e, 0xc6, 0x72, 0xd5, 0x6f, 0xf4, 0x0f, 0xb0, 0x62,
+	0x7e, 0x9e, 0x5e, 0x2c, 0xaf, 0xe7, 0xe5, 0x9d, 0x7c, 0x82, 0x2a, 0x56, 0xf9, 0x57, 0x96, 0xd7,
+	0x06, 0xa4, 0x83, 0x46, 0x2c, 0xfc, 0xe4, 0xf2, 0x7a, 0x55, 0x4e, 0x57, 0x2f, 0xe3, 0xd5, 0xcb,
+	0xc7, 0x98, 0xad, 0x8a, 0xad, 0x38, 0x35, 0x0e, 0x0d, 0x4d, 0xe5, 0x1f, 0x4f, 0x85, 0x56, 0x0c,
+	0x00, 0x7d, 0x33, 0x68, 0xd5, 0x3b, 0x71, 0x30, 0x05, 0x88, 0x9e, 0x9f, 0xb2, 0xc2, 0xaa, 0x6d,
+	0x2f, 0xdd, 0x68, 0x40, 0x14, 0x7e, 0xf1, 0xb0, 0xfa, 0x5b, 0x58, 0xff, 0x4e, 0x58, 0x76, 0xaf,
+	0x5a, 0xe0, 0x9c, 0x65, 0x9d, 0x6a, 0xdb, 0xc0, 0x8b, 0x34, 0x6e, 0x5b, 0xef, 0xa0, 0x7e, 0xb6,
+	0x63, 0x17, 0x90, 0xed, 0x3d, 0x72, 0xb1, 0x7a, 0x34, 0x75, 0x24, 0x16, 0x1c, 0xee, 0xa3, 0x07,
+	0x67, 0x03, 0x2d, 0xd2, 0x58, 0xdb, 0x49, 0xbb, 0xf3, 0xa0, 0xa8, 0x86, 0x9a, 0x1f, 0xb1, 0xf4,
+	0x19, 0x7e, 0x12, 0x9a, 0xa2, 0x42, 0xc9, 0x8f, 0xd9, 0xbc, 0x83, 0x46, 0x49, 0x0a, 0x5e, 0x54,
+	0x93, 0xc1, 0x59, 0x7a, 0x97, 0x29, 0x16, 0x69, 0xac, 0x59, 0xf5, 0x3a, 0xa5, 0x49, 0x2b, 0xd2,
+	0xfc, 0x0b, 0x9b, 0xfb, 0xe7, 0xf4, 0xd8, 0xd8, 0x87, 0xd8, 0xa6, 0x46, 0x7e, 0xe9, 0x13, 0x83,
+	0x93, 0x62, 0x49, 0x03, 0x9f, 0xdf, 0x0d, 0x3c, 0xd0, 0x87, 0x55, 0x51, 0xd3, 0xfa, 0xd7, 0x8c,
+	0x65, 0xf8, 0xbe, 0x78, 0xf6, 0x00, 0x60, 0x22, 0x27, 0xd4, 0xff, 0xf9, 0xb2, 0x7c, 0x4a, 0x39,
+	0xa8, 0x80, 0x08, 0x25, 0x32, 0xa5, 0x13, 0x6a, 0xdd, 0x06, 0x46, 0x7b, 0x4f, 0x59, 0xfd, 0xbf,
+	0x10, 0x39, 0xa1, 0xc6, 0x1d, 0x46, 0xd3, 0x46, 0x4e, 0x5e, 0x22, 0x27, 0xfb, 0x22, 0x4d, 0xe7,
+	0x39, 0xa5, 0xc8, 0x89, 0xcc, 0x6d, 0xf6, 0x2d, 0x19, 0x36, 0x9b, 0x05, 0xed, 0x75, 0xf3, 0x06,
+	0x23, 0xd6, 0x8d, 0xb0, 0x54, 0x03, 0x00, 0x00,
 }
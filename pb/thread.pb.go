@@ -33,6 +33,8 @@ const (
 	ThreadBlock_FILES    ThreadBlock_Type = 7
 	ThreadBlock_COMMENT  ThreadBlock_Type = 8
 	ThreadBlock_LIKE     ThreadBlock_Type = 9
+	ThreadBlock_DEVICE   ThreadBlock_Type = 10
+	ThreadBlock_SNAPSHOT ThreadBlock_Type = 11
 	ThreadBlock_INVITE   ThreadBlock_Type = 50
 )
 
@@ -47,6 +49,8 @@ var ThreadBlock_Type_name = map[int32]string{
 	7:  "FILES",
 	8:  "COMMENT",
 	9:  "LIKE",
+	10: "DEVICE",
+	11: "SNAPSHOT",
 	50: "INVITE",
 }
 var ThreadBlock_Type_value = map[string]int32{
@@ -60,6 +64,8 @@ var ThreadBlock_Type_value = map[string]int32{
 	"FILES":    7,
 	"COMMENT":  8,
 	"LIKE":     9,
+	"DEVICE":   10,
+	"SNAPSHOT": 11,
 	"INVITE":   50,
 }
 
@@ -67,7 +73,7 @@ func (x ThreadBlock_Type) String() string {
 	return proto.EnumName(ThreadBlock_Type_name, int32(x))
 }
 func (ThreadBlock_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{1, 0}
+	return fileDescriptor_thread_94a3569b430f979d, []int{1, 0}
 }
 
 // for wire transport
@@ -84,7 +90,7 @@ func (m *ThreadEnvelope) Reset()         { *m = ThreadEnvelope{} }
 func (m *ThreadEnvelope) String() string { return proto.CompactTextString(m) }
 func (*ThreadEnvelope) ProtoMessage()    {}
 func (*ThreadEnvelope) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{0}
+	return fileDescriptor_thread_94a3569b430f979d, []int{0}
 }
 func (m *ThreadEnvelope) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadEnvelope.Unmarshal(m, b)
@@ -138,7 +144,7 @@ func (m *ThreadBlock) Reset()         { *m = ThreadBlock{} }
 func (m *ThreadBlock) String() string { return proto.CompactTextString(m) }
 func (*ThreadBlock) ProtoMessage()    {}
 func (*ThreadBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{1}
+	return fileDescriptor_thread_94a3569b430f979d, []int{1}
 }
 func (m *ThreadBlock) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadBlock.Unmarshal(m, b)
@@ -193,7 +199,7 @@ func (m *ThreadBlockHeader) Reset()         { *m = ThreadBlockHeader{} }
 func (m *ThreadBlockHeader) String() string { return proto.CompactTextString(m) }
 func (*ThreadBlockHeader) ProtoMessage()    {}
 func (*ThreadBlockHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{2}
+	return fileDescriptor_thread_94a3569b430f979d, []int{2}
 }
 func (m *ThreadBlockHeader) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadBlockHeader.Unmarshal(m, b)
@@ -242,21 +248,24 @@ func (m *ThreadBlockHeader) GetAddress() string {
 }
 
 type ThreadInvite struct {
-	Sk                   []byte   `protobuf:"bytes,1,opt,name=sk,proto3" json:"sk,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Schema               string   `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
-	Initiator            string   `protobuf:"bytes,4,opt,name=initiator,proto3" json:"initiator,omitempty"`
-	Contact              *Contact `protobuf:"bytes,5,opt,name=contact,proto3" json:"contact,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Sk                   []byte               `protobuf:"bytes,1,opt,name=sk,proto3" json:"sk,omitempty"`
+	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Schema               string               `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Initiator            string               `protobuf:"bytes,4,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	Contact              *Contact             `protobuf:"bytes,5,opt,name=contact,proto3" json:"contact,omitempty"`
+	Direct               bool                 `protobuf:"varint,6,opt,name=direct,proto3" json:"direct,omitempty"`
+	AccountSig           []byte               `protobuf:"bytes,7,opt,name=accountSig,proto3" json:"accountSig,omitempty"`
+	AccountDate          *timestamp.Timestamp `protobuf:"bytes,8,opt,name=accountDate,proto3" json:"accountDate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *ThreadInvite) Reset()         { *m = ThreadInvite{} }
 func (m *ThreadInvite) String() string { return proto.CompactTextString(m) }
 func (*ThreadInvite) ProtoMessage()    {}
 func (*ThreadInvite) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{3}
+	return fileDescriptor_thread_94a3569b430f979d, []int{3}
 }
 func (m *ThreadInvite) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadInvite.Unmarshal(m, b)
@@ -311,6 +320,27 @@ func (m *ThreadInvite) GetContact() *Contact {
 	return nil
 }
 
+func (m *ThreadInvite) GetDirect() bool {
+	if m != nil {
+		return m.Direct
+	}
+	return false
+}
+
+func (m *ThreadInvite) GetAccountSig() []byte {
+	if m != nil {
+		return m.AccountSig
+	}
+	return nil
+}
+
+func (m *ThreadInvite) GetAccountDate() *timestamp.Timestamp {
+	if m != nil {
+		return m.AccountDate
+	}
+	return nil
+}
+
 type ThreadIgnore struct {
 	Target               string   `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -322,7 +352,7 @@ func (m *ThreadIgnore) Reset()         { *m = ThreadIgnore{} }
 func (m *ThreadIgnore) String() string { return proto.CompactTextString(m) }
 func (*ThreadIgnore) ProtoMessage()    {}
 func (*ThreadIgnore) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{4}
+	return fileDescriptor_thread_94a3569b430f979d, []int{4}
 }
 func (m *ThreadIgnore) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadIgnore.Unmarshal(m, b)
@@ -360,7 +390,7 @@ func (m *ThreadFlag) Reset()         { *m = ThreadFlag{} }
 func (m *ThreadFlag) String() string { return proto.CompactTextString(m) }
 func (*ThreadFlag) ProtoMessage()    {}
 func (*ThreadFlag) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{5}
+	return fileDescriptor_thread_94a3569b430f979d, []int{5}
 }
 func (m *ThreadFlag) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadFlag.Unmarshal(m, b)
@@ -399,7 +429,7 @@ func (m *ThreadJoin) Reset()         { *m = ThreadJoin{} }
 func (m *ThreadJoin) String() string { return proto.CompactTextString(m) }
 func (*ThreadJoin) ProtoMessage()    {}
 func (*ThreadJoin) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{6}
+	return fileDescriptor_thread_94a3569b430f979d, []int{6}
 }
 func (m *ThreadJoin) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadJoin.Unmarshal(m, b)
@@ -444,7 +474,7 @@ func (m *ThreadAnnounce) Reset()         { *m = ThreadAnnounce{} }
 func (m *ThreadAnnounce) String() string { return proto.CompactTextString(m) }
 func (*ThreadAnnounce) ProtoMessage()    {}
 func (*ThreadAnnounce) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{7}
+	return fileDescriptor_thread_94a3569b430f979d, []int{7}
 }
 func (m *ThreadAnnounce) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadAnnounce.Unmarshal(m, b)
@@ -482,7 +512,7 @@ func (m *ThreadMessage) Reset()         { *m = ThreadMessage{} }
 func (m *ThreadMessage) String() string { return proto.CompactTextString(m) }
 func (*ThreadMessage) ProtoMessage()    {}
 func (*ThreadMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{8}
+	return fileDescriptor_thread_94a3569b430f979d, []int{8}
 }
 func (m *ThreadMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadMessage.Unmarshal(m, b)
@@ -522,7 +552,7 @@ func (m *ThreadFiles) Reset()         { *m = ThreadFiles{} }
 func (m *ThreadFiles) String() string { return proto.CompactTextString(m) }
 func (*ThreadFiles) ProtoMessage()    {}
 func (*ThreadFiles) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{9}
+	return fileDescriptor_thread_94a3569b430f979d, []int{9}
 }
 func (m *ThreadFiles) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadFiles.Unmarshal(m, b)
@@ -575,7 +605,7 @@ func (m *ThreadComment) Reset()         { *m = ThreadComment{} }
 func (m *ThreadComment) String() string { return proto.CompactTextString(m) }
 func (*ThreadComment) ProtoMessage()    {}
 func (*ThreadComment) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{10}
+	return fileDescriptor_thread_94a3569b430f979d, []int{10}
 }
 func (m *ThreadComment) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadComment.Unmarshal(m, b)
@@ -620,7 +650,7 @@ func (m *ThreadLike) Reset()         { *m = ThreadLike{} }
 func (m *ThreadLike) String() string { return proto.CompactTextString(m) }
 func (*ThreadLike) ProtoMessage()    {}
 func (*ThreadLike) Descriptor() ([]byte, []int) {
-	return fileDescriptor_thread_d695b8acd230a8b4, []int{11}
+	return fileDescriptor_thread_94a3569b430f979d, []int{11}
 }
 func (m *ThreadLike) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ThreadLike.Unmarshal(m, b)
@@ -647,6 +677,160 @@ func (m *ThreadLike) GetTarget() string {
 	return ""
 }
 
+type ThreadDevice struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Removed              bool     `protobuf:"varint,3,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ThreadDevice) Reset()         { *m = ThreadDevice{} }
+func (m *ThreadDevice) String() string { return proto.CompactTextString(m) }
+func (*ThreadDevice) ProtoMessage()    {}
+func (*ThreadDevice) Descriptor() ([]byte, []int) {
+	return fileDescriptor_thread_94a3569b430f979d, []int{12}
+}
+func (m *ThreadDevice) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ThreadDevice.Unmarshal(m, b)
+}
+func (m *ThreadDevice) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ThreadDevice.Marshal(b, m, deterministic)
+}
+func (dst *ThreadDevice) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ThreadDevice.Merge(dst, src)
+}
+func (m *ThreadDevice) XXX_Size() int {
+	return xxx_messageInfo_ThreadDevice.Size(m)
+}
+func (m *ThreadDevice) XXX_DiscardUnknown() {
+	xxx_messageInfo_ThreadDevice.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ThreadDevice proto.InternalMessageInfo
+
+func (m *ThreadDevice) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ThreadDevice) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ThreadDevice) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type ThreadSnapshot struct {
+	State                []byte   `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Sig                  []byte   `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ThreadSnapshot) Reset()         { *m = ThreadSnapshot{} }
+func (m *ThreadSnapshot) String() string { return proto.CompactTextString(m) }
+func (*ThreadSnapshot) ProtoMessage()    {}
+func (*ThreadSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_thread_94a3569b430f979d, []int{13}
+}
+func (m *ThreadSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ThreadSnapshot.Unmarshal(m, b)
+}
+func (m *ThreadSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ThreadSnapshot.Marshal(b, m, deterministic)
+}
+func (dst *ThreadSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ThreadSnapshot.Merge(dst, src)
+}
+func (m *ThreadSnapshot) XXX_Size() int {
+	return xxx_messageInfo_ThreadSnapshot.Size(m)
+}
+func (m *ThreadSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_ThreadSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ThreadSnapshot proto.InternalMessageInfo
+
+func (m *ThreadSnapshot) GetState() []byte {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+func (m *ThreadSnapshot) GetSig() []byte {
+	if m != nil {
+		return m.Sig
+	}
+	return nil
+}
+
+type ThreadSnapshotState struct {
+	Members              []*Contact `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	Files                []string   `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	Ignores              []string   `protobuf:"bytes,3,rep,name=ignores,proto3" json:"ignores,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ThreadSnapshotState) Reset()         { *m = ThreadSnapshotState{} }
+func (m *ThreadSnapshotState) String() string { return proto.CompactTextString(m) }
+func (*ThreadSnapshotState) ProtoMessage()    {}
+func (*ThreadSnapshotState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_thread_94a3569b430f979d, []int{14}
+}
+func (m *ThreadSnapshotState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ThreadSnapshotState.Unmarshal(m, b)
+}
+func (m *ThreadSnapshotState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ThreadSnapshotState.Marshal(b, m, deterministic)
+}
+func (dst *ThreadSnapshotState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ThreadSnapshotState.Merge(dst, src)
+}
+func (m *ThreadSnapshotState) XXX_Size() int {
+	return xxx_messageInfo_ThreadSnapshotState.Size(m)
+}
+func (m *ThreadSnapshotState) XXX_DiscardUnknown() {
+	xxx_messageInfo_ThreadSnapshotState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ThreadSnapshotState proto.InternalMessageInfo
+
+func (m *ThreadSnapshotState) GetMembers() []*Contact {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *ThreadSnapshotState) GetFiles() []string {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *ThreadSnapshotState) GetIgnores() []string {
+	if m != nil {
+		return m.Ignores
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ThreadEnvelope)(nil), "ThreadEnvelope")
 	proto.RegisterType((*ThreadBlock)(nil), "ThreadBlock")
@@ -661,53 +845,66 @@ func init() {
 	proto.RegisterMapType((map[string]string)(nil), "ThreadFiles.KeysEntry")
 	proto.RegisterType((*ThreadComment)(nil), "ThreadComment")
 	proto.RegisterType((*ThreadLike)(nil), "ThreadLike")
+	proto.RegisterType((*ThreadDevice)(nil), "ThreadDevice")
+	proto.RegisterType((*ThreadSnapshot)(nil), "ThreadSnapshot")
+	proto.RegisterType((*ThreadSnapshotState)(nil), "ThreadSnapshotState")
 	proto.RegisterEnum("ThreadBlock_Type", ThreadBlock_Type_name, ThreadBlock_Type_value)
 }
 
-func init() { proto.RegisterFile("thread.proto", fileDescriptor_thread_d695b8acd230a8b4) }
-
-var fileDescriptor_thread_d695b8acd230a8b4 = []byte{
-	// 657 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x4b, 0x6f, 0xd3, 0x40,
-	0x10, 0xc6, 0x8e, 0xf3, 0x9a, 0x84, 0xca, 0x5d, 0x55, 0x95, 0xa9, 0x10, 0x44, 0xe6, 0xa1, 0xa8,
-	0x07, 0x57, 0x0a, 0x48, 0x20, 0x38, 0xa5, 0xd1, 0x26, 0xa4, 0xcd, 0x43, 0x72, 0x43, 0x0f, 0x88,
-	0xcb, 0x26, 0x5e, 0x12, 0x2b, 0xce, 0xae, 0x65, 0x6f, 0x2a, 0xfc, 0x03, 0xb8, 0x21, 0x71, 0xe5,
-	0xc4, 0x6f, 0x45, 0xbb, 0xf6, 0xa6, 0x11, 0x55, 0x0e, 0xdc, 0xf6, 0x9b, 0xf9, 0x66, 0xe6, 0x9b,
-	0xc7, 0x42, 0x53, 0xac, 0x12, 0x4a, 0x02, 0x2f, 0x4e, 0xb8, 0xe0, 0x67, 0x4f, 0x96, 0x9c, 0x2f,
-	0x23, 0x7a, 0xa1, 0xd0, 0x7c, 0xfb, 0xed, 0x82, 0xb0, 0xac, 0x70, 0x3d, 0xff, 0xd7, 0x25, 0xc2,
-	0x0d, 0x4d, 0x05, 0xd9, 0xc4, 0x05, 0xa1, 0xb1, 0xe1, 0x01, 0x8d, 0x72, 0xe0, 0x7e, 0x85, 0xa3,
-	0x99, 0x4a, 0x8c, 0xd9, 0x1d, 0x8d, 0x78, 0x4c, 0xd1, 0x29, 0x54, 0xf2, 0x52, 0x8e, 0xd1, 0x32,
-	0xda, 0x75, 0xbf, 0x40, 0x08, 0x81, 0xb5, 0x22, 0xe9, 0xca, 0x31, 0x95, 0x55, 0xbd, 0xd1, 0x33,
-	0x80, 0x45, 0x18, 0xaf, 0x68, 0x22, 0xe8, 0x77, 0xe1, 0x94, 0x5a, 0x46, 0xbb, 0xe9, 0xef, 0x59,
-	0xdc, 0xdf, 0x26, 0x34, 0xf2, 0xf4, 0x97, 0x11, 0x5f, 0xac, 0xd1, 0x39, 0x54, 0x56, 0x94, 0x04,
-	0x34, 0x51, 0xb9, 0x1b, 0x1d, 0xe4, 0xed, 0x79, 0x3f, 0x29, 0x8f, 0x5f, 0x30, 0xd0, 0x2b, 0xb0,
-	0x44, 0x16, 0x53, 0x55, 0xef, 0xa8, 0x73, 0xbc, 0xcf, 0xf4, 0x66, 0x59, 0x4c, 0x7d, 0xe5, 0x46,
-	0x1e, 0x54, 0x63, 0x92, 0x45, 0x9c, 0x04, 0xaa, 0x7e, 0xa3, 0x73, 0xe2, 0xe5, 0x03, 0xf0, 0xf4,
-	0x00, 0xbc, 0x2e, 0xcb, 0x7c, 0x4d, 0x72, 0x7f, 0x18, 0x60, 0xc9, 0x70, 0x54, 0x87, 0xf2, 0x18,
-	0xfb, 0x03, 0x6c, 0x3f, 0x42, 0x00, 0x95, 0xe1, 0x60, 0x32, 0xf5, 0xb1, 0x6d, 0xa0, 0x1a, 0x58,
-	0xfd, 0x51, 0x77, 0x60, 0x9b, 0xf2, 0x75, 0x35, 0x1d, 0x4e, 0xec, 0x12, 0x6a, 0x42, 0xad, 0x3b,
-	0x99, 0x4c, 0x3f, 0x4f, 0x7a, 0xd8, 0xb6, 0x64, 0xe0, 0x08, 0x77, 0x6f, 0xb1, 0x5d, 0x46, 0x0d,
-	0xa8, 0x8e, 0xf1, 0xcd, 0x4d, 0x77, 0x80, 0xed, 0x8a, 0xb4, 0xf7, 0x87, 0x23, 0x7c, 0x63, 0x57,
-	0xa5, 0xbd, 0x37, 0x1d, 0x8f, 0xf1, 0x64, 0x66, 0xd7, 0x64, 0x9e, 0xd1, 0xf0, 0x1a, 0xdb, 0x75,
-	0x55, 0x67, 0x72, 0x3b, 0x9c, 0x61, 0xbb, 0xe3, 0xfe, 0x32, 0xe0, 0xf8, 0x41, 0xf3, 0xc8, 0x03,
-	0x2b, 0x20, 0x82, 0x16, 0xe3, 0x39, 0x7b, 0xd0, 0xca, 0x4c, 0xef, 0xd2, 0x57, 0x3c, 0xe4, 0xc8,
-	0xee, 0x13, 0xca, 0x44, 0xea, 0x98, 0xad, 0x52, 0xbb, 0xee, 0x6b, 0x28, 0xd7, 0x48, 0xb6, 0x62,
-	0xc5, 0x13, 0x35, 0x96, 0xba, 0x5f, 0x20, 0x19, 0x41, 0x82, 0x20, 0xa1, 0x69, 0xea, 0x58, 0xca,
-	0xa1, 0xa1, 0xfb, 0xd3, 0x80, 0x66, 0xae, 0x68, 0xc8, 0xee, 0x42, 0x41, 0xd1, 0x11, 0x98, 0xe9,
-	0x5a, 0x49, 0x69, 0xfa, 0x66, 0xba, 0x96, 0x17, 0xc0, 0xc8, 0x86, 0xea, 0x0b, 0x90, 0x6f, 0x59,
-	0x26, 0x5d, 0xac, 0xe8, 0x86, 0xe8, 0x32, 0x39, 0x42, 0x4f, 0xa1, 0x1e, 0xb2, 0x50, 0x84, 0x44,
-	0xf0, 0xa4, 0x28, 0x74, 0x6f, 0x40, 0x2e, 0x54, 0x17, 0x9c, 0x09, 0xb2, 0x10, 0x4e, 0x59, 0x75,
-	0x5a, 0xf3, 0x7a, 0x39, 0xf6, 0xb5, 0xc3, 0x7d, 0xbd, 0x53, 0xb3, 0x64, 0x3c, 0xc9, 0xef, 0x92,
-	0x24, 0x4b, 0x2a, 0x76, 0x77, 0xa9, 0x90, 0xfb, 0x12, 0x20, 0xe7, 0xf5, 0x23, 0xb2, 0x3c, 0xc8,
-	0xba, 0xd2, 0xac, 0x2b, 0x1e, 0x32, 0x39, 0x84, 0x50, 0xf5, 0x98, 0x14, 0x34, 0x0d, 0xf7, 0x95,
-	0x99, 0x87, 0x94, 0xbd, 0xd5, 0x7f, 0xa6, 0xcb, 0x18, 0xdf, 0xb2, 0x05, 0xdd, 0x8f, 0x32, 0x0e,
-	0x45, 0xbd, 0x80, 0xc7, 0x79, 0xd4, 0x98, 0xa6, 0x29, 0x59, 0x52, 0x39, 0xce, 0x39, 0x0f, 0xb2,
-	0x42, 0x81, 0x7a, 0xbb, 0x7f, 0x0c, 0xfd, 0x61, 0xfa, 0x61, 0x44, 0xd3, 0x43, 0xed, 0xec, 0x62,
-	0xcd, 0xfb, 0x58, 0x74, 0x0e, 0xd6, 0x9a, 0x66, 0xa9, 0x53, 0x6a, 0x95, 0xda, 0x8d, 0xce, 0xa9,
-	0xb7, 0x97, 0xc7, 0xbb, 0xa6, 0x59, 0x8a, 0x99, 0x48, 0x32, 0x5f, 0x71, 0xce, 0xde, 0x41, 0x7d,
-	0x67, 0x42, 0x36, 0x94, 0xd6, 0x54, 0xeb, 0x90, 0x4f, 0x74, 0x02, 0xe5, 0x3b, 0x12, 0x6d, 0xf5,
-	0xaa, 0x73, 0xf0, 0xc1, 0x7c, 0x6f, 0xb8, 0x1f, 0x75, 0x17, 0x3d, 0xbe, 0xd9, 0x50, 0x26, 0xfe,
-	0x47, 0xe1, 0xfd, 0xaa, 0x46, 0xe1, 0xfa, 0xe0, 0x42, 0x2f, 0xad, 0x2f, 0x66, 0x3c, 0x9f, 0x57,
-	0xd4, 0xcd, 0xbf, 0xf9, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x2f, 0x9a, 0x38, 0x55, 0xf8, 0x04, 0x00,
-	0x00,
+func init() { proto.RegisterFile("thread.proto", fileDescriptor_thread_94a3569b430f979d) }
+
+var fileDescriptor_thread_94a3569b430f979d = []byte{
+	// 821 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xdd, 0x8e, 0xe3, 0x34,
+	0x14, 0x26, 0x69, 0xa6, 0x3f, 0xa7, 0x65, 0x94, 0x35, 0xab, 0x55, 0x18, 0x21, 0xa8, 0xc2, 0x8f,
+	0xaa, 0xbd, 0xc8, 0x4a, 0x05, 0x89, 0x15, 0x70, 0xd3, 0xed, 0x64, 0x66, 0x3b, 0xdb, 0x1f, 0xe4,
+	0x96, 0xb9, 0x40, 0xdc, 0xb8, 0x89, 0xb7, 0xb5, 0xda, 0xd8, 0x51, 0xec, 0x56, 0xe4, 0x96, 0x17,
+	0xe0, 0x0d, 0xe0, 0x1d, 0x78, 0x42, 0x64, 0x27, 0x9e, 0xe9, 0xb2, 0x54, 0x88, 0x3b, 0x7f, 0xe7,
+	0x7c, 0xe7, 0x1c, 0xfb, 0x3b, 0xe7, 0x18, 0x7a, 0x6a, 0x5b, 0x50, 0x92, 0x46, 0x79, 0x21, 0x94,
+	0xb8, 0xfa, 0x78, 0x23, 0xc4, 0x66, 0x4f, 0x5f, 0x18, 0xb4, 0x3e, 0xbc, 0x7d, 0x41, 0x78, 0x59,
+	0xbb, 0x3e, 0xfb, 0xa7, 0x4b, 0xb1, 0x8c, 0x4a, 0x45, 0xb2, 0xbc, 0x26, 0x74, 0x33, 0x91, 0xd2,
+	0x7d, 0x05, 0xc2, 0x5f, 0xe0, 0x72, 0x65, 0x12, 0xc7, 0xfc, 0x48, 0xf7, 0x22, 0xa7, 0xe8, 0x19,
+	0x34, 0xab, 0x52, 0x81, 0xd3, 0x77, 0x06, 0x1d, 0x5c, 0x23, 0x84, 0xc0, 0xdb, 0x12, 0xb9, 0x0d,
+	0x5c, 0x63, 0x35, 0x67, 0xf4, 0x29, 0x40, 0xc2, 0xf2, 0x2d, 0x2d, 0x14, 0xfd, 0x55, 0x05, 0x8d,
+	0xbe, 0x33, 0xe8, 0xe1, 0x13, 0x4b, 0xf8, 0x97, 0x0b, 0xdd, 0x2a, 0xfd, 0xab, 0xbd, 0x48, 0x76,
+	0xe8, 0x39, 0x34, 0xb7, 0x94, 0xa4, 0xb4, 0x30, 0xb9, 0xbb, 0x43, 0x14, 0x9d, 0x78, 0x5f, 0x1b,
+	0x0f, 0xae, 0x19, 0xe8, 0x4b, 0xf0, 0x54, 0x99, 0x53, 0x53, 0xef, 0x72, 0xf8, 0xe4, 0x94, 0x19,
+	0xad, 0xca, 0x9c, 0x62, 0xe3, 0x46, 0x11, 0xb4, 0x72, 0x52, 0xee, 0x05, 0x49, 0x4d, 0xfd, 0xee,
+	0xf0, 0x69, 0x54, 0x09, 0x10, 0x59, 0x01, 0xa2, 0x11, 0x2f, 0xb1, 0x25, 0x85, 0x7f, 0x3a, 0xe0,
+	0xe9, 0x70, 0xd4, 0x81, 0x8b, 0x59, 0x8c, 0x6f, 0x63, 0xff, 0x03, 0x04, 0xd0, 0x9c, 0xdc, 0xce,
+	0x17, 0x38, 0xf6, 0x1d, 0xd4, 0x06, 0xef, 0x66, 0x3a, 0xba, 0xf5, 0x5d, 0x7d, 0xba, 0x5b, 0x4c,
+	0xe6, 0x7e, 0x03, 0xf5, 0xa0, 0x3d, 0x9a, 0xcf, 0x17, 0x3f, 0xcd, 0xc7, 0xb1, 0xef, 0xe9, 0xc0,
+	0x69, 0x3c, 0xba, 0x8f, 0xfd, 0x0b, 0xd4, 0x85, 0xd6, 0x2c, 0x5e, 0x2e, 0x47, 0xb7, 0xb1, 0xdf,
+	0xd4, 0xf6, 0x9b, 0xc9, 0x34, 0x5e, 0xfa, 0x2d, 0x6d, 0x1f, 0x2f, 0x66, 0xb3, 0x78, 0xbe, 0xf2,
+	0xdb, 0x3a, 0xcf, 0x74, 0xf2, 0x26, 0xf6, 0x3b, 0xba, 0xce, 0x75, 0x7c, 0x3f, 0x19, 0xc7, 0x3e,
+	0xe8, 0x9c, 0xcb, 0xf9, 0xe8, 0xc7, 0xe5, 0xeb, 0xc5, 0xca, 0xef, 0x9a, 0x1b, 0xcc, 0xef, 0x27,
+	0xab, 0xd8, 0x1f, 0x86, 0xbf, 0x3b, 0xf0, 0xe4, 0x3d, 0x59, 0x50, 0x04, 0x5e, 0x4a, 0x14, 0xad,
+	0x85, 0xbb, 0x7a, 0xef, 0x91, 0x2b, 0xdb, 0x65, 0x6c, 0x78, 0x28, 0xd0, 0xba, 0x14, 0x94, 0x2b,
+	0x19, 0xb8, 0xfd, 0xc6, 0xa0, 0x83, 0x2d, 0xd4, 0x0d, 0x26, 0x07, 0xb5, 0x15, 0x85, 0x11, 0xac,
+	0x83, 0x6b, 0xa4, 0x23, 0x48, 0x9a, 0x16, 0x54, 0xca, 0xc0, 0x33, 0x0e, 0x0b, 0xc3, 0xdf, 0x5c,
+	0xe8, 0x55, 0x37, 0x9a, 0xf0, 0x23, 0x53, 0x14, 0x5d, 0x82, 0x2b, 0x77, 0xe6, 0x2a, 0x3d, 0xec,
+	0xca, 0x9d, 0x9e, 0x0d, 0x4e, 0x32, 0x6a, 0x67, 0x43, 0x9f, 0x75, 0x19, 0x99, 0x6c, 0x69, 0x46,
+	0x6c, 0x99, 0x0a, 0xa1, 0x4f, 0xa0, 0xc3, 0x38, 0x53, 0x8c, 0x28, 0x51, 0xd4, 0x85, 0x1e, 0x0d,
+	0x28, 0x84, 0x56, 0x22, 0xb8, 0x22, 0x89, 0x0a, 0x2e, 0xcc, 0x4b, 0xdb, 0xd1, 0xb8, 0xc2, 0xd8,
+	0x3a, 0x74, 0xe6, 0x94, 0x15, 0x34, 0x51, 0x41, 0xb3, 0xef, 0x0c, 0xda, 0xb8, 0x46, 0x7a, 0x1a,
+	0x49, 0x92, 0x88, 0x03, 0x57, 0x4b, 0xb6, 0x09, 0x5a, 0xd5, 0x34, 0x3e, 0x5a, 0xd0, 0x0f, 0xd0,
+	0xad, 0xd1, 0xb5, 0x56, 0xb2, 0xfd, 0x9f, 0x4a, 0x9e, 0xd2, 0xc3, 0xaf, 0x1e, 0x34, 0xd8, 0x70,
+	0x51, 0x54, 0x7b, 0x42, 0x8a, 0x0d, 0x55, 0x0f, 0x7b, 0x62, 0x50, 0xf8, 0x05, 0x40, 0xc5, 0xbb,
+	0xd9, 0x93, 0xcd, 0x59, 0xd6, 0x9d, 0x65, 0xdd, 0x09, 0xc6, 0xb5, 0xf4, 0xcc, 0x28, 0x5b, 0xd4,
+	0x34, 0x0b, 0x4f, 0xf5, 0x70, 0xcf, 0xe8, 0x11, 0x7e, 0x63, 0x77, 0x78, 0xc4, 0xb9, 0x38, 0xf0,
+	0x84, 0x9e, 0x46, 0x39, 0xe7, 0xa2, 0x3e, 0x87, 0x0f, 0xab, 0xa8, 0x19, 0x95, 0x92, 0x6c, 0xa8,
+	0x6e, 0xe2, 0x5a, 0xa4, 0x65, 0x7d, 0x03, 0x73, 0x0e, 0xff, 0x70, 0xec, 0x02, 0xdf, 0xb0, 0x3d,
+	0x95, 0xe7, 0x9e, 0xf3, 0x10, 0xeb, 0x3e, 0xc6, 0xa2, 0xe7, 0xe0, 0xed, 0x68, 0x29, 0x83, 0x46,
+	0xbf, 0x31, 0xe8, 0x0e, 0x9f, 0x45, 0x27, 0x79, 0xa2, 0x37, 0xb4, 0x94, 0x31, 0x57, 0x45, 0x89,
+	0x0d, 0xe7, 0xea, 0x5b, 0xe8, 0x3c, 0x98, 0x90, 0x0f, 0x8d, 0x1d, 0xb5, 0xf7, 0xd0, 0x47, 0xf4,
+	0x14, 0x2e, 0x8e, 0x64, 0x7f, 0xb0, 0x03, 0x56, 0x81, 0xef, 0xdc, 0x97, 0x4e, 0xf8, 0xbd, 0x7d,
+	0xc5, 0x58, 0x64, 0x19, 0xe5, 0xea, 0xff, 0xdc, 0xf0, 0xb1, 0x55, 0x53, 0xb6, 0x3b, 0xdf, 0xd0,
+	0xa9, 0x6d, 0xfc, 0x35, 0x3d, 0xb2, 0xc4, 0x0c, 0x3f, 0xb3, 0x9f, 0xa3, 0xcb, 0xd2, 0x7f, 0x1d,
+	0xfe, 0x00, 0x5a, 0x05, 0xcd, 0xc4, 0x91, 0x56, 0xbf, 0x52, 0x1b, 0x5b, 0x18, 0xbe, 0xb4, 0xcd,
+	0x5a, 0x72, 0x92, 0xcb, 0xad, 0x50, 0xfa, 0x71, 0x52, 0xd9, 0xd5, 0xee, 0xe1, 0x0a, 0x68, 0x11,
+	0x24, 0xdb, 0x98, 0xa4, 0x3d, 0xac, 0x8f, 0x21, 0x83, 0x8f, 0xde, 0x8d, 0x5c, 0x1a, 0x62, 0x08,
+	0xad, 0x8c, 0x66, 0x6b, 0x5a, 0xc8, 0xc0, 0xe9, 0x37, 0xde, 0xed, 0x75, 0xed, 0xd0, 0x25, 0xde,
+	0x6a, 0xdd, 0xeb, 0xaf, 0xa0, 0x02, 0x66, 0xea, 0xcc, 0x2c, 0x57, 0x3d, 0xea, 0x60, 0x0b, 0x5f,
+	0x79, 0x3f, 0xbb, 0xf9, 0x7a, 0xdd, 0x34, 0x2b, 0xf1, 0xf5, 0xdf, 0x03, 0x00, 0x6a, 0x63, 0x85,
+	0x7f, 0x7b, 0x06, 0x00, 0x00,
 }
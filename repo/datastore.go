@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"database/sql"
 	"strings"
 	"time"
 
@@ -26,17 +25,13 @@ type Datastore interface {
 	CafeClients() CafeClientStore
 	CafeClientThreads() CafeClientThreadStore
 	CafeClientMessages() CafeClientMessageStore
+	BlockedPeers() BlockedPeerStore
+	Devices() DeviceStore
+	Export(dbPath string) error
 	Ping() error
 	Close()
 }
 
-type Queryable interface {
-	BeginTransaction() (*sql.Tx, error)
-	PrepareQuery(string) (*sql.Stmt, error)
-	PrepareAndExecuteQuery(string, ...interface{}) (*sql.Rows, error)
-	ExecuteQuery(string, ...interface{}) (sql.Result, error)
-}
-
 type ConfigStore interface {
 	Init(pin string) error
 	Configure(accnt *keypair.Full, created time.Time) error
@@ -46,7 +41,6 @@ type ConfigStore interface {
 }
 
 type ContactStore interface {
-	Queryable
 	Add(contact *Contact) error
 	AddOrUpdate(contact *Contact) error
 	Get(id string) *Contact
@@ -56,34 +50,36 @@ type ContactStore interface {
 	UpdateUsername(id string, username string) error
 	UpdateAvatar(id string, avatar string) error
 	UpdateInboxes(id string, inboxes []Cafe) error
+	UpdateVerified(id string, verified bool) error
+	UpdateEndpoint(id string, endpoint string) error
+	UpdateSignature(id string, signature []byte) error
 	Delete(id string) error
 }
 
 type FileStore interface {
-	Queryable
 	Add(file *File) error
 	Get(hash string) *File
 	GetByPrimary(mill string, checksum string) *File
 	GetBySource(mill string, source string, opts string) *File
 	AddTarget(hash string, target string) error
 	RemoveTarget(hash string, target string) error
+	List() []File
 	Count() int
 	Delete(hash string) error
 }
 
 type ThreadStore interface {
-	Queryable
 	Add(thread *Thread) error
 	Get(id string) *Thread
 	GetByKey(key string) *Thread
 	List() []Thread
 	Count() int
 	UpdateHead(id string, head string) error
+	UpdateKey(id string, key string) error
 	Delete(id string) error
 }
 
 type ThreadInviteStore interface {
-	Queryable
 	Add(invite *ThreadInvite) error
 	Get(id string) *ThreadInvite
 	List() []ThreadInvite
@@ -91,13 +87,13 @@ type ThreadInviteStore interface {
 }
 
 type ThreadPeerStore interface {
-	Queryable
 	Add(peer *ThreadPeer) error
 	List() []ThreadPeer
 	ListById(id string) []ThreadPeer
 	ListByThread(threadId string) []ThreadPeer
 	ListUnwelcomedByThread(threadId string) []ThreadPeer
 	WelcomeByThread(thread string) error
+	UpdateLastSeen(id string, date time.Time, addrs []string) error
 	Count(distinct bool) int
 	Delete(id string, thread string) error
 	DeleteById(id string) error
@@ -105,14 +101,14 @@ type ThreadPeerStore interface {
 }
 
 type ThreadMessageStore interface {
-	Queryable
 	Add(msg *ThreadMessage) error
 	List(offset string, limit int) []ThreadMessage
+	ListByPeer(peerId string, offset string, limit int) []ThreadMessage
+	Count() int
 	Delete(id string) error
 }
 
 type BlockStore interface {
-	Queryable
 	Add(block *Block) error
 	Get(id string) *Block
 	List(offset string, limit int, query string) []Block
@@ -122,7 +118,6 @@ type BlockStore interface {
 }
 
 type NotificationStore interface {
-	Queryable
 	Add(notification *Notification) error
 	Get(id string) *Notification
 	Read(id string) error
@@ -145,15 +140,14 @@ type CafeSessionStore interface {
 }
 
 type CafeRequestStore interface {
-	Queryable
 	Add(req *CafeRequest) error
 	List(offset string, limit int) []CafeRequest
+	Count() int
 	Delete(id string) error
 	DeleteByCafe(cafeId string) error
 }
 
 type CafeMessageStore interface {
-	Queryable
 	Add(msg *CafeMessage) error
 	List(offset string, limit int) []CafeMessage
 	AddAttempt(id string) error
@@ -193,6 +187,24 @@ type CafeClientMessageStore interface {
 	DeleteByClient(clientId string, limit int) error
 }
 
+// ConflictError returns whether or not err is a unique constraint violation (sqlite or postgres)
 func ConflictError(err error) bool {
-	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value violates unique constraint")
+}
+
+type BlockedPeerStore interface {
+	AddOrUpdate(peer *BlockedPeer) error
+	Get(id string) *BlockedPeer
+	List() []BlockedPeer
+	Delete(id string) error
+}
+
+type DeviceStore interface {
+	AddOrUpdate(device *Device) error
+	Get(id string) *Device
+	List(query string) []Device
+	Count(query string) int
+	Delete(id string) error
 }
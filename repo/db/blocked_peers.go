@@ -0,0 +1,92 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type BlockedPeerDB struct {
+	modelStore
+}
+
+func NewBlockedPeerStore(db *sql.DB, lock *sync.Mutex) repo.BlockedPeerStore {
+	return &BlockedPeerDB{newModelStore("blocked_peers", db, lock)}
+}
+
+func (c *BlockedPeerDB) AddOrUpdate(peer *repo.BlockedPeer) error {
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	stm := `insert or replace into blocked_peers(id, muted, date) values(?,?,?)`
+	stmt, err := c.txPrepared(tx, stm)
+	if err != nil {
+		log.Errorf("error in tx prepare: %s", err)
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(
+		peer.Id,
+		peer.Muted,
+		peer.Date.UnixNano(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (c *BlockedPeerDB) Get(id string) *repo.BlockedPeer {
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from blocked_peers where id=?;", id))
+	if len(ret) == 0 {
+		return nil
+	}
+	return &ret[0]
+}
+
+func (c *BlockedPeerDB) List() []repo.BlockedPeer {
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from blocked_peers order by date desc;"))
+}
+
+func (c *BlockedPeerDB) Delete(id string) error {
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("delete from blocked_peers where id=?", id)
+	return err
+}
+
+func (c *BlockedPeerDB) handleRows(rows *sql.Rows, err error) []repo.BlockedPeer {
+	var ret []repo.BlockedPeer
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var mutedInt int
+		var dateInt int64
+		if err := rows.Scan(&id, &mutedInt, &dateInt); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.BlockedPeer{
+			Id:    id,
+			Muted: mutedInt == 1,
+			Date:  time.Unix(0, dateInt),
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
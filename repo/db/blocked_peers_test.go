@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+var blockedPeerStore *BlockedPeerDB
+
+func init() {
+	setupBlockedPeerDB()
+}
+
+func setupBlockedPeerDB() {
+	conn, _ := sql.Open("sqlite3", ":memory:")
+	initDatabaseTables(conn, "")
+	blockedPeerStore = NewBlockedPeerStore(conn, new(sync.Mutex)).(*BlockedPeerDB)
+}
+
+func TestBlockedPeerDB_AddOrUpdate(t *testing.T) {
+	if err := blockedPeerStore.AddOrUpdate(&repo.BlockedPeer{
+		Id:   "abcde",
+		Date: time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	stmt, err := blockedPeerStore.db.Prepare("select id from blocked_peers where id=?")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer stmt.Close()
+	var id string
+	if err := stmt.QueryRow("abcde").Scan(&id); err != nil {
+		t.Error(err)
+		return
+	}
+	if id != "abcde" {
+		t.Errorf(`expected "abcde" got %s`, id)
+	}
+}
+
+func TestBlockedPeerDB_Get(t *testing.T) {
+	blocked := blockedPeerStore.Get("abcde")
+	if blocked == nil {
+		t.Error("could not get blocked peer")
+		return
+	}
+	if blocked.Muted {
+		t.Error("blocked peer should not be muted")
+	}
+}
+
+func TestBlockedPeerDB_Mute(t *testing.T) {
+	if err := blockedPeerStore.AddOrUpdate(&repo.BlockedPeer{
+		Id:    "abcde",
+		Muted: true,
+		Date:  time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	blocked := blockedPeerStore.Get("abcde")
+	if blocked == nil || !blocked.Muted {
+		t.Error("blocked peer was not muted")
+	}
+}
+
+func TestBlockedPeerDB_List(t *testing.T) {
+	setupBlockedPeerDB()
+	if err := blockedPeerStore.AddOrUpdate(&repo.BlockedPeer{
+		Id:   "abcde",
+		Date: time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := blockedPeerStore.AddOrUpdate(&repo.BlockedPeer{
+		Id:    "fghij",
+		Muted: true,
+		Date:  time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	list := blockedPeerStore.List()
+	if len(list) != 2 {
+		t.Error("returned incorrect number of blocked peers")
+		return
+	}
+	if list[0].Id != "fghij" {
+		t.Error("returned incorrect order")
+	}
+}
+
+func TestBlockedPeerDB_Delete(t *testing.T) {
+	if err := blockedPeerStore.Delete("abcde"); err != nil {
+		t.Error(err)
+		return
+	}
+	if blockedPeerStore.Get("abcde") != nil {
+		t.Error("delete failed")
+	}
+}
@@ -15,18 +15,19 @@ type BlockDB struct {
 }
 
 func NewBlockStore(db *sql.DB, lock *sync.Mutex) repo.BlockStore {
-	return &BlockDB{modelStore{db, lock}}
+	return &BlockDB{newModelStore("blocks", db, lock)}
 }
 
 func (c *BlockDB) Add(block *repo.Block) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into blocks(id, threadId, authorId, type, date, parents, target, body) values(?,?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -51,9 +52,8 @@ func (c *BlockDB) Add(block *repo.Block) error {
 }
 
 func (c *BlockDB) Get(id string) *repo.Block {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from blocks where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from blocks where id=?", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -61,8 +61,7 @@ func (c *BlockDB) Get(id string) *repo.Block {
 }
 
 func (c *BlockDB) List(offset string, limit int, query string) []repo.Block {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.observe("List", time.Now())
 	var stm, q string
 	if offset != "" {
 		if query != "" {
@@ -79,8 +78,7 @@ func (c *BlockDB) List(offset string, limit int, query string) []repo.Block {
 }
 
 func (c *BlockDB) Count(query string) int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.observe("Count", time.Now())
 	var q string
 	if query != "" {
 		q = " where " + query
@@ -92,26 +90,32 @@ func (c *BlockDB) Count(query string) int {
 }
 
 func (c *BlockDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from blocks where id=?", id)
+	_, err := c.exec("delete from blocks where id=?", id)
 	return err
 }
 
 func (c *BlockDB) DeleteByThread(threadId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByThread", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from blocks where threadId=?", threadId)
+	_, err := c.exec("delete from blocks where threadId=?", threadId)
 	return err
 }
 
 func (c *BlockDB) handleQuery(stm string) []repo.Block {
-	var ret []repo.Block
-	rows, err := c.db.Query(stm)
+	return c.handleRows(c.query(stm))
+}
+
+func (c *BlockDB) handleRows(rows *sql.Rows, err error) []repo.Block {
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
+	var ret []repo.Block
 	for rows.Next() {
 		var id, threadId, authorId, parents, target, body string
 		var typeInt int
@@ -137,5 +141,6 @@ func (c *BlockDB) handleQuery(stm string) []repo.Block {
 			Body:     body,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
@@ -2,6 +2,10 @@ package db
 
 import (
 	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -9,7 +13,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 )
 
-var blockStore repo.BlockStore
+var blockStore *BlockDB
 
 func init() {
 	setupBlockDB()
@@ -18,7 +22,7 @@ func init() {
 func setupBlockDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	blockStore = NewBlockStore(conn, new(sync.Mutex))
+	blockStore = NewBlockStore(conn, new(sync.Mutex)).(*BlockDB)
 }
 
 func TestBlockDB_Add(t *testing.T) {
@@ -35,7 +39,7 @@ func TestBlockDB_Add(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := blockStore.PrepareQuery("select id from blocks where id=?")
+	stmt, err := blockStore.db.Prepare("select id from blocks where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abcde").Scan(&id)
@@ -142,7 +146,7 @@ func TestBlockDB_Delete(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := blockStore.PrepareQuery("select id from blocks where id=?")
+	stmt, err := blockStore.db.Prepare("select id from blocks where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abcde").Scan(&id)
@@ -156,7 +160,7 @@ func TestBlockDB_DeleteByThread(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := blockStore.PrepareQuery("select id from blocks where id=?")
+	stmt, err := blockStore.db.Prepare("select id from blocks where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abcde2").Scan(&id)
@@ -164,3 +168,57 @@ func TestBlockDB_DeleteByThread(t *testing.T) {
 		t.Error("delete by thread id failed")
 	}
 }
+
+func BenchmarkBlockDB_ListDuringWrites(b *testing.B) {
+	dir, err := ioutil.TempDir("", "textile-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(path.Join(dir, "datastore"), 0755); err != nil {
+		b.Fatal(err)
+	}
+	store, err := Create(dir, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.InitTables(""); err != nil {
+		b.Fatal(err)
+	}
+
+	// heavy writer, similar to indexing a busy thread
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			store.Blocks().Add(&repo.Block{
+				Id:       "block" + strconv.Itoa(i),
+				ThreadId: "thread_id",
+				AuthorId: "author_id",
+				Type:     repo.FilesBlock,
+				Date:     time.Now(),
+				Parents:  []string{"Qm123"},
+				Target:   "Qm456",
+				Body:     "body",
+			})
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			store.Blocks().List("", 20, "threadId='thread_id'")
+		}
+	})
+	b.StopTimer()
+
+	close(done)
+	<-stopped
+}
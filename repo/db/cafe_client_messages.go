@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"strconv"
 	"sync"
 	"time"
 
@@ -14,18 +13,19 @@ type CafeClientMessagesDB struct {
 }
 
 func NewCafeClientMessageStore(db *sql.DB, lock *sync.Mutex) repo.CafeClientMessageStore {
-	return &CafeClientMessagesDB{modelStore{db, lock}}
+	return &CafeClientMessagesDB{newModelStore("cafe_client_messages", db, lock)}
 }
 
 func (c *CafeClientMessagesDB) AddOrUpdate(message *repo.CafeClientMessage) error {
-	c.lock.Lock()
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert or replace into cafe_client_messages(id, peerId, clientId, date) values(?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -46,44 +46,41 @@ func (c *CafeClientMessagesDB) AddOrUpdate(message *repo.CafeClientMessage) erro
 }
 
 func (c *CafeClientMessagesDB) ListByClient(clientId string, limit int) []repo.CafeClientMessage {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from cafe_client_messages where clientId='" + clientId + "' order by date asc limit " + strconv.Itoa(limit) + ";"
-	return c.handleQuery(stm)
+	defer c.observe("ListByClient", time.Now())
+	return c.handleRows(c.queryStatic("select * from cafe_client_messages where clientId=? order by date asc limit ?;", clientId, limit))
 }
 
 func (c *CafeClientMessagesDB) CountByClient(clientId string) int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from cafe_client_messages where clientId='" + clientId + "';")
+	defer c.observe("CountByClient", time.Now())
+	row := c.queryRow("select Count(*) from cafe_client_messages where clientId=?;", clientId)
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *CafeClientMessagesDB) Delete(id string, clientId string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_client_messages where id=? and clientId=?", id, clientId)
+	_, err := c.exec("delete from cafe_client_messages where id=? and clientId=?", id, clientId)
 	return err
 }
 
 func (c *CafeClientMessagesDB) DeleteByClient(clientId string, limit int) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByClient", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	sel := "select id from cafe_client_messages where clientId='" + clientId + "' order by date asc limit " + strconv.Itoa(limit)
-	query := "delete from cafe_client_messages where id in (" + sel + ");"
-	_, err := c.db.Exec(query)
+	_, err := c.exec("delete from cafe_client_messages where id in (select id from cafe_client_messages where clientId=? order by date asc limit ?);", clientId, limit)
 	return err
 }
 
-func (c *CafeClientMessagesDB) handleQuery(stm string) []repo.CafeClientMessage {
+func (c *CafeClientMessagesDB) handleRows(rows *sql.Rows, err error) []repo.CafeClientMessage {
 	var ret []repo.CafeClientMessage
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, peerId, clientId string
 		var dateInt int64
@@ -98,5 +95,6 @@ func (c *CafeClientMessagesDB) handleQuery(stm string) []repo.CafeClientMessage
 			Date:     time.Unix(0, dateInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
@@ -13,18 +13,19 @@ type CafeClientNonceDB struct {
 }
 
 func NewCafeClientNonceStore(db *sql.DB, lock *sync.Mutex) repo.CafeClientNonceStore {
-	return &CafeClientNonceDB{modelStore{db, lock}}
+	return &CafeClientNonceDB{newModelStore("cafe_client_nonces", db, lock)}
 }
 
 func (c *CafeClientNonceDB) Add(nonce *repo.CafeClientNonce) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into cafe_client_nonces(value, address, date) values(?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -44,9 +45,8 @@ func (c *CafeClientNonceDB) Add(nonce *repo.CafeClientNonce) error {
 }
 
 func (c *CafeClientNonceDB) Get(value string) *repo.CafeClientNonce {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from cafe_client_nonces where value='" + value + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from cafe_client_nonces where value=?;", value))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -54,19 +54,20 @@ func (c *CafeClientNonceDB) Get(value string) *repo.CafeClientNonce {
 }
 
 func (c *CafeClientNonceDB) Delete(value string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_client_nonces where value=?", value)
+	_, err := c.exec("delete from cafe_client_nonces where value=?", value)
 	return err
 }
 
-func (c *CafeClientNonceDB) handleQuery(stm string) []repo.CafeClientNonce {
+func (c *CafeClientNonceDB) handleRows(rows *sql.Rows, err error) []repo.CafeClientNonce {
 	var ret []repo.CafeClientNonce
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var value, address string
 		var dateInt int64
@@ -80,5 +81,6 @@ func (c *CafeClientNonceDB) handleQuery(stm string) []repo.CafeClientNonce {
 			Date:    time.Unix(0, dateInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
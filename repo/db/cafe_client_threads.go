@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"sync"
+	"time"
 
 	"github.com/textileio/textile-go/repo"
 )
@@ -12,18 +13,19 @@ type CafeClientThreadDB struct {
 }
 
 func NewCafeClientThreadStore(db *sql.DB, lock *sync.Mutex) repo.CafeClientThreadStore {
-	return &CafeClientThreadDB{modelStore{db, lock}}
+	return &CafeClientThreadDB{newModelStore("cafe_client_threads", db, lock)}
 }
 
 func (c *CafeClientThreadDB) AddOrUpdate(thrd *repo.CafeClientThread) error {
-	c.lock.Lock()
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert or replace into cafe_client_threads(id, clientId, ciphertext) values(?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -43,33 +45,33 @@ func (c *CafeClientThreadDB) AddOrUpdate(thrd *repo.CafeClientThread) error {
 }
 
 func (c *CafeClientThreadDB) ListByClient(clientId string) []repo.CafeClientThread {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from cafe_client_threads where clientId='" + clientId + "';"
-	return c.handleQuery(stm)
+	defer c.observe("ListByClient", time.Now())
+	return c.handleRows(c.queryStatic("select * from cafe_client_threads where clientId=?;", clientId))
 }
 
 func (c *CafeClientThreadDB) Delete(id string, clientId string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_client_threads where id=? and clientId=?", id, clientId)
+	_, err := c.exec("delete from cafe_client_threads where id=? and clientId=?", id, clientId)
 	return err
 }
 
 func (c *CafeClientThreadDB) DeleteByClient(clientId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByClient", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_client_threads where clientId=?", clientId)
+	_, err := c.exec("delete from cafe_client_threads where clientId=?", clientId)
 	return err
 }
 
-func (c *CafeClientThreadDB) handleQuery(stm string) []repo.CafeClientThread {
+func (c *CafeClientThreadDB) handleRows(rows *sql.Rows, err error) []repo.CafeClientThread {
 	var ret []repo.CafeClientThread
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, clientId string
 		var ciphertext []byte
@@ -83,5 +85,6 @@ func (c *CafeClientThreadDB) handleQuery(stm string) []repo.CafeClientThread {
 			Ciphertext: ciphertext,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
@@ -13,18 +13,19 @@ type CafeClientDB struct {
 }
 
 func NewCafeClientStore(db *sql.DB, lock *sync.Mutex) repo.CafeClientStore {
-	return &CafeClientDB{modelStore{db, lock}}
+	return &CafeClientDB{newModelStore("cafe_clients", db, lock)}
 }
 
 func (c *CafeClientDB) Add(client *repo.CafeClient) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into cafe_clients(id, address, created, lastSeen) values(?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -45,9 +46,8 @@ func (c *CafeClientDB) Add(client *repo.CafeClient) error {
 }
 
 func (c *CafeClientDB) Get(id string) *repo.CafeClient {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from cafe_clients where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from cafe_clients where id=?;", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -55,49 +55,46 @@ func (c *CafeClientDB) Get(id string) *repo.CafeClient {
 }
 
 func (c *CafeClientDB) Count() int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from cafe_clients;")
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from cafe_clients;")
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *CafeClientDB) List() []repo.CafeClient {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from cafe_clients order by lastSeen desc;"
-	return c.handleQuery(stm)
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from cafe_clients order by lastSeen desc;"))
 }
 
 func (c *CafeClientDB) ListByAddress(address string) []repo.CafeClient {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from cafe_clients where address='" + address + "' order by lastSeen desc;"
-	return c.handleQuery(stm)
+	defer c.observe("ListByAddress", time.Now())
+	return c.handleRows(c.queryStatic("select * from cafe_clients where address=? order by lastSeen desc;", address))
 }
 
 func (c *CafeClientDB) UpdateLastSeen(id string, date time.Time) error {
-	c.lock.Lock()
+	defer c.observe("UpdateLastSeen", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update cafe_clients set lastSeen=? where id=?", int64(date.UnixNano()), id)
+	_, err := c.exec("update cafe_clients set lastSeen=? where id=?", int64(date.UnixNano()), id)
 	return err
 }
 
 func (c *CafeClientDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_clients where id=?", id)
+	_, err := c.exec("delete from cafe_clients where id=?", id)
 	return err
 }
 
-func (c *CafeClientDB) handleQuery(stm string) []repo.CafeClient {
+func (c *CafeClientDB) handleRows(rows *sql.Rows, err error) []repo.CafeClient {
 	var ret []repo.CafeClient
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, address string
 		var createdInt, lastSeenInt int64
@@ -112,5 +109,6 @@ func (c *CafeClientDB) handleQuery(stm string) []repo.CafeClient {
 			LastSeen: time.Unix(0, lastSeenInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
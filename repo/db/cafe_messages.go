@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"strconv"
 	"sync"
 	"time"
 
@@ -14,18 +13,19 @@ type CafeMessageDB struct {
 }
 
 func NewCafeMessageStore(db *sql.DB, lock *sync.Mutex) repo.CafeMessageStore {
-	return &CafeMessageDB{modelStore{db, lock}}
+	return &CafeMessageDB{newModelStore("cafe_messages", db, lock)}
 }
 
 func (c *CafeMessageDB) Add(req *repo.CafeMessage) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into cafe_messages(id, peerId, date, attempts) values(?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -46,38 +46,36 @@ func (c *CafeMessageDB) Add(req *repo.CafeMessage) error {
 }
 
 func (c *CafeMessageDB) List(offset string, limit int) []repo.CafeMessage {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	var stm string
+	defer c.observe("List", time.Now())
 	if offset != "" {
-		stm = "select * from cafe_messages where date>(select date from cafe_messages where id='" + offset + "') order by date asc limit " + strconv.Itoa(limit) + ";"
-	} else {
-		stm = "select * from cafe_messages order by date asc limit " + strconv.Itoa(limit) + ";"
+		return c.handleRows(c.queryStatic("select * from cafe_messages where date>(select date from cafe_messages where id=?) order by date asc limit ?;", offset, limit))
 	}
-	return c.handleQuery(stm)
+	return c.handleRows(c.queryStatic("select * from cafe_messages order by date asc limit ?;", limit))
 }
 
 func (c *CafeMessageDB) AddAttempt(id string) error {
-	c.lock.Lock()
+	defer c.observe("AddAttempt", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update cafe_messages set attempts=attempts+1 where id=?", id)
+	_, err := c.exec("update cafe_messages set attempts=attempts+1 where id=?", id)
 	return err
 }
 
 func (c *CafeMessageDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_messages where id=?", id)
+	_, err := c.exec("delete from cafe_messages where id=?", id)
 	return err
 }
 
-func (c *CafeMessageDB) handleQuery(stm string) []repo.CafeMessage {
+func (c *CafeMessageDB) handleRows(rows *sql.Rows, err error) []repo.CafeMessage {
 	var ret []repo.CafeMessage
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, peerId string
 		var dateInt int64
@@ -93,5 +91,6 @@ func (c *CafeMessageDB) handleQuery(stm string) []repo.CafeMessage {
 			Attempts: attempts,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
@@ -3,7 +3,6 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
-	"strconv"
 	"sync"
 	"time"
 
@@ -16,18 +15,19 @@ type CafeRequestDB struct {
 }
 
 func NewCafeRequestStore(db *sql.DB, lock *sync.Mutex) repo.CafeRequestStore {
-	return &CafeRequestDB{modelStore{db, lock}}
+	return &CafeRequestDB{newModelStore("cafe_requests", db, lock)}
 }
 
 func (c *CafeRequestDB) Add(req *repo.CafeRequest) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into cafe_requests(id, peerId, targetId, cafeId, cafe, type, date) values(?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -57,38 +57,44 @@ func (c *CafeRequestDB) Add(req *repo.CafeRequest) error {
 }
 
 func (c *CafeRequestDB) List(offset string, limit int) []repo.CafeRequest {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	var stm string
+	defer c.observe("List", time.Now())
 	if offset != "" {
-		stm = "select * from cafe_requests where date>(select date from cafe_requests where id='" + offset + "') order by date asc limit " + strconv.Itoa(limit) + ";"
-	} else {
-		stm = "select * from cafe_requests order by date asc limit " + strconv.Itoa(limit) + ";"
+		return c.handleRows(c.queryStatic("select * from cafe_requests where date>(select date from cafe_requests where id=?) order by date asc limit ?;", offset, limit))
 	}
-	return c.handleQuery(stm)
+	return c.handleRows(c.queryStatic("select * from cafe_requests order by date asc limit ?;", limit))
+}
+
+func (c *CafeRequestDB) Count() int {
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from cafe_requests;")
+	var count int
+	row.Scan(&count)
+	return count
 }
 
 func (c *CafeRequestDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_requests where id=?", id)
+	_, err := c.exec("delete from cafe_requests where id=?", id)
 	return err
 }
 
 func (c *CafeRequestDB) DeleteByCafe(cafeId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByCafe", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_requests where cafeId=?", cafeId)
+	_, err := c.exec("delete from cafe_requests where cafeId=?", cafeId)
 	return err
 }
 
-func (c *CafeRequestDB) handleQuery(stm string) []repo.CafeRequest {
+func (c *CafeRequestDB) handleRows(rows *sql.Rows, err error) []repo.CafeRequest {
 	var ret []repo.CafeRequest
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, peerId, targetId, cafeId string
 		var typeInt int
@@ -114,5 +120,6 @@ func (c *CafeRequestDB) handleQuery(stm string) []repo.CafeRequest {
 			Date:     time.Unix(0, dateInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
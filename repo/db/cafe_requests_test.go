@@ -10,7 +10,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 )
 
-var cafeRequestStore repo.CafeRequestStore
+var cafeRequestStore *CafeRequestDB
 
 func init() {
 	setupCafeRequestDB()
@@ -19,7 +19,7 @@ func init() {
 func setupCafeRequestDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	cafeRequestStore = NewCafeRequestStore(conn, new(sync.Mutex))
+	cafeRequestStore = NewCafeRequestStore(conn, new(sync.Mutex)).(*CafeRequestDB)
 }
 
 func TestCafeRequestDB_Add(t *testing.T) {
@@ -41,7 +41,7 @@ func TestCafeRequestDB_Add(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := cafeRequestStore.PrepareQuery("select id from cafe_requests where id=?")
+	stmt, err := cafeRequestStore.db.Prepare("select id from cafe_requests where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abcde").Scan(&id)
@@ -102,12 +102,18 @@ func TestCafeRequestDB_List(t *testing.T) {
 	}
 }
 
+func TestCafeRequestDB_Count(t *testing.T) {
+	if cafeRequestStore.Count() != 2 {
+		t.Error("returned incorrect count of requests")
+	}
+}
+
 func TestCafeRequestDB_Delete(t *testing.T) {
 	err := cafeRequestStore.Delete("abcde")
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := cafeRequestStore.PrepareQuery("select id from cafe_requests where id=?")
+	stmt, err := cafeRequestStore.db.Prepare("select id from cafe_requests where id=?")
 	defer stmt.Close()
 	var id string
 	if err := stmt.QueryRow("abcde").Scan(&id); err == nil {
@@ -139,7 +145,7 @@ func TestCafeRequestDB_DeleteByCafe(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := cafeRequestStore.PrepareQuery("select id from cafe_requests where id=?")
+	stmt, err := cafeRequestStore.db.Prepare("select id from cafe_requests where id=?")
 	defer stmt.Close()
 	var id string
 	if err := stmt.QueryRow("zyx").Scan(&id); err == nil {
@@ -16,18 +16,19 @@ type CafeSessionDB struct {
 }
 
 func NewCafeSessionStore(db *sql.DB, lock *sync.Mutex) repo.CafeSessionStore {
-	return &CafeSessionDB{modelStore{db, lock}}
+	return &CafeSessionDB{newModelStore("cafe_sessions", db, lock)}
 }
 
 func (c *CafeSessionDB) AddOrUpdate(session *pb.CafeSession) error {
-	c.lock.Lock()
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert or replace into cafe_sessions(cafeId, access, refresh, expiry, cafe) values(?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -60,9 +61,8 @@ func (c *CafeSessionDB) AddOrUpdate(session *pb.CafeSession) error {
 }
 
 func (c *CafeSessionDB) Get(cafeId string) *pb.CafeSession {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from cafe_sessions where cafeId='" + cafeId + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from cafe_sessions where cafeId=?;", cafeId))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -70,26 +70,25 @@ func (c *CafeSessionDB) Get(cafeId string) *pb.CafeSession {
 }
 
 func (c *CafeSessionDB) List() []*pb.CafeSession {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from cafe_sessions order by expiry desc;"
-	return c.handleQuery(stm)
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from cafe_sessions order by expiry desc;"))
 }
 
 func (c *CafeSessionDB) Delete(cafeId string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from cafe_sessions where cafeId=?", cafeId)
+	_, err := c.exec("delete from cafe_sessions where cafeId=?", cafeId)
 	return err
 }
 
-func (c *CafeSessionDB) handleQuery(stm string) []*pb.CafeSession {
+func (c *CafeSessionDB) handleRows(rows *sql.Rows, err error) []*pb.CafeSession {
 	var ret []*pb.CafeSession
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var cafeId, access, refresh string
 		var expiryInt int64
@@ -120,5 +119,6 @@ func (c *CafeSessionDB) handleQuery(stm string) []*pb.CafeSession {
 			Cafe:    rcafe,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
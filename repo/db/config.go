@@ -52,8 +52,6 @@ func (c *ConfigDB) Configure(accnt *keypair.Full, created time.Time) error {
 }
 
 func (c *ConfigDB) GetAccount() (*keypair.Full, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
 	stmt, err := c.db.Prepare("select value from config where key=?")
 	if err != nil {
 		return nil, err
@@ -81,8 +79,6 @@ func (c *ConfigDB) GetAccount() (*keypair.Full, error) {
 }
 
 func (c *ConfigDB) GetCreationDate() (time.Time, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
 	var t time.Time
 	stmt, err := c.db.Prepare("select value from config where key=?")
 	if err != nil {
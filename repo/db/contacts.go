@@ -14,18 +14,19 @@ type ContactDB struct {
 }
 
 func NewContactStore(db *sql.DB, lock *sync.Mutex) repo.ContactStore {
-	return &ContactDB{modelStore{db, lock}}
+	return &ContactDB{newModelStore("contacts", db, lock)}
 }
 
 func (c *ContactDB) Add(contact *repo.Contact) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
-	stm := `insert into contacts(id, address, username, avatar, inboxes, created, updated) values(?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stm := `insert into contacts(id, address, username, avatar, inboxes, created, updated, verified, endpoint, signature) values(?,?,?,?,?,?,?,?,?,?)`
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -45,6 +46,9 @@ func (c *ContactDB) Add(contact *repo.Contact) error {
 		inboxes,
 		time.Now().UnixNano(),
 		time.Now().UnixNano(),
+		contact.Verified,
+		contact.Endpoint,
+		contact.Signature,
 	)
 	if err != nil {
 		tx.Rollback()
@@ -55,14 +59,16 @@ func (c *ContactDB) Add(contact *repo.Contact) error {
 }
 
 func (c *ContactDB) AddOrUpdate(contact *repo.Contact) error {
-	c.lock.Lock()
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
-	stm := `insert or replace into contacts(id, address, username, avatar, inboxes, created, updated) values(?,?,?,?,?,coalesce((select created from contacts where id=?),?),?)`
-	stmt, err := tx.Prepare(stm)
+	// verification is kept only while the address binding is unchanged
+	stm := `insert or replace into contacts(id, address, username, avatar, inboxes, created, updated, verified, endpoint, signature) values(?,?,?,?,?,coalesce((select created from contacts where id=?),?),?,coalesce((select verified from contacts where id=? and address=?),0),?,?)`
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -83,6 +89,10 @@ func (c *ContactDB) AddOrUpdate(contact *repo.Contact) error {
 		contact.Id,
 		contact.Created.UnixNano(),
 		time.Now().UnixNano(),
+		contact.Id,
+		contact.Address,
+		contact.Endpoint,
+		contact.Signature,
 	)
 	if err != nil {
 		tx.Rollback()
@@ -93,9 +103,8 @@ func (c *ContactDB) AddOrUpdate(contact *repo.Contact) error {
 }
 
 func (c *ContactDB) Get(id string) *repo.Contact {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from contacts where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from contacts where id=?;", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -103,16 +112,14 @@ func (c *ContactDB) Get(id string) *repo.Contact {
 }
 
 func (c *ContactDB) List() []repo.Contact {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.handleQuery("select * from contacts order by username asc;")
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from contacts order by username asc;"))
 }
 
 func (c *ContactDB) Find(id string, address string, username string) []repo.Contact {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.observe("Find", time.Now())
 	if id != "" {
-		return c.handleQuery("select * from contacts where id='" + id + "';")
+		return c.handleRows(c.queryStatic("select * from contacts where id=?;", id))
 	}
 	if address == "" && username == "" {
 		return nil
@@ -132,58 +139,90 @@ func (c *ContactDB) Find(id string, address string, username string) []repo.Cont
 }
 
 func (c *ContactDB) Count() int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from contacts;")
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from contacts;")
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *ContactDB) UpdateUsername(id string, username string) error {
-	c.lock.Lock()
+	defer c.observe("UpdateUsername", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update contacts set username=?, updated=? where id=?", username, time.Now().UnixNano(), id)
+	_, err := c.exec("update contacts set username=?, updated=? where id=?", username, time.Now().UnixNano(), id)
 	return err
 }
 
 func (c *ContactDB) UpdateAvatar(id string, avatar string) error {
-	c.lock.Lock()
+	defer c.observe("UpdateAvatar", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update contacts set avatar=?, updated=? where id=?", avatar, time.Now().UnixNano(), id)
+	_, err := c.exec("update contacts set avatar=?, updated=? where id=?", avatar, time.Now().UnixNano(), id)
 	return err
 }
 
 func (c *ContactDB) UpdateInboxes(id string, inboxes []repo.Cafe) error {
-	c.lock.Lock()
+	defer c.observe("UpdateInboxes", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	inboxesb, err := json.Marshal(inboxes)
 	if err != nil {
 		return err
 	}
-	_, err = c.db.Exec("update contacts set inboxes=?, updated=? where id=?", inboxesb, time.Now().UnixNano(), id)
+	_, err = c.exec("update contacts set inboxes=?, updated=? where id=?", inboxesb, time.Now().UnixNano(), id)
+	return err
+}
+
+func (c *ContactDB) UpdateVerified(id string, verified bool) error {
+	defer c.observe("UpdateVerified", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("update contacts set verified=? where id=?", verified, id)
+	return err
+}
+
+func (c *ContactDB) UpdateEndpoint(id string, endpoint string) error {
+	defer c.observe("UpdateEndpoint", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("update contacts set endpoint=?, updated=? where id=?", endpoint, time.Now().UnixNano(), id)
+	return err
+}
+
+func (c *ContactDB) UpdateSignature(id string, signature []byte) error {
+	defer c.observe("UpdateSignature", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("update contacts set signature=? where id=?", signature, id)
 	return err
 }
 
 func (c *ContactDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from contacts where id=?", id)
+	_, err := c.exec("delete from contacts where id=?", id)
 	return err
 }
 
 func (c *ContactDB) handleQuery(stm string) []repo.Contact {
+	return c.handleRows(c.query(stm))
+}
+
+func (c *ContactDB) handleRows(rows *sql.Rows, err error) []repo.Contact {
 	var ret []repo.Contact
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
-		var id, address, username, avatar string
-		var inboxes []byte
+		var id, address, username, avatar, endpoint string
+		var inboxes, signature []byte
 		var createdInt, updatedInt int64
-		if err := rows.Scan(&id, &address, &username, &avatar, &inboxes, &createdInt, &updatedInt); err != nil {
+		var verifiedInt int
+		if err := rows.Scan(&id, &address, &username, &avatar, &inboxes, &createdInt, &updatedInt, &verifiedInt, &endpoint, &signature); err != nil {
 			log.Errorf("error in db scan: %s", err)
 			continue
 		}
@@ -195,14 +234,18 @@ func (c *ContactDB) handleQuery(stm string) []repo.Contact {
 		}
 
 		ret = append(ret, repo.Contact{
-			Id:       id,
-			Address:  address,
-			Username: username,
-			Avatar:   avatar,
-			Inboxes:  ilist,
-			Created:  time.Unix(0, createdInt),
-			Updated:  time.Unix(0, updatedInt),
+			Id:        id,
+			Address:   address,
+			Username:  username,
+			Avatar:    avatar,
+			Inboxes:   ilist,
+			Created:   time.Unix(0, createdInt),
+			Updated:   time.Unix(0, updatedInt),
+			Verified:  verifiedInt == 1,
+			Endpoint:  endpoint,
+			Signature: signature,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
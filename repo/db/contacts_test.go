@@ -8,7 +8,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 )
 
-var contactStore repo.ContactStore
+var contactStore *ContactDB
 
 var testContact *repo.Contact
 var testCafe = repo.Cafe{
@@ -27,7 +27,7 @@ func init() {
 func setupContactDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	contactStore = NewContactStore(conn, new(sync.Mutex))
+	contactStore = NewContactStore(conn, new(sync.Mutex)).(*ContactDB)
 }
 
 func TestContactDB_Add(t *testing.T) {
@@ -38,7 +38,7 @@ func TestContactDB_Add(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	stmt, err := contactStore.PrepareQuery("select id from contacts where id=?")
+	stmt, err := contactStore.db.Prepare("select id from contacts where id=?")
 	if err != nil {
 		t.Error(err)
 		return
@@ -69,7 +69,7 @@ func TestContactDB_AddOrUpdate(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	stmt, err := contactStore.PrepareQuery("select username, updated from contacts where id=?")
+	stmt, err := contactStore.db.Prepare("select username, updated from contacts where id=?")
 	if err != nil {
 		t.Error(err)
 		return
@@ -173,11 +173,69 @@ func TestContactDB_UpdateInboxes(t *testing.T) {
 	}
 }
 
+func TestContactDB_UpdateVerified(t *testing.T) {
+	if err := contactStore.UpdateVerified(testContact.Id, true); err != nil {
+		t.Error(err)
+		return
+	}
+	updated := contactStore.Get(testContact.Id)
+	if !updated.Verified {
+		t.Error("update verified failed")
+		return
+	}
+
+	// same address keeps verification
+	if err := contactStore.AddOrUpdate(updated); err != nil {
+		t.Error(err)
+		return
+	}
+	if !contactStore.Get(testContact.Id).Verified {
+		t.Error("verification was lost on update")
+		return
+	}
+
+	// new address drops verification
+	updated.Address = "address3"
+	if err := contactStore.AddOrUpdate(updated); err != nil {
+		t.Error(err)
+		return
+	}
+	if contactStore.Get(testContact.Id).Verified {
+		t.Error("verification was kept after address change")
+	}
+}
+
+func TestContactDB_UpdateEndpoint(t *testing.T) {
+	if err := contactStore.UpdateEndpoint(testContact.Id, "https://example.com/cafe/v0/service/threads"); err != nil {
+		t.Error(err)
+		return
+	}
+	updated := contactStore.Get(testContact.Id)
+	if updated.Endpoint != "https://example.com/cafe/v0/service/threads" {
+		t.Error("update endpoint failed")
+		return
+	}
+	if !updated.Updated.After(testContact.Updated) {
+		t.Error("update was not updated")
+	}
+}
+
+func TestContactDB_UpdateSignature(t *testing.T) {
+	if err := contactStore.UpdateSignature(testContact.Id, []byte("signature")); err != nil {
+		t.Error(err)
+		return
+	}
+	updated := contactStore.Get(testContact.Id)
+	if string(updated.Signature) != "signature" {
+		t.Error("update signature failed")
+	}
+}
+
 func TestContactDB_Delete(t *testing.T) {
 	if err := contactStore.Delete("abcde"); err != nil {
 		t.Error(err)
 	}
-	stmt, err := contactStore.PrepareQuery("select id from contacts where id=?")
+	stmt, err := contactStore.db.Prepare("select id from contacts where id=?")
 	if err != nil {
 		t.Error(err)
 	}
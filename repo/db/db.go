@@ -1,8 +1,12 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"net/url"
 	"path"
+	"strconv"
+	"strings"
 	"sync"
 
 	logging "gx/ipfs/QmZChCsSt8DctjceaL56Eibc29CVQq4dGKRXC5JRZ6Ppae/go-log"
@@ -13,6 +17,9 @@ import (
 
 var log = logging.Logger("tex-datastore")
 
+// busyTimeout is how long (ms) a connection waits on a locked database
+const busyTimeout = 5000
+
 type SQLiteDatastore struct {
 	config             repo.ConfigStore
 	contacts           repo.ContactStore
@@ -30,6 +37,8 @@ type SQLiteDatastore struct {
 	cafeClients        repo.CafeClientStore
 	cafeClientThreads  repo.CafeClientThreadStore
 	cafeClientMessages repo.CafeClientMessageStore
+	blockedPeers       repo.BlockedPeerStore
+	devices            repo.DeviceStore
 	db                 *sql.DB
 	lock               *sync.Mutex
 }
@@ -37,13 +46,13 @@ type SQLiteDatastore struct {
 func Create(repoPath, pin string) (*SQLiteDatastore, error) {
 	var dbPath string
 	dbPath = path.Join(repoPath, "datastore", "mainnet.db")
-	conn, err := sql.Open("sqlite3", dbPath)
+	conn, err := sql.Open("sqlite3", dsn(dbPath, pin))
 	if err != nil {
 		return nil, err
 	}
-	if pin != "" {
-		p := "pragma key='" + pin + "';"
-		conn.Exec(p)
+	// WAL lets readers run alongside the (single) writer
+	if _, err := conn.Exec("pragma journal_mode=WAL;"); err != nil {
+		return nil, err
 	}
 	mux := new(sync.Mutex)
 	sqliteDB := &SQLiteDatastore{
@@ -63,6 +72,8 @@ func Create(repoPath, pin string) (*SQLiteDatastore, error) {
 		cafeClients:        NewCafeClientStore(conn, mux),
 		cafeClientThreads:  NewCafeClientThreadStore(conn, mux),
 		cafeClientMessages: NewCafeClientMessageStore(conn, mux),
+		blockedPeers:       NewBlockedPeerStore(conn, mux),
+		devices:            NewDeviceStore(conn, mux),
 		db:                 conn,
 		lock:               mux,
 	}
@@ -70,6 +81,17 @@ func Create(repoPath, pin string) (*SQLiteDatastore, error) {
 	return sqliteDB, nil
 }
 
+// dsn returns the data source name for a datastore. The key and busy timeout
+// are part of the dsn so that they apply to every connection in the pool.
+func dsn(dbPath string, pin string) string {
+	params := url.Values{}
+	params.Set("_busy_timeout", strconv.Itoa(busyTimeout))
+	if pin != "" {
+		params.Set("_pragma_key", pin)
+	}
+	return dbPath + "?" + params.Encode()
+}
+
 func (d *SQLiteDatastore) Ping() error {
 	return d.db.Ping()
 }
@@ -142,6 +164,14 @@ func (d *SQLiteDatastore) CafeClientMessages() repo.CafeClientMessageStore {
 	return d.cafeClientMessages
 }
 
+func (d *SQLiteDatastore) BlockedPeers() repo.BlockedPeerStore {
+	return d.blockedPeers
+}
+
+func (d *SQLiteDatastore) Devices() repo.DeviceStore {
+	return d.devices
+}
+
 func (d *SQLiteDatastore) Copy(dbPath string, pin string) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -178,6 +208,32 @@ func (d *SQLiteDatastore) Copy(dbPath string, pin string) error {
 	return nil
 }
 
+// Export writes a consistent copy of the datastore to dbPath, encrypted with the same key.
+// Writers are blocked until the copy is done.
+func (d *SQLiteDatastore) Export(dbPath string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// attached databases are per-connection
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "attach database ? as export;", dbPath); err != nil {
+		log.Errorf("error in export: %s", err)
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "select sqlcipher_export('export');"); err != nil {
+		conn.ExecContext(ctx, "detach database export;")
+		log.Errorf("error in export: %s", err)
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "detach database export;")
+	return err
+}
+
 func (d *SQLiteDatastore) InitTables(pin string) error {
 	return initDatabaseTables(d.db, pin)
 }
@@ -185,12 +241,12 @@ func (d *SQLiteDatastore) InitTables(pin string) error {
 func initDatabaseTables(db *sql.DB, pin string) error {
 	var sqlStmt string
 	if pin != "" {
-		sqlStmt = "PRAGMA key = '" + pin + "';"
+		sqlStmt = "PRAGMA key = '" + strings.Replace(pin, "'", "''", -1) + "';"
 	}
 	sqlStmt += `
     create table config (key text primary key not null, value blob);
 
-    create table contacts (id text primary key not null, address text not null, username text not null, avatar text not null, inboxes blob not null, created integer not null, updated integer not null);
+    create table contacts (id text primary key not null, address text not null, username text not null, avatar text not null, inboxes blob not null, created integer not null, updated integer not null, verified integer not null default 0, endpoint text not null default '', signature blob);
     create index contact_address on contacts (address);
     create index contact_username on contacts (username);
     create index contact_updated on contacts (updated);
@@ -205,7 +261,7 @@ func initDatabaseTables(db *sql.DB, pin string) error {
     create table thread_invites (id text primary key not null, block blob not null, name text not null, contact blob not null, date integer not null);
     create index thread_invite_date on thread_invites (date);
 
-    create table thread_peers (id text not null, threadId text not null, welcomed integer not null, primary key (id, threadId));
+    create table thread_peers (id text not null, threadId text not null, welcomed integer not null, lastSeen integer not null default 0, addrs text not null default '', primary key (id, threadId));
     create index thread_peer_id on thread_peers (id);
     create index thread_peer_threadId on thread_peers (threadId);
     create index thread_peer_welcomed on thread_peers (welcomed);
@@ -247,6 +303,10 @@ func initDatabaseTables(db *sql.DB, pin string) error {
     create table cafe_client_messages (id text not null, peerId text not null, clientId text not null, date integer not null, primary key (id, clientId));
     create index cafe_client_message_clientId on cafe_client_messages (clientId);
     create index cafe_client_message_date on cafe_client_messages (date);
+
+    create table blocked_peers (id text primary key not null, muted integer not null, date integer not null);
+
+    create table devices (id text primary key not null, name text not null, pending integer not null, date integer not null);
     `
 	if _, err := db.Exec(sqlStmt); err != nil {
 		return err
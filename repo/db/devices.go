@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type DeviceDB struct {
+	modelStore
+}
+
+func NewDeviceStore(db *sql.DB, lock *sync.Mutex) repo.DeviceStore {
+	return &DeviceDB{newModelStore("devices", db, lock)}
+}
+
+func (c *DeviceDB) AddOrUpdate(device *repo.Device) error {
+	defer c.observe("AddOrUpdate", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	stm := `insert or replace into devices(id, name, pending, date) values(?,?,?,?)`
+	stmt, err := c.txPrepared(tx, stm)
+	if err != nil {
+		log.Errorf("error in tx prepare: %s", err)
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(
+		device.Id,
+		device.Name,
+		device.Pending,
+		device.Date.UnixNano(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (c *DeviceDB) Get(id string) *repo.Device {
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from devices where id=?;", id))
+	if len(ret) == 0 {
+		return nil
+	}
+	return &ret[0]
+}
+
+func (c *DeviceDB) List(query string) []repo.Device {
+	defer c.observe("List", time.Now())
+	var q string
+	if query != "" {
+		q = "where " + query + " "
+	}
+	return c.handleQuery("select * from devices " + q + "order by date asc;")
+}
+
+func (c *DeviceDB) Count(query string) int {
+	defer c.observe("Count", time.Now())
+	var q string
+	if query != "" {
+		q = " where " + query
+	}
+	row := c.db.QueryRow("select Count(*) from devices" + q + ";")
+	var count int
+	row.Scan(&count)
+	return count
+}
+
+func (c *DeviceDB) Delete(id string) error {
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("delete from devices where id=?", id)
+	return err
+}
+
+func (c *DeviceDB) handleQuery(stm string) []repo.Device {
+	return c.handleRows(c.query(stm))
+}
+
+func (c *DeviceDB) handleRows(rows *sql.Rows, err error) []repo.Device {
+	var ret []repo.Device
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, name string
+		var pendingInt int
+		var dateInt int64
+		if err := rows.Scan(&id, &name, &pendingInt, &dateInt); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.Device{
+			Id:      id,
+			Name:    name,
+			Pending: pendingInt == 1,
+			Date:    time.Unix(0, dateInt),
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+var deviceStore *DeviceDB
+
+func init() {
+	setupDeviceDB()
+}
+
+func setupDeviceDB() {
+	conn, _ := sql.Open("sqlite3", ":memory:")
+	initDatabaseTables(conn, "")
+	deviceStore = NewDeviceStore(conn, new(sync.Mutex)).(*DeviceDB)
+}
+
+func TestDeviceDB_AddOrUpdate(t *testing.T) {
+	if err := deviceStore.AddOrUpdate(&repo.Device{
+		Id:      "abcde",
+		Name:    "laptop",
+		Pending: true,
+		Date:    time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	stmt, err := deviceStore.db.Prepare("select id from devices where id=?")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer stmt.Close()
+	var id string
+	if err := stmt.QueryRow("abcde").Scan(&id); err != nil {
+		t.Error(err)
+		return
+	}
+	if id != "abcde" {
+		t.Errorf(`expected "abcde" got %s`, id)
+	}
+}
+
+func TestDeviceDB_Get(t *testing.T) {
+	device := deviceStore.Get("abcde")
+	if device == nil {
+		t.Error("could not get device")
+		return
+	}
+	if !device.Pending {
+		t.Error("device should be pending")
+	}
+}
+
+func TestDeviceDB_Approve(t *testing.T) {
+	if err := deviceStore.AddOrUpdate(&repo.Device{
+		Id:   "abcde",
+		Name: "laptop",
+		Date: time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	device := deviceStore.Get("abcde")
+	if device == nil || device.Pending {
+		t.Error("device should be approved")
+	}
+}
+
+func TestDeviceDB_Count(t *testing.T) {
+	if err := deviceStore.AddOrUpdate(&repo.Device{
+		Id:      "fghij",
+		Pending: true,
+		Date:    time.Now(),
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+	if cnt := deviceStore.Count(""); cnt != 2 {
+		t.Errorf("expected 2 devices, got %d", cnt)
+	}
+	if cnt := deviceStore.Count("pending=0"); cnt != 1 {
+		t.Errorf("expected 1 approved device, got %d", cnt)
+	}
+}
+
+func TestDeviceDB_List(t *testing.T) {
+	list := deviceStore.List("pending=1")
+	if len(list) != 1 {
+		t.Error("wrong number of pending devices")
+		return
+	}
+	if list[0].Id != "fghij" {
+		t.Error("wrong pending device")
+	}
+}
+
+func TestDeviceDB_Delete(t *testing.T) {
+	if err := deviceStore.Delete("abcde"); err != nil {
+		t.Error(err)
+		return
+	}
+	if deviceStore.Get("abcde") != nil {
+		t.Error("delete failed")
+	}
+}
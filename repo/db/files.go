@@ -16,18 +16,19 @@ type FileDB struct {
 }
 
 func NewFileStore(db *sql.DB, lock *sync.Mutex) repo.FileStore {
-	return &FileDB{modelStore{db, lock}}
+	return &FileDB{newModelStore("files", db, lock)}
 }
 
 func (c *FileDB) Add(file *repo.File) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into files(mill, checksum, source, opts, hash, key, media, name, size, added, meta, targets) values(?,?,?,?,?,?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -72,9 +73,8 @@ func (c *FileDB) Add(file *repo.File) error {
 }
 
 func (c *FileDB) Get(hash string) *repo.File {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from files where hash='" + hash + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from files where hash=?;", hash))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -82,9 +82,8 @@ func (c *FileDB) Get(hash string) *repo.File {
 }
 
 func (c *FileDB) GetByPrimary(mill string, checksum string) *repo.File {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from files where mill='" + mill + "' and checksum='" + checksum + "';")
+	defer c.observe("GetByPrimary", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from files where mill=? and checksum=?;", mill, checksum))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -92,9 +91,8 @@ func (c *FileDB) GetByPrimary(mill string, checksum string) *repo.File {
 }
 
 func (c *FileDB) GetBySource(mill string, source string, opts string) *repo.File {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from files where mill='" + mill + "' and source='" + source + "' and opts='" + opts + "';")
+	defer c.observe("GetBySource", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from files where mill=? and source=? and opts=?;", mill, source, opts))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -102,10 +100,11 @@ func (c *FileDB) GetBySource(mill string, source string, opts string) *repo.File
 }
 
 func (c *FileDB) AddTarget(hash string, target string) error {
-	c.lock.Lock()
+	defer c.observe("AddTarget", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 
-	res := c.handleTargetsQuery("select targets from files where hash='" + hash + "';")
+	res := c.handleTargetsQuery("select targets from files where hash=?;", hash)
 	if len(res) == 0 {
 		return errors.New("file not found")
 	}
@@ -118,15 +117,16 @@ func (c *FileDB) AddTarget(hash string, target string) error {
 	etargets = append(etargets, target)
 	targets := strings.Join(etargets, ",")
 
-	_, err := c.db.Exec("update files set targets=? where hash=?", targets, hash)
+	_, err := c.exec("update files set targets=? where hash=?", targets, hash)
 	return err
 }
 
 func (c *FileDB) RemoveTarget(hash string, target string) error {
-	c.lock.Lock()
+	defer c.observe("RemoveTarget", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 
-	res := c.handleTargetsQuery("select targets from files where hash='" + hash + "';")
+	res := c.handleTargetsQuery("select targets from files where hash=?;", hash)
 	if len(res) == 0 {
 		return errors.New("file not found")
 	}
@@ -149,33 +149,38 @@ func (c *FileDB) RemoveTarget(hash string, target string) error {
 		targets = &tmp
 	}
 
-	_, err := c.db.Exec("update files set targets=? where hash=?", targets, hash)
+	_, err := c.exec("update files set targets=? where hash=?", targets, hash)
 	return err
 }
 
+func (c *FileDB) List() []repo.File {
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from files order by added desc;"))
+}
+
 func (c *FileDB) Count() int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from files;")
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from files;")
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *FileDB) Delete(hash string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from files where hash=?", hash)
+	_, err := c.exec("delete from files where hash=?", hash)
 	return err
 }
 
-func (c *FileDB) handleQuery(stm string) []repo.File {
+func (c *FileDB) handleRows(rows *sql.Rows, err error) []repo.File {
 	var res []repo.File
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var mill, checksum, source, opts, hash, key, media, name string
 		var size int
@@ -221,16 +226,18 @@ func (c *FileDB) handleQuery(stm string) []repo.File {
 		})
 	}
 
+	c.observeRows(len(res))
 	return res
 }
 
-func (c *FileDB) handleTargetsQuery(stm string) [][]string {
+func (c *FileDB) handleTargetsQuery(stm string, args ...interface{}) [][]string {
 	var res [][]string
-	rows, err := c.db.Query(stm)
+	rows, err := c.queryStatic(stm, args...)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var targets *string
 
@@ -251,6 +258,7 @@ func (c *FileDB) handleTargetsQuery(stm string) [][]string {
 		res = append(res, tlist)
 	}
 
+	c.observeRows(len(res))
 	return res
 }
 
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/textileio/textile-go/metrics"
+)
+
+var (
+	callSeconds = metrics.NewSummary(
+		"textile_datastore_call_seconds",
+		"Time spent in datastore store methods.",
+		"store", "method")
+	rowsTotal = metrics.NewCounter(
+		"textile_datastore_rows_total",
+		"Rows returned by datastore queries.",
+		"store")
+	lockWaitSeconds = metrics.NewSummary(
+		"textile_datastore_lock_wait_seconds",
+		"Time spent waiting on the datastore write lock.",
+		"store")
+)
+
+// slowQueryThreshold is stored as nanoseconds, zero disables slow query logging
+var slowQueryThreshold int64
+
+// SetSlowQueryThreshold sets the duration over which store calls and queries are logged
+func SetSlowQueryThreshold(d time.Duration) {
+	atomic.StoreInt64(&slowQueryThreshold, int64(d))
+}
+
+// slow returns whether or not a duration is over the slow query threshold
+func slow(d time.Duration) bool {
+	threshold := atomic.LoadInt64(&slowQueryThreshold)
+	return threshold > 0 && int64(d) > threshold
+}
+
+// ObserveCall records the latency of a store method started at start, logging it if slow.
+// Other datastore backends use it so that all stores report the same metrics.
+func ObserveCall(store string, method string, start time.Time) {
+	elapsed := time.Since(start)
+	callSeconds.Observe(elapsed.Seconds(), store, method)
+	if slow(elapsed) {
+		log.Warningf("slow datastore call %s.%s took %s", store, method, elapsed)
+	}
+}
+
+// ObserveRows records the number of rows returned by a query on store
+func ObserveRows(store string, n int) {
+	rowsTotal.Add(float64(n), store)
+}
+
+// ObserveQuery logs a query on store started at start if slow
+func ObserveQuery(store string, stm string, start time.Time) {
+	if elapsed := time.Since(start); slow(elapsed) {
+		log.Warningf("slow datastore query on %s took %s: %s", store, elapsed, stm)
+	}
+}
+
+// observe records the latency of a store method started at start.
+// Usage: defer c.observe("Method", time.Now())
+func (m *modelStore) observe(method string, start time.Time) {
+	ObserveCall(m.name, method, start)
+}
+
+// observeRows records the number of rows returned by a query
+func (m *modelStore) observeRows(n int) {
+	ObserveRows(m.name, n)
+}
+
+// lockWrite acquires the shared write lock, recording the time spent waiting
+func (m *modelStore) lockWrite() {
+	start := time.Now()
+	m.lock.Lock()
+	lockWaitSeconds.Observe(time.Since(start).Seconds(), m.name)
+}
+
+// query runs a dynamic query, logging it if slow
+func (m *modelStore) query(stm string) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := m.db.Query(stm)
+	ObserveQuery(m.name, stm, start)
+	return rows, err
+}
+
+// queryStatic runs a static query through the statement cache, logging it if slow
+func (m *modelStore) queryStatic(stm string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	stmt, err := m.prepared(stm)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(args...)
+	ObserveQuery(m.name, stm, start)
+	return rows, err
+}
@@ -5,9 +5,17 @@ import (
 	"sync"
 )
 
+// modelStore is embedded by each store. Reads go straight to the connection pool,
+// which WAL mode allows to run alongside a writer. lock only serializes writers.
 type modelStore struct {
-	db   *sql.DB
-	lock *sync.Mutex
+	name  string
+	db    *sql.DB
+	lock  *sync.Mutex
+	stmts *stmtCache
+}
+
+func newModelStore(name string, db *sql.DB, lock *sync.Mutex) modelStore {
+	return modelStore{name: name, db: db, lock: lock, stmts: newStmtCache(db)}
 }
 
 // BeginTransaction returns a *sql.Tx for transactional query support
@@ -15,17 +23,61 @@ func (m *modelStore) BeginTransaction() (*sql.Tx, error) {
 	return m.db.Begin()
 }
 
-// PrepareQuery returns a *sql.Stmt to the wrapped DB
-func (m *modelStore) PrepareQuery(query string) (*sql.Stmt, error) {
-	return m.db.Prepare(query)
+// prepared returns a cached statement for a static query
+func (m *modelStore) prepared(query string) (*sql.Stmt, error) {
+	return m.stmts.get(query)
+}
+
+// txPrepared returns a transaction-specific version of a cached statement
+func (m *modelStore) txPrepared(tx *sql.Tx, query string) (*sql.Stmt, error) {
+	stmt, err := m.prepared(query)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Stmt(stmt), nil
+}
+
+// exec runs a static statement without returning rows
+func (m *modelStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := m.prepared(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+// queryRow runs a static query expected to return at most one row
+func (m *modelStore) queryRow(query string, args ...interface{}) *sql.Row {
+	stmt, err := m.prepared(query)
+	if err != nil {
+		// let the connection surface the prepare error on scan
+		return m.db.QueryRow(query, args...)
+	}
+	return stmt.QueryRow(args...)
+}
+
+// stmtCache holds prepared statements for reuse across calls.
+// Only static queries (with ? placeholders) should be cached.
+type stmtCache struct {
+	db    *sql.DB
+	stmts map[string]*sql.Stmt
+	mux   sync.Mutex
 }
 
-// PrepareAndExecuteQuery returns the resulting *sql.Rows for the executed query
-func (m *modelStore) PrepareAndExecuteQuery(query string, args ...interface{}) (*sql.Rows, error) {
-	return m.db.Query(query, args...)
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{db: db, stmts: make(map[string]*sql.Stmt)}
 }
 
-// ExecuteQuery returns the *sql.Result for the executed query without returning Rows
-func (m *modelStore) ExecuteQuery(query string, args ...interface{}) (sql.Result, error) {
-	return m.db.Exec(query, args...)
+func (c *stmtCache) get(query string) (*sql.Stmt, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := c.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
 }
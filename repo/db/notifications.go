@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"strconv"
 	"sync"
 	"time"
 
@@ -14,18 +13,19 @@ type NotificationDB struct {
 }
 
 func NewNotificationStore(db *sql.DB, lock *sync.Mutex) repo.NotificationStore {
-	return &NotificationDB{modelStore{db, lock}}
+	return &NotificationDB{newModelStore("notifications", db, lock)}
 }
 
 func (c *NotificationDB) Add(notification *repo.Notification) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into notifications(id, date, actorId, subject, subjectId, blockId, target, type, body, read) values(?,?,?,?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -52,9 +52,8 @@ func (c *NotificationDB) Add(notification *repo.Notification) error {
 }
 
 func (c *NotificationDB) Get(id string) *repo.Notification {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from notifications where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from notifications where id=?;", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -62,75 +61,76 @@ func (c *NotificationDB) Get(id string) *repo.Notification {
 }
 
 func (c *NotificationDB) Read(id string) error {
-	c.lock.Lock()
+	defer c.observe("Read", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update notifications set read=1 where id=?", id)
+	_, err := c.exec("update notifications set read=1 where id=?", id)
 	return err
 }
 
 func (c *NotificationDB) ReadAll() error {
-	c.lock.Lock()
+	defer c.observe("ReadAll", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update notifications set read=1")
+	_, err := c.exec("update notifications set read=1")
 	return err
 }
 
 func (c *NotificationDB) List(offset string, limit int) []repo.Notification {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	var stm string
+	defer c.observe("List", time.Now())
 	if offset != "" {
-		stm = "select * from notifications where date<(select date from notifications where id='" + offset + "') order by date desc limit " + strconv.Itoa(limit) + ";"
-	} else {
-		stm = "select * from notifications order by date desc limit " + strconv.Itoa(limit) + ";"
+		return c.handleRows(c.queryStatic("select * from notifications where date<(select date from notifications where id=?) order by date desc limit ?;", offset, limit))
 	}
-	return c.handleQuery(stm)
+	return c.handleRows(c.queryStatic("select * from notifications order by date desc limit ?;", limit))
 }
 
 func (c *NotificationDB) CountUnread() int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from notifications where read=0;")
+	defer c.observe("CountUnread", time.Now())
+	row := c.queryRow("select Count(*) from notifications where read=0;")
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *NotificationDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from notifications where id=?", id)
+	_, err := c.exec("delete from notifications where id=?", id)
 	return err
 }
 
 func (c *NotificationDB) DeleteByActor(actorId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByActor", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from notifications where actorId=?", actorId)
+	_, err := c.exec("delete from notifications where actorId=?", actorId)
 	return err
 }
 
 func (c *NotificationDB) DeleteBySubject(subjectId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteBySubject", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from notifications where subjectId=?", subjectId)
+	_, err := c.exec("delete from notifications where subjectId=?", subjectId)
 	return err
 }
 
 func (c *NotificationDB) DeleteByBlock(blockId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByBlock", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from notifications where blockId=?", blockId)
+	_, err := c.exec("delete from notifications where blockId=?", blockId)
 	return err
 }
 
-func (c *NotificationDB) handleQuery(stm string) []repo.Notification {
+func (c *NotificationDB) handleRows(rows *sql.Rows, err error) []repo.Notification {
 	var ret []repo.Notification
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, actorId, subject, subjectId, blockId, target, body string
 		var dateInt int64
@@ -156,5 +156,6 @@ func (c *NotificationDB) handleQuery(stm string) []repo.Notification {
 			Read:      read,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
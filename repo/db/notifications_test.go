@@ -10,7 +10,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 )
 
-var notificationStore repo.NotificationStore
+var notificationStore *NotificationDB
 
 func init() {
 	setupNotificationDB()
@@ -19,7 +19,7 @@ func init() {
 func setupNotificationDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	notificationStore = NewNotificationStore(conn, new(sync.Mutex))
+	notificationStore = NewNotificationStore(conn, new(sync.Mutex)).(*NotificationDB)
 }
 
 func TestNotificationDB_Add(t *testing.T) {
@@ -35,7 +35,7 @@ func TestNotificationDB_Add(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := notificationStore.PrepareQuery("select id from notifications where id=?")
+	stmt, err := notificationStore.db.Prepare("select id from notifications where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abcde").Scan(&id)
@@ -183,7 +183,7 @@ func TestNotificationDB_Delete(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := notificationStore.PrepareQuery("select id from notifications where id=?")
+	stmt, err := notificationStore.db.Prepare("select id from notifications where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abc").Scan(&id)
@@ -197,7 +197,7 @@ func TestNotificationDB_DeleteByActor(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := notificationStore.PrepareQuery("select id from notifications where id=?")
+	stmt, err := notificationStore.db.Prepare("select id from notifications where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("def").Scan(&id)
@@ -211,7 +211,7 @@ func TestNotificationDB_DeleteBySubject(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := notificationStore.PrepareQuery("select id from notifications where id=?")
+	stmt, err := notificationStore.db.Prepare("select id from notifications where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("jkl").Scan(&id)
@@ -225,7 +225,7 @@ func TestNotificationDB_DeleteByBlock(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := notificationStore.PrepareQuery("select id from notifications where id=?")
+	stmt, err := notificationStore.db.Prepare("select id from notifications where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("ghi").Scan(&id)
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type CafeClientMessageDB struct {
+	modelStore
+}
+
+func NewCafeClientMessageStore(db *sql.DB) repo.CafeClientMessageStore {
+	return &CafeClientMessageDB{newModelStore("cafe_client_messages", db)}
+}
+
+func (c *CafeClientMessageDB) AddOrUpdate(message *repo.CafeClientMessage) error {
+	defer c.observe("AddOrUpdate", time.Now())
+	_, err := c.db.Exec(`
+        insert into cafe_client_messages(id, peerId, clientId, date) values($1,$2,$3,$4)
+        on conflict (id, clientId) do update set peerId=excluded.peerId, date=excluded.date`,
+		message.Id,
+		message.PeerId,
+		message.ClientId,
+		message.Date.UnixNano(),
+	)
+	return err
+}
+
+func (c *CafeClientMessageDB) ListByClient(clientId string, limit int) []repo.CafeClientMessage {
+	defer c.observe("ListByClient", time.Now())
+	return c.handleQuery("select id, peerId, clientId, date from cafe_client_messages where clientId=$1 order by date asc limit $2", clientId, limit)
+}
+
+func (c *CafeClientMessageDB) CountByClient(clientId string) int {
+	defer c.observe("CountByClient", time.Now())
+	row := c.db.QueryRow("select count(*) from cafe_client_messages where clientId=$1", clientId)
+	var count int
+	row.Scan(&count)
+	return count
+}
+
+func (c *CafeClientMessageDB) Delete(id string, clientId string) error {
+	defer c.observe("Delete", time.Now())
+	_, err := c.db.Exec("delete from cafe_client_messages where id=$1 and clientId=$2", id, clientId)
+	return err
+}
+
+func (c *CafeClientMessageDB) DeleteByClient(clientId string, limit int) error {
+	defer c.observe("DeleteByClient", time.Now())
+	_, err := c.db.Exec(`
+        delete from cafe_client_messages where clientId=$1 and id in (
+            select id from cafe_client_messages where clientId=$1 order by date asc limit $2
+        )`, clientId, limit)
+	return err
+}
+
+func (c *CafeClientMessageDB) handleQuery(stm string, args ...interface{}) []repo.CafeClientMessage {
+	var ret []repo.CafeClientMessage
+	rows, err := c.query(stm, args...)
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, peerId, clientId string
+		var dateInt int64
+		if err := rows.Scan(&id, &peerId, &clientId, &dateInt); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.CafeClientMessage{
+			Id:       id,
+			PeerId:   peerId,
+			ClientId: clientId,
+			Date:     time.Unix(0, dateInt),
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+func TestCafeClientMessageDB_DeleteByClient(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientMessageStore(conn)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := store.AddOrUpdate(&repo.CafeClientMessage{
+			Id:       "msg" + strconv.Itoa(i),
+			PeerId:   "peer",
+			ClientId: "client",
+			Date:     now.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.CountByClient("client") != 5 {
+		t.Fatal("count was not 5")
+	}
+	if err := store.DeleteByClient("client", 2); err != nil {
+		t.Fatal(err)
+	}
+	list := store.ListByClient("client", 10)
+	if len(list) != 3 || list[0].Id != "msg2" {
+		t.Error("delete by client did not remove the oldest messages")
+	}
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type CafeClientNonceDB struct {
+	modelStore
+}
+
+func NewCafeClientNonceStore(db *sql.DB) repo.CafeClientNonceStore {
+	return &CafeClientNonceDB{newModelStore("cafe_client_nonces", db)}
+}
+
+func (c *CafeClientNonceDB) Add(nonce *repo.CafeClientNonce) error {
+	defer c.observe("Add", time.Now())
+	_, err := c.db.Exec(
+		"insert into cafe_client_nonces(value, address, date) values($1,$2,$3)",
+		nonce.Value,
+		nonce.Address,
+		nonce.Date.UnixNano(),
+	)
+	return err
+}
+
+func (c *CafeClientNonceDB) Get(value string) *repo.CafeClientNonce {
+	defer c.observe("Get", time.Now())
+	ret := c.handleQuery("select value, address, date from cafe_client_nonces where value=$1", value)
+	if len(ret) == 0 {
+		return nil
+	}
+	return &ret[0]
+}
+
+func (c *CafeClientNonceDB) Delete(value string) error {
+	defer c.observe("Delete", time.Now())
+	_, err := c.db.Exec("delete from cafe_client_nonces where value=$1", value)
+	return err
+}
+
+func (c *CafeClientNonceDB) handleQuery(stm string, args ...interface{}) []repo.CafeClientNonce {
+	var ret []repo.CafeClientNonce
+	rows, err := c.query(stm, args...)
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value, address string
+		var dateInt int64
+		if err := rows.Scan(&value, &address, &dateInt); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.CafeClientNonce{
+			Value:   value,
+			Address: address,
+			Date:    time.Unix(0, dateInt),
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+func TestCafeClientNonceDB_AddGetDelete(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientNonceStore(conn)
+
+	if err := store.Add(&repo.CafeClientNonce{
+		Value:   "nonce",
+		Address: "address",
+		Date:    time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nonce := store.Get("nonce")
+	if nonce == nil || nonce.Address != "address" {
+		t.Fatal("could not get nonce")
+	}
+	if err := store.Delete("nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Get("nonce") != nil {
+		t.Error("delete failed")
+	}
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type CafeClientThreadDB struct {
+	modelStore
+}
+
+func NewCafeClientThreadStore(db *sql.DB) repo.CafeClientThreadStore {
+	return &CafeClientThreadDB{newModelStore("cafe_client_threads", db)}
+}
+
+func (c *CafeClientThreadDB) AddOrUpdate(thrd *repo.CafeClientThread) error {
+	defer c.observe("AddOrUpdate", time.Now())
+	_, err := c.db.Exec(`
+        insert into cafe_client_threads(id, clientId, ciphertext) values($1,$2,$3)
+        on conflict (id, clientId) do update set ciphertext=excluded.ciphertext`,
+		thrd.Id,
+		thrd.ClientId,
+		thrd.Ciphertext,
+	)
+	return err
+}
+
+func (c *CafeClientThreadDB) ListByClient(clientId string) []repo.CafeClientThread {
+	defer c.observe("ListByClient", time.Now())
+	return c.handleQuery("select id, clientId, ciphertext from cafe_client_threads where clientId=$1", clientId)
+}
+
+func (c *CafeClientThreadDB) Delete(id string, clientId string) error {
+	defer c.observe("Delete", time.Now())
+	_, err := c.db.Exec("delete from cafe_client_threads where id=$1 and clientId=$2", id, clientId)
+	return err
+}
+
+func (c *CafeClientThreadDB) DeleteByClient(clientId string) error {
+	defer c.observe("DeleteByClient", time.Now())
+	_, err := c.db.Exec("delete from cafe_client_threads where clientId=$1", clientId)
+	return err
+}
+
+func (c *CafeClientThreadDB) handleQuery(stm string, args ...interface{}) []repo.CafeClientThread {
+	var ret []repo.CafeClientThread
+	rows, err := c.query(stm, args...)
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, clientId string
+		var ciphertext []byte
+		if err := rows.Scan(&id, &clientId, &ciphertext); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.CafeClientThread{
+			Id:         id,
+			ClientId:   clientId,
+			Ciphertext: ciphertext,
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
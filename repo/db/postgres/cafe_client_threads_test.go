@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+func TestCafeClientThreadDB_AddOrUpdate(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientThreadStore(conn)
+
+	for _, ciphertext := range [][]byte{[]byte("old"), []byte("new")} {
+		if err := store.AddOrUpdate(&repo.CafeClientThread{
+			Id:         "abcde",
+			ClientId:   "client",
+			Ciphertext: ciphertext,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := store.ListByClient("client")
+	if len(list) != 1 || !bytes.Equal(list[0].Ciphertext, []byte("new")) {
+		t.Error("add or update failed")
+	}
+	if err := store.DeleteByClient("client"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.ListByClient("client")) != 0 {
+		t.Error("delete by client failed")
+	}
+}
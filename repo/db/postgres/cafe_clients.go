@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+type CafeClientDB struct {
+	modelStore
+}
+
+func NewCafeClientStore(db *sql.DB) repo.CafeClientStore {
+	return &CafeClientDB{newModelStore("cafe_clients", db)}
+}
+
+func (c *CafeClientDB) Add(client *repo.CafeClient) error {
+	defer c.observe("Add", time.Now())
+	_, err := c.db.Exec(
+		"insert into cafe_clients(id, address, created, lastSeen) values($1,$2,$3,$4)",
+		client.Id,
+		client.Address,
+		client.Created.UnixNano(),
+		client.LastSeen.UnixNano(),
+	)
+	return err
+}
+
+func (c *CafeClientDB) Get(id string) *repo.CafeClient {
+	defer c.observe("Get", time.Now())
+	ret := c.handleQuery("select id, address, created, lastSeen from cafe_clients where id=$1", id)
+	if len(ret) == 0 {
+		return nil
+	}
+	return &ret[0]
+}
+
+func (c *CafeClientDB) Count() int {
+	defer c.observe("Count", time.Now())
+	row := c.db.QueryRow("select count(*) from cafe_clients")
+	var count int
+	row.Scan(&count)
+	return count
+}
+
+func (c *CafeClientDB) List() []repo.CafeClient {
+	defer c.observe("List", time.Now())
+	return c.handleQuery("select id, address, created, lastSeen from cafe_clients order by lastSeen desc")
+}
+
+func (c *CafeClientDB) ListByAddress(address string) []repo.CafeClient {
+	defer c.observe("ListByAddress", time.Now())
+	return c.handleQuery("select id, address, created, lastSeen from cafe_clients where address=$1 order by lastSeen desc", address)
+}
+
+func (c *CafeClientDB) UpdateLastSeen(id string, date time.Time) error {
+	defer c.observe("UpdateLastSeen", time.Now())
+	_, err := c.db.Exec("update cafe_clients set lastSeen=$1 where id=$2", date.UnixNano(), id)
+	return err
+}
+
+func (c *CafeClientDB) Delete(id string) error {
+	defer c.observe("Delete", time.Now())
+	_, err := c.db.Exec("delete from cafe_clients where id=$1", id)
+	return err
+}
+
+func (c *CafeClientDB) handleQuery(stm string, args ...interface{}) []repo.CafeClient {
+	var ret []repo.CafeClient
+	rows, err := c.query(stm, args...)
+	if err != nil {
+		log.Errorf("error in db query: %s", err)
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, address string
+		var createdInt, lastSeenInt int64
+		if err := rows.Scan(&id, &address, &createdInt, &lastSeenInt); err != nil {
+			log.Errorf("error in db scan: %s", err)
+			continue
+		}
+		ret = append(ret, repo.CafeClient{
+			Id:       id,
+			Address:  address,
+			Created:  time.Unix(0, createdInt),
+			LastSeen: time.Unix(0, lastSeenInt),
+		})
+	}
+	c.observeRows(len(ret))
+	return ret
+}
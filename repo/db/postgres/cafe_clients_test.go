@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/repo"
+)
+
+func TestCafeClientDB_Add(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientStore(conn)
+
+	client := &repo.CafeClient{
+		Id:       "abcde",
+		Address:  "address",
+		Created:  time.Now(),
+		LastSeen: time.Now(),
+	}
+	if err := store.Add(client); err != nil {
+		t.Fatal(err)
+	}
+	err := store.Add(client)
+	if err == nil || !repo.ConflictError(err) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestCafeClientDB_List(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientStore(conn)
+
+	for i, id := range []string{"abcde", "fghij"} {
+		if err := store.Add(&repo.CafeClient{
+			Id:       id,
+			Address:  "address",
+			Created:  time.Now(),
+			LastSeen: time.Now().Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.Count() != 2 {
+		t.Error("count was not 2")
+	}
+	list := store.ListByAddress("address")
+	if len(list) != 2 || list[0].Id != "fghij" {
+		t.Error("list by address has wrong length or order")
+	}
+}
+
+func TestCafeClientDB_UpdateLastSeen(t *testing.T) {
+	conn := setupDB(t)
+	defer conn.Close()
+	store := NewCafeClientStore(conn)
+
+	if err := store.Add(&repo.CafeClient{
+		Id:       "abcde",
+		Address:  "address",
+		Created:  time.Now(),
+		LastSeen: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	seen := time.Now().Add(time.Hour)
+	if err := store.UpdateLastSeen("abcde", seen); err != nil {
+		t.Fatal(err)
+	}
+	client := store.Get("abcde")
+	if client == nil || client.LastSeen.UnixNano() != seen.UnixNano() {
+		t.Error("update last seen failed")
+	}
+	if err := store.Delete("abcde"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Get("abcde") != nil {
+		t.Error("delete failed")
+	}
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	repodb "github.com/textileio/textile-go/repo/db"
+)
+
+// modelStore records the same per-call metrics and slow queries as the local datastore stores
+type modelStore struct {
+	name string
+	db   *sql.DB
+}
+
+func newModelStore(name string, db *sql.DB) modelStore {
+	return modelStore{name: name, db: db}
+}
+
+// observe records the latency of a store method started at start.
+// Usage: defer c.observe("Method", time.Now())
+func (m *modelStore) observe(method string, start time.Time) {
+	repodb.ObserveCall(m.name, method, start)
+}
+
+// observeRows records the number of rows returned by a query
+func (m *modelStore) observeRows(n int) {
+	repodb.ObserveRows(m.name, n)
+}
+
+// query runs a query, logging it if slow
+func (m *modelStore) query(stm string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := m.db.Query(stm, args...)
+	repodb.ObserveQuery(m.name, stm, start)
+	return rows, err
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+
+	logging "gx/ipfs/QmZChCsSt8DctjceaL56Eibc29CVQq4dGKRXC5JRZ6Ppae/go-log"
+
+	_ "github.com/lib/pq"
+	"github.com/textileio/textile-go/repo"
+)
+
+var log = logging.Logger("tex-datastore")
+
+// Datastore keeps cafe host-side data in postgres, deferring everything else
+// to a wrapped (local) datastore
+type Datastore struct {
+	repo.Datastore
+	cafeClientNonces   repo.CafeClientNonceStore
+	cafeClients        repo.CafeClientStore
+	cafeClientThreads  repo.CafeClientThreadStore
+	cafeClientMessages repo.CafeClientMessageStore
+	db                 *sql.DB
+}
+
+// Create opens a postgres connection and wraps local
+func Create(url string, local repo.Datastore) (*Datastore, error) {
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := initDatabaseTables(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Datastore{
+		Datastore:          local,
+		cafeClientNonces:   NewCafeClientNonceStore(conn),
+		cafeClients:        NewCafeClientStore(conn),
+		cafeClientThreads:  NewCafeClientThreadStore(conn),
+		cafeClientMessages: NewCafeClientMessageStore(conn),
+		db:                 conn,
+	}, nil
+}
+
+func (d *Datastore) Ping() error {
+	if err := d.Datastore.Ping(); err != nil {
+		return err
+	}
+	return d.db.Ping()
+}
+
+func (d *Datastore) Close() {
+	d.Datastore.Close()
+	d.db.Close()
+}
+
+func (d *Datastore) CafeClientNonces() repo.CafeClientNonceStore {
+	return d.cafeClientNonces
+}
+
+func (d *Datastore) CafeClients() repo.CafeClientStore {
+	return d.cafeClients
+}
+
+func (d *Datastore) CafeClientThreads() repo.CafeClientThreadStore {
+	return d.cafeClientThreads
+}
+
+func (d *Datastore) CafeClientMessages() repo.CafeClientMessageStore {
+	return d.cafeClientMessages
+}
+
+func initDatabaseTables(db *sql.DB) error {
+	sqlStmt := `
+    create table if not exists cafe_client_nonces (value text primary key not null, address text not null, date bigint not null);
+
+    create table if not exists cafe_clients (id text primary key not null, address text not null, created bigint not null, lastSeen bigint not null);
+    create index if not exists cafe_client_address on cafe_clients (address);
+    create index if not exists cafe_client_lastSeen on cafe_clients (lastSeen);
+
+    create table if not exists cafe_client_threads (id text not null, clientId text not null, ciphertext bytea not null, primary key (id, clientId));
+    create index if not exists cafe_client_thread_clientId on cafe_client_threads (clientId);
+
+    create table if not exists cafe_client_messages (id text not null, peerId text not null, clientId text not null, date bigint not null, primary key (id, clientId));
+    create index if not exists cafe_client_message_clientId on cafe_client_messages (clientId);
+    create index if not exists cafe_client_message_date on cafe_client_messages (date);
+    `
+	_, err := db.Exec(sqlStmt)
+	return err
+}
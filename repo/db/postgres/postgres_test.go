@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// testURL points the tests at a local postgres instance, e.g.,
+// TEXTILE_POSTGRES_URL=postgres://postgres@localhost/textile_test?sslmode=disable
+var testURL = os.Getenv("TEXTILE_POSTGRES_URL")
+
+// setupDB opens a clean database, skipping the test if postgres is not configured
+func setupDB(t *testing.T) *sql.DB {
+	if testURL == "" {
+		t.Skip("TEXTILE_POSTGRES_URL not set")
+	}
+	conn, err := sql.Open("postgres", testURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(`drop table if exists cafe_client_nonces, cafe_clients, cafe_client_threads, cafe_client_messages`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := initDatabaseTables(conn); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path"
+	"sync"
+)
+
+// ErrInvalidPin indicates the datastore could not be opened with a pin code
+var ErrInvalidPin = errors.New("invalid pin code")
+
+// Rekey re-encrypts the datastore in repoPath with newPin. An empty oldPin means the
+// datastore is not encrypted, and an empty newPin removes encryption.
+// The datastore is exported to a new file, which only replaces the original after
+// the account is verified to decrypt with newPin. The datastore must not be in use.
+func Rekey(repoPath string, oldPin string, newPin string) error {
+	dbPath := path.Join(repoPath, "datastore", "mainnet.db")
+	tmpPath := dbPath + ".rekey"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+
+	address, err := accountAddress(dbPath, oldPin)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open("sqlite3", dsn(dbPath, oldPin))
+	if err != nil {
+		return err
+	}
+	// attached databases are per-connection
+	conn.SetMaxOpenConns(1)
+	err = exportKeyed(conn, tmpPath, newPin)
+	conn.Close()
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	// verify before replacing
+	rekeyed, err := accountAddress(tmpPath, newPin)
+	if err != nil || rekeyed != address {
+		os.RemoveAll(tmpPath)
+		return errors.New("rekeyed datastore failed verification")
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		os.Remove(dbPath + suffix)
+	}
+
+	log.Info("rekeyed datastore")
+
+	return nil
+}
+
+// exportKeyed copies the datastore open on conn to dbPath, encrypted with pin.
+// The path and pin are bound so that quotes in either can't break the statement.
+func exportKeyed(conn *sql.DB, dbPath string, pin string) error {
+	if _, err := conn.Exec("attach database ? as rekeyed key ?;", dbPath, pin); err != nil {
+		return err
+	}
+	if _, err := conn.Exec("select sqlcipher_export('rekeyed');"); err != nil {
+		conn.Exec("detach database rekeyed;")
+		return err
+	}
+	_, err := conn.Exec("detach database rekeyed;")
+	return err
+}
+
+// accountAddress opens a datastore file and returns the address of its account
+func accountAddress(dbPath string, pin string) (string, error) {
+	conn, err := sql.Open("sqlite3", dsn(dbPath, pin))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	accnt, err := NewConfigStore(conn, new(sync.Mutex), dbPath).GetAccount()
+	if err != nil || accnt == nil {
+		return "", ErrInvalidPin
+	}
+	return accnt.Address(), nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/textileio/textile-go/wallet"
+)
+
+func setupRekeyDB(t *testing.T, pin string) (string, string) {
+	dir, err := ioutil.TempDir("", "textile-rekey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(path.Join(dir, "datastore"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Create(dir, pin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.config.Init(pin); err != nil {
+		t.Fatal(err)
+	}
+	w, err := wallet.NewWallet(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accnt, err := w.AccountAt(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.config.Configure(accnt, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return dir, accnt.Address()
+}
+
+func checkRekeyDB(t *testing.T, dir string, pin string, address string) {
+	store, err := Create(dir, pin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	accnt, err := store.config.GetAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accnt == nil || accnt.Address() != address {
+		t.Error("account did not survive rekey")
+	}
+}
+
+func TestRekey_PlaintextToEncrypted(t *testing.T) {
+	dir, address := setupRekeyDB(t, "")
+	defer os.RemoveAll(dir)
+
+	if err := Rekey(dir, "", "letmein"); err != nil {
+		t.Fatal(err)
+	}
+	checkRekeyDB(t, dir, "letmein", address)
+
+	if _, err := accountAddress(path.Join(dir, "datastore", "mainnet.db"), ""); err != ErrInvalidPin {
+		t.Error("datastore should not open without a pin")
+	}
+}
+
+func TestRekey_EncryptedToEncrypted(t *testing.T) {
+	dir, address := setupRekeyDB(t, "letmein")
+	defer os.RemoveAll(dir)
+
+	if err := Rekey(dir, "letmein", "letmeout"); err != nil {
+		t.Fatal(err)
+	}
+	checkRekeyDB(t, dir, "letmeout", address)
+
+	if _, err := accountAddress(path.Join(dir, "datastore", "mainnet.db"), "letmein"); err != ErrInvalidPin {
+		t.Error("datastore should not open with the old pin")
+	}
+}
+
+func TestRekey_QuotedPin(t *testing.T) {
+	dir, address := setupRekeyDB(t, "")
+	defer os.RemoveAll(dir)
+
+	pin := "it's'; drop table config; --"
+	if err := Rekey(dir, "", pin); err != nil {
+		t.Fatal(err)
+	}
+	checkRekeyDB(t, dir, pin, address)
+
+	if err := Rekey(dir, pin, "letmein"); err != nil {
+		t.Fatal(err)
+	}
+	checkRekeyDB(t, dir, "letmein", address)
+}
+
+func TestRekey_WrongPin(t *testing.T) {
+	dir, address := setupRekeyDB(t, "letmein")
+	defer os.RemoveAll(dir)
+
+	if err := Rekey(dir, "wrong", "letmeout"); err != ErrInvalidPin {
+		t.Errorf("expected invalid pin error, got %v", err)
+	}
+	checkRekeyDB(t, dir, "letmein", address)
+}
+
+func TestSQLiteDatastore_Export(t *testing.T) {
+	dir, address := setupRekeyDB(t, "letmein")
+	defer os.RemoveAll(dir)
+
+	store, err := Create(dir, "letmein")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	exported := path.Join(dir, "exported.db")
+	if err := store.Export(exported); err != nil {
+		t.Fatal(err)
+	}
+
+	// the copy keeps the key
+	got, err := accountAddress(exported, "letmein")
+	if err != nil || got != address {
+		t.Error("account did not survive export")
+	}
+	if _, err := accountAddress(exported, ""); err != ErrInvalidPin {
+		t.Error("exported datastore should not open without a pin")
+	}
+}
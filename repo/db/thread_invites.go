@@ -14,18 +14,19 @@ type ThreadInviteDB struct {
 }
 
 func NewThreadInviteStore(db *sql.DB, lock *sync.Mutex) repo.ThreadInviteStore {
-	return &ThreadInviteDB{modelStore{db, lock}}
+	return &ThreadInviteDB{newModelStore("thread_invites", db, lock)}
 }
 
 func (c *ThreadInviteDB) Add(invite *repo.ThreadInvite) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into thread_invites(id, block, name, contact, date) values(?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -53,9 +54,8 @@ func (c *ThreadInviteDB) Add(invite *repo.ThreadInvite) error {
 }
 
 func (c *ThreadInviteDB) Get(id string) *repo.ThreadInvite {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from thread_invites where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from thread_invites where id=?;", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -63,25 +63,25 @@ func (c *ThreadInviteDB) Get(id string) *repo.ThreadInvite {
 }
 
 func (c *ThreadInviteDB) List() []repo.ThreadInvite {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.handleQuery("select * from thread_invites order by date desc;")
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from thread_invites order by date desc;"))
 }
 
 func (c *ThreadInviteDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from thread_invites where id=?", id)
+	_, err := c.exec("delete from thread_invites where id=?", id)
 	return err
 }
 
-func (c *ThreadInviteDB) handleQuery(stm string) []repo.ThreadInvite {
+func (c *ThreadInviteDB) handleRows(rows *sql.Rows, err error) []repo.ThreadInvite {
 	var ret []repo.ThreadInvite
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, name string
 		var block, contactb []byte
@@ -105,5 +105,6 @@ func (c *ThreadInviteDB) handleQuery(stm string) []repo.ThreadInvite {
 			Date:    time.Unix(0, dateInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"strconv"
 	"sync"
 	"time"
 
@@ -16,18 +15,19 @@ type ThreadMessageDB struct {
 }
 
 func NewThreadMessageStore(db *sql.DB, lock *sync.Mutex) repo.ThreadMessageStore {
-	return &ThreadMessageDB{modelStore{db, lock}}
+	return &ThreadMessageDB{newModelStore("thread_messages", db, lock)}
 }
 
 func (c *ThreadMessageDB) Add(msg *repo.ThreadMessage) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into thread_messages(id, peerId, envelope, date) values(?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -53,31 +53,44 @@ func (c *ThreadMessageDB) Add(msg *repo.ThreadMessage) error {
 }
 
 func (c *ThreadMessageDB) List(offset string, limit int) []repo.ThreadMessage {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	var stm string
+	defer c.observe("List", time.Now())
 	if offset != "" {
-		stm = "select * from thread_messages where date>(select date from thread_messages where id='" + offset + "') order by date asc limit " + strconv.Itoa(limit) + ";"
-	} else {
-		stm = "select * from thread_messages order by date asc limit " + strconv.Itoa(limit) + ";"
+		return c.handleRows(c.queryStatic("select * from thread_messages where date>(select date from thread_messages where id=?) order by date asc limit ?;", offset, limit))
 	}
-	return c.handleQuery(stm)
+	return c.handleRows(c.queryStatic("select * from thread_messages order by date asc limit ?;", limit))
+}
+
+func (c *ThreadMessageDB) ListByPeer(peerId string, offset string, limit int) []repo.ThreadMessage {
+	defer c.observe("ListByPeer", time.Now())
+	if offset != "" {
+		return c.handleRows(c.queryStatic("select * from thread_messages where peerId=? and date>(select date from thread_messages where id=?) order by date asc limit ?;", peerId, offset, limit))
+	}
+	return c.handleRows(c.queryStatic("select * from thread_messages where peerId=? order by date asc limit ?;", peerId, limit))
+}
+
+func (c *ThreadMessageDB) Count() int {
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from thread_messages;")
+	var count int
+	row.Scan(&count)
+	return count
 }
 
 func (c *ThreadMessageDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from thread_messages where id=?", id)
+	_, err := c.exec("delete from thread_messages where id=?", id)
 	return err
 }
 
-func (c *ThreadMessageDB) handleQuery(stm string) []repo.ThreadMessage {
+func (c *ThreadMessageDB) handleRows(rows *sql.Rows, err error) []repo.ThreadMessage {
 	var ret []repo.ThreadMessage
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, peerId string
 		var dateInt int64
@@ -99,5 +112,6 @@ func (c *ThreadMessageDB) handleQuery(stm string) []repo.ThreadMessage {
 			Date:     time.Unix(0, dateInt),
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
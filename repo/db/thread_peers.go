@@ -2,7 +2,9 @@ package db
 
 import (
 	"database/sql"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/textileio/textile-go/repo"
 )
@@ -12,27 +14,34 @@ type ThreadPeerDB struct {
 }
 
 func NewThreadPeerStore(db *sql.DB, lock *sync.Mutex) repo.ThreadPeerStore {
-	return &ThreadPeerDB{modelStore{db, lock}}
+	return &ThreadPeerDB{newModelStore("thread_peers", db, lock)}
 }
 
 func (c *ThreadPeerDB) Add(peer *repo.ThreadPeer) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
-	stm := `insert into thread_peers(id, threadId, welcomed) values(?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stm := `insert into thread_peers(id, threadId, welcomed, lastSeen, addrs) values(?,?,?,?,?)`
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
 	}
 	defer stmt.Close()
+	var lastSeen int64
+	if !peer.LastSeen.IsZero() {
+		lastSeen = peer.LastSeen.UnixNano()
+	}
 	_, err = stmt.Exec(
 		peer.Id,
 		peer.ThreadId,
 		false,
+		lastSeen,
+		strings.Join(peer.Addrs, ","),
 	)
 	if err != nil {
 		tx.Rollback()
@@ -43,87 +52,97 @@ func (c *ThreadPeerDB) Add(peer *repo.ThreadPeer) error {
 }
 
 func (c *ThreadPeerDB) List() []repo.ThreadPeer {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from thread_peers;"
-	return c.handleQuery(stm)
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from thread_peers;"))
 }
 
 func (c *ThreadPeerDB) ListById(id string) []repo.ThreadPeer {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from thread_peers where id='" + id + "';"
-	return c.handleQuery(stm)
+	defer c.observe("ListById", time.Now())
+	return c.handleRows(c.queryStatic("select * from thread_peers where id=?;", id))
 }
 
 func (c *ThreadPeerDB) ListByThread(threadId string) []repo.ThreadPeer {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from thread_peers where threadId='" + threadId + "';"
-	return c.handleQuery(stm)
+	defer c.observe("ListByThread", time.Now())
+	return c.handleRows(c.queryStatic("select * from thread_peers where threadId=?;", threadId))
 }
 
 func (c *ThreadPeerDB) ListUnwelcomedByThread(threadId string) []repo.ThreadPeer {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	stm := "select * from thread_peers where threadId='" + threadId + "' and welcomed=0;"
-	return c.handleQuery(stm)
+	defer c.observe("ListUnwelcomedByThread", time.Now())
+	return c.handleRows(c.queryStatic("select * from thread_peers where threadId=? and welcomed=0;", threadId))
 }
 
 func (c *ThreadPeerDB) WelcomeByThread(threadId string) error {
-	c.lock.Lock()
+	defer c.observe("WelcomeByThread", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update thread_peers set welcomed=1 where threadId=?", threadId)
+	_, err := c.exec("update thread_peers set welcomed=1 where threadId=?", threadId)
 	return err
 }
 
-func (c *ThreadPeerDB) Count(distinct bool) int {
-	c.lock.Lock()
+// UpdateLastSeen records when a peer was last seen in all of its threads.
+// Known addresses are kept if none are given.
+func (c *ThreadPeerDB) UpdateLastSeen(id string, date time.Time, addrs []string) error {
+	defer c.observe("UpdateLastSeen", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
+	if len(addrs) == 0 {
+		_, err := c.exec("update thread_peers set lastSeen=? where id=?", date.UnixNano(), id)
+		return err
+	}
+	_, err := c.exec("update thread_peers set lastSeen=?, addrs=? where id=?", date.UnixNano(), strings.Join(addrs, ","), id)
+	return err
+}
+
+func (c *ThreadPeerDB) Count(distinct bool) int {
+	defer c.observe("Count", time.Now())
 	var stm string
 	if distinct {
 		stm = "select Count(distinct id) from thread_peers;"
 	} else {
 		stm = "select Count(*) from thread_peers;"
 	}
-	row := c.db.QueryRow(stm)
+	row := c.queryRow(stm)
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *ThreadPeerDB) Delete(id string, threadId string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from thread_peers where id=? and threadId=?", id, threadId)
+	_, err := c.exec("delete from thread_peers where id=? and threadId=?", id, threadId)
 	return err
 }
 
 func (c *ThreadPeerDB) DeleteById(id string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteById", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from thread_peers where id=?", id)
+	_, err := c.exec("delete from thread_peers where id=?", id)
 	return err
 }
 
 func (c *ThreadPeerDB) DeleteByThread(threadId string) error {
-	c.lock.Lock()
+	defer c.observe("DeleteByThread", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from thread_peers where threadId=?", threadId)
+	_, err := c.exec("delete from thread_peers where threadId=?", threadId)
 	return err
 }
 
-func (c *ThreadPeerDB) handleQuery(stm string) []repo.ThreadPeer {
+func (c *ThreadPeerDB) handleRows(rows *sql.Rows, err error) []repo.ThreadPeer {
 	var ret []repo.ThreadPeer
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
-		var id, threadId string
+		var id, threadId, addrs string
 		var welcomedInt int
-		if err := rows.Scan(&id, &threadId, &welcomedInt); err != nil {
+		var lastSeenInt int64
+		if err := rows.Scan(&id, &threadId, &welcomedInt, &lastSeenInt, &addrs); err != nil {
 			log.Errorf("error in db scan: %s", err)
 			continue
 		}
@@ -131,11 +150,24 @@ func (c *ThreadPeerDB) handleQuery(stm string) []repo.ThreadPeer {
 		if welcomedInt == 1 {
 			welcomed = true
 		}
+		var lastSeen time.Time
+		if lastSeenInt > 0 {
+			lastSeen = time.Unix(0, lastSeenInt)
+		}
+		alist := make([]string, 0)
+		for _, a := range strings.Split(addrs, ",") {
+			if a != "" {
+				alist = append(alist, a)
+			}
+		}
 		ret = append(ret, repo.ThreadPeer{
 			Id:       id,
 			ThreadId: threadId,
 			Welcomed: welcomed,
+			LastSeen: lastSeen,
+			Addrs:    alist,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
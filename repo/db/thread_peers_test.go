@@ -4,12 +4,13 @@ import (
 	"database/sql"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ksuid"
 	"github.com/textileio/textile-go/repo"
 )
 
-var threadPeerStore repo.ThreadPeerStore
+var threadPeerStore *ThreadPeerDB
 
 func init() {
 	setupThreadPeerDB()
@@ -18,7 +19,7 @@ func init() {
 func setupThreadPeerDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	threadPeerStore = NewThreadPeerStore(conn, new(sync.Mutex))
+	threadPeerStore = NewThreadPeerStore(conn, new(sync.Mutex)).(*ThreadPeerDB)
 }
 
 func TestThreadPeerDB_Add(t *testing.T) {
@@ -30,7 +31,7 @@ func TestThreadPeerDB_Add(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadPeerStore.PrepareQuery("select id from thread_peers where id=?")
+	stmt, err := threadPeerStore.db.Prepare("select id from thread_peers where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("abc").Scan(&id)
@@ -130,6 +131,42 @@ func TestThreadPeerDB_Count(t *testing.T) {
 	}
 }
 
+func TestThreadPeerDB_UpdateLastSeen(t *testing.T) {
+	setupThreadPeerDB()
+	for _, tid := range []string{"1", "2"} {
+		if err := threadPeerStore.Add(&repo.ThreadPeer{
+			Id:       "seen",
+			ThreadId: tid,
+		}); err != nil {
+			t.Error(err)
+		}
+	}
+	list := threadPeerStore.ListById("seen")
+	if len(list) != 2 || !list[0].LastSeen.IsZero() || len(list[0].Addrs) != 0 {
+		t.Error("new peers should not be seen")
+		return
+	}
+	now := time.Now()
+	addrs := []string{"/ip4/127.0.0.1/tcp/4001", "/ip4/192.168.1.2/tcp/4001"}
+	if err := threadPeerStore.UpdateLastSeen("seen", now, addrs); err != nil {
+		t.Error(err)
+		return
+	}
+	later := now.Add(time.Minute)
+	if err := threadPeerStore.UpdateLastSeen("seen", later, nil); err != nil {
+		t.Error(err)
+		return
+	}
+	for _, p := range threadPeerStore.ListById("seen") {
+		if !p.LastSeen.Equal(later) {
+			t.Errorf("wrong last seen for thread %s: %s", p.ThreadId, p.LastSeen)
+		}
+		if len(p.Addrs) != 2 || p.Addrs[1] != addrs[1] {
+			t.Errorf("wrong addrs for thread %s: %v", p.ThreadId, p.Addrs)
+		}
+	}
+}
+
 func TestThreadPeerDB_Delete(t *testing.T) {
 	err := threadPeerStore.Add(&repo.ThreadPeer{
 		Id:       "car",
@@ -143,7 +180,7 @@ func TestThreadPeerDB_Delete(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadPeerStore.PrepareQuery("select id from thread_peers where id=?")
+	stmt, err := threadPeerStore.db.Prepare("select id from thread_peers where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("car").Scan(&id)
@@ -157,7 +194,7 @@ func TestThreadPeerDB_DeleteById(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadPeerStore.PrepareQuery("select id from thread_peers where id=?")
+	stmt, err := threadPeerStore.db.Prepare("select id from thread_peers where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("bar").Scan(&id)
@@ -171,7 +208,7 @@ func TestThreadPeerDB_DeleteByThread(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadPeerStore.PrepareQuery("select id from thread_peers where id=?")
+	stmt, err := threadPeerStore.db.Prepare("select id from thread_peers where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("bar2").Scan(&id)
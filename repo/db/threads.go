@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"sync"
+	"time"
 
 	"github.com/textileio/textile-go/repo"
 )
@@ -12,18 +13,19 @@ type ThreadDB struct {
 }
 
 func NewThreadStore(db *sql.DB, lock *sync.Mutex) repo.ThreadStore {
-	return &ThreadDB{modelStore{db, lock}}
+	return &ThreadDB{newModelStore("threads", db, lock)}
 }
 
 func (c *ThreadDB) Add(thread *repo.Thread) error {
-	c.lock.Lock()
+	defer c.observe("Add", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
 	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 	stm := `insert into threads(id, key, sk, name, schema, initiator, type, state, head) values(?,?,?,?,?,?,?,?,?)`
-	stmt, err := tx.Prepare(stm)
+	stmt, err := c.txPrepared(tx, stm)
 	if err != nil {
 		log.Errorf("error in tx prepare: %s", err)
 		return err
@@ -49,9 +51,8 @@ func (c *ThreadDB) Add(thread *repo.Thread) error {
 }
 
 func (c *ThreadDB) Get(id string) *repo.Thread {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from threads where id='" + id + "';")
+	defer c.observe("Get", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from threads where id=?;", id))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -59,9 +60,8 @@ func (c *ThreadDB) Get(id string) *repo.Thread {
 }
 
 func (c *ThreadDB) GetByKey(key string) *repo.Thread {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	ret := c.handleQuery("select * from threads where key='" + key + "';")
+	defer c.observe("GetByKey", time.Now())
+	ret := c.handleRows(c.queryStatic("select * from threads where key=?;", key))
 	if len(ret) == 0 {
 		return nil
 	}
@@ -69,41 +69,49 @@ func (c *ThreadDB) GetByKey(key string) *repo.Thread {
 }
 
 func (c *ThreadDB) List() []repo.Thread {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.handleQuery("select * from threads;")
+	defer c.observe("List", time.Now())
+	return c.handleRows(c.queryStatic("select * from threads;"))
 }
 
 func (c *ThreadDB) Count() int {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	row := c.db.QueryRow("select Count(*) from threads;")
+	defer c.observe("Count", time.Now())
+	row := c.queryRow("select Count(*) from threads;")
 	var count int
 	row.Scan(&count)
 	return count
 }
 
 func (c *ThreadDB) UpdateHead(id string, head string) error {
-	c.lock.Lock()
+	defer c.observe("UpdateHead", time.Now())
+	c.lockWrite()
+	defer c.lock.Unlock()
+	_, err := c.exec("update threads set head=? where id=?", head, id)
+	return err
+}
+
+func (c *ThreadDB) UpdateKey(id string, key string) error {
+	defer c.observe("UpdateKey", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("update threads set head=? where id=?", head, id)
+	_, err := c.exec("update threads set key=? where id=?", key, id)
 	return err
 }
 
 func (c *ThreadDB) Delete(id string) error {
-	c.lock.Lock()
+	defer c.observe("Delete", time.Now())
+	c.lockWrite()
 	defer c.lock.Unlock()
-	_, err := c.db.Exec("delete from threads where id=?", id)
+	_, err := c.exec("delete from threads where id=?", id)
 	return err
 }
 
-func (c *ThreadDB) handleQuery(stm string) []repo.Thread {
+func (c *ThreadDB) handleRows(rows *sql.Rows, err error) []repo.Thread {
 	var ret []repo.Thread
-	rows, err := c.db.Query(stm)
 	if err != nil {
 		log.Errorf("error in db query: %s", err)
 		return nil
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var id, key, name, schema, initiator, head string
 		var skb []byte
@@ -124,5 +132,6 @@ func (c *ThreadDB) handleQuery(stm string) []repo.Thread {
 			Head:      head,
 		})
 	}
+	c.observeRows(len(ret))
 	return ret
 }
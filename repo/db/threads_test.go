@@ -9,7 +9,7 @@ import (
 	"github.com/textileio/textile-go/repo"
 )
 
-var threadStore repo.ThreadStore
+var threadStore *ThreadDB
 
 func init() {
 	setupThreadDB()
@@ -18,7 +18,7 @@ func init() {
 func setupThreadDB() {
 	conn, _ := sql.Open("sqlite3", ":memory:")
 	initDatabaseTables(conn, "")
-	threadStore = NewThreadStore(conn, new(sync.Mutex))
+	threadStore = NewThreadStore(conn, new(sync.Mutex)).(*ThreadDB)
 }
 
 func TestThreadDB_Add(t *testing.T) {
@@ -35,7 +35,7 @@ func TestThreadDB_Add(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadStore.PrepareQuery("select id from threads where id=?")
+	stmt, err := threadStore.db.Prepare("select id from threads where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow("Qmabc123").Scan(&id)
@@ -152,6 +152,31 @@ func TestThreadDB_UpdateHead(t *testing.T) {
 	}
 }
 
+func TestThreadDB_UpdateKey(t *testing.T) {
+	setupThreadDB()
+	err := threadStore.Add(&repo.Thread{
+		Id:        "Qmdef",
+		Key:       ksuid.New().String(),
+		PrivKey:   make([]byte, 8),
+		Name:      "boom",
+		Schema:    "Qm...",
+		Initiator: "123",
+		Type:      repo.PrivateThread,
+		State:     repo.ThreadLoaded,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	err = threadStore.UpdateKey("Qmdef", "newkey")
+	if err != nil {
+		t.Error(err)
+	}
+	th := threadStore.GetByKey("newkey")
+	if th == nil || th.Id != "Qmdef" {
+		t.Error("update key failed")
+	}
+}
+
 func TestThreadDB_Delete(t *testing.T) {
 	setupThreadDB()
 	err := threadStore.Add(&repo.Thread{
@@ -176,7 +201,7 @@ func TestThreadDB_Delete(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	stmt, err := threadStore.PrepareQuery("select id from threads where id=?")
+	stmt, err := threadStore.db.Prepare("select id from threads where id=?")
 	defer stmt.Close()
 	var id string
 	err = stmt.QueryRow(all[0].Id).Scan(&id)
@@ -24,8 +24,9 @@ var ErrRepoExists = errors.New("repo not empty, reinitializing would overwrite y
 var ErrRepoDoesNotExist = errors.New("repo does not exist, initialization is required")
 var ErrMigrationRequired = errors.New("repo needs migration")
 var ErrRepoCorrupted = errors.New("repo is corrupted")
+var ErrRepoLocked = errors.New("repo is locked by another process, stop the daemon first")
 
-const repover = "8"
+const repover = "14"
 
 func Init(repoPath string, version string) error {
 	if err := checkWriteable(repoPath); err != nil {
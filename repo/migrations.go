@@ -1,19 +1,57 @@
 package repo
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	m "github.com/textileio/textile-go/repo/migrations"
 )
 
+// maxDatastoreBackups is the number of pre-migration datastore backups kept around
+const maxDatastoreBackups = 3
+
+// ErrInvalidMigrationTarget indicates a migration target version is out of range
+var ErrInvalidMigrationTarget = errors.New("invalid migration target version")
+
+// ErrCannotMigrateDown indicates a down migration would cross a major migration
+var ErrCannotMigrateDown = errors.New("cannot migrate down past a major migration")
+
 // Migration performs minor up and down migrations
 type Migration interface {
 	Up(repoPath string, pinCode string, testnet bool) error
 	Down(repoPath string, pinCode string, testnet bool) error
 	Major() bool
+	Description() string
+}
+
+// MigrationStep describes a single planned migration
+type MigrationStep struct {
+	From        int    `json:"from"`
+	To          int    `json:"to"`
+	Down        bool   `json:"down,omitempty"`
+	Major       bool   `json:"major,omitempty"`
+	Description string `json:"description"`
+}
+
+// String returns a readable version of the step
+func (s MigrationStep) String() string {
+	dir := "up"
+	if s.Down {
+		dir = "down"
+	}
+	desc := s.Description
+	if s.Major {
+		desc += " (major)"
+	}
+	return fmt.Sprintf("%s %d -> %d: %s", dir, s.From, s.To, desc)
 }
 
 // minors are current minor migrations that need to be run for lower repovers
@@ -26,6 +64,22 @@ var migrations = []Migration{
 	m.Major005{},
 	m.Minor006{},
 	m.Minor007{},
+	m.Minor008{},
+	m.Minor009{},
+	m.Minor010{},
+	m.Minor011{},
+	m.Minor012{},
+	m.Minor013{},
+}
+
+// LatestVersion returns the repo version reached after all migrations
+func LatestVersion() int {
+	return len(migrations)
+}
+
+// Version returns the version of the repo at path
+func Version(repoPath string) (int, error) {
+	return version(repoPath)
 }
 
 // Stat returns whether or not there's a major migration ahead of the current repover
@@ -45,28 +99,169 @@ func Stat(repoPath string) error {
 	return nil
 }
 
+// PlanMigration lists the steps needed to move the repo at path to the target version
+func PlanMigration(repoPath string, to int) ([]MigrationStep, error) {
+	repover, err := version(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) < repover {
+		return nil, ErrRepoCorrupted
+	}
+	if to < 0 || to > len(migrations) {
+		return nil, ErrInvalidMigrationTarget
+	}
+
+	steps := make([]MigrationStep, 0)
+	for x := repover; x < to; x++ {
+		steps = append(steps, MigrationStep{
+			From:        x,
+			To:          x + 1,
+			Major:       migrations[x].Major(),
+			Description: migrations[x].Description(),
+		})
+	}
+	for x := repover; x > to; x-- {
+		migration := migrations[x-1]
+		if migration.Major() {
+			return nil, ErrCannotMigrateDown
+		}
+		steps = append(steps, MigrationStep{
+			From:        x,
+			To:          x - 1,
+			Down:        true,
+			Description: migration.Description(),
+		})
+	}
+	return steps, nil
+}
+
 // MigrateUp applies minor migrations all the way up to current
 func MigrateUp(repoPath string, pinCode string, testnet bool) error {
-	repover, err := version(repoPath)
+	return MigrateTo(repoPath, pinCode, testnet, len(migrations))
+}
+
+// MigrateTo applies up or down migrations until the repo reaches the target version.
+// The datastore is backed up before any changes are made.
+func MigrateTo(repoPath string, pinCode string, testnet bool, to int) error {
+	steps, err := PlanMigration(repoPath, to)
 	if err != nil {
 		return err
 	}
-	if len(migrations) < repover {
-		return ErrRepoCorrupted
+	if len(steps) == 0 {
+		return nil
 	}
-	x := repover
-	for _, migration := range migrations[repover:] {
-		log.Infof("migrating repo to version %d...", x+1)
-		err := migration.Up(repoPath, pinCode, testnet)
+
+	backup, err := backupDatastore(repoPath, steps[0].From)
+	if err != nil {
+		log.Errorf("error backing up datastore: %s", err)
+		return err
+	}
+	log.Infof("backed up datastore to %s", backup)
+	if err := pruneDatastoreBackups(repoPath, maxDatastoreBackups); err != nil {
+		log.Warningf("error pruning datastore backups: %s", err)
+	}
+
+	for _, step := range steps {
+		log.Infof("migrating repo to version %d...", step.To)
+		if step.Down {
+			err = migrations[step.To].Down(repoPath, pinCode, testnet)
+		} else {
+			err = migrations[step.From].Up(repoPath, pinCode, testnet)
+		}
 		if err != nil {
-			log.Errorf("error migrating repo to version %d: %s", x+1, err)
+			log.Errorf("error migrating repo to version %d: %s", step.To, err)
 			return err
 		}
-		x++
 	}
 	return nil
 }
 
+// backupDatastore copies the datastore directory aside, returning the backup path
+func backupDatastore(repoPath string, repover int) (string, error) {
+	src := path.Join(repoPath, "datastore")
+	dst := path.Join(repoPath, "backups", fmt.Sprintf("datastore-%d-%d", repover, time.Now().Unix()))
+
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		if f.IsDir() || f.Name() == "LOCK" {
+			continue
+		}
+		if err := copyFile(path.Join(src, f.Name()), path.Join(dst, f.Name())); err != nil {
+			return "", err
+		}
+	}
+	return dst, nil
+}
+
+// pruneDatastoreBackups removes all but the newest keep datastore backups
+func pruneDatastoreBackups(repoPath string, keep int) error {
+	dir := path.Join(repoPath, "backups")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var backups []os.FileInfo
+	for _, f := range files {
+		if f.IsDir() && strings.HasPrefix(f.Name(), "datastore-") {
+			backups = append(backups, f)
+		}
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backupTime(backups[i].Name()) > backupTime(backups[j].Name())
+	})
+	for _, f := range backups[keep:] {
+		if err := os.RemoveAll(path.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupTime returns the unix time a datastore backup was taken from its name
+func backupTime(name string) int64 {
+	parts := strings.Split(name, "-")
+	ts, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return ts
+}
+
+// copyFile copies a single file
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
 // version returns repo at path's version int
 func version(repoPath string) (int, error) {
 	version, err := ioutil.ReadFile(path.Join(repoPath, "repover"))
@@ -75,7 +270,7 @@ func version(repoPath string) (int, error) {
 	} else if err != nil && os.IsNotExist(err) {
 		version = []byte("0")
 	}
-	v, err := strconv.Atoi(string(version[0]))
+	v, err := strconv.Atoi(strings.TrimSpace(string(version)))
 	if err != nil {
 		return 0, err
 	}
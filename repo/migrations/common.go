@@ -1,9 +1,12 @@
 package migrations
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"strings"
 )
 
@@ -35,3 +38,52 @@ func blastRepo(repoPath string) error {
 func conflictError(err error) bool {
 	return strings.Contains(err.Error(), "UNIQUE constraint failed")
 }
+
+// openDatastore opens the repo's sqlite datastore. The key is part of the dsn
+// so that it applies to every connection in the pool. Callers close the db.
+func openDatastore(repoPath string, pinCode string, testnet bool) (*sql.DB, error) {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	if pinCode != "" {
+		params := url.Values{}
+		params.Set("_pragma_key", pinCode)
+		dbPath += "?" + params.Encode()
+	}
+	return sql.Open("sqlite3", dbPath)
+}
+
+// writeVersion overwrites the repo version file
+func writeVersion(repoPath string, version string) error {
+	f, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write([]byte(version))
+	return err
+}
+
+// rebuildTable recreates a table from schema (which should include indexes),
+// copying over the given columns. sqlite cannot drop columns, so this is how they are removed.
+func rebuildTable(db *sql.DB, table string, schema string, columns string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+    create table %[1]s_tmp as select %[2]s from %[1]s;
+    drop table %[1]s;
+    %[3]s
+    insert into %[1]s (%[2]s) select %[2]s from %[1]s_tmp;
+    drop table %[1]s_tmp;
+    `, table, columns, schema)
+	if _, err := tx.Exec(query); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
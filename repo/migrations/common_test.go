@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+type reversible interface {
+	Up(repoPath string, pinCode string, testnet bool) error
+	Down(repoPath string, pinCode string, testnet bool) error
+}
+
+var upDownTests = []struct {
+	name      string
+	migration reversible
+	init      func(db *sql.DB, pin string) error
+	version   string // repover after going back down
+	present   string // must work against the restored schema
+	absent    string // must fail against the restored schema
+}{
+	{"006", Minor006{}, initAt005, "6", "select value from profile where key='username'", ""},
+	{"007", Minor007{}, initAt006, "7", "select inviter from thread_invites", "select contact from thread_invites"},
+	{"008", Minor008{}, initAt007, "8", "select address from contacts where id='test'", "select verified from contacts"},
+	{"009", Minor009{}, nil, "9", "", "select * from blocked_peers"},
+	{"010", Minor010{}, nil, "10", "", "select * from devices"},
+	{"011", Minor011{}, initAt010, "11", "select verified from contacts where id='test'", "select endpoint from contacts"},
+	{"012", Minor012{}, initAt011, "12", "select welcomed from thread_peers where id='test'", "select lastSeen from thread_peers"},
+	{"013", Minor013{}, initAt012, "13", "select endpoint from contacts where id='test'", "select signature from contacts"},
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	for _, test := range upDownTests {
+		os.Mkdir("./datastore", os.ModePerm)
+		db, err := sql.Open("sqlite3", path.Join("./", "datastore", "mainnet.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.init != nil {
+			if err := test.init(db, ""); err != nil {
+				t.Errorf("%s: init: %s", test.name, err)
+			}
+		}
+
+		if err := test.migration.Up("./", "", false); err != nil {
+			t.Errorf("%s: up: %s", test.name, err)
+		}
+		if err := test.migration.Down("./", "", false); err != nil {
+			t.Errorf("%s: down: %s", test.name, err)
+		}
+
+		version, err := ioutil.ReadFile("./repover")
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		} else if string(version) != test.version {
+			t.Errorf("%s: expected version %s got %s", test.name, test.version, string(version))
+		}
+		if test.present != "" {
+			if _, err := db.Exec(test.present); err != nil {
+				t.Errorf("%s: schema not restored: %s", test.name, err)
+			}
+		}
+		if test.absent != "" {
+			if _, err := db.Exec(test.absent); err == nil {
+				t.Errorf("%s: schema change not reverted", test.name)
+			}
+		}
+
+		db.Close()
+		os.RemoveAll("./datastore")
+		os.RemoveAll("./repover")
+		os.RemoveAll("./config")
+	}
+}
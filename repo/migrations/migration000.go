@@ -56,6 +56,7 @@ func (Minor000) Up(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
 
+// Down is a no-op, migrations before Major005 can't be reverted
 func (Minor000) Down(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
@@ -63,3 +64,7 @@ func (Minor000) Down(repoPath string, pinCode string, testnet bool) error {
 func (Minor000) Major() bool {
 	return false
 }
+
+func (Minor000) Description() string {
+	return "add encrypted username column to blocks"
+}
@@ -56,6 +56,7 @@ func (Minor001) Up(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
 
+// Down is a no-op, migrations before Major005 can't be reverted
 func (Minor001) Down(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
@@ -63,3 +64,7 @@ func (Minor001) Down(repoPath string, pinCode string, testnet bool) error {
 func (Minor001) Major() bool {
 	return false
 }
+
+func (Minor001) Description() string {
+	return "add encrypted metadata column to blocks"
+}
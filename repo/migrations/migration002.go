@@ -62,6 +62,7 @@ func (Minor002) Up(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
 
+// Down is a no-op, migrations before Major005 can't be reverted
 func (Minor002) Down(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
@@ -69,3 +70,7 @@ func (Minor002) Down(repoPath string, pinCode string, testnet bool) error {
 func (Minor002) Major() bool {
 	return false
 }
+
+func (Minor002) Description() string {
+	return "add notifications table"
+}
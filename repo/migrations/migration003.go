@@ -66,6 +66,7 @@ func (Minor003) Up(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
 
+// Down is a no-op, migrations before Major005 can't be reverted
 func (Minor003) Down(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
@@ -73,3 +74,7 @@ func (Minor003) Down(repoPath string, pinCode string, testnet bool) error {
 func (Minor003) Major() bool {
 	return false
 }
+
+func (Minor003) Description() string {
+	return "add actor username and category columns to notifications"
+}
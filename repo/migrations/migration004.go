@@ -75,6 +75,7 @@ func (Minor004) Up(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
 
+// Down is a no-op, migrations before Major005 can't be reverted
 func (Minor004) Down(repoPath string, pinCode string, testnet bool) error {
 	return nil
 }
@@ -82,3 +83,7 @@ func (Minor004) Down(repoPath string, pinCode string, testnet bool) error {
 func (Minor004) Major() bool {
 	return false
 }
+
+func (Minor004) Description() string {
+	return "recreate notifications table with subjects"
+}
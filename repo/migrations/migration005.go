@@ -192,3 +192,7 @@ func (Major005) Down(repoPath string, pinCode string, testnet bool) error {
 func (Major005) Major() bool {
 	return true
 }
+
+func (Major005) Description() string {
+	return "export threads and default photos, then reset the repo"
+}
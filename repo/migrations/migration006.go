@@ -175,9 +175,57 @@ func (Minor006) Up(repoPath string, pinCode string, testnet bool) error {
 }
 
 func (Minor006) Down(repoPath string, pinCode string, testnet bool) error {
-	return nil
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// get peer id from IPFS config
+	configPath := path.Join(repoPath, "config")
+	jsonFile, err := os.Open(configPath)
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
+	var config native.Config
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	if err := json.Unmarshal(byteValue, &config); err != nil {
+		return err
+	}
+
+	// add profile table back
+	if _, err := db.Exec("create table profile (key text primary key not null, value blob);"); err != nil {
+		return err
+	}
+
+	// move username and avatar from the contact for self
+	var username, avatar string
+	row := db.QueryRow("select username, avatar from contacts where id=?;", config.Identity.PeerID)
+	if err := row.Scan(&username, &avatar); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if username != "" {
+		if _, err := db.Exec("insert into profile(key, value) values(?,?)", "username", []byte(username)); err != nil {
+			return err
+		}
+	}
+	if avatar != "" {
+		if _, err := db.Exec("insert into profile(key, value) values(?,?)", "avatar", []byte("/ipfs/"+avatar)); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec("delete from contacts where id=?", config.Identity.PeerID); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "6")
 }
 
 func (Minor006) Major() bool {
 	return false
 }
+
+func (Minor006) Description() string {
+	return "move profile into a contact for self"
+}
@@ -54,9 +54,29 @@ func (Minor007) Up(repoPath string, pinCode string, testnet bool) error {
 }
 
 func (Minor007) Down(repoPath string, pinCode string, testnet bool) error {
-	return nil
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// invites were dropped on the way up, so they're dropped on the way down too
+	query := `
+    drop table thread_invites;
+    create table thread_invites (id text primary key not null, block blob not null, name text not null, inviter text not null, date integer not null);
+    create index thread_invite_date on thread_invites (date);
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "7")
 }
 
 func (Minor007) Major() bool {
 	return false
 }
+
+func (Minor007) Description() string {
+	return "recreate thread invites table with contact"
+}
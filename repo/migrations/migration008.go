@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path"
+
+	_ "github.com/mutecomm/go-sqlcipher"
+)
+
+type Minor008 struct{}
+
+func (Minor008) Up(repoPath string, pinCode string, testnet bool) error {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	if pinCode != "" {
+		if _, err := db.Exec("pragma key='" + pinCode + "';"); err != nil {
+			return err
+		}
+	}
+
+	// add verified column to contacts
+	query := `
+    alter table contacts add column verified integer not null default 0;
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// update version
+	f9, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f9.Close()
+	if _, err = f9.Write([]byte("9")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (Minor008) Down(repoPath string, pinCode string, testnet bool) error {
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// remove verified column from contacts
+	schema := `
+    create table contacts (id text primary key not null, address text not null, username text not null, avatar text not null, inboxes blob not null, created integer not null, updated integer not null);
+    create index contact_address on contacts (address);
+    create index contact_username on contacts (username);
+    create index contact_updated on contacts (updated);
+    `
+	columns := "id, address, username, avatar, inboxes, created, updated"
+	if err := rebuildTable(db, "contacts", schema, columns); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "8")
+}
+
+func (Minor008) Major() bool {
+	return false
+}
+
+func (Minor008) Description() string {
+	return "add verified column to contacts"
+}
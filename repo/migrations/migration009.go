@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path"
+
+	_ "github.com/mutecomm/go-sqlcipher"
+)
+
+type Minor009 struct{}
+
+func (Minor009) Up(repoPath string, pinCode string, testnet bool) error {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	if pinCode != "" {
+		if _, err := db.Exec("pragma key='" + pinCode + "';"); err != nil {
+			return err
+		}
+	}
+
+	// add blocked peers table
+	query := `
+    create table blocked_peers (id text primary key not null, muted integer not null, date integer not null);
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// update version
+	f10, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f10.Close()
+	if _, err = f10.Write([]byte("10")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (Minor009) Down(repoPath string, pinCode string, testnet bool) error {
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// delete blocked peers table
+	if _, err := db.Exec("drop table blocked_peers;"); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "9")
+}
+
+func (Minor009) Major() bool {
+	return false
+}
+
+func (Minor009) Description() string {
+	return "add blocked peers table"
+}
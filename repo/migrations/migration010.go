@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path"
+
+	_ "github.com/mutecomm/go-sqlcipher"
+)
+
+type Minor010 struct{}
+
+func (Minor010) Up(repoPath string, pinCode string, testnet bool) error {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	if pinCode != "" {
+		if _, err := db.Exec("pragma key='" + pinCode + "';"); err != nil {
+			return err
+		}
+	}
+
+	// add devices table
+	query := `
+    create table devices (id text primary key not null, name text not null, pending integer not null, date integer not null);
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// update version
+	f11, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f11.Close()
+	if _, err = f11.Write([]byte("11")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (Minor010) Down(repoPath string, pinCode string, testnet bool) error {
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// delete devices table
+	if _, err := db.Exec("drop table devices;"); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "10")
+}
+
+func (Minor010) Major() bool {
+	return false
+}
+
+func (Minor010) Description() string {
+	return "add devices table"
+}
@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path"
+
+	_ "github.com/mutecomm/go-sqlcipher"
+)
+
+type Minor012 struct{}
+
+func (Minor012) Up(repoPath string, pinCode string, testnet bool) error {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	if pinCode != "" {
+		if _, err := db.Exec("pragma key='" + pinCode + "';"); err != nil {
+			return err
+		}
+	}
+
+	// add presence columns to thread peers
+	query := `
+    alter table thread_peers add column lastSeen integer not null default 0;
+    alter table thread_peers add column addrs text not null default '';
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// update version
+	f13, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f13.Close()
+	if _, err = f13.Write([]byte("13")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (Minor012) Down(repoPath string, pinCode string, testnet bool) error {
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// remove presence columns from thread peers
+	schema := `
+    create table thread_peers (id text not null, threadId text not null, welcomed integer not null, primary key (id, threadId));
+    create index thread_peer_id on thread_peers (id);
+    create index thread_peer_threadId on thread_peers (threadId);
+    create index thread_peer_welcomed on thread_peers (welcomed);
+    `
+	columns := "id, threadId, welcomed"
+	if err := rebuildTable(db, "thread_peers", schema, columns); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "12")
+}
+
+func (Minor012) Major() bool {
+	return false
+}
+
+func (Minor012) Description() string {
+	return "add last seen and addrs columns to thread peers"
+}
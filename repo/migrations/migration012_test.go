@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func initAt011(db *sql.DB, pin string) error {
+	var sqlStmt string
+	if pin != "" {
+		sqlStmt = "PRAGMA key = '" + pin + "';"
+	}
+	sqlStmt += `
+    create table thread_peers (id text not null, threadId text not null, welcomed integer not null, primary key (id, threadId));
+    create index thread_peer_id on thread_peers (id);
+    `
+	_, err := db.Exec(sqlStmt)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("insert into thread_peers(id, threadId, welcomed) values(?,?,?)", "test", "thread", 1)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func Test012(t *testing.T) {
+	var dbPath string
+	os.Mkdir("./datastore", os.ModePerm)
+	dbPath = path.Join("./", "datastore", "mainnet.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err := initAt011(db, ""); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// go up
+	var m Minor012
+	if err := m.Up("./", "", false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// test new column defaults on the existing row
+	var lastSeen int64
+	var addrs string
+	if err := db.QueryRow("select lastSeen, addrs from thread_peers where id=?", "test").Scan(&lastSeen, &addrs); err != nil {
+		t.Error(err)
+		return
+	}
+	if lastSeen != 0 || addrs != "" {
+		t.Error("existing thread peer should not be seen")
+		return
+	}
+
+	// ensure that version file was updated
+	version, err := ioutil.ReadFile("./repover")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(version) != "13" {
+		t.Error("failed to write new repo version")
+		return
+	}
+
+	if err := m.Down("./", "", false); err != nil {
+		t.Error(err)
+		return
+	}
+	os.RemoveAll("./datastore")
+	os.RemoveAll("./repover")
+}
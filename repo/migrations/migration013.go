@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path"
+
+	_ "github.com/mutecomm/go-sqlcipher"
+)
+
+type Minor013 struct{}
+
+func (Minor013) Up(repoPath string, pinCode string, testnet bool) error {
+	var dbPath string
+	if testnet {
+		dbPath = path.Join(repoPath, "datastore", "testnet.db")
+	} else {
+		dbPath = path.Join(repoPath, "datastore", "mainnet.db")
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	if pinCode != "" {
+		if _, err := db.Exec("pragma key='" + pinCode + "';"); err != nil {
+			return err
+		}
+	}
+
+	// add signature column to contacts
+	query := `
+    alter table contacts add column signature blob;
+    `
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// update version
+	f14, err := os.Create(path.Join(repoPath, "repover"))
+	if err != nil {
+		return err
+	}
+	defer f14.Close()
+	if _, err = f14.Write([]byte("14")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (Minor013) Down(repoPath string, pinCode string, testnet bool) error {
+	db, err := openDatastore(repoPath, pinCode, testnet)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// remove signature column from contacts
+	schema := `
+    create table contacts (id text primary key not null, address text not null, username text not null, avatar text not null, inboxes blob not null, created integer not null, updated integer not null, verified integer not null default 0, endpoint text not null default '');
+    create index contact_address on contacts (address);
+    create index contact_username on contacts (username);
+    create index contact_updated on contacts (updated);
+    `
+	columns := "id, address, username, avatar, inboxes, created, updated, verified, endpoint"
+	if err := rebuildTable(db, "contacts", schema, columns); err != nil {
+		return err
+	}
+
+	return writeVersion(repoPath, "13")
+}
+
+func (Minor013) Major() bool {
+	return false
+}
+
+func (Minor013) Description() string {
+	return "add signature column to contacts"
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+var migrationsRepoPath = "testdata/.migrations"
+
+func TestPlanMigration_ToZero(t *testing.T) {
+	os.RemoveAll(migrationsRepoPath)
+	if err := os.MkdirAll(migrationsRepoPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(migrationsRepoPath)
+	if err := ioutil.WriteFile(path.Join(migrationsRepoPath, "repover"), []byte("2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := PlanMigration(migrationsRepoPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || !steps[0].Down || steps[1].To != 0 {
+		t.Errorf("expected two down steps to version 0, got %v", steps)
+	}
+}
+
+func TestPruneDatastoreBackups(t *testing.T) {
+	os.RemoveAll(migrationsRepoPath)
+	defer os.RemoveAll(migrationsRepoPath)
+	dir := path.Join(migrationsRepoPath, "backups")
+	for i := 1; i <= 5; i++ {
+		if err := os.MkdirAll(path.Join(dir, fmt.Sprintf("datastore-%d-%d", i, 1000+i)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneDatastoreBackups(migrationsRepoPath, 2); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(files))
+	}
+	for _, f := range files {
+		if f.Name() != "datastore-4-1004" && f.Name() != "datastore-5-1005" {
+			t.Errorf("expected newest backups to be kept, found %s", f.Name())
+		}
+	}
+}
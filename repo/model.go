@@ -98,9 +98,11 @@ type ThreadInvite struct {
 }
 
 type ThreadPeer struct {
-	Id       string `json:"id"`
-	ThreadId string `json:"thread_id"`
-	Welcomed bool   `json:"welcomed"`
+	Id       string    `json:"id"`
+	ThreadId string    `json:"thread_id"`
+	Welcomed bool      `json:"welcomed"`
+	LastSeen time.Time `json:"last_seen"`
+	Addrs    []string  `json:"addrs,omitempty"`
 }
 
 type ThreadMessage struct {
@@ -134,6 +136,8 @@ const (
 	FilesBlock
 	CommentBlock
 	LikeBlock
+	DeviceBlock
+	SnapshotBlock
 )
 
 func (b BlockType) Description() string {
@@ -158,19 +162,26 @@ func (b BlockType) Description() string {
 		return "COMMENT"
 	case LikeBlock:
 		return "LIKE"
+	case DeviceBlock:
+		return "DEVICE"
+	case SnapshotBlock:
+		return "SNAPSHOT"
 	default:
 		return "INVALID"
 	}
 }
 
 type Contact struct {
-	Id       string    `json:"id"`
-	Address  string    `json:"address"`
-	Username string    `json:"username,omitempty"`
-	Avatar   string    `json:"avatar,omitempty"`
-	Inboxes  []Cafe    `json:"inboxes,omitempty"`
-	Created  time.Time `json:"created"`
-	Updated  time.Time `json:"updated"`
+	Id        string    `json:"id"`
+	Address   string    `json:"address"`
+	Username  string    `json:"username,omitempty"`
+	Avatar    string    `json:"avatar,omitempty"`
+	Inboxes   []Cafe    `json:"inboxes,omitempty"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Verified  bool      `json:"verified,omitempty"`
+	Endpoint  string    `json:"endpoint,omitempty"`
+	Signature []byte    `json:"signature,omitempty"`
 }
 
 type Notification struct {
@@ -197,6 +208,8 @@ const (
 	FilesAddedNotification
 	CommentAddedNotification
 	LikeAddedNotification
+	ContactChangedNotification
+	DeviceLinkRequestNotification
 )
 
 func (n NotificationType) Description() string {
@@ -217,6 +230,10 @@ func (n NotificationType) Description() string {
 		return "COMMENT_ADDED"
 	case LikeAddedNotification:
 		return "LIKE_ADDED"
+	case ContactChangedNotification:
+		return "CONTACT_CHANGED"
+	case DeviceLinkRequestNotification:
+		return "DEVICE_LINK_REQUEST"
 	default:
 		return "INVALID"
 	}
@@ -294,3 +311,16 @@ type CafeClientMessage struct {
 	ClientId string    `json:"client_id"`
 	Date     time.Time `json:"date"`
 }
+
+type BlockedPeer struct {
+	Id    string    `json:"id"`
+	Muted bool      `json:"muted,omitempty"` // only notifications are suppressed
+	Date  time.Time `json:"date"`
+}
+
+type Device struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Pending bool      `json:"pending,omitempty"` // link requested, not yet approved
+	Date    time.Time `json:"date"`
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/metrics"
+	"github.com/textileio/textile-go/pb"
+)
+
+// ErrRateLimited indicates a peer sent too many messages of a type
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrPeerBanned indicates a peer is temporarily banned
+var ErrPeerBanned = errors.New("peer is banned")
+
+// limiterSweepInterval is how often idle buckets and expired bans are dropped
+const limiterSweepInterval = time.Minute
+
+var (
+	limitedTotal = metrics.NewCounter(
+		"textile_service_limited_total",
+		"Number of inbound messages dropped by rate limits.",
+		"type")
+	verifyFailuresTotal = metrics.NewCounter(
+		"textile_service_verify_failures_total",
+		"Number of inbound envelopes which failed verification.")
+	bansTotal = metrics.NewCounter(
+		"textile_service_bans_total",
+		"Number of peers banned for repeated verification failures.")
+)
+
+// LimitRate is a token bucket rate
+type LimitRate struct {
+	Rate  float64 // tokens added per second
+	Burst int     // bucket size
+}
+
+// LimiterConfig configures a Limiter
+type LimiterConfig struct {
+	Default      LimitRate                     // rate used for types without an override, zero rate disables
+	Types        map[pb.Message_Type]LimitRate // per message type overrides
+	BanThreshold int                           // verify failures within BanWindow before a ban, 0 disables
+	BanWindow    time.Duration                 // window in which verify failures are counted
+	BanDuration  time.Duration                 // how long a ban lasts
+}
+
+// LimiterStats holds limiter counters
+type LimiterStats struct {
+	Limited        map[string]int `json:"limited"`
+	VerifyFailures int            `json:"verify_failures"`
+	Bans           int            `json:"bans"`
+	Banned         []Ban          `json:"banned"`
+}
+
+// Ban is a temporarily banned peer
+type Ban struct {
+	Id     string    `json:"id"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// Limiter applies token bucket limits per peer and message type, and
+// temporarily bans peers which repeatedly send envelopes that fail verification.
+// A nil Limiter allows everything.
+type Limiter struct {
+	conf     LimiterConfig
+	buckets  map[bucketKey]*bucket
+	failures map[peer.ID][]time.Time
+	bans     map[peer.ID]time.Time
+	stats    LimiterStats
+	swept    time.Time
+	mux      sync.Mutex
+}
+
+type bucketKey struct {
+	pid   peer.ID
+	mtype pb.Message_Type
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   LimitRate
+}
+
+// NewLimiter returns a limiter for the given config
+func NewLimiter(conf LimiterConfig) *Limiter {
+	return &Limiter{
+		conf:     conf,
+		buckets:  make(map[bucketKey]*bucket),
+		failures: make(map[peer.ID][]time.Time),
+		bans:     make(map[peer.ID]time.Time),
+		stats:    LimiterStats{Limited: make(map[string]int)},
+		swept:    time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket for a peer and message type,
+// returning false if the bucket is empty
+func (l *Limiter) Allow(pid peer.ID, mtype pb.Message_Type) bool {
+	if l == nil {
+		return true
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	rate, ok := l.conf.Types[mtype]
+	if !ok {
+		rate = l.conf.Default
+	}
+	if rate.Rate <= 0 {
+		return true
+	}
+
+	key := bucketKey{pid: pid, mtype: mtype}
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: float64(rate.Burst), last: now, rate: rate}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		l.stats.Limited[mtype.String()]++
+		limitedTotal.Inc(mtype.String())
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Fail records a verify failure for a peer, returning true if the peer is now banned
+func (l *Limiter) Fail(pid peer.ID) bool {
+	if l == nil {
+		return false
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.stats.VerifyFailures++
+	verifyFailuresTotal.Inc()
+	if l.conf.BanThreshold <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	recent := []time.Time{now}
+	for _, t := range l.failures[pid] {
+		if now.Sub(t) < l.conf.BanWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) < l.conf.BanThreshold {
+		l.failures[pid] = recent
+		return false
+	}
+
+	delete(l.failures, pid)
+	l.bans[pid] = now.Add(l.conf.BanDuration)
+	l.stats.Bans++
+	bansTotal.Inc()
+	log.Warningf("banned %s for %s after %d verify failures", pid.Pretty(), l.conf.BanDuration, len(recent))
+	return true
+}
+
+// Banned returns whether or not a peer is currently banned
+func (l *Limiter) Banned(pid peer.ID) bool {
+	if l == nil {
+		return false
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	expiry, ok := l.bans[pid]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiry) {
+		delete(l.bans, pid)
+		return false
+	}
+	return true
+}
+
+// Unban lifts a peer's ban
+func (l *Limiter) Unban(pid peer.ID) {
+	if l == nil {
+		return
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	delete(l.bans, pid)
+	delete(l.failures, pid)
+}
+
+// Stats returns a copy of the limiter counters and current bans
+func (l *Limiter) Stats() LimiterStats {
+	stats := LimiterStats{Limited: make(map[string]int), Banned: make([]Ban, 0)}
+	if l == nil {
+		return stats
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for mtype, count := range l.stats.Limited {
+		stats.Limited[mtype] = count
+	}
+	stats.VerifyFailures = l.stats.VerifyFailures
+	stats.Bans = l.stats.Bans
+	now := time.Now()
+	for pid, expiry := range l.bans {
+		if now.Before(expiry) {
+			stats.Banned = append(stats.Banned, Ban{Id: pid.Pretty(), Expiry: expiry})
+		}
+	}
+	return stats
+}
+
+// sweep drops full (idle) buckets, stale failures, and expired bans
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < limiterSweepInterval {
+		return
+	}
+	l.swept = now
+
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.rate.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	for pid, times := range l.failures {
+		if len(times) == 0 || now.Sub(times[0]) >= l.conf.BanWindow {
+			delete(l.failures, pid)
+		}
+	}
+	for pid, expiry := range l.bans {
+		if now.After(expiry) {
+			delete(l.bans, pid)
+		}
+	}
+}
+
+// refill adds tokens for the time elapsed since the last refill
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate.Rate
+	if b.tokens > float64(b.rate.Burst) {
+		b.tokens = float64(b.rate.Burst)
+	}
+	b.last = now
+}
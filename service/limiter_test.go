@@ -0,0 +1,103 @@
+package service
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	libp2pc "gx/ipfs/QmPvyPwuCgJ7pDmrKDxRtsScJgBaM5h4EpRL2qQJsmXf4n/go-libp2p-crypto"
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+
+	"github.com/textileio/textile-go/pb"
+)
+
+var limiterTestPeer = peer.ID("QmTestPeer")
+
+func TestLimiter_Allow(t *testing.T) {
+	l := NewLimiter(LimiterConfig{
+		Default: LimitRate{Rate: 1, Burst: 2},
+		Types: map[pb.Message_Type]LimitRate{
+			pb.Message_CAFE_CONTACT_QUERY: {Rate: 0.001, Burst: 1},
+		},
+	})
+	pid := limiterTestPeer
+	if !l.Allow(pid, pb.Message_PING) || !l.Allow(pid, pb.Message_PING) {
+		t.Fatal("burst should be allowed")
+	}
+	if l.Allow(pid, pb.Message_PING) {
+		t.Error("empty bucket should not be allowed")
+	}
+	if !l.Allow(pid, pb.Message_CAFE_CONTACT_QUERY) {
+		t.Fatal("first query should be allowed")
+	}
+	if l.Allow(pid, pb.Message_CAFE_CONTACT_QUERY) {
+		t.Error("type override should apply")
+	}
+	stats := l.Stats()
+	if stats.Limited["PING"] != 1 || stats.Limited["CAFE_CONTACT_QUERY"] != 1 {
+		t.Errorf("unexpected limited counts: %v", stats.Limited)
+	}
+}
+
+func TestLimiter_Fail(t *testing.T) {
+	l := NewLimiter(LimiterConfig{
+		BanThreshold: 2,
+		BanWindow:    time.Minute,
+		BanDuration:  time.Minute,
+	})
+	pid := limiterTestPeer
+	if l.Fail(pid) {
+		t.Error("first failure should not ban")
+	}
+	if !l.Fail(pid) || !l.Banned(pid) {
+		t.Fatal("second failure should ban")
+	}
+	stats := l.Stats()
+	if stats.VerifyFailures != 2 || stats.Bans != 1 || len(stats.Banned) != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	l.Unban(pid)
+	if l.Banned(pid) {
+		t.Error("unban failed")
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+	if !l.Allow(limiterTestPeer, pb.Message_PING) || l.Fail(limiterTestPeer) || l.Banned(limiterTestPeer) {
+		t.Error("nil limiter should allow everything")
+	}
+}
+
+func TestService_AdmitClaimed(t *testing.T) {
+	_, pk, err := libp2pc.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Service{limiter: NewLimiter(LimiterConfig{
+		BanThreshold: 1,
+		BanWindow:    time.Minute,
+		BanDuration:  time.Minute,
+	})}
+	env := &pb.Envelope{
+		Message: &pb.Message{Type: pb.Message_PING},
+		Sig:     []byte("forged"),
+	}
+
+	if err := srv.AdmitClaimed(env, pid); err == nil {
+		t.Fatal("forged envelope should not be admitted")
+	}
+	if srv.limiter.Banned(pid) {
+		t.Error("failures for a claimed sender should not ban it")
+	}
+	if err := srv.Admit(env, pid); err == nil {
+		t.Fatal("forged envelope should not be admitted")
+	}
+	if !srv.limiter.Banned(pid) {
+		t.Error("failures for an authenticated sender should ban it")
+	}
+}
@@ -40,9 +40,13 @@ type Service struct {
 	Node    func() *core.IpfsNode
 
 	handler Handler
+	limiter *Limiter
 
 	strmap map[peer.ID]*messageSender
 	smlk   sync.Mutex
+
+	versions map[peer.ID]*PeerVersion
+	vlk      sync.Mutex
 }
 
 // defaultTimeout is the context timeout for sending / requesting messages
@@ -59,48 +63,76 @@ const (
 	PeerOffline PeerStatus = "offline"
 )
 
-// Handler is used to handle messages for a specific protocol
+// Handler is used to handle messages for a specific protocol.
+// Protocol is the base protocol, which doubles as the pubsub topic.
+// Protocols lists the supported stream protocols (newest first), which
+// are negotiated when a stream is opened. Capabilities are optional
+// feature flags exchanged with peers in PING and PONG messages.
+// Handle should return ErrUnsupportedMessage for message types it doesn't handle.
 type Handler interface {
 	Protocol() protocol.ID
+	Protocols() []protocol.ID
+	Capabilities() []string
 	Ping(pid peer.ID) (PeerStatus, error)
 	Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error)
 }
 
-// NewService returns a service for the given config
-func NewService(account *keypair.Full, handler Handler, node func() *core.IpfsNode) *Service {
+// NewService returns a service for the given config.
+// limiter is shared across services and may be nil.
+func NewService(account *keypair.Full, handler Handler, node func() *core.IpfsNode, limiter *Limiter) *Service {
 	return &Service{
-		Account: account,
-		Node:    node,
-		handler: handler,
-		strmap:  make(map[peer.ID]*messageSender),
+		Account:  account,
+		Node:     node,
+		handler:  handler,
+		limiter:  limiter,
+		strmap:   make(map[peer.ID]*messageSender),
+		versions: make(map[peer.ID]*PeerVersion),
 	}
 }
 
-// Start sets the peer host stream handler
+// Start sets the peer host stream handlers
 func (srv *Service) Start() {
-	srv.Node().PeerHost.SetStreamHandler(srv.handler.Protocol(), srv.handleNewStream)
+	for _, pt := range srv.handler.Protocols() {
+		srv.Node().PeerHost.SetStreamHandler(pt, srv.handleNewStream)
+		log.Debugf("registered service: %s", pt)
+	}
 	go srv.listen()
-	log.Debugf("registered service: %s", srv.handler.Protocol())
 }
 
 // Ping pings another peer and returns status
 func (srv *Service) Ping(p peer.ID) (PeerStatus, error) {
-	id := rand.Int31()
-	env, err := srv.NewEnvelope(pb.Message_PING, nil, &id, false)
+	env, err := srv.newPing()
 	if err != nil {
 		return "", err
 	}
 
-	if _, err := srv.SendRequest(p, env); err != nil {
+	res, err := srv.SendRequest(p, env)
+	if err != nil {
 		return PeerOffline, nil
 	}
+	srv.readCapabilities(p, res)
 
 	return PeerOnline, nil
 }
 
+// newPing returns a PING message carrying local capabilities
+func (srv *Service) newPing() (*pb.Envelope, error) {
+	id := rand.Int31()
+	return srv.NewEnvelope(pb.Message_PING, srv.capabilities(), &id)
+}
+
 // SendRequest sends out a request
 func (srv *Service) SendRequest(p peer.ID, pmes *pb.Envelope) (*pb.Envelope, error) {
-	log.Debugf("sending %s to %s", pmes.Message.Type.String(), p.Pretty())
+	done := StartSpan(SpanSendRequest, pmes, p.Pretty())
+	rpmes, err := srv.sendRequest(p, pmes)
+	done(err)
+	return rpmes, err
+}
+
+// sendRequest sends out a request and waits for the response
+func (srv *Service) sendRequest(p peer.ID, pmes *pb.Envelope) (*pb.Envelope, error) {
+	log.Debugf("sending %s to %s (trace %s)", pmes.Message.Type.String(), p.Pretty(), pmes.Message.TraceId)
+	pmes.Request = true
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -130,7 +162,21 @@ func (srv *Service) SendRequest(p peer.ID, pmes *pb.Envelope) (*pb.Envelope, err
 
 // SendHTTPRequest sends a request over HTTP
 func (srv *Service) SendHTTPRequest(addr string, pmes *pb.Envelope) (*pb.Envelope, error) {
-	log.Debugf("sending %s to %s", pmes.Message.Type.String(), addr)
+	return srv.SendHTTPRequestContext(context.Background(), addr, pmes)
+}
+
+// SendHTTPRequestContext sends a request over HTTP, aborting when ctx is done
+func (srv *Service) SendHTTPRequestContext(ctx context.Context, addr string, pmes *pb.Envelope) (*pb.Envelope, error) {
+	done := StartSpan(SpanSendHTTP, pmes, addr)
+	rpmes, err := srv.sendHTTPRequest(ctx, addr, pmes)
+	done(err)
+	return rpmes, err
+}
+
+// sendHTTPRequest sends a request over HTTP and reads the response
+func (srv *Service) sendHTTPRequest(ctx context.Context, addr string, pmes *pb.Envelope) (*pb.Envelope, error) {
+	log.Debugf("sending %s to %s (trace %s)", pmes.Message.Type.String(), addr, pmes.Message.TraceId)
+	pmes.Request = true
 
 	payload, err := proto.Marshal(pmes)
 	if err != nil {
@@ -141,6 +187,7 @@ func (srv *Service) SendHTTPRequest(addr string, pmes *pb.Envelope) (*pb.Envelop
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Set("X-Textile-Peer", srv.Node().Identity.Pretty())
 
 	client := &http.Client{}
@@ -187,7 +234,15 @@ func (srv *Service) SendHTTPRequest(addr string, pmes *pb.Envelope) (*pb.Envelop
 
 // SendMessage sends out a message
 func (srv *Service) SendMessage(ctx context.Context, p peer.ID, pmes *pb.Envelope) error {
-	log.Debugf("sending %s to %s", pmes.Message.Type.String(), p.Pretty())
+	done := StartSpan(SpanSend, pmes, p.Pretty())
+	err := srv.sendMessage(ctx, p, pmes)
+	done(err)
+	return err
+}
+
+// sendMessage sends out a message without waiting for a response
+func (srv *Service) sendMessage(ctx context.Context, p peer.ID, pmes *pb.Envelope) error {
+	log.Debugf("sending %s to %s (trace %s)", pmes.Message.Type.String(), p.Pretty(), pmes.Message.TraceId)
 
 	if ctx == nil {
 		var cancel context.CancelFunc
@@ -209,7 +264,20 @@ func (srv *Service) SendMessage(ctx context.Context, p peer.ID, pmes *pb.Envelop
 
 // SendHTTPMessage sends a message over HTTP
 func (srv *Service) SendHTTPMessage(addr string, pmes *pb.Envelope) error {
-	log.Debugf("sending %s to %s", pmes.Message.Type.String(), addr)
+	return srv.SendHTTPMessageContext(context.Background(), addr, pmes)
+}
+
+// SendHTTPMessageContext sends a message over HTTP, aborting when ctx is done
+func (srv *Service) SendHTTPMessageContext(ctx context.Context, addr string, pmes *pb.Envelope) error {
+	done := StartSpan(SpanSendHTTP, pmes, addr)
+	err := srv.sendHTTPMessage(ctx, addr, pmes)
+	done(err)
+	return err
+}
+
+// sendHTTPMessage sends a message over HTTP without reading a response
+func (srv *Service) sendHTTPMessage(ctx context.Context, addr string, pmes *pb.Envelope) error {
+	log.Debugf("sending %s to %s (trace %s)", pmes.Message.Type.String(), addr, pmes.Message.TraceId)
 
 	payload, err := proto.Marshal(pmes)
 	if err != nil {
@@ -220,6 +288,7 @@ func (srv *Service) SendHTTPMessage(addr string, pmes *pb.Envelope) error {
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Set("X-Textile-Peer", srv.Node().Identity.Pretty())
 
 	client := &http.Client{}
@@ -230,7 +299,7 @@ func (srv *Service) SendHTTPMessage(addr string, pmes *pb.Envelope) error {
 	defer res.Body.Close()
 
 	if res.StatusCode >= 400 {
-		res, err := util.UnmarshalString(req.Body)
+		res, err := util.UnmarshalString(res.Body)
 		if err != nil {
 			return err
 		}
@@ -240,8 +309,36 @@ func (srv *Service) SendHTTPMessage(addr string, pmes *pb.Envelope) error {
 	return nil
 }
 
-// NewEnvelope returns a signed pb message for transport
-func (srv *Service) NewEnvelope(mtype pb.Message_Type, msg proto.Message, id *int32, response bool) (*pb.Envelope, error) {
+// NewEnvelope returns a signed pb message for transport, which starts a new trace
+func (srv *Service) NewEnvelope(mtype pb.Message_Type, msg proto.Message, id *int32) (*pb.Envelope, error) {
+	message, err := newMessage(mtype, msg)
+	if err != nil {
+		return nil, err
+	}
+	if id != nil {
+		message.RequestId = *id
+	}
+	message.TraceId = newTraceId()
+
+	return srv.sign(message)
+}
+
+// NewResponse returns a signed pb message for transport in response to req.
+// The response carries the request id and trace id of req.
+func (srv *Service) NewResponse(mtype pb.Message_Type, msg proto.Message, req *pb.Envelope) (*pb.Envelope, error) {
+	message, err := newMessage(mtype, msg)
+	if err != nil {
+		return nil, err
+	}
+	message.RequestId = req.Message.RequestId
+	message.TraceId = req.Message.TraceId
+	message.IsResponse = true
+
+	return srv.sign(message)
+}
+
+// newMessage returns an unsigned pb message with an optional payload
+func newMessage(mtype pb.Message_Type, msg proto.Message) (*pb.Message, error) {
 	var payload *any.Any
 	if msg != nil {
 		var err error
@@ -250,15 +347,11 @@ func (srv *Service) NewEnvelope(mtype pb.Message_Type, msg proto.Message, id *in
 			return nil, err
 		}
 	}
-	message := &pb.Message{Type: mtype, Payload: payload}
-
-	if id != nil {
-		message.RequestId = *id
-	}
-	if response {
-		message.IsResponse = true
-	}
+	return &pb.Message{Type: mtype, Payload: payload}, nil
+}
 
+// sign returns an envelope with a signed message
+func (srv *Service) sign(message *pb.Message) (*pb.Envelope, error) {
 	ser, err := proto.Marshal(message)
 	if err != nil {
 		return nil, err
@@ -279,11 +372,11 @@ func (srv *Service) NewEnvelope(mtype pb.Message_Type, msg proto.Message, id *in
 }
 
 // NewError returns a signed pb error message
-func (srv *Service) NewError(code int, msg string, id int32) (*pb.Envelope, error) {
-	return srv.NewEnvelope(pb.Message_ERROR, &pb.Error{
+func (srv *Service) NewError(code int, msg string, req *pb.Envelope) (*pb.Envelope, error) {
+	return srv.NewResponse(pb.Message_ERROR, &pb.Error{
 		Code:    uint32(code),
 		Message: msg,
-	}, &id, true)
+	}, req)
 }
 
 // VerifyEnvelope verifies the authenticity of an envelope
@@ -301,6 +394,38 @@ func (srv *Service) VerifyEnvelope(env *pb.Envelope, pid peer.ID) error {
 	return crypto.Verify(pk, ser, env.Sig)
 }
 
+// Admit checks whether or not an inbound envelope should be handled.
+// The sender must not be banned, the envelope must verify, and the sender
+// must be within its rate limit for the message type.
+// Repeated verify failures result in a temporary ban, so pid must be
+// authenticated by the transport, i.e., the remote peer of a stream.
+func (srv *Service) Admit(env *pb.Envelope, pid peer.ID) error {
+	return srv.admit(env, pid, true)
+}
+
+// AdmitClaimed is like Admit for a sender which is only claimed, e.g., by an HTTP
+// header or a pubsub message. Verify failures are not counted against the claimed
+// sender, otherwise anyone could get it banned.
+func (srv *Service) AdmitClaimed(env *pb.Envelope, pid peer.ID) error {
+	return srv.admit(env, pid, false)
+}
+
+func (srv *Service) admit(env *pb.Envelope, pid peer.ID, authenticated bool) error {
+	if srv.limiter.Banned(pid) {
+		return ErrPeerBanned
+	}
+	if err := srv.VerifyEnvelope(env, pid); err != nil {
+		if authenticated {
+			srv.limiter.Fail(pid)
+		}
+		return err
+	}
+	if !srv.limiter.Allow(pid, env.Message.Type) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
 // handleError receives an error response
 func (srv *Service) handleError(env *pb.Envelope) error {
 	if env.Message.Payload == nil && env.Message.Type != pb.Message_PONG {
@@ -316,6 +441,9 @@ func (srv *Service) handleError(env *pb.Envelope) error {
 		if err := ptypes.UnmarshalAny(env.Message.Payload, errMsg); err != nil {
 			return err
 		}
+		if errMsg.Code == http.StatusNotImplemented && errMsg.Message == ErrUnsupportedMessage.Error() {
+			return ErrUnsupportedMessage
+		}
 		return errors.New(errMsg.Message)
 	}
 }
@@ -330,9 +458,10 @@ func (srv *Service) handleCore(mtype pb.Message_Type) func(peer.ID, *pb.Envelope
 	}
 }
 
-// handlePing receives a PING message
+// handlePing receives a PING message, responding with local capabilities
 func (srv *Service) handlePing(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
-	return srv.NewEnvelope(pb.Message_PONG, nil, &env.Message.RequestId, true)
+	srv.readCapabilities(pid, env)
+	return srv.NewResponse(pb.Message_PONG, srv.capabilities(), env)
 }
 
 var dhtReadMessageTimeout = time.Minute
@@ -376,6 +505,13 @@ func (srv *Service) handleNewMessage(s inet.Stream) {
 	w := newBufferedDelimitedWriter(cw)
 	mPeer := s.Conn().RemotePeer()
 
+	if srv.limiter.Banned(mPeer) {
+		s.Reset()
+		log.Debugf("refused stream from banned peer %s", mPeer.Pretty())
+		return
+	}
+	srv.setProtocol(mPeer, s.Protocol())
+
 	for {
 		select {
 		// end loop on context close
@@ -397,7 +533,14 @@ func (srv *Service) handleNewMessage(s inet.Stream) {
 			return
 		}
 
-		if err := srv.VerifyEnvelope(pmes, mPeer); err != nil {
+		switch err := srv.Admit(pmes, mPeer); err {
+		case nil:
+		case ErrPeerBanned, ErrRateLimited:
+			// drop the stream, the sender must open a new one
+			s.Reset()
+			log.Warningf("refused message from %s: %s", mPeer.Pretty(), err)
+			return
+		default:
 			log.Warningf("error verifying message: %s", err)
 			continue
 		}
@@ -409,8 +552,13 @@ func (srv *Service) handleNewMessage(s inet.Stream) {
 			handler = srv.handler.Handle
 		}
 
-		log.Debugf("received %s from %s", pmes.Message.Type.String(), mPeer.Pretty())
+		log.Debugf("received %s from %s (trace %s)", pmes.Message.Type.String(), mPeer.Pretty(), pmes.Message.TraceId)
+		done := StartSpan(SpanHandle, pmes, mPeer.Pretty())
 		rpmes, err := handler(mPeer, pmes)
+		if err == ErrUnsupportedMessage {
+			rpmes, err = srv.handleUnsupported(mPeer, pmes)
+		}
+		done(err)
 		if err != nil {
 			s.Reset()
 			log.Errorf("%s handle message error: %s", pmes.Message.Type.String(), err)
@@ -423,13 +571,15 @@ func (srv *Service) handleNewMessage(s inet.Stream) {
 		}
 
 		// send out response msg
-		log.Debugf("responding with %s to %s", rpmes.Message.Type.String(), mPeer.Pretty())
+		log.Debugf("responding with %s to %s (trace %s)", rpmes.Message.Type.String(), mPeer.Pretty(), rpmes.Message.TraceId)
 
 		// send out response msg
+		done = StartSpan(SpanRespond, rpmes, mPeer.Pretty())
 		err = w.WriteMsg(rpmes)
 		if err == nil {
 			err = w.Flush()
 		}
+		done(err)
 		if err != nil {
 			s.Reset()
 			log.Errorf("send response error: %s", err)
@@ -471,8 +621,8 @@ func (srv *Service) listen() {
 				continue
 			}
 
-			if err := srv.VerifyEnvelope(pmes, mPeer); err != nil {
-				log.Warningf("error verifying message: %s", err)
+			if err := srv.AdmitClaimed(pmes, mPeer); err != nil {
+				log.Warningf("refused pubsub message from %s: %s", mPeer.Pretty(), err)
 				continue
 			}
 
@@ -483,8 +633,15 @@ func (srv *Service) listen() {
 				handler = srv.handler.Handle
 			}
 
-			log.Debugf("received pubsub %s from %s", pmes.Message.Type.String(), mPeer.Pretty())
+			log.Debugf("received pubsub %s from %s (trace %s)", pmes.Message.Type.String(), mPeer.Pretty(), pmes.Message.TraceId)
+			done := StartSpan(SpanHandlePub, pmes, mPeer.Pretty())
 			rpmes, err := handler(mPeer, pmes)
+			if err == ErrUnsupportedMessage {
+				done(nil)
+				log.Debugf("ignoring unsupported pubsub %s from %s", pmes.Message.Type.String(), mPeer.Pretty())
+				continue
+			}
+			done(err)
 			if err != nil {
 				log.Errorf("%s handle message error: %s", pmes.Message.Type.String(), err)
 				continue
@@ -496,7 +653,7 @@ func (srv *Service) listen() {
 			}
 
 			// send out response msg
-			log.Debugf("responding with %s to %s", rpmes.Message.Type.String(), mPeer.Pretty())
+			log.Debugf("responding with %s to %s (trace %s)", rpmes.Message.Type.String(), mPeer.Pretty(), rpmes.Message.TraceId)
 
 			ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 			if err := srv.SendMessage(ctx, mPeer, rpmes); err != nil {
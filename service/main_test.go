@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	pstore "gx/ipfs/QmTTJcDL3gsnGDALjh2fDGg1onGRUdVgNL2hU2WEZcVrMX/go-libp2p-peerstore"
+	"gx/ipfs/QmZNkThpqfVXs9GNbexPrfBbXSLNYeKrE7jwFM2oqHbyqN/go-libp2p-protocol"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/core"
+	"github.com/textileio/textile-go/keypair"
+	"github.com/textileio/textile-go/pb"
+	. "github.com/textileio/textile-go/service"
+)
+
+var oldRepoPath = "testdata/.textile-old"
+var newRepoPath = "testdata/.textile-new"
+
+var oldNode, newNode *core.Textile
+var oldService, newService *Service
+
+const (
+	testProtocolV1 = protocol.ID("/textile/test/1.0.0")
+	testProtocolV2 = protocol.ID("/textile/test/1.1.0")
+)
+
+// testHandler echoes THREAD_ENVELOPE requests.
+// Handlers with the newer protocol also echo CAFE_CONTACT_QUERY requests.
+type testHandler struct {
+	service   *Service
+	protocols []protocol.ID
+	caps      []string
+}
+
+func (h *testHandler) Protocol() protocol.ID {
+	return testProtocolV1
+}
+
+func (h *testHandler) Protocols() []protocol.ID {
+	return h.protocols
+}
+
+func (h *testHandler) Capabilities() []string {
+	return h.caps
+}
+
+func (h *testHandler) Ping(pid peer.ID) (PeerStatus, error) {
+	return h.service.Ping(pid)
+}
+
+func (h *testHandler) Handle(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
+	switch env.Message.Type {
+	case pb.Message_THREAD_ENVELOPE:
+	case pb.Message_CAFE_CONTACT_QUERY:
+		if h.protocols[0] != testProtocolV2 {
+			return nil, ErrUnsupportedMessage
+		}
+	default:
+		return nil, ErrUnsupportedMessage
+	}
+	if !env.Request {
+		return nil, nil
+	}
+	msg := new(pb.ThreadEnvelope)
+	if err := ptypes.UnmarshalAny(env.Message.Payload, msg); err != nil {
+		return nil, err
+	}
+	return h.service.NewResponse(env.Message.Type, msg, env)
+}
+
+func startTestNode(t *testing.T, repoPath string, addrs []string) *core.Textile {
+	os.RemoveAll(repoPath)
+	if err := core.InitRepo(core.InitConfig{
+		Account:     keypair.Random(),
+		RepoPath:    repoPath,
+		ApiAddr:     addrs[0],
+		CafeApiAddr: addrs[1],
+		GatewayAddr: addrs[2],
+	}); err != nil {
+		t.Fatalf("init node failed: %s", err)
+	}
+	node, err := core.NewTextile(core.RunConfig{RepoPath: repoPath})
+	if err != nil {
+		t.Fatalf("create node failed: %s", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node failed: %s", err)
+	}
+	<-node.OnlineCh()
+	return node
+}
+
+func newTestService(node *core.Textile, protocols []protocol.ID, caps []string) *Service {
+	handler := &testHandler{protocols: protocols, caps: caps}
+	handler.service = NewService(nil, handler, node.Ipfs, nil)
+	handler.service.Start()
+	return handler.service
+}
+
+func testRequest(srv *Service, mtype pb.Message_Type) (*pb.Envelope, error) {
+	env, err := srv.NewEnvelope(mtype, &pb.ThreadEnvelope{Thread: "test"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return srv.SendRequest(oldNode.Ipfs().Identity, env)
+}
+
+func TestService_Setup(t *testing.T) {
+	oldNode = startTestNode(t, oldRepoPath, []string{"127.0.0.1:40700", "127.0.0.1:40701", "127.0.0.1:5150"})
+	newNode = startTestNode(t, newRepoPath, []string{"127.0.0.1:40800", "127.0.0.1:40801", "127.0.0.1:5250"})
+
+	oldService = newTestService(oldNode, []protocol.ID{testProtocolV1}, []string{"echo"})
+	newService = newTestService(newNode, []protocol.ID{testProtocolV2, testProtocolV1}, []string{"echo", "query"})
+
+	old := oldNode.Ipfs()
+	if err := newNode.Ipfs().PeerHost.Connect(context.Background(), pstore.PeerInfo{
+		ID:    old.Identity,
+		Addrs: old.PeerHost.Addrs(),
+	}); err != nil {
+		t.Fatalf("connect failed: %s", err)
+	}
+}
+
+func TestService_Version(t *testing.T) {
+	v, err := newService.Version(oldNode.Ipfs().Identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Protocol != testProtocolV1 {
+		t.Errorf("expected negotiated protocol %s, got %s", testProtocolV1, v.Protocol)
+	}
+	if v.Latest != testProtocolV1 {
+		t.Errorf("expected latest protocol %s, got %s", testProtocolV1, v.Latest)
+	}
+	if !v.Supports("echo") || v.Supports("query") {
+		t.Errorf("wrong capabilities: %v", v.Capabilities)
+	}
+
+	// the old peer learned about the new one from the PING
+	if !oldService.Supports(newNode.Ipfs().Identity, "query") {
+		t.Error("old peer should know new peer capabilities")
+	}
+	v, err = oldService.Version(newNode.Ipfs().Identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Latest != testProtocolV2 {
+		t.Errorf("expected latest protocol %s, got %s", testProtocolV2, v.Latest)
+	}
+}
+
+func TestService_UnsupportedRequest(t *testing.T) {
+	if _, err := testRequest(newService, pb.Message_CAFE_CONTACT_QUERY); err != ErrUnsupportedMessage {
+		t.Errorf("expected unsupported message error, got %v", err)
+	}
+
+	// the stream should still be usable
+	res, err := testRequest(newService, pb.Message_THREAD_ENVELOPE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Message.Type != pb.Message_THREAD_ENVELOPE || !res.Message.IsResponse {
+		t.Errorf("wrong response: %s", res.Message.Type.String())
+	}
+}
+
+func TestService_ResponseTrace(t *testing.T) {
+	env, err := newService.NewEnvelope(pb.Message_THREAD_ENVELOPE, &pb.ThreadEnvelope{Thread: "test"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := newService.SendRequest(oldNode.Ipfs().Identity, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Message.TraceId != env.Message.TraceId {
+		t.Errorf("response trace %s does not match request trace %s", res.Message.TraceId, env.Message.TraceId)
+	}
+	if err := newService.VerifyEnvelope(res, oldNode.Ipfs().Identity); err != nil {
+		t.Errorf("response signature does not verify: %s", err)
+	}
+}
+
+func TestService_UnsupportedMessage(t *testing.T) {
+	env, err := newService.NewEnvelope(pb.Message_CAFE_CONTACT_QUERY, &pb.ThreadEnvelope{Thread: "test"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newService.SendMessage(context.Background(), oldNode.Ipfs().Identity, env); err != nil {
+		t.Fatal(err)
+	}
+
+	// no response was written, so the next request gets its own
+	res, err := testRequest(newService, pb.Message_THREAD_ENVELOPE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Message.Type != pb.Message_THREAD_ENVELOPE {
+		t.Errorf("wrong response: %s", res.Message.Type.String())
+	}
+}
+
+func TestService_Teardown(t *testing.T) {
+	oldNode.Stop()
+	newNode.Stop()
+	oldNode = nil
+	newNode = nil
+	os.RemoveAll(oldRepoPath)
+	os.RemoveAll(newRepoPath)
+}
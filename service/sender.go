@@ -22,7 +22,7 @@ type messageSender struct {
 	p  peer.ID
 
 	srv  *Service
-	pt   protocol.ID
+	pts  []protocol.ID
 	reqs map[int32]chan *pb.Envelope
 
 	invalid   bool
@@ -39,7 +39,7 @@ func (srv *Service) messageSenderForPeer(ctx context.Context, p peer.ID) (*messa
 	ms = &messageSender{
 		p:    p,
 		srv:  srv,
-		pt:   srv.handler.Protocol(),
+		pts:  srv.handler.Protocols(),
 		reqs: make(map[int32]chan *pb.Envelope, 2),
 	}
 	srv.strmap[p] = ms
@@ -95,10 +95,12 @@ func (ms *messageSender) prep(ctx context.Context) error {
 		return nil
 	}
 
-	nstr, err := ms.srv.Node().PeerHost.NewStream(ctx, ms.p, ms.pt)
+	// the newest protocol supported by both sides is negotiated
+	nstr, err := ms.srv.Node().PeerHost.NewStream(ctx, ms.p, ms.pts...)
 	if err != nil {
 		return err
 	}
+	ms.srv.setProtocol(ms.p, nstr.Protocol())
 
 	ms.r = ggio.NewDelimitedReader(nstr, inet.MessageSizeMax)
 	ms.w = newBufferedDelimitedWriter(nstr)
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/textileio/textile-go/pb"
+)
+
+// maxTraces is the number of recent traces kept in memory
+const maxTraces = 256
+
+// maxTraceSpans is the number of spans kept per trace
+const maxTraceSpans = 64
+
+// span names
+const (
+	SpanSend        = "send"
+	SpanSendRequest = "send_request"
+	SpanSendHTTP    = "send_http"
+	SpanHandle      = "handle"
+	SpanHandlePub   = "handle_pubsub"
+	SpanHandleHTTP  = "handle_http"
+	SpanRespond     = "respond"
+)
+
+// Span is a timed step in the life of a traced message
+type Span struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Peer     string        `json:"peer"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Trace is the set of local spans for a request and its response
+type Trace struct {
+	Id    string    `json:"id"`
+	Start time.Time `json:"start"`
+	Spans []Span    `json:"spans"`
+}
+
+// tracer keeps the most recent traces
+type tracer struct {
+	traces map[string]*Trace
+	order  []string
+	mux    sync.Mutex
+}
+
+var traces = &tracer{traces: make(map[string]*Trace)}
+
+// Traces returns up to limit recent traces, newest first. A negative limit returns all.
+func Traces(limit int) []Trace {
+	traces.mux.Lock()
+	defer traces.mux.Unlock()
+
+	list := make([]Trace, 0)
+	for i := len(traces.order) - 1; i >= 0; i-- {
+		if limit >= 0 && len(list) >= limit {
+			break
+		}
+		list = append(list, traces.traces[traces.order[i]].copy())
+	}
+	return list
+}
+
+// GetTrace returns a trace by id
+func GetTrace(id string) *Trace {
+	traces.mux.Lock()
+	defer traces.mux.Unlock()
+
+	t, ok := traces.traces[id]
+	if !ok {
+		return nil
+	}
+	cp := t.copy()
+	return &cp
+}
+
+// StartSpan starts a span for a traced message, returning a func which
+// records the span when called. Messages from older peers carry no trace
+// id, their spans are not recorded.
+func StartSpan(name string, env *pb.Envelope, peer string) func(err error) {
+	if env == nil || env.Message == nil || env.Message.TraceId == "" {
+		return func(error) {}
+	}
+	id := env.Message.TraceId
+	span := Span{
+		Name:  name,
+		Type:  env.Message.Type.String(),
+		Peer:  peer,
+		Start: time.Now(),
+	}
+	return func(err error) {
+		span.Duration = time.Since(span.Start)
+		if err != nil {
+			span.Error = err.Error()
+		}
+		traces.add(id, span)
+	}
+}
+
+// add records a span, evicting the oldest trace if needed
+func (r *tracer) add(id string, span Span) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	t, ok := r.traces[id]
+	if !ok {
+		t = &Trace{Id: id, Start: span.Start}
+		r.traces[id] = t
+		r.order = append(r.order, id)
+		if len(r.order) > maxTraces {
+			delete(r.traces, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	if len(t.Spans) >= maxTraceSpans {
+		return
+	}
+	if span.Start.Before(t.Start) {
+		t.Start = span.Start
+	}
+	t.Spans = append(t.Spans, span)
+}
+
+// copy returns a copy of a trace which is safe to hand out
+func (t *Trace) copy() Trace {
+	cp := *t
+	cp.Spans = make([]Span, len(t.Spans))
+	copy(cp.Spans, t.Spans)
+	sort.SliceStable(cp.Spans, func(i, j int) bool {
+		return cp.Spans[i].Start.Before(cp.Spans[j].Start)
+	})
+	return cp
+}
+
+// newTraceId returns a random trace id
+func newTraceId() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/textileio/textile-go/pb"
+)
+
+func testTraceEnvelope(id string) *pb.Envelope {
+	return &pb.Envelope{Message: &pb.Message{Type: pb.Message_PING, TraceId: id}}
+}
+
+func TestStartSpan(t *testing.T) {
+	env := testTraceEnvelope(newTraceId())
+	StartSpan(SpanSendRequest, env, "peer")(nil)
+	StartSpan(SpanHandle, env, "peer")(errors.New("boom"))
+
+	trace := GetTrace(env.Message.TraceId)
+	if trace == nil {
+		t.Fatal("trace not recorded")
+	}
+	if len(trace.Spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(trace.Spans))
+	}
+	if trace.Spans[0].Name != SpanSendRequest || trace.Spans[0].Type != "PING" {
+		t.Errorf("wrong first span: %+v", trace.Spans[0])
+	}
+	if trace.Spans[1].Error != "boom" {
+		t.Errorf("expected span error, got %s", trace.Spans[1].Error)
+	}
+
+	// untraced messages are ignored
+	StartSpan(SpanHandle, testTraceEnvelope(""), "peer")(nil)
+	if GetTrace("") != nil {
+		t.Error("untraced message should not be recorded")
+	}
+}
+
+func TestTraces(t *testing.T) {
+	for i := 0; i < maxTraces+1; i++ {
+		StartSpan(SpanSend, testTraceEnvelope(fmt.Sprintf("trace-%d", i)), "peer")(nil)
+	}
+	if GetTrace("trace-0") != nil {
+		t.Error("oldest trace should be evicted")
+	}
+	list := Traces(-1)
+	if len(list) != maxTraces {
+		t.Fatalf("expected %d traces, got %d", maxTraces, len(list))
+	}
+	if list[0].Id != fmt.Sprintf("trace-%d", maxTraces) {
+		t.Errorf("expected newest trace first, got %s", list[0].Id)
+	}
+	if len(Traces(5)) != 5 {
+		t.Error("limit not applied")
+	}
+}
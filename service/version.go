@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"gx/ipfs/QmTRhk7cgjUf2gfQ3p2M9KPECNZEW9XUrmHcFCgog4cPgB/go-libp2p-peer"
+	"gx/ipfs/QmZNkThpqfVXs9GNbexPrfBbXSLNYeKrE7jwFM2oqHbyqN/go-libp2p-protocol"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/textileio/textile-go/pb"
+)
+
+// ErrUnsupportedMessage is returned by handlers for message types they don't handle
+var ErrUnsupportedMessage = errors.New("unsupported message type")
+
+// PeerVersion describes what is known about a peer's service version
+type PeerVersion struct {
+	Protocol     protocol.ID // last negotiated stream protocol
+	Latest       protocol.ID // newest protocol spoken by the peer, empty for peers which don't send capabilities
+	Capabilities []string    // optional features supported by the peer
+
+	exchanged bool // whether or not a PING / PONG was seen
+}
+
+// Supports returns whether or not the peer advertised a capability flag
+func (v *PeerVersion) Supports(flag string) bool {
+	for _, f := range v.Capabilities {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns what is known about a peer's service version.
+// The peer is pinged if capabilities have not yet been exchanged.
+func (srv *Service) Version(pid peer.ID) (*PeerVersion, error) {
+	if v := srv.version(pid); v != nil && v.exchanged {
+		return v, nil
+	}
+
+	env, err := srv.newPing()
+	if err != nil {
+		return nil, err
+	}
+	res, err := srv.SendRequest(pid, env)
+	if err != nil {
+		return nil, err
+	}
+	srv.readCapabilities(pid, res)
+
+	if v := srv.version(pid); v != nil {
+		return v, nil
+	}
+	return &PeerVersion{}, nil
+}
+
+// Supports returns whether or not a peer is known to support a capability flag.
+// Unlike Version, this does not contact the peer.
+func (srv *Service) Supports(pid peer.ID, flag string) bool {
+	v := srv.version(pid)
+	return v != nil && v.Supports(flag)
+}
+
+// version returns a copy of the known version for a peer, if any
+func (srv *Service) version(pid peer.ID) *PeerVersion {
+	srv.vlk.Lock()
+	defer srv.vlk.Unlock()
+	v, ok := srv.versions[pid]
+	if !ok {
+		return nil
+	}
+	cp := *v
+	return &cp
+}
+
+// setProtocol records the stream protocol negotiated with a peer
+func (srv *Service) setProtocol(pid peer.ID, pt protocol.ID) {
+	srv.vlk.Lock()
+	defer srv.vlk.Unlock()
+	v, ok := srv.versions[pid]
+	if !ok {
+		v = &PeerVersion{}
+		srv.versions[pid] = v
+	}
+	v.Protocol = pt
+}
+
+// setCapabilities records the capabilities a peer sent with a PING or PONG
+func (srv *Service) setCapabilities(pid peer.ID, caps *pb.Capabilities) {
+	srv.vlk.Lock()
+	defer srv.vlk.Unlock()
+	v, ok := srv.versions[pid]
+	if !ok {
+		v = &PeerVersion{}
+		srv.versions[pid] = v
+	}
+	v.Latest = protocol.ID(caps.Protocol)
+	v.Capabilities = caps.Flags
+	v.exchanged = true
+}
+
+// capabilities returns the local capabilities, sent with PING and PONG messages
+func (srv *Service) capabilities() *pb.Capabilities {
+	return &pb.Capabilities{
+		Protocol: string(srv.handler.Protocols()[0]),
+		Flags:    srv.handler.Capabilities(),
+	}
+}
+
+// readCapabilities records capabilities from a PING or PONG payload, if present.
+// Older peers send these messages without a payload.
+func (srv *Service) readCapabilities(pid peer.ID, env *pb.Envelope) {
+	caps := new(pb.Capabilities)
+	if env.Message.Payload == nil {
+		srv.setCapabilities(pid, caps)
+		return
+	}
+	if err := ptypes.UnmarshalAny(env.Message.Payload, caps); err != nil {
+		log.Debugf("error reading capabilities from %s: %s", pid.Pretty(), err)
+		return
+	}
+	srv.setCapabilities(pid, caps)
+}
+
+// handleUnsupported responds to a message type the handler doesn't handle.
+// Senders waiting for a response get an error instead of a timeout, others are ignored.
+func (srv *Service) handleUnsupported(pid peer.ID, env *pb.Envelope) (*pb.Envelope, error) {
+	log.Debugf("ignoring unsupported %s from %s", env.Message.Type.String(), pid.Pretty())
+	if !env.Request {
+		return nil, nil
+	}
+	return srv.NewError(http.StatusNotImplemented, ErrUnsupportedMessage.Error(), env)
+}
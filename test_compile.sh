@@ -20,6 +20,7 @@ go test -coverprofile=jwt.cover.out ./jwt
 
 # repo
 go test -coverprofile=repo.db.cover.out ./repo/db
+go test -coverprofile=repo.db.postgres.cover.out ./repo/db/postgres
 go test -coverprofile=repo.migrations.cover.out ./repo/migrations
 
 # mill
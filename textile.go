@@ -74,6 +74,26 @@ Shows the derived accounts (address/seed pairs) in a wallet.
 `
 }
 
+type repoCmd struct {
+	Rekey repoRekeyCmd `command:"rekey" description:"Change the datastore pin code"`
+	Fsck  cmd.FsckCmd  `command:"fsck" description:"Check (and repair) datastore integrity"`
+}
+
+type repoRekeyCmd struct {
+	RepoPath   string `short:"r" long:"repo-dir" description:"Specify a custom repository path."`
+	PinCode    string `short:"p" long:"pin-code" description:"Specify the current pin code (omit if none was used)."`
+	NewPinCode string `short:"n" long:"new-pin-code" description:"Specify the new pin code (omit to remove encryption)."`
+}
+
+func (x *repoRekeyCmd) Usage() string {
+	return `
+
+Changes the pin code used to encrypt the datastore, or encrypts
+a datastore which was initialized without one.
+The daemon must not be running.
+`
+}
+
 type versionCmd struct{}
 
 type initCmd struct {
@@ -89,6 +109,21 @@ type initCmd struct {
 type migrateCmd struct {
 	RepoPath string `short:"r" long:"repo-dir" description:"Specify a custom repository path."`
 	PinCode  string `short:"p" long:"pin-code" description:"Specify the pin code for datastore encryption (omit of none was used during init)."`
+	To       int    `long:"to" description:"Specify a target repo version, which may be lower than the current version. Defaults to latest." default:"-1"`
+	DryRun   bool   `long:"dry-run" description:"Only report the planned migrations."`
+}
+
+type backupCmd struct {
+	PinCode    string `short:"p" long:"pin-code" description:"Specify the pin code for datastore encryption (omit of none was used during init)."`
+	RepoPath   string `short:"r" long:"repo-dir" description:"Specify a custom repository path."`
+	Output     string `required:"true" short:"o" long:"output" description:"Path to write the backup archive to."`
+	PinnedOnly bool   `long:"pinned-only" description:"Leave out blocks which are not pinned."`
+}
+
+type restoreCmd struct {
+	AccountSeed string `required:"true" short:"s" long:"seed" description:"Account seed used to create the backup."`
+	RepoPath    string `short:"r" long:"repo-dir" description:"Specify a custom repository path."`
+	Input       string `required:"true" short:"i" long:"input" description:"Path to the backup archive."`
 }
 
 type daemonCmd struct {
@@ -122,6 +157,19 @@ func init() {
 		"Migrate the node repo and exit",
 		"Migrate the node repository and exit.",
 		&migrateCmd{})
+	parser.AddCommand("backup",
+		"Back up the node repo and exit",
+		"Write an encrypted archive of the node repository (datastore, IPFS repo, config, and blocks) "+
+			"and exit. The daemon must be stopped. Use 'textile restore' with the same seed to restore it.",
+		&backupCmd{})
+	parser.AddCommand("restore",
+		"Restore the node repo from a backup and exit",
+		"Restore the node repository from an encrypted backup archive and exit.",
+		&restoreCmd{})
+	parser.AddCommand("repo",
+		"Manage the node repo",
+		"Manage the node repository, e.g., change the datastore pin code or check its integrity.",
+		&repoCmd{})
 	parser.AddCommand("daemon",
 		"Start the daemon",
 		"Start a node daemon session.",
@@ -264,16 +312,109 @@ func (x *migrateCmd) Execute(args []string) error {
 		return err
 	}
 
-	if err := core.MigrateRepo(core.MigrateConfig{
+	conf := core.MigrateConfig{
 		PinCode:  x.PinCode,
 		RepoPath: repoPath,
-	}); err != nil {
+	}
+	if x.To >= 0 {
+		conf.To = &x.To
+	}
+	steps, err := core.PlanRepoMigration(conf)
+	if err != nil {
+		return errors.New(fmt.Sprintf("plan migration: %s", err))
+	}
+	if len(steps) == 0 {
+		fmt.Println("Repo is up to date")
+		return nil
+	}
+	for _, step := range steps {
+		fmt.Println(step.String())
+	}
+	if x.DryRun {
+		return nil
+	}
+
+	if err := core.MigrateRepo(conf); err != nil {
 		return errors.New(fmt.Sprintf("migrate repo: %s", err))
 	}
 	fmt.Println("Repo was successfully migrated")
 	return nil
 }
 
+func (x *repoRekeyCmd) Execute(args []string) error {
+	repoPath, err := getRepoPath(x.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	if err := core.RekeyRepo(core.RekeyConfig{
+		RepoPath:   repoPath,
+		PinCode:    x.PinCode,
+		NewPinCode: x.NewPinCode,
+	}); err != nil {
+		return errors.New(fmt.Sprintf("rekey repo: %s", err))
+	}
+	fmt.Println("Datastore was successfully rekeyed")
+	return nil
+}
+
+func (x *backupCmd) Execute(args []string) error {
+	repoPath, err := getRepoPath(x.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(x.Output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := core.BackupRepo(core.BackupConfig{
+		RepoPath:   repoPath,
+		PinCode:    x.PinCode,
+		Writer:     file,
+		PinnedOnly: x.PinnedOnly,
+	}); err != nil {
+		os.Remove(x.Output)
+		return errors.New(fmt.Sprintf("backup repo: %s", err))
+	}
+	fmt.Println("Repo was successfully backed up to " + x.Output)
+	return nil
+}
+
+func (x *restoreCmd) Execute(args []string) error {
+	kp, err := keypair.Parse(x.AccountSeed)
+	if err != nil {
+		return errors.New(fmt.Sprintf("parse account seed failed: %s", err))
+	}
+	accnt, ok := kp.(*keypair.Full)
+	if !ok {
+		return keypair.ErrInvalidKey
+	}
+
+	repoPath, err := getRepoPath(x.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(x.Input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := core.RestoreRepo(core.RestoreConfig{
+		Account:  accnt,
+		RepoPath: repoPath,
+		Reader:   file,
+	}); err != nil {
+		return errors.New(fmt.Sprintf("restore repo: %s", err))
+	}
+	fmt.Println("Repo was successfully restored")
+	return nil
+}
+
 func (x *daemonCmd) Execute(args []string) error {
 	repoPathf, err := getRepoPath(x.RepoPath)
 	if err != nil {